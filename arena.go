@@ -26,6 +26,9 @@
 package libconfig
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"strconv"
 )
 
@@ -101,6 +104,23 @@ func (a *Arena) NewStringBytes(b []byte) *Value {
 	return v
 }
 
+// NewStringBytesNoEscape returns new string value containing b
+// without escaping it.
+//
+// This is faster than NewStringBytes for strings known to be safe.
+// The caller must ensure b contains no '"', '\\' and control chars.
+// Otherwise MarshalTo produces invalid output.
+//
+// The returned string is valid until Reset is called on a.
+func (a *Arena) NewStringBytesNoEscape(b []byte) *Value {
+	v := a.c.getValue()
+	v.t = typeRawString
+	bLen := len(a.b)
+	a.b = append(a.b, b...)
+	v.s = b2s(a.b[bLen:])
+	return v
+}
+
 // NewNumberFloat64 returns new number value containing f.
 //
 // The returned number is valid until Reset is called on a.
@@ -110,6 +130,7 @@ func (a *Arena) NewNumberFloat64(f float64) *Value {
 	bLen := len(a.b)
 	a.b = strconv.AppendFloat(a.b, f, 'g', -1, 64)
 	v.s = b2s(a.b[bLen:])
+	v.o.flags |= flagGenerated
 	return v
 }
 
@@ -122,6 +143,7 @@ func (a *Arena) NewNumberInt(n int) *Value {
 	bLen := len(a.b)
 	a.b = strconv.AppendInt(a.b, int64(n), 10)
 	v.s = b2s(a.b[bLen:])
+	v.o.flags |= flagGenerated
 	return v
 }
 
@@ -132,6 +154,38 @@ func (a *Arena) NewNumberString(s string) *Value {
 	v := a.c.getValue()
 	v.t = TypeNumber
 	v.s = s
+	v.o.flags &^= flagGenerated
+	return v
+}
+
+// NewRawJSON returns new value containing pre-serialized JSON fragment b.
+//
+// The fragment is embedded as is into the output of MarshalTo, so cached
+// or upstream JSON may be passed through without re-parsing or double-escaping.
+// An error is returned if b isn't valid JSON. Use NewRawJSONTrusted
+// for skipping the validation.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) NewRawJSON(b []byte) (*Value, error) {
+	if !json.Valid(b) {
+		return nil, fmt.Errorf("invalid JSON fragment: %q", startEndString(b2s(b)))
+	}
+	return a.NewRawJSONTrusted(b), nil
+}
+
+// NewRawJSONTrusted returns new value containing pre-serialized JSON fragment b
+// without validating it.
+//
+// The caller must ensure b contains valid JSON. Otherwise MarshalTo
+// produces invalid output.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) NewRawJSONTrusted(b []byte) *Value {
+	v := a.c.getValue()
+	v.t = TypeRawJSON
+	bLen := len(a.b)
+	a.b = append(a.b, b...)
+	v.s = b2s(a.b[bLen:])
 	return v
 }
 
@@ -149,3 +203,142 @@ func (a *Arena) NewTrue() *Value {
 func (a *Arena) NewFalse() *Value {
 	return valueFalse
 }
+
+var (
+	valueSize = int(reflect.TypeOf(Value{}).Size())
+	kvSize    = int(reflect.TypeOf(kv{}).Size())
+	ptrSize   = int(reflect.TypeOf((*Value)(nil)).Size())
+)
+
+// Size returns the approximate number of bytes retained by a.
+//
+// The size includes memory for Values, strings and numbers, object entries
+// and array items allocated via a, including memory occupied by values
+// replaced or deleted via Set* and Del calls. Use Compact for reclaiming it.
+func (a *Arena) Size() int {
+	n := cap(a.b) + cap(a.c.vs)*valueSize
+	vs := a.c.vs[:cap(a.c.vs)]
+	for i := range vs {
+		n += cap(vs[i].o.kvs)*kvSize + cap(vs[i].a)*ptrSize
+	}
+	return n
+}
+
+// Garbage returns the approximate number of bytes retained by a,
+// which aren't reachable from v.
+//
+// v must be the only live value allocated via a. Group multiple live
+// values into an array or object before the call.
+func (a *Arena) Garbage(v *Value) int {
+	n := a.Size() - liveSize(v)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Compact copies v into fresh a memory and returns the copy together with
+// the number of reclaimed bytes.
+//
+// This prevents unbounded memory growth for long-lived arenas, which
+// accumulate replaced values via Set* calls. All the values previously
+// allocated via a, including v, cannot be used after the Compact call.
+// v must be the only live value allocated via a.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) Compact(v *Value) (*Value, int) {
+	sizeBefore := a.Size()
+	var fz freezer
+	if v != nil {
+		fz.count(v)
+	}
+	fz.vs = make([]Value, 0, fz.n)
+	fz.b = make([]byte, 0, fz.bLen)
+	var cv *Value
+	if v != nil {
+		cv = fz.copy(v)
+	}
+	a.c.vs = fz.vs
+	a.b = fz.b
+	return cv, sizeBefore - a.Size()
+}
+
+// CopyValue returns a deep copy of src allocated in a.
+//
+// src may be obtained from any Parser or Arena. The returned value doesn't
+// reference src buffers, so it remains valid after the Parser returned src
+// is re-used. This allows composing subtrees from multiple documents
+// into a single document. The returned copy isn't frozen even if src is frozen.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) CopyValue(src *Value) *Value {
+	if src == nil {
+		return nil
+	}
+	switch src.Type() {
+	case TypeObject:
+		v := a.NewObject()
+		src.o.unescapeKeys()
+		for _, kv := range src.o.kvs {
+			appendObjectKV(&v.o, a.copyString(kv.k), a.CopyValue(kv.v))
+		}
+		v.o.keysUnescaped = true
+		v.o.sorted = src.o.sorted
+		v.o.flags |= src.o.flags & flagExtended
+		return v
+	case TypeArray:
+		v := a.NewArray()
+		for _, item := range src.a {
+			v.a = append(v.a, a.CopyValue(item))
+		}
+		return v
+	case TypeString:
+		return a.NewString(src.s)
+	case TypeNumber, TypeRawJSON:
+		v := a.c.getValue()
+		v.t = src.t
+		v.s = a.copyString(src.s)
+		v.o.flags |= src.o.flags & flagGenerated
+		return v
+	case TypeTrue:
+		return valueTrue
+	case TypeFalse:
+		return valueFalse
+	default:
+		return valueNull
+	}
+}
+
+// copyString returns a copy of s allocated in a.
+func (a *Arena) copyString(s string) string {
+	bLen := len(a.b)
+	a.b = append(a.b, s...)
+	return b2s(a.b[bLen:])
+}
+
+// liveSize returns the approximate number of bytes occupied by v
+// after Arena.Compact.
+func liveSize(v *Value) int {
+	if v == nil {
+		return 0
+	}
+	switch v.t {
+	case TypeObject:
+		n := valueSize + len(v.o.kvs)*kvSize
+		for _, kv := range v.o.kvs {
+			n += len(kv.k) + liveSize(kv.v)
+		}
+		return n
+	case TypeArray:
+		n := valueSize + len(v.a)*ptrSize
+		for _, item := range v.a {
+			n += liveSize(item)
+		}
+		return n
+	case TypeString, typeRawString, TypeNumber, TypeRawJSON:
+		return valueSize + len(v.s)
+	default:
+		// Singletons don't occupy arena memory.
+		return 0
+	}
+}
@@ -75,3 +75,160 @@ func testArena(a *Arena) error {
 	}
 	return nil
 }
+
+func TestArenaNewRawJSON(t *testing.T) {
+	var a Arena
+	o := a.NewObject()
+	raw, err := a.NewRawJSON([]byte(`{"cached":[1,"x\"y"],"n":null}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if raw.Type() != TypeRawJSON {
+		t.Fatalf("unexpected type; got %s; want %s", raw.Type(), TypeRawJSON)
+	}
+	o.Set("blob", raw)
+	o.Set("trusted", a.NewRawJSONTrusted([]byte(`"pass-through"`)))
+	o.Set("name", a.NewString("foo"))
+
+	s := o.String()
+	sExpected := `{"blob":{"cached":[1,"x\"y"],"n":null},"trusted":"pass-through","name":"foo"}`
+	if s != sExpected {
+		t.Fatalf("unexpected result; got %s; want %s", s, sExpected)
+	}
+
+	// Raw JSON must survive Clone.
+	if s := o.Clone().String(); s != sExpected {
+		t.Fatalf("unexpected cloned result; got %s; want %s", s, sExpected)
+	}
+
+	for _, b := range []string{``, `{`, `[1,]`, `{"a":1} x`} {
+		if _, err := a.NewRawJSON([]byte(b)); err == nil {
+			t.Fatalf("expecting non-nil error for %q", b)
+		}
+	}
+}
+
+func TestArenaNewStringBytesNoEscape(t *testing.T) {
+	var a Arena
+	b := []byte("foo bar")
+	v := a.NewStringBytesNoEscape(b)
+	b[0] = 'x'
+	if s := v.String(); s != `"foo bar"` {
+		t.Fatalf("unexpected string representation; got %q; want %q", s, `"foo bar"`)
+	}
+	if s := string(v.GetStringBytes()); s != "foo bar" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "foo bar")
+	}
+}
+
+func TestArenaCompact(t *testing.T) {
+	var a Arena
+	v := a.NewObject()
+	v.Set("name", a.NewString("app"))
+	for i := 0; i < 1000; i++ {
+		v.Set("counter", a.NewNumberInt(i))
+		v.Set("message", a.NewString(fmt.Sprintf("message number %d", i)))
+	}
+	items := a.NewArray()
+	items.SetArrayItem(0, a.NewTrue())
+	v.Set("items", items)
+	resultExpected := `{"name":"app","counter":999,"message":"message number 999","items":[true]}`
+
+	garbage := a.Garbage(v)
+	if garbage <= 0 {
+		t.Fatalf("expecting positive garbage size; got %d", garbage)
+	}
+	sizeBefore := a.Size()
+
+	cv, reclaimed := a.Compact(v)
+	if reclaimed <= 0 {
+		t.Fatalf("expecting positive number of reclaimed bytes; got %d", reclaimed)
+	}
+	if sizeAfter := a.Size(); sizeAfter != sizeBefore-reclaimed {
+		t.Fatalf("unexpected size after Compact; got %d; want %d", sizeAfter, sizeBefore-reclaimed)
+	}
+	if g := a.Garbage(cv); g != 0 {
+		t.Fatalf("unexpected garbage after Compact; got %d; want 0", g)
+	}
+	if result := cv.String(); result != resultExpected {
+		t.Fatalf("unexpected result after Compact\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// The arena must remain usable after Compact.
+	cv.Set("extra", a.NewString("value"))
+	resultExpected = `{"name":"app","counter":999,"message":"message number 999","items":[true],"extra":"value"}`
+	if result := cv.String(); result != resultExpected {
+		t.Fatalf("unexpected result after Set\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Compacting nil value releases all the memory.
+	if cv, _ = a.Compact(nil); cv != nil {
+		t.Fatalf("expecting nil value; got %s", cv)
+	}
+	if size := a.Size(); size != 0 {
+		t.Fatalf("unexpected size after compacting nil value; got %d; want 0", size)
+	}
+}
+
+func TestArenaCopyValue(t *testing.T) {
+	var p1, p2 Parser
+	v1, err := p1.Parse(`db = {host = "db\tl"; port = 0x10; tags = ["a", "b"];}; debug = true;`)
+	if err != nil {
+		t.Fatalf("cannot parse the first document: %s", err)
+	}
+	v2, err := p2.Parse(`cache = {ttl = 1.5; "x" = null;};`)
+	if err != nil {
+		t.Fatalf("cannot parse the second document: %s", err)
+	}
+
+	var a Arena
+	v := a.NewObject()
+	v.Set("db", a.CopyValue(v1.Get("db")))
+	v.Set("debug", a.CopyValue(v1.Get("debug")))
+	v.Set("cache", a.CopyValue(v2.Get("cache")))
+	resultExpected := `{"db":{"host":"db\tl","port":0x10,"tags":["a","b"]},"debug":true,"cache":{"ttl":1.5,"\"x\"":null}}`
+	if result := v.String(); result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// The copy must remain valid after the parsers are re-used.
+	if _, err := p1.Parse(`xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx = 1;`); err != nil {
+		t.Fatalf("cannot parse the first document: %s", err)
+	}
+	if _, err := p2.Parse(`yyyyyyyyyyyyyyyyyyyyyyyyyyyyyyyyy = 2;`); err != nil {
+		t.Fatalf("cannot parse the second document: %s", err)
+	}
+	if result := v.String(); result != resultExpected {
+		t.Fatalf("unexpected result after re-using parsers\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Modifying the copy doesn't affect the source.
+	src := MustParse(`a = [1];`).Freeze()
+	cv := a.CopyValue(src)
+	cv.Get("a").SetArrayItem(1, a.NewNumberInt(2))
+	if result := src.String(); result != `{"a":[1]}` {
+		t.Fatalf("unexpected source after modifying the copy: %s", result)
+	}
+	if cv.IsFrozen() {
+		t.Fatalf("the copy mustn't be frozen")
+	}
+	if a.CopyValue(nil) != nil {
+		t.Fatalf("expecting nil copy for nil value")
+	}
+}
+
+func TestArenaResetFlags(t *testing.T) {
+	var a Arena
+	a.NewNumberFloat64(1e6)
+	a.Reset()
+
+	// The value re-used after Reset mustn't be treated as generated.
+	v := a.CopyValue(MustParse(`x = 1e6;`).Get("x"))
+	opts := &MarshalOptions{
+		FloatFormat:          FloatShortest,
+		PreserveNumberTokens: true,
+	}
+	if s := string(v.MarshalToOptions(nil, opts)); s != "1e6" {
+		t.Fatalf("unexpected result; got %q; want %q", s, "1e6")
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditEventKind is the kind of AuditEvent.
+type AuditEventKind int
+
+const (
+	// AuditLoaded is emitted after config data has been successfully
+	// read, verified and parsed.
+	AuditLoaded AuditEventKind = 0
+
+	// AuditLoadFailed is emitted when config data cannot be read,
+	// verified or parsed.
+	AuditLoadFailed AuditEventKind = 1
+
+	// AuditValidationFailed is emitted when the parsed config
+	// is rejected by validation.
+	AuditValidationFailed AuditEventKind = 2
+
+	// AuditActivated is emitted when a new config snapshot becomes active.
+	AuditActivated AuditEventKind = 3
+
+	// AuditRolledBack is emitted when the previous config snapshot
+	// is re-activated.
+	AuditRolledBack AuditEventKind = 4
+)
+
+// String returns string representation of k.
+func (k AuditEventKind) String() string {
+	switch k {
+	case AuditLoaded:
+		return "loaded"
+	case AuditLoadFailed:
+		return "load_failed"
+	case AuditValidationFailed:
+		return "validation_failed"
+	case AuditActivated:
+		return "activated"
+	case AuditRolledBack:
+		return "rolled_back"
+	default:
+		return "unknown"
+	}
+}
+
+// AuditEvent describes a config lifecycle event.
+type AuditEvent struct {
+	// Kind is the event kind.
+	Kind AuditEventKind
+
+	// Time is the event time.
+	Time time.Time
+
+	// Source is the config source such as file path.
+	Source string
+
+	// Checksum is hex-encoded sha256 checksum of the config data
+	// the event relates to. It is empty if the data is unavailable.
+	Checksum string
+
+	// Err is the error for failure events.
+	Err error
+}
+
+// AuditSink receives config lifecycle events.
+//
+// AuditSink implementations must be safe for calling from concurrent goroutines.
+type AuditSink interface {
+	Audit(e *AuditEvent)
+}
+
+// AuditSinkFunc is an adapter allowing ordinary functions to be used as AuditSink.
+type AuditSinkFunc func(e *AuditEvent)
+
+// Audit calls f(e).
+func (f AuditSinkFunc) Audit(e *AuditEvent) {
+	f(e)
+}
+
+// AuditWriter is AuditSink writing events as JSON lines to W.
+type AuditWriter struct {
+	W io.Writer
+
+	mu sync.Mutex
+	a  Arena
+	b  []byte
+}
+
+// Audit writes e to aw.W.
+//
+// Write errors are ignored, since audit must never break config reloads.
+func (aw *AuditWriter) Audit(e *AuditEvent) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	a := &aw.a
+	o := a.NewObject()
+	o.Set("time", a.NewString(e.Time.UTC().Format(time.RFC3339Nano)))
+	o.Set("event", a.NewString(e.Kind.String()))
+	o.Set("source", a.NewString(e.Source))
+	if e.Checksum != "" {
+		o.Set("sha256", a.NewString(e.Checksum))
+	}
+	if e.Err != nil {
+		o.Set("error", a.NewString(e.Err.Error()))
+	}
+	aw.b = o.MarshalTo(aw.b[:0])
+	aw.b = append(aw.b, '\n')
+	_, _ = aw.W.Write(aw.b)
+	a.Reset()
+}
+
+func emitAudit(sink AuditSink, kind AuditEventKind, source, checksum string, err error) {
+	if sink == nil {
+		return
+	}
+	sink.Audit(&AuditEvent{
+		Kind:     kind,
+		Time:     time.Now(),
+		Source:   source,
+		Checksum: checksum,
+		Err:      err,
+	})
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,102 @@
+package libconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAuditWriter(t *testing.T) {
+	var bb bytes.Buffer
+	aw := &AuditWriter{W: &bb}
+	aw.Audit(&AuditEvent{
+		Kind:     AuditLoaded,
+		Time:     time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:   "app.cfg",
+		Checksum: "abcd",
+	})
+	aw.Audit(&AuditEvent{
+		Kind:   AuditValidationFailed,
+		Time:   time.Date(2021, 1, 2, 3, 4, 6, 0, time.UTC),
+		Source: "app.cfg",
+		Err:    fmt.Errorf(`bad "port"`),
+	})
+	s := bb.String()
+	sExpected := `{"time":"2021-01-02T03:04:05Z","event":"loaded","source":"app.cfg","sha256":"abcd"}` + "\n" +
+		`{"time":"2021-01-02T03:04:06Z","event":"validation_failed","source":"app.cfg","error":"bad \"port\""}` + "\n"
+	if s != sExpected {
+		t.Fatalf("unexpected audit log\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+}
+
+func TestWatchAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.cfg")
+	writeFile := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("cannot write file: %s", err)
+		}
+	}
+
+	var mu sync.Mutex
+	var events []string
+	sink := AuditSinkFunc(func(e *AuditEvent) {
+		if e.Source != path || e.Time.IsZero() {
+			panic(fmt.Errorf("unexpected event: %+v", e))
+		}
+		mu.Lock()
+		events = append(events, e.Kind.String())
+		mu.Unlock()
+	})
+
+	writeFile(`port = 1;`)
+	w, err := Watch(path, &WatchOptions{
+		Interval:  time.Hour,
+		AuditSink: sink,
+		Validate: func(v *Value) error {
+			if v.GetInt("port") == 0 {
+				return fmt.Errorf("missing port")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer w.Stop()
+
+	if w.Rollback() {
+		t.Fatalf("unexpected rollback without previous snapshot")
+	}
+	writeFile(`port = 2;`)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	writeFile(`foo = 1;`)
+	if err := w.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	writeFile(`port = `)
+	if err := w.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !w.Rollback() {
+		t.Fatalf("expecting successful rollback")
+	}
+	if n := w.Load().GetInt("port"); n != 1 {
+		t.Fatalf("unexpected port after rollback; got %d; want 1", n)
+	}
+
+	mu.Lock()
+	s := strings.Join(events, ",")
+	mu.Unlock()
+	sExpected := "loaded,activated,loaded,activated,loaded,validation_failed,load_failed,rolled_back"
+	if s != sExpected {
+		t.Fatalf("unexpected events\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+}
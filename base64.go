@@ -0,0 +1,96 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Base64 returns bytes decoded from base64-encoded string value v.
+//
+// Both standard and URL-safe alphabets are accepted with or without
+// padding. Whitespace and line breaks are ignored, so PEM-like bodies
+// of certificates and keys may be decoded too.
+//
+// Use GetBase64 if you don't need error handling.
+func (v *Value) Base64() ([]byte, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain base64 string; it contains %s", v.Type())
+	}
+	s := v.s
+	if strings.ContainsAny(s, " \t\r\n") {
+		s = strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	s = strings.TrimRight(s, "=")
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.RawURLEncoding
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode base64 string: %s", err)
+	}
+	return b, nil
+}
+
+// GetBase64 returns bytes decoded from base64-encoded string
+// by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// See Value.Base64 for details.
+//
+// nil is returned for non-existing keys path or for invalid value.
+func (v *Value) GetBase64(keys ...string) []byte {
+	v = v.Get(keys...)
+	if v == nil {
+		return nil
+	}
+	b, err := v.Base64()
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// NewBase64 returns new string value containing standard base64 encoding of b.
+//
+// The returned string is valid until Reset is called on a.
+func (a *Arena) NewBase64(b []byte) *Value {
+	return a.newAppendedString(func(dst []byte) []byte {
+		n := len(dst)
+		dst = append(dst, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+		base64.StdEncoding.Encode(dst[n:], b)
+		return dst
+	})
+}
@@ -0,0 +1,49 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueBase64(t *testing.T) {
+	v := MustParse(`
+		std = "/+8=";
+		std_raw = "/+8";
+		url = "_-8";
+		url_padded = "_-8=";
+		pem = "aGVs\nbG8=";
+		empty = "";
+		bad = "a!b";
+		num = 123;
+	`)
+
+	f := func(key, resultExpected string) {
+		t.Helper()
+		b := v.GetBase64(key)
+		if b == nil {
+			t.Fatalf("unexpected nil result for %q", key)
+		}
+		if string(b) != resultExpected {
+			t.Fatalf("unexpected result for %q; got %q; want %q", key, b, resultExpected)
+		}
+	}
+	f("std", "\xff\xef")
+	f("std_raw", "\xff\xef")
+	f("url", "\xff\xef")
+	f("url_padded", "\xff\xef")
+	f("pem", "hello")
+	f("empty", "")
+
+	for _, key := range []string{"bad", "num", "missing"} {
+		if b := v.GetBase64(key); b != nil {
+			t.Fatalf("expecting nil result for %q; got %q", key, b)
+		}
+	}
+	if _, err := v.Get("bad").Base64(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	var a Arena
+	if s := a.NewBase64([]byte("hello")).String(); s != `"aGVsbG8="` {
+		t.Fatalf("unexpected string representation; got %s; want %s", s, `"aGVsbG8="`)
+	}
+}
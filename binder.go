@@ -0,0 +1,656 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"github.com/gitteamer/libconfig/fastfloat"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses data and stores the result in the value pointed to by dst.
+//
+// See Value.Unmarshal for details.
+func Unmarshal(data []byte, dst interface{}) error {
+	p := handyPool.Get()
+	defer handyPool.Put(p)
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return v.Unmarshal(dst)
+}
+
+// Unmarshal stores v in the value pointed to by dst.
+//
+// Struct fields are matched against object keys by the name from
+// the `libconfig:"name"` tag or by the field name. Fields with `libconfig:"-"`
+// tag are skipped. Exact key matches are preferred over case-insensitive ones.
+//
+// Types implementing encoding.TextUnmarshaler receive string values
+// via UnmarshalText call. Such types may be also used as map keys
+// in the same way as in encoding/json.
+//
+// Fields of json.RawMessage type receive JSON representation of the value,
+// fields of json.Number type receive the number token, while types
+// implementing json.Unmarshaler receive JSON representation of the value
+// via UnmarshalJSON call. This allows re-using types from encoding/json.
+//
+// Strings and byte slices in dst don't reference v, so they remain valid
+// after the Parser returned v is re-used.
+func (v *Value) Unmarshal(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cannot unmarshal into non-pointer %T", dst)
+	}
+	return bindValue(v, rv.Elem())
+}
+
+var (
+	rawMessageType      = reflect.TypeOf(json.RawMessage(nil))
+	numberType          = reflect.TypeOf(json.Number(""))
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func bindValue(v *Value, rv reflect.Value) error {
+	rt := rv.Type()
+
+	// Escape hatches to encoding/json types.
+	if rt == rawMessageType {
+		rv.SetBytes(marshalJSON(nil, v))
+		return nil
+	}
+	if rt == numberType {
+		if v.Type() != TypeNumber {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		rv.SetString(jsonNumber(v.s))
+		return nil
+	}
+	if rt.Kind() != reflect.Ptr && rv.CanAddr() && reflect.PtrTo(rt).Implements(jsonUnmarshalerType) {
+		u := rv.Addr().Interface().(json.Unmarshaler)
+		return u.UnmarshalJSON(marshalJSON(nil, v))
+	}
+	if rt.Kind() != reflect.Ptr && rv.CanAddr() && v.Type() == TypeString && reflect.PtrTo(rt).Implements(textUnmarshalerType) {
+		u := rv.Addr().Interface().(encoding.TextUnmarshaler)
+		return u.UnmarshalText(s2b(v.s))
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr:
+		if v.Type() == TypeNull {
+			rv.Set(reflect.Zero(rt))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rt.Elem()))
+		}
+		return bindValue(v, rv.Elem())
+	case reflect.Interface:
+		if rt.NumMethod() != 0 {
+			return fmt.Errorf("cannot unmarshal %s into non-empty interface %s", v.Type(), rt)
+		}
+		x, err := interfaceValue(v)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			rv.Set(reflect.Zero(rt))
+		} else {
+			rv.Set(reflect.ValueOf(x))
+		}
+		return nil
+	case reflect.Struct:
+		return bindStruct(v, rv)
+	case reflect.Map:
+		return bindMap(v, rv)
+	case reflect.Slice:
+		if v.Type() == TypeNull {
+			rv.Set(reflect.Zero(rt))
+			return nil
+		}
+		if rt.Elem().Kind() == reflect.Uint8 && v.Type() == TypeString {
+			rv.SetBytes(append([]byte(nil), v.s...))
+			return nil
+		}
+		if v.t != TypeArray {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		s := reflect.MakeSlice(rt, len(v.a), len(v.a))
+		for i, item := range v.a {
+			if err := bindValue(item, s.Index(i)); err != nil {
+				return fmt.Errorf("cannot unmarshal item #%d: %s", i, err)
+			}
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Array:
+		if v.t != TypeArray {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		if len(v.a) > rv.Len() {
+			return fmt.Errorf("cannot unmarshal array with %d items into %s", len(v.a), rt)
+		}
+		for i, item := range v.a {
+			if err := bindValue(item, rv.Index(i)); err != nil {
+				return fmt.Errorf("cannot unmarshal item #%d: %s", i, err)
+			}
+		}
+		return nil
+	case reflect.String:
+		if v.Type() != TypeString {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		rv.SetString(strings.Clone(v.s))
+		return nil
+	case reflect.Bool:
+		b, err := v.Bool()
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() != TypeNumber {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		n, err := parseIntToken(v.s)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("number %q overflows %s", v.s, rt)
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Type() != TypeNumber {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		n, err := parseUintToken(v.s)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("number %q overflows %s", v.s, rt)
+		}
+		rv.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if v.Type() != TypeNumber {
+			return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+		}
+		f, err := fastfloat.Parse(v.s)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowFloat(f) {
+			return fmt.Errorf("number %q overflows %s", v.s, rt)
+		}
+		rv.SetFloat(f)
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal %s into unsupported type %s", v.Type(), rt)
+	}
+}
+
+func bindStruct(v *Value, rv reflect.Value) error {
+	if v.t != TypeObject {
+		return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rv.Type())
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, ok := fieldName(sf)
+		if !ok {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						return fmt.Errorf("cannot set embedded pointer to unexported struct %s", sf.Type.Elem())
+					}
+					fv.Set(reflect.New(sf.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := bindStruct(v, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		item := lookupFieldValue(&v.o, name)
+		if item == nil {
+			continue
+		}
+		if err := bindValue(item, rv.Field(i)); err != nil {
+			return fmt.Errorf("cannot unmarshal field %q: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldName returns the object key name for sf.
+//
+// An empty name is returned for fields without explicit name.
+// false is returned for fields which must be skipped.
+func fieldName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("libconfig")
+	if tag == "-" {
+		return "", false
+	}
+	if n := strings.IndexByte(tag, ','); n >= 0 {
+		tag = tag[:n]
+	}
+	if sf.Anonymous && tag == "" {
+		t := sf.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+	if sf.PkgPath != "" {
+		// Unexported field.
+		return "", false
+	}
+	return tag, true
+}
+
+func lookupFieldValue(o *Object, name string) *Value {
+	if v := o.Get(name); v != nil {
+		return v
+	}
+	for _, kv := range o.kvs {
+		if strings.EqualFold(kv.k, name) {
+			return kv.v
+		}
+	}
+	return nil
+}
+
+func bindMap(v *Value, rv reflect.Value) error {
+	rt := rv.Type()
+	if v.Type() == TypeNull {
+		rv.Set(reflect.Zero(rt))
+		return nil
+	}
+	if v.t != TypeObject {
+		return fmt.Errorf("cannot unmarshal %s into %s", v.Type(), rt)
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(rt, v.o.Len()))
+	}
+	v.o.unescapeKeys()
+	for _, kv := range v.o.kvs {
+		ev := reflect.New(rt.Elem()).Elem()
+		if err := bindValue(kv.v, ev); err != nil {
+			return fmt.Errorf("cannot unmarshal key %q: %s", kv.k, err)
+		}
+		k, err := mapKey(kv.k, rt.Key())
+		if err != nil {
+			return err
+		}
+		rv.SetMapIndex(k, ev)
+	}
+	return nil
+}
+
+func mapKey(key string, kt reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+		k := reflect.New(kt)
+		if err := k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return k, fmt.Errorf("cannot unmarshal key %q into %s: %s", key, kt, err)
+		}
+		return k.Elem(), nil
+	}
+	k := reflect.New(kt).Elem()
+	switch kt.Kind() {
+	case reflect.String:
+		k.SetString(strings.Clone(key))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || k.OverflowInt(n) {
+			return k, fmt.Errorf("cannot unmarshal key %q into %s", key, kt)
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || k.OverflowUint(n) {
+			return k, fmt.Errorf("cannot unmarshal key %q into %s", key, kt)
+		}
+		k.SetUint(n)
+	default:
+		return k, fmt.Errorf("cannot unmarshal object into map with unsupported key type %s", kt)
+	}
+	return k, nil
+}
+
+// interfaceValue converts v to the corresponding Go value in the same way
+// as encoding/json does when unmarshaling into interface{}.
+func interfaceValue(v *Value) (interface{}, error) {
+	switch v.Type() {
+	case TypeObject:
+		m := make(map[string]interface{}, v.o.Len())
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			x, err := interfaceValue(kv.v)
+			if err != nil {
+				return nil, err
+			}
+			m[strings.Clone(kv.k)] = x
+		}
+		return m, nil
+	case TypeArray:
+		a := make([]interface{}, len(v.a))
+		for i, item := range v.a {
+			x, err := interfaceValue(item)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = x
+		}
+		return a, nil
+	case TypeString:
+		return strings.Clone(v.s), nil
+	case TypeRawJSON:
+		return json.RawMessage(v.s), nil
+	case TypeNumber:
+		if isIntToken(v.s) {
+			n, err := parseIntToken(v.s)
+			if err == nil {
+				return float64(n), nil
+			}
+		}
+		return fastfloat.Parse(v.s)
+	case TypeTrue:
+		return true, nil
+	case TypeFalse:
+		return false, nil
+	default:
+		return nil, nil
+	}
+}
+
+// marshalJSON appends JSON representation of v to dst.
+//
+// Unlike Value.MarshalTo, it converts hex and big int number tokens
+// to plain decimal numbers, so the result is valid JSON.
+func marshalJSON(dst []byte, v *Value) []byte {
+	switch v.Type() {
+	case TypeObject:
+		dst = append(dst, '{')
+		v.o.unescapeKeys()
+		for i, kv := range v.o.kvs {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = escapeString(dst, kv.k)
+			dst = append(dst, ':')
+			dst = marshalJSON(dst, kv.v)
+		}
+		return append(dst, '}')
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, item := range v.a {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = marshalJSON(dst, item)
+		}
+		return append(dst, ']')
+	case TypeNumber:
+		return append(dst, jsonNumber(v.s)...)
+	default:
+		return v.MarshalTo(dst)
+	}
+}
+
+// jsonNumber converts libconfig number token s to JSON number.
+func jsonNumber(s string) string {
+	if isIntToken(s) {
+		if n, err := parseIntToken(s); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+		if n, err := parseUintToken(s); err == nil {
+			return strconv.FormatUint(n, 10)
+		}
+	}
+	return strings.TrimSuffix(s, "L")
+}
+
+func isIntToken(s string) bool {
+	if isHexToken(s) {
+		return true
+	}
+	return strings.IndexAny(s, ".eEnNiI") < 0
+}
+
+func isHexToken(s string) bool {
+	return len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X")
+}
+
+// parseIntToken parses libconfig integer token s, which may be hex
+// or may contain big int 'L' suffix.
+func parseIntToken(s string) (int64, error) {
+	if isHexToken(s) {
+		return strconv.ParseInt(strings.TrimSuffix(s[2:], "L"), 16, 64)
+	}
+	return fastfloat.ParseInt64(strings.TrimSuffix(s, "L"))
+}
+
+// parseUintToken is the same as parseIntToken, but for unsigned numbers.
+func parseUintToken(s string) (uint64, error) {
+	if isHexToken(s) {
+		return strconv.ParseUint(strings.TrimSuffix(s[2:], "L"), 16, 64)
+	}
+	return fastfloat.ParseUint64(strings.TrimSuffix(s, "L"))
+}
+
+// Marshal returns a Value for x allocated in a.
+//
+// Structs are converted into objects with keys obtained in the same way
+// as in Value.Unmarshal. Fields with `libconfig:",omitempty"` tag are
+// skipped if they contain zero value.
+//
+// Types implementing encoding.TextMarshaler are converted into strings
+// via MarshalText call. Such types may be also used as map keys.
+// Map keys are sorted in the returned objects.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) Marshal(x interface{}) (*Value, error) {
+	return a.marshalValue(reflect.ValueOf(x))
+}
+
+func (a *Arena) marshalValue(rv reflect.Value) (*Value, error) {
+	if !rv.IsValid() {
+		return valueNull, nil
+	}
+	rt := rv.Type()
+	if rt.Implements(textMarshalerType) && !(rt.Kind() == reflect.Ptr && rv.IsNil()) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal %s: %s", rt, err)
+		}
+		return a.NewStringBytes(b), nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return valueNull, nil
+		}
+		return a.marshalValue(rv.Elem())
+	case reflect.Struct:
+		o := a.NewObject()
+		if err := a.marshalStruct(o, rv); err != nil {
+			return nil, err
+		}
+		return o, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return valueNull, nil
+		}
+		return a.marshalMap(rv)
+	case reflect.Slice:
+		if rv.IsNil() {
+			return valueNull, nil
+		}
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return a.NewStringBytes(rv.Bytes()), nil
+		}
+		return a.marshalArray(rv)
+	case reflect.Array:
+		return a.marshalArray(rv)
+	case reflect.String:
+		return a.NewString(rv.String()), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return valueTrue, nil
+		}
+		return valueFalse, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bLen := len(a.b)
+		a.b = strconv.AppendInt(a.b, rv.Int(), 10)
+		v := a.NewNumberString(b2s(a.b[bLen:]))
+		v.o.flags |= flagGenerated
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bLen := len(a.b)
+		a.b = strconv.AppendUint(a.b, rv.Uint(), 10)
+		v := a.NewNumberString(b2s(a.b[bLen:]))
+		v.o.flags |= flagGenerated
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		return a.NewNumberFloat64(rv.Float()), nil
+	default:
+		return nil, fmt.Errorf("cannot marshal unsupported type %s", rt)
+	}
+}
+
+func (a *Arena) marshalStruct(o *Value, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		name, ok := fieldName(sf)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && name == "" {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := a.marshalStruct(o, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(sf.Tag.Get("libconfig"), ",omitempty") && fv.IsZero() {
+			continue
+		}
+		v, err := a.marshalValue(fv)
+		if err != nil {
+			return fmt.Errorf("cannot marshal field %q: %s", name, err)
+		}
+		o.Set(name, v)
+	}
+	return nil
+}
+
+func (a *Arena) marshalMap(rv reflect.Value) (*Value, error) {
+	type mapItem struct {
+		k string
+		v reflect.Value
+	}
+	items := make([]mapItem, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		var ks string
+		switch {
+		case k.Type().Implements(textMarshalerType):
+			b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, fmt.Errorf("cannot marshal map key %v: %s", k, err)
+			}
+			ks = string(b)
+		case k.Kind() == reflect.String:
+			ks = k.String()
+		case k.CanInt():
+			ks = strconv.FormatInt(k.Int(), 10)
+		case k.CanUint():
+			ks = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, fmt.Errorf("cannot marshal map with unsupported key type %s", k.Type())
+		}
+		items = append(items, mapItem{k: ks, v: iter.Value()})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].k < items[j].k
+	})
+
+	o := a.NewObject()
+	for _, item := range items {
+		v, err := a.marshalValue(item.v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal key %q: %s", item.k, err)
+		}
+		o.Set(item.k, v)
+	}
+	return o, nil
+}
+
+func (a *Arena) marshalArray(rv reflect.Value) (*Value, error) {
+	arr := a.NewArray()
+	for i := 0; i < rv.Len(); i++ {
+		v, err := a.marshalValue(rv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal item #%d: %s", i, err)
+		}
+		arr.SetArrayItem(i, v)
+	}
+	return arr, nil
+}
@@ -0,0 +1,277 @@
+package libconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUpperString string
+
+func (s *testUpperString) UnmarshalJSON(b []byte) error {
+	var x string
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	*s = testUpperString(strings.ToUpper(x))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	type window struct {
+		Title string
+		Size  struct {
+			W int `libconfig:"w"`
+			H int `libconfig:"h"`
+		} `libconfig:"size"`
+	}
+	type app struct {
+		Version string `libconfig:"version"`
+		Window  window `libconfig:"window"`
+		Tags    []string
+		Mask    uint16 `libconfig:"bitmask"`
+		Big     int64  `libconfig:"bigint"`
+		BigHex  uint64 `libconfig:"bighex"`
+		Pi      float64
+		Enabled *bool
+		Extra   map[string]int    `libconfig:"extra"`
+		Any     interface{}       `libconfig:"any"`
+		Skipped string            `libconfig:"-"`
+		Raw     json.RawMessage   `libconfig:"raw"`
+		Num     json.Number       `libconfig:"num"`
+		Name    testUpperString   `libconfig:"name"`
+		Names   []testUpperString `libconfig:"names"`
+	}
+
+	data := []byte(`
+		version = "1.0";
+		window = { title = "My App"; size = { w = 640; h = 480; }; };
+		tags = ["a", "b"];
+		bitmask = 0x1FC3;
+		bigint = 9223372036854775807L;
+		bighex = 0xFFFFFFFFFFFFFFFFL;
+		pi = 3.14;
+		enabled = true;
+		extra = { x = 1; y = 2; };
+		any = { list = (1, "s", false); };
+		skipped = "x";
+		raw = { foo = [1, 0x10]; };
+		num = 12345678901234567890;
+		name = "foo";
+		names = ("bar", "baz");
+	`)
+	var a app
+	if err := Unmarshal(data, &a); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a.Version != "1.0" || a.Window.Title != "My App" || a.Window.Size.W != 640 || a.Window.Size.H != 480 {
+		t.Fatalf("unexpected struct: %+v", a)
+	}
+	if !reflect.DeepEqual(a.Tags, []string{"a", "b"}) {
+		t.Fatalf("unexpected tags: %q", a.Tags)
+	}
+	if a.Mask != 0x1FC3 || a.Big != 9223372036854775807 || a.BigHex != 0xFFFFFFFFFFFFFFFF || a.Pi != 3.14 {
+		t.Fatalf("unexpected numbers: %+v", a)
+	}
+	if a.Enabled == nil || !*a.Enabled {
+		t.Fatalf("unexpected enabled: %v", a.Enabled)
+	}
+	if !reflect.DeepEqual(a.Extra, map[string]int{"x": 1, "y": 2}) {
+		t.Fatalf("unexpected extra: %v", a.Extra)
+	}
+	anyExpected := map[string]interface{}{"list": []interface{}{float64(1), "s", false}}
+	if !reflect.DeepEqual(a.Any, anyExpected) {
+		t.Fatalf("unexpected any: %v", a.Any)
+	}
+	if a.Skipped != "" {
+		t.Fatalf("skipped field must be empty; got %q", a.Skipped)
+	}
+	if string(a.Raw) != `{"foo":[1,16]}` {
+		t.Fatalf("unexpected raw message: %s", a.Raw)
+	}
+	if a.Num != "12345678901234567890" {
+		t.Fatalf("unexpected number: %s", a.Num)
+	}
+	if a.Name != "FOO" || !reflect.DeepEqual(a.Names, []testUpperString{"BAR", "BAZ"}) {
+		t.Fatalf("unexpected json.Unmarshaler results: %q, %q", a.Name, a.Names)
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	f := func(data string, dst interface{}) {
+		t.Helper()
+		if err := Unmarshal([]byte(data), dst); err == nil {
+			t.Fatalf("expecting non-nil error for %q", data)
+		}
+	}
+
+	var s struct {
+		N    int8
+		S    string
+		Num  json.Number
+		List [1]int
+	}
+	f(`n = 1000;`, &s)
+	f(`n = "x";`, &s)
+	f(`s = 1;`, &s)
+	f(`num = "1";`, &s)
+	f(`list = [1, 2];`, &s)
+	f(`foo = 1;`, s)
+	f(`foo = `, &s)
+
+	// nil embedded pointer to unexported struct cannot be set.
+	var e struct {
+		*testInner
+		B int
+	}
+	f(`A = 1; B = 2;`, &e)
+
+	// Already allocated embedded pointer is filled.
+	e.testInner = &testInner{}
+	if err := Unmarshal([]byte(`A = 1; B = 2;`), &e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if e.A != 1 || e.B != 2 {
+		t.Fatalf("unexpected result; got A=%d, B=%d; want A=1, B=2", e.A, e.B)
+	}
+}
+
+type testInner struct {
+	A int
+}
+
+type testLevel int
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	switch l {
+	case 0:
+		return []byte("debug"), nil
+	case 1:
+		return []byte("info"), nil
+	default:
+		return nil, fmt.Errorf("unknown level %d", int(l))
+	}
+}
+
+func (l *testLevel) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level %q", b)
+	}
+	return nil
+}
+
+func TestUnmarshalTextUnmarshaler(t *testing.T) {
+	type cfg struct {
+		Level   testLevel
+		Since   time.Time
+		Addr    netip.Addr
+		Limits  map[netip.Addr]int
+		Levels  map[testLevel]bool
+		Indexes map[int]string
+	}
+	data := []byte(`
+		level = "info";
+		since = "2021-03-04T05:06:07Z";
+		addr = "10.0.0.1";
+		limits = { 192.168.0.1 = 10; 10.0.0.2 = 20; };
+		levels = { debug = false; info = true; };
+		indexes = { 1 = "a"; 20 = "b"; };
+	`)
+	var c cfg
+	if err := Unmarshal(data, &c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cExpected := cfg{
+		Level: 1,
+		Since: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Addr:  netip.MustParseAddr("10.0.0.1"),
+		Limits: map[netip.Addr]int{
+			netip.MustParseAddr("192.168.0.1"): 10,
+			netip.MustParseAddr("10.0.0.2"):    20,
+		},
+		Levels:  map[testLevel]bool{0: false, 1: true},
+		Indexes: map[int]string{1: "a", 20: "b"},
+	}
+	if !reflect.DeepEqual(c, cExpected) {
+		t.Fatalf("unexpected result\ngot\n%+v\nwant\n%+v", c, cExpected)
+	}
+
+	if err := Unmarshal([]byte(`level = "trace";`), &c); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if err := Unmarshal([]byte(`levels = { trace = true; };`), &c); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestArenaMarshal(t *testing.T) {
+	type server struct {
+		Host string `libconfig:"host"`
+		Port int    `libconfig:"port,omitempty"`
+	}
+	type cfg struct {
+		Name    string             `libconfig:"name"`
+		Level   testLevel          `libconfig:"level"`
+		Since   time.Time          `libconfig:"since"`
+		Servers []server           `libconfig:"servers"`
+		Limits  map[netip.Addr]int `libconfig:"limits"`
+		Ratio   float64            `libconfig:"ratio"`
+		Enabled bool               `libconfig:"enabled"`
+		Skipped int                `libconfig:"-"`
+		Nil     *server            `libconfig:"nil"`
+	}
+	c := cfg{
+		Name:  "foo",
+		Level: 1,
+		Since: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Servers: []server{
+			{Host: "a", Port: 80},
+			{Host: "b"},
+		},
+		Limits: map[netip.Addr]int{
+			netip.MustParseAddr("192.168.0.2"): 20,
+			netip.MustParseAddr("192.168.0.1"): 10,
+		},
+		Ratio:   0.5,
+		Enabled: true,
+		Skipped: 123,
+	}
+
+	var a Arena
+	v, err := a.Marshal(&c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s := v.String()
+	sExpected := `{"name":"foo","level":"info","since":"2021-03-04T05:06:07Z","servers":[{"host":"a","port":80},{"host":"b"}],` +
+		`"limits":{"192.168.0.1":10,"192.168.0.2":20},"ratio":0.5,"enabled":true,"nil":null}`
+	if s != sExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+
+	// Round trip.
+	var c2 cfg
+	if err := v.Unmarshal(&c2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Skipped = 0
+	if !reflect.DeepEqual(c, c2) {
+		t.Fatalf("unexpected round trip result\ngot\n%+v\nwant\n%+v", c2, c)
+	}
+
+	if _, err := a.Marshal(testLevel(5)); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if _, err := a.Marshal(make(chan int)); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
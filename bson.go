@@ -0,0 +1,469 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseBSON parses b containing BSON document.
+//
+// BSON values are mapped to relaxed MongoDB Extended JSON v2, so they may
+// be accessed via the usual getters:
+//
+//   - double, int32 and int64 are mapped to numbers; NaN and infinities
+//     are mapped to nan and inf numbers;
+//   - ObjectId is mapped to {"$oid": "<hex>"};
+//   - DateTime is mapped to {"$date": "<RFC 3339>"} for years 1970-9999
+//     and to {"$date": {"$numberLong": "<millis>"}} otherwise;
+//   - binary is mapped to {"$binary": {"base64": "...", "subType": "<hex>"}};
+//   - Decimal128 is mapped to {"$numberDecimal": "<decimal>"};
+//   - timestamp, regular expression, JavaScript code, symbol, DBPointer,
+//     undefined, MinKey and MaxKey are mapped to the corresponding
+//     Extended JSON objects.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseBSON(b []byte) (*Value, error) {
+	if err := p.Config.checkInputSize(len(b)); err != nil {
+		return nil, fmt.Errorf("cannot parse BSON: %s", err)
+	}
+	p.b = append(p.b[:0], b...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	bp := &bsonParser{
+		b: p.b,
+		c: &p.c,
+	}
+	v, err := bp.parseDocument(false, 0)
+	if err == nil && bp.n < len(bp.b) {
+		err = fmt.Errorf("unexpected trailing data at offset %d", bp.n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse BSON: %s", err)
+	}
+	return v, nil
+}
+
+// ParseBSON parses b containing BSON document.
+//
+// The function is slower than the Parser.ParseBSON for re-used Parser.
+func ParseBSON(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseBSON(b)
+}
+
+type bsonParser struct {
+	b []byte
+
+	// n is the offset of the next byte in b.
+	n int
+
+	c *cache
+}
+
+func (bp *bsonParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", bp.n, fmt.Sprintf(format, args...))
+}
+
+// read returns the next n bytes.
+func (bp *bsonParser) read(n int) ([]byte, error) {
+	if n < 0 || n > len(bp.b)-bp.n {
+		return nil, bp.errorf("unexpected end of data; cannot read %d bytes", n)
+	}
+	b := bp.b[bp.n : bp.n+n]
+	bp.n += n
+	return b, nil
+}
+
+func (bp *bsonParser) readInt32() (int32, error) {
+	b, err := bp.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (bp *bsonParser) readUint64() (uint64, error) {
+	b, err := bp.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// readCString reads zero-terminated string.
+func (bp *bsonParser) readCString() (string, error) {
+	n := strings.IndexByte(b2s(bp.b[bp.n:]), 0)
+	if n < 0 {
+		return "", bp.errorf("missing zero byte at the end of cstring")
+	}
+	b := bp.b[bp.n : bp.n+n]
+	if !utf8.Valid(b) {
+		return "", bp.errorf("invalid UTF-8 in cstring")
+	}
+	bp.n += n + 1
+	return b2s(b), nil
+}
+
+// readString reads length-prefixed zero-terminated string.
+func (bp *bsonParser) readString() (string, error) {
+	n, err := bp.readInt32()
+	if err != nil {
+		return "", err
+	}
+	if n < 1 {
+		return "", bp.errorf("invalid string length %d", n)
+	}
+	b, err := bp.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	if b[len(b)-1] != 0 {
+		return "", bp.errorf("missing zero byte at the end of string")
+	}
+	b = b[:len(b)-1]
+	if !utf8.Valid(b) {
+		return "", bp.errorf("invalid UTF-8 in string")
+	}
+	s := b2s(b)
+	if err := bp.c.cfg.checkStringLen(s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func (bp *bsonParser) newNumber(s string) *Value {
+	v := bp.c.getValue()
+	v.t = TypeNumber
+	v.s = s
+	return v
+}
+
+func (bp *bsonParser) newString(s string) *Value {
+	v := bp.c.getValue()
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+// newObject returns object with the given key and value.
+func (bp *bsonParser) newObject(key string, v *Value) *Value {
+	o := bp.c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	o.o.keysUnescaped = true
+	appendObjectKV(&o.o, key, v)
+	return o
+}
+
+// parseDocument parses embedded document or array.
+//
+// Array keys are ignored, since BSON arrays are documents
+// with "0", "1", ... keys.
+func (bp *bsonParser) parseDocument(isArray bool, depth int) (*Value, error) {
+	depth++
+	if maxDepth := bp.c.cfg.maxDepth(); depth > maxDepth {
+		return nil, bp.errorf("too big depth for the nested BSON; it exceeds %d", maxDepth)
+	}
+	start := bp.n
+	size, err := bp.readInt32()
+	if err != nil {
+		return nil, err
+	}
+	if size < 5 || int(size) > len(bp.b)-start {
+		bp.n = start
+		return nil, bp.errorf("invalid document size %d", size)
+	}
+	end := start + int(size)
+	if bp.b[end-1] != 0 {
+		bp.n = end - 1
+		return nil, bp.errorf("missing zero byte at the end of document")
+	}
+
+	v := bp.c.getValue()
+	if isArray {
+		v.t = TypeArray
+		v.a = v.a[:0]
+	} else {
+		v.t = TypeObject
+		v.o.reset()
+		v.o.keysUnescaped = true
+	}
+	// Limit the parser to the document contents.
+	b := bp.b
+	bp.b = bp.b[:end-1]
+	for bp.n < len(bp.b) {
+		elemOffset := bp.n
+		typ := bp.b[bp.n]
+		bp.n++
+		key, err := bp.readCString()
+		if err != nil {
+			return nil, err
+		}
+		item, err := bp.parseElement(typ, depth)
+		if err != nil {
+			return nil, err
+		}
+		if isArray {
+			if err := bp.c.cfg.checkArrayLen(len(v.a) + 1); err != nil {
+				return nil, err
+			}
+			v.a = append(v.a, item)
+			continue
+		}
+		if bp.c.cfg != nil && bp.c.cfg.RejectDuplicateKeys && v.o.Get(key) != nil {
+			bp.n = elemOffset
+			return nil, bp.errorf("duplicate key %q", key)
+		}
+		appendObjectKV(&v.o, key, item)
+	}
+	bp.b = b
+	bp.n = end
+	return v, nil
+}
+
+func (bp *bsonParser) parseElement(typ byte, depth int) (*Value, error) {
+	switch typ {
+	case 0x01:
+		n, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newNumber(formatCBORFloat(math.Float64frombits(n))), nil
+	case 0x02:
+		s, err := bp.readString()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newString(s), nil
+	case 0x03:
+		return bp.parseDocument(false, depth)
+	case 0x04:
+		return bp.parseDocument(true, depth)
+	case 0x05:
+		n, err := bp.readInt32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := bp.read(int(n) + 1)
+		if err != nil {
+			return nil, err
+		}
+		bin := bp.newObject("base64", bp.newString(base64.StdEncoding.EncodeToString(b[1:])))
+		appendObjectKV(&bin.o, "subType", bp.newString(hex.EncodeToString(b[:1])))
+		return bp.newObject("$binary", bin), nil
+	case 0x06:
+		return bp.newObject("$undefined", valueTrue), nil
+	case 0x07:
+		b, err := bp.read(12)
+		if err != nil {
+			return nil, err
+		}
+		return bp.newObject("$oid", bp.newString(hex.EncodeToString(b))), nil
+	case 0x08:
+		b, err := bp.read(1)
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case 0:
+			return valueFalse, nil
+		case 1:
+			return valueTrue, nil
+		default:
+			bp.n--
+			return nil, bp.errorf("invalid boolean value %d", b[0])
+		}
+	case 0x09:
+		n, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newObject("$date", bp.newDate(int64(n))), nil
+	case 0x0a:
+		return valueNull, nil
+	case 0x0b:
+		pattern, err := bp.readCString()
+		if err != nil {
+			return nil, err
+		}
+		options, err := bp.readCString()
+		if err != nil {
+			return nil, err
+		}
+		re := bp.newObject("pattern", bp.newString(pattern))
+		appendObjectKV(&re.o, "options", bp.newString(options))
+		return bp.newObject("$regularExpression", re), nil
+	case 0x0c:
+		ref, err := bp.readString()
+		if err != nil {
+			return nil, err
+		}
+		b, err := bp.read(12)
+		if err != nil {
+			return nil, err
+		}
+		ptr := bp.newObject("$ref", bp.newString(ref))
+		appendObjectKV(&ptr.o, "$id", bp.newObject("$oid", bp.newString(hex.EncodeToString(b))))
+		return bp.newObject("$dbPointer", ptr), nil
+	case 0x0d:
+		s, err := bp.readString()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newObject("$code", bp.newString(s)), nil
+	case 0x0e:
+		s, err := bp.readString()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newObject("$symbol", bp.newString(s)), nil
+	case 0x0f:
+		if _, err := bp.readInt32(); err != nil {
+			return nil, err
+		}
+		s, err := bp.readString()
+		if err != nil {
+			return nil, err
+		}
+		scope, err := bp.parseDocument(false, depth)
+		if err != nil {
+			return nil, err
+		}
+		code := bp.newObject("$code", bp.newString(s))
+		appendObjectKV(&code.o, "$scope", scope)
+		return code, nil
+	case 0x10:
+		n, err := bp.readInt32()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newNumber(strconv.FormatInt(int64(n), 10)), nil
+	case 0x11:
+		n, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		ts := bp.newObject("t", bp.newNumber(strconv.FormatUint(n>>32, 10)))
+		appendObjectKV(&ts.o, "i", bp.newNumber(strconv.FormatUint(n&math.MaxUint32, 10)))
+		return bp.newObject("$timestamp", ts), nil
+	case 0x12:
+		n, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newNumber(strconv.FormatInt(int64(n), 10)), nil
+	case 0x13:
+		low, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		high, err := bp.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		return bp.newObject("$numberDecimal", bp.newString(formatDecimal128(high, low))), nil
+	case 0x7f:
+		return bp.newObject("$maxKey", bp.newNumber("1")), nil
+	case 0xff:
+		return bp.newObject("$minKey", bp.newNumber("1")), nil
+	default:
+		return nil, bp.errorf("unsupported element type 0x%02x", typ)
+	}
+}
+
+// newDate returns Extended JSON representation for the given milliseconds
+// since the Unix epoch.
+func (bp *bsonParser) newDate(ms int64) *Value {
+	t := time.UnixMilli(ms).UTC()
+	if t.Year() < 1970 || t.Year() > 9999 {
+		return bp.newObject("$numberLong", bp.newString(strconv.FormatInt(ms, 10)))
+	}
+	return bp.newString(t.Format("2006-01-02T15:04:05.999Z07:00"))
+}
+
+// formatDecimal128 returns string representation of IEEE 754-2008 decimal128
+// number with the given bits according to the BSON Decimal128 specification.
+func formatDecimal128(high, low uint64) string {
+	sign := ""
+	if high>>63 != 0 {
+		sign = "-"
+	}
+	var exp int
+	var coef big.Int
+	if (high>>61)&3 == 3 {
+		switch (high >> 58) & 0x1f {
+		case 0x1f:
+			return "NaN"
+		case 0x1e:
+			return sign + "Infinity"
+		}
+		// The coefficient exceeds the maximum, so it is treated as zero.
+		exp = int((high>>47)&0x3fff) - 6176
+	} else {
+		exp = int((high>>49)&0x3fff) - 6176
+		coef.SetUint64(high & (1<<49 - 1))
+		coef.Lsh(&coef, 64)
+		coef.Or(&coef, new(big.Int).SetUint64(low))
+		if coef.Cmp(decimal128MaxCoef) > 0 {
+			coef.SetUint64(0)
+		}
+	}
+
+	digits := coef.String()
+	adjustedExp := exp + len(digits) - 1
+	if exp > 0 || adjustedExp < -6 {
+		// Scientific notation.
+		s := digits[:1]
+		if len(digits) > 1 {
+			s += "." + digits[1:]
+		}
+		return sign + s + "E" + fmt.Sprintf("%+d", adjustedExp)
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	n := len(digits) + exp
+	if n > 0 {
+		return sign + digits[:n] + "." + digits[n:]
+	}
+	return sign + "0." + strings.Repeat("0", -n) + digits
+}
+
+var decimal128MaxCoef = func() *big.Int {
+	n := new(big.Int).Exp(big.NewInt(10), big.NewInt(34), nil)
+	return n.Sub(n, big.NewInt(1))
+}()
@@ -0,0 +1,122 @@
+package libconfig
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// bsonDoc returns hex-encoded BSON document with the given hex-encoded elements.
+func bsonDoc(elems ...string) string {
+	s := strings.Join(elems, "")
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(s)/2+5))
+	return hex.EncodeToString(size[:]) + s + "00"
+}
+
+func TestParseBSON(t *testing.T) {
+	f := func(hexData, resultExpected string) {
+		t.Helper()
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		v, err := ParseBSON(data)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %s: %s", hexData, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", hexData, result, resultExpected)
+		}
+	}
+
+	f("0500000000", "{}")
+	f("10000000016400000000000000F03F00", `{"d":1}`)
+	f(bsonDoc("016400"+"000000000000F87F"), `{"d":nan}`)
+	f("0e00000002610002000000620000", `{"a":"b"}`)
+	f(bsonDoc("106900"+"ffffffff", "126c00"+"0000000000000080"), `{"i":-1,"l":-9223372036854775808}`)
+	f(bsonDoc("087400"+"01", "086600"+"00", "0a6e00"), `{"t":true,"f":false,"n":null}`)
+	f(bsonDoc("046100"+bsonDoc("103000"+"01000000", "023100"+"020000007800")), `{"a":[1,"x"]}`)
+	f(bsonDoc("036f00"+bsonDoc("036f00"+"0500000000")), `{"o":{"o":{}}}`)
+
+	// Extended JSON mappings.
+	f("1400000007610056E1FC72E0C917E9C471416100", `{"a":{"$oid":"56e1fc72e0c917e9c4714161"}}`)
+	f("10000000096100000000000000000000", `{"a":{"$date":"1970-01-01T00:00:00Z"}}`)
+	f("10000000096100C5D8D6CC3B01000000", `{"a":{"$date":"2012-12-24T12:15:30.501Z"}}`)
+	f("10000000096100C33CE7B9BDFFFFFF00", `{"a":{"$date":{"$numberLong":"-284643869501"}}}`)
+	f("0F0000000578000200000000FFFF00", `{"x":{"$binary":{"base64":"//8=","subType":"00"}}}`)
+	f(bsonDoc("057800"+"01000000"+"04"+"2a"), `{"x":{"$binary":{"base64":"Kg==","subType":"04"}}}`)
+	f(bsonDoc("0b7200"+"61626300"+"697800"), `{"r":{"$regularExpression":{"pattern":"abc","options":"ix"}}}`)
+	f(bsonDoc("117400"+"0200000001000000"), `{"t":{"$timestamp":{"t":1,"i":2}}}`)
+	f(bsonDoc("0d6300"+"020000007800"), `{"c":{"$code":"x"}}`)
+	f(bsonDoc("066100", "ff6d00", "7f7800"), `{"a":{"$undefined":true},"m":{"$minKey":1},"x":{"$maxKey":1}}`)
+	f(bsonDoc("136400"+"00000000000000000000000000004030"), `{"d":{"$numberDecimal":"0"}}`)
+	f(bsonDoc("136400"+"010000000000000000000000000040B0"), `{"d":{"$numberDecimal":"-1"}}`)
+	f(bsonDoc("136400"+"01000000000000000000000000003e30"), `{"d":{"$numberDecimal":"0.1"}}`)
+
+	// Errors
+	for _, hexData := range []string{
+		"",
+		"05000000",
+		"0500000001",
+		"0600000000",
+		"0400000000",
+		"050000000000",
+		"0800000000000001",
+		bsonDoc("0a61"),
+		bsonDoc("106900" + "0100"),
+		bsonDoc("026100" + "00000000"),
+		bsonDoc("026100" + "020000007878"),
+		bsonDoc("026100" + "02000000ff00"),
+		bsonDoc("086100" + "02"),
+		bsonDoc("206100"),
+		bsonDoc("036100" + "0500000000" + "00"),
+	} {
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		if _, err := ParseBSON(data); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", hexData)
+		}
+	}
+
+	// Random input must not result in panic.
+	r := rand.New(rand.NewSource(1))
+	var p Parser
+	for i := 0; i < 10000; i++ {
+		b := make([]byte, r.Intn(32))
+		r.Read(b)
+		if len(b) >= 4 {
+			binary.LittleEndian.PutUint32(b, uint32(len(b)))
+		}
+		_, _ = p.ParseBSON(b)
+	}
+}
+
+func TestFormatDecimal128(t *testing.T) {
+	f := func(high, low uint64, resultExpected string) {
+		t.Helper()
+		result := formatDecimal128(high, low)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %016x%016x; got %q; want %q", high, low, result, resultExpected)
+		}
+	}
+
+	f(0x3040000000000000, 0, "0")
+	f(0x3040000000000000, 1, "1")
+	f(0x3040000000000000, 12345, "12345")
+	f(0x303c000000000000, 12345, "123.45")
+	f(0x302c000000000000, 12345, "0.0000012345")
+	f(0x3032000000000000, 1, "1E-7")
+	f(0x3046000000000000, 1, "1E+3")
+	f(0x3046000000000000, 12, "1.2E+4")
+	f(0xb040000000000000, 1, "-1")
+	f(0x7c00000000000000, 0, "NaN")
+	f(0x7800000000000000, 0, "Infinity")
+	f(0xf800000000000000, 0, "-Infinity")
+	f(0x3041ed09bead87c0, 0x378d8e63ffffffff, "9999999999999999999999999999999999")
+	f(0x6c10000000000000, 0, "0")
+}
@@ -0,0 +1,335 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+)
+
+// Bundle is a self-contained config graph consisting of the root config
+// and all the files pulled via @include.
+//
+// Bundles may be stored in a single tar archive or JSON file, so deploy
+// artifacts carry exactly the config graph that was validated.
+type Bundle struct {
+	// Root is the name of the root config in Files.
+	Root string
+
+	// Files maps file names to file contents.
+	//
+	// File names are slash-separated paths relative to the root config
+	// directory. @include directives are resolved against Files.
+	Files map[string][]byte
+}
+
+// NewBundle returns bundle containing the config file at path and all
+// the files it includes.
+//
+// The config is parsed in order to verify it and to discover includes.
+func NewBundle(path string) (*Bundle, error) {
+	p := handyPool.Get()
+	defer handyPool.Put(p)
+	if _, _, err := loadFileInternal(p, path, &LoadOptions{}); err != nil {
+		return nil, fmt.Errorf("cannot bundle %q: %s", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot bundle %q: %s", path, err)
+	}
+	b := &Bundle{
+		Root: filepath.Base(path),
+		Files: map[string][]byte{
+			filepath.Base(path): data,
+		},
+	}
+	dir := filepath.Dir(path)
+	for _, r := range p.Includes() {
+		rel, err := filepath.Rel(dir, r.File)
+		if err != nil {
+			return nil, fmt.Errorf("cannot bundle %q: %s", r.File, err)
+		}
+		data, err := os.ReadFile(r.File)
+		if err != nil {
+			return nil, fmt.Errorf("cannot bundle %q: %s", r.File, err)
+		}
+		b.Files[filepath.ToSlash(rel)] = data
+	}
+	return b, nil
+}
+
+// names returns sorted file names from b with the root config first.
+func (b *Bundle) names() []string {
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		if name != b.Root {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{b.Root}, names...)
+}
+
+func (b *Bundle) validate() error {
+	if _, ok := b.Files[b.Root]; !ok {
+		return fmt.Errorf("missing root config %q in bundle", b.Root)
+	}
+	return nil
+}
+
+// bundleJSON is the JSON representation of Bundle.
+type bundleJSON struct {
+	Root  string            `json:"root"`
+	Files map[string]string `json:"files"`
+}
+
+// WriteJSON writes b to w as a single JSON document.
+//
+// File contents are embedded as strings, so they must contain valid UTF-8.
+func (b *Bundle) WriteJSON(w io.Writer) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+	bj := bundleJSON{
+		Root:  b.Root,
+		Files: make(map[string]string, len(b.Files)),
+	}
+	for name, data := range b.Files {
+		if !utf8.Valid(data) {
+			return fmt.Errorf("cannot embed %q into JSON bundle: invalid UTF-8", name)
+		}
+		bj.Files[name] = string(data)
+	}
+	data, err := json.MarshalIndent(&bj, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteTar writes b to w as a tar archive.
+//
+// The root config is written first.
+func (b *Bundle) WriteTar(w io.Writer) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+	for _, name := range b.names() {
+		data := b.Files[name]
+		h := &tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(data)),
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return fmt.Errorf("cannot write %q to tar bundle: %s", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("cannot write %q to tar bundle: %s", name, err)
+		}
+	}
+	return tw.Close()
+}
+
+// ReadBundleJSON reads bundle written by Bundle.WriteJSON.
+func ReadBundleJSON(r io.Reader) (*Bundle, error) {
+	var bj bundleJSON
+	if err := json.NewDecoder(r).Decode(&bj); err != nil {
+		return nil, fmt.Errorf("cannot read JSON bundle: %s", err)
+	}
+	b := &Bundle{
+		Root:  bj.Root,
+		Files: make(map[string][]byte, len(bj.Files)),
+	}
+	for name, data := range bj.Files {
+		b.Files[name] = []byte(data)
+	}
+	if err := b.validate(); err != nil {
+		return nil, fmt.Errorf("cannot read JSON bundle: %s", err)
+	}
+	return b, nil
+}
+
+// ReadBundleTar reads bundle written by Bundle.WriteTar.
+//
+// The first regular file in the archive is the root config.
+func ReadBundleTar(r io.Reader) (*Bundle, error) {
+	b := &Bundle{
+		Files: make(map[string][]byte),
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read tar bundle: %s", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %q from tar bundle: %s", h.Name, err)
+		}
+		name := path.Clean(h.Name)
+		if b.Root == "" {
+			b.Root = name
+		}
+		b.Files[name] = data
+	}
+	if b.Root == "" {
+		return nil, fmt.Errorf("cannot read tar bundle: no files found")
+	}
+	return b, nil
+}
+
+// ParseBundle parses the root config from b.
+//
+// @include directives are resolved against b.Files instead of the file
+// system. Compressed root config is transparently decompressed;
+// see RegisterDecompressor.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseBundle(b *Bundle) (*Value, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	data, err := decompress(b.Files[b.Root], &p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %q from bundle: %s", b.Root, err)
+	}
+	p.d = "."
+	p.f = b.Root
+	p.bundle = b
+	defer func() {
+		p.d = ""
+		p.f = ""
+		p.bundle = nil
+	}()
+	return p.ParseBytes(data)
+}
+
+// LoadBundle loads and parses the bundle file at path.
+//
+// The bundle may be either a tar archive or a JSON file written
+// by Bundle.WriteTar or Bundle.WriteJSON. Compressed bundles are
+// transparently decompressed; see RegisterDecompressor.
+// opts.Verifier, if set, verifies the whole bundle file.
+//
+// opts may be nil. The returned value doesn't reference any Parser,
+// so it remains valid for arbitrary long time.
+func LoadBundle(path string, opts *LoadOptions) (*Value, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	v, checksum, err := loadBundle(path, opts)
+	if err != nil {
+		emitAudit(opts.AuditSink, AuditLoadFailed, path, checksum, err)
+		return nil, err
+	}
+	emitAudit(opts.AuditSink, AuditLoaded, path, checksum, nil)
+	return v, nil
+}
+
+func loadBundle(path string, opts *LoadOptions) (*Value, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read bundle file error: %s", err)
+	}
+	checksum := sha256Hex(data)
+	cfg := opts.parserConfig()
+	if err := cfg.checkInputSize(len(data)); err != nil {
+		return nil, checksum, fmt.Errorf("cannot load bundle file %q: %s", path, err)
+	}
+	if opts.Verifier != nil {
+		if err := opts.Verifier.Verify(path, data); err != nil {
+			return nil, checksum, fmt.Errorf("cannot verify bundle file %q: %s", path, err)
+		}
+	}
+	if data, err = decompress(data, cfg); err != nil {
+		return nil, checksum, fmt.Errorf("cannot load bundle file %q: %s", path, err)
+	}
+
+	var b *Bundle
+	if s := skipWS(b2s(data)); len(s) > 0 && s[0] == '{' {
+		b, err = ReadBundleJSON(bytes.NewReader(data))
+	} else {
+		b, err = ReadBundleTar(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, checksum, fmt.Errorf("cannot load bundle file %q: %s", path, err)
+	}
+
+	// The root config may be compressed inside the bundle too.
+	var p Parser
+	p.Config.MaxInputSize = cfg.MaxInputSize
+	v, err := p.ParseBundle(b)
+	if err != nil {
+		return nil, checksum, fmt.Errorf("cannot parse bundle file %q: %s", path, err)
+	}
+	return v.Clone(), checksum, nil
+}
+
+// scanInclude returns files matching @include pattern.
+func (c *cache) scanInclude(pattern string) []string {
+	if c.bundle == nil {
+		return scanMatch(pattern)
+	}
+	pattern = path.Clean(pattern)
+	dir, base := path.Dir(pattern), path.Base(pattern)
+	var files []string
+	for _, name := range c.bundle.names() {
+		if path.Dir(name) == dir && matchFile(path.Base(name), base) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// readInclude returns the contents of the included file.
+func (c *cache) readInclude(file string) ([]byte, error) {
+	if c.bundle == nil {
+		return os.ReadFile(file)
+	}
+	data, ok := c.bundle.Files[file]
+	if !ok {
+		return nil, fmt.Errorf("missing file in bundle")
+	}
+	return data, nil
+}
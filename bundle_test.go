@@ -0,0 +1,137 @@
+package libconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"root.cfg": `a = 1;
+@include "a.cfg"
+@include "conf.d/*.cfg"
+`,
+		"a.cfg": `b = 2;`,
+	})
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0755); err != nil {
+		t.Fatalf("cannot create dir: %s", err)
+	}
+	for name, data := range map[string]string{
+		"x.cfg": `x = "x";`,
+		"y.cfg": `y = "y";`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "conf.d", name), []byte(data), 0644); err != nil {
+			t.Fatalf("cannot write %q: %s", name, err)
+		}
+	}
+	resultExpected := `{"a":1,"b":2,"x":"x","y":"y"}`
+
+	b, err := NewBundle(filepath.Join(dir, "root.cfg"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if b.Root != "root.cfg" {
+		t.Fatalf("unexpected root; got %q; want %q", b.Root, "root.cfg")
+	}
+	names := strings.Join(b.names(), ",")
+	if names != "root.cfg,a.cfg,conf.d/x.cfg,conf.d/y.cfg" {
+		t.Fatalf("unexpected bundle files: %s", names)
+	}
+
+	// Remove the original files, so the bundle must be self-contained.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("cannot remove dir: %s", err)
+	}
+
+	var p Parser
+	v, err := p.ParseBundle(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != resultExpected {
+		t.Fatalf("unexpected value; got %s; want %s", s, resultExpected)
+	}
+	if n := len(p.Includes()); n != 3 {
+		t.Fatalf("unexpected number of includes; got %d; want 3", n)
+	}
+
+	f := func(name string, write func(b *Bundle, w *bytes.Buffer) error) {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := write(b, &buf); err != nil {
+			t.Fatalf("cannot write bundle: %s", err)
+		}
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("cannot write %q: %s", path, err)
+		}
+		v, err := LoadBundle(path, &LoadOptions{
+			Verifier: SHA256Sum(sha256Hex(buf.Bytes())),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error when loading %s: %s", name, err)
+		}
+		if s := v.String(); s != resultExpected {
+			t.Fatalf("unexpected value for %s; got %s; want %s", name, s, resultExpected)
+		}
+	}
+	f("bundle.tar", func(b *Bundle, w *bytes.Buffer) error {
+		return b.WriteTar(w)
+	})
+	f("bundle.json", func(b *Bundle, w *bytes.Buffer) error {
+		return b.WriteJSON(w)
+	})
+}
+
+func TestBundleErrors(t *testing.T) {
+	// Missing includes are ignored in the same way as on disk.
+	b := &Bundle{
+		Root: "root.cfg",
+		Files: map[string][]byte{
+			"root.cfg": []byte(`@include "missing.cfg"
+a = 1;`),
+		},
+	}
+	var p Parser
+	v, err := p.ParseBundle(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != `{"a":1}` {
+		t.Fatalf("unexpected value; got %s; want %s", s, `{"a":1}`)
+	}
+
+	// Include cycle.
+	b.Files["a.cfg"] = []byte(`@include "root.cfg"`)
+	b.Files["root.cfg"] = []byte(`@include "a.cfg"`)
+	if _, err := p.ParseBundle(b); err == nil {
+		t.Fatalf("expecting non-nil error for include cycle")
+	}
+
+	// Missing root.
+	b.Root = "missing.cfg"
+	if _, err := p.ParseBundle(b); err == nil {
+		t.Fatalf("expecting non-nil error for missing root")
+	}
+	if err := b.WriteTar(&bytes.Buffer{}); err == nil {
+		t.Fatalf("expecting non-nil error for missing root")
+	}
+	if _, err := ReadBundleJSON(strings.NewReader(`{"root":"x","files":{}}`)); err == nil {
+		t.Fatalf("expecting non-nil error for missing root")
+	}
+
+	// Invalid UTF-8 in JSON bundle.
+	b.Root = "a.cfg"
+	b.Files["a.cfg"] = []byte("\xff")
+	if err := b.WriteJSON(&bytes.Buffer{}); err == nil {
+		t.Fatalf("expecting non-nil error for invalid UTF-8")
+	}
+
+	// Empty tar.
+	if _, err := ReadBundleTar(&bytes.Buffer{}); err == nil {
+		t.Fatalf("expecting non-nil error for empty tar")
+	}
+}
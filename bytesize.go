@@ -0,0 +1,115 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteSizeUnits contains multipliers for byte size units in lower case.
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ByteSize returns the number of bytes for the v.
+//
+// The value may be a non-negative integer number of bytes or a string
+// with a number followed by a unit, e.g. "512", "64KB", "1.5 GiB" or "10MiB".
+// Decimal units KB, MB, GB, TB and PB are powers of 1000, while binary units
+// KiB, MiB, GiB, TiB and PiB are powers of 1024. Units are case-insensitive.
+//
+// Use GetByteSize for obtaining byte size by keys path.
+func (v *Value) ByteSize() (int64, error) {
+	switch v.Type() {
+	case TypeNumber:
+		if !isIntToken(v.s) {
+			return 0, fmt.Errorf("byte size must be an integer; got %s", v.s)
+		}
+		n, err := parseIntToken(v.s)
+		if err != nil {
+			return 0, fmt.Errorf("cannot parse byte size %s: %s", v.s, err)
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("byte size cannot be negative; got %d", n)
+		}
+		return n, nil
+	case TypeString:
+		return parseByteSize(v.s)
+	default:
+		return 0, fmt.Errorf("value doesn't contain byte size; it contains %s", v.Type())
+	}
+}
+
+// GetByteSize returns the number of bytes by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// See Value.ByteSize for details.
+func (v *Value) GetByteSize(keys ...string) (int64, error) {
+	x := v.Get(keys...)
+	if x == nil {
+		return 0, fmt.Errorf("missing value at %q", Path(keys))
+	}
+	return x.ByteSize()
+}
+
+func parseByteSize(s string) (int64, error) {
+	n := 0
+	for n < len(s) && (isDigit(s[n]) || s[n] == '.') {
+		n++
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("cannot parse byte size %q: missing number", s)
+	}
+	f, err := strconv.ParseFloat(s[:n], 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse byte size %q: %s", s, err)
+	}
+	unit := strings.ToLower(strings.TrimSpace(s[n:]))
+	m, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("cannot parse byte size %q: unknown unit %q; supported units: B, KB, MB, GB, TB, PB, KiB, MiB, GiB, TiB, PiB", s, s[n:])
+	}
+	f = math.Round(f * m)
+	if f >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q is too big", s)
+	}
+	return int64(f), nil
+}
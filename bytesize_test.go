@@ -0,0 +1,51 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueGetByteSize(t *testing.T) {
+	f := func(s string, nExpected int64) {
+		t.Helper()
+		v := MustParse("size = " + s + ";")
+		n, err := v.GetByteSize("size")
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if n != nExpected {
+			t.Fatalf("unexpected byte size for %s; got %d; want %d", s, n, nExpected)
+		}
+	}
+
+	f(`512`, 512)
+	f(`0x10`, 16)
+	f(`"512"`, 512)
+	f(`"512B"`, 512)
+	f(`"64KB"`, 64000)
+	f(`"64kb"`, 64000)
+	f(`"10MiB"`, 10<<20)
+	f(`"1.5 GiB"`, 3<<29)
+	f(`"2GB"`, 2e9)
+	f(`"1TiB"`, 1<<40)
+	f(`"3 pb"`, 3e15)
+
+	fErr := func(s string) {
+		t.Helper()
+		v := MustParse("size = " + s + ";")
+		if _, err := v.GetByteSize("size"); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+	}
+	fErr(`-1`)
+	fErr(`1.5`)
+	fErr(`"-1KB"`)
+	fErr(`"KB"`)
+	fErr(`"10 XB"`)
+	fErr(`"1.2.3MB"`)
+	fErr(`"100000PiB"`)
+	fErr(`true`)
+
+	if _, err := MustParse(`a = 1;`).GetByteSize("missing"); err == nil {
+		t.Fatalf("expecting non-nil error for missing value")
+	}
+}
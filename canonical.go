@@ -0,0 +1,243 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MarshalCanonicalTo appends RFC 8785 (JSON Canonicalization Scheme)
+// representation of v to dst and returns the result.
+//
+// Object keys are sorted by their UTF-16 code units, numbers are formatted
+// as JavaScript numbers and strings are escaped minimally, so the result
+// may be hashed or signed deterministically.
+//
+// Integers outside [MinSafeInteger ... MaxSafeInteger] range are rounded
+// to the nearest IEEE 754 double as JavaScript does, e.g.
+// 12345678901234567890 is written as 12345678901234567000.
+//
+// An error is returned if v contains duplicate object keys, invalid UTF-8,
+// NaN or Inf, since they have no canonical representation. dst is returned
+// unchanged on error.
+func (v *Value) MarshalCanonicalTo(dst []byte) ([]byte, error) {
+	dstLen := len(dst)
+	dst, err := marshalCanonical(dst, v, 0)
+	if err != nil {
+		return dst[:dstLen], err
+	}
+	return dst, nil
+}
+
+func marshalCanonical(dst []byte, v *Value, depth int) ([]byte, error) {
+	depth++
+	if depth > MaxDepth {
+		return dst, fmt.Errorf("too big depth for the nested value; it exceeds %d", MaxDepth)
+	}
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		kvs := make([]kv, len(v.o.kvs))
+		copy(kvs, v.o.kvs)
+		sort.Slice(kvs, func(i, j int) bool {
+			return lessUTF16(kvs[i].k, kvs[j].k)
+		})
+		dst = append(dst, '{')
+		for i := range kvs {
+			if i > 0 {
+				if kvs[i].k == kvs[i-1].k {
+					return dst, fmt.Errorf("duplicate key %q", kvs[i].k)
+				}
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = appendCanonicalString(dst, kvs[i].k); err != nil {
+				return dst, err
+			}
+			dst = append(dst, ':')
+			if dst, err = marshalCanonical(dst, kvs[i].v, depth); err != nil {
+				return dst, fmt.Errorf("cannot marshal value for key %q: %s", kvs[i].k, err)
+			}
+		}
+		return append(dst, '}'), nil
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, x := range v.a {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			var err error
+			if dst, err = marshalCanonical(dst, x, depth); err != nil {
+				return dst, fmt.Errorf("cannot marshal array item #%d: %s", i, err)
+			}
+		}
+		return append(dst, ']'), nil
+	case TypeString:
+		return appendCanonicalString(dst, v.s)
+	case TypeNumber:
+		f, err := canonicalNumber(v)
+		if err != nil {
+			return dst, err
+		}
+		return appendCanonicalNumber(dst, f), nil
+	case TypeTrue:
+		return append(dst, "true"...), nil
+	case TypeFalse:
+		return append(dst, "false"...), nil
+	case TypeNull:
+		return append(dst, "null"...), nil
+	case TypeRawJSON:
+		var x Value
+		if err := x.UnmarshalJSON(s2b(v.s)); err != nil {
+			return dst, err
+		}
+		return marshalCanonical(dst, &x, depth-1)
+	default:
+		panic(fmt.Errorf("BUG: unexpected Value type: %d", v.t))
+	}
+}
+
+// canonicalNumber returns the IEEE 754 double nearest to the number in v.
+//
+// Unlike Value.JSNumber, it doesn't reject integers outside
+// [MinSafeInteger ... MaxSafeInteger] range.
+func canonicalNumber(v *Value) (float64, error) {
+	n, ok := parseBigIntToken(v.s)
+	if !ok {
+		return v.JSNumber()
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	if math.IsInf(f, 0) {
+		return 0, fmt.Errorf("integer %q cannot be represented in JSON", v.s)
+	}
+	return f, nil
+}
+
+// lessUTF16 returns true if a is less than b when compared
+// by UTF-16 code units as required by RFC 8785.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// appendCanonicalString appends s quoted according to RFC 8785 to dst.
+func appendCanonicalString(dst []byte, s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return dst, fmt.Errorf("string %q contains invalid UTF-8", s)
+	}
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '"' || ch == '\\':
+			dst = append(dst, '\\', ch)
+		case ch == '\b':
+			dst = append(dst, `\b`...)
+		case ch == '\t':
+			dst = append(dst, `\t`...)
+		case ch == '\n':
+			dst = append(dst, `\n`...)
+		case ch == '\f':
+			dst = append(dst, `\f`...)
+		case ch == '\r':
+			dst = append(dst, `\r`...)
+		case ch < 0x20:
+			dst = append(dst, `\u00`...)
+			dst = append(dst, "0123456789abcdef"[ch>>4], "0123456789abcdef"[ch&0xf])
+		default:
+			dst = append(dst, ch)
+		}
+	}
+	return append(dst, '"'), nil
+}
+
+// appendCanonicalNumber appends f formatted as in ECMAScript
+// Number.prototype.toString to dst. f must be finite.
+func appendCanonicalNumber(dst []byte, f float64) []byte {
+	if f == 0 {
+		// Negative zero is serialized as 0.
+		return append(dst, '0')
+	}
+	if f < 0 {
+		dst = append(dst, '-')
+		f = -f
+	}
+
+	// Obtain the shortest digits d and the exponent n, so f = 0.d * 10^n.
+	var buf [32]byte
+	b := strconv.AppendFloat(buf[:0], f, 'e', -1, 64)
+	nE := strings.IndexByte(b2s(b), 'e')
+	exp, _ := strconv.Atoi(b2s(b[nE+1:]))
+	digits := b[:1]
+	if nE > 1 {
+		digits = append(digits, b[2:nE]...)
+	}
+	k := len(digits)
+	n := exp + 1
+
+	switch {
+	case k <= n && n <= 21:
+		dst = append(dst, digits...)
+		for i := k; i < n; i++ {
+			dst = append(dst, '0')
+		}
+	case 0 < n && n <= 21:
+		dst = append(dst, digits[:n]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[n:]...)
+	case -6 < n && n <= 0:
+		dst = append(dst, "0."...)
+		for i := n; i < 0; i++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	default:
+		dst = append(dst, digits[0])
+		if k > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if n-1 >= 0 {
+			dst = append(dst, '+')
+		}
+		dst = strconv.AppendInt(dst, int64(n-1), 10)
+	}
+	return dst
+}
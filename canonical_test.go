@@ -0,0 +1,104 @@
+package libconfig
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMarshalCanonicalTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse("x = " + s + ";").Get("x")
+		b, err := v.MarshalCanonicalTo(nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result := string(b); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", s, result, resultExpected)
+		}
+	}
+
+	f(`{b = 1; a = [true, false, null]; c = {z = "x"; y = {};};}`, `{"a":[true,false,null],"b":1,"c":{"y":{},"z":"x"}}`)
+	f(`"a\"b\\c\n\t\u0001/€"`, `"a\"b\\c\n\t\u0001/€"`)
+	f(`0x10`, `16`)
+	f(`100L`, `100`)
+	f(`-0.0`, `0`)
+	f(`.5`, `0.5`)
+	f(`5.`, `5`)
+	f(`[-.25, 1.e2]`, `[-0.25,100]`)
+
+	// Number examples from RFC 8785.
+	f(`1e30`, `1e+30`)
+	f(`4.50`, `4.5`)
+	f(`2e-3`, `0.002`)
+	f(`0.000001`, `0.000001`)
+	f(`1e-7`, `1e-7`)
+	f(`333333333.33333329`, `333333333.3333333`)
+	f(`1e21`, `1e+21`)
+	f(`1e20`, `100000000000000000000`)
+	f(`-1.5e-10`, `-1.5e-10`)
+	f(`9007199254740991`, `9007199254740991`)
+
+	// Integers outside safe range are rounded to IEEE 754 doubles.
+	f(`9007199254740993`, `9007199254740992`)
+	f(`12345678901234567890`, `12345678901234567000`)
+	f(`-12345678901234567890`, `-12345678901234567000`)
+	f(`0xFFFFFFFFFFFFFFFF`, `18446744073709552000`)
+
+	// Errors
+	for _, s := range []string{`nan`, `inf`, `1` + strings.Repeat("0", 400), `{a = 1; a = 2;}`, `[1, 1e400]`, `{a = "x"; b = nan;}`} {
+		v := MustParse("x = " + s + ";").Get("x")
+		dst := []byte("prefix")
+		dst, err := v.MarshalCanonicalTo(dst)
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+		if string(dst) != "prefix" {
+			t.Fatalf("unexpected dst on error for %s; got %q; want %q", s, dst, "prefix")
+		}
+	}
+}
+
+func TestMarshalCanonicalToKeyOrder(t *testing.T) {
+	// The example from RFC 8785, section 3.2.3.
+	var a Arena
+	v := a.NewObject()
+	for _, k := range []string{"\u20ac", "\r", "\ufb33", "1", "\U0001F600", "\u0080", "\u00f6", "</script>"} {
+		v.Set(k, a.NewString(k))
+	}
+	b, err := v.MarshalCanonicalTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resultExpected := "{\"\\r\":\"\\r\",\"1\":\"1\",\"</script>\":\"</script>\",\"\u0080\":\"\u0080\",\"\u00f6\":\"\u00f6\",\"\u20ac\":\"\u20ac\",\"\U0001F600\":\"\U0001F600\",\"\ufb33\":\"\ufb33\"}"
+	if result := string(b); result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Invalid UTF-8 must be rejected.
+	if _, err := a.NewString("\xff").MarshalCanonicalTo(nil); err == nil {
+		t.Fatalf("expecting non-nil error for invalid UTF-8")
+	}
+}
+
+func TestAppendCanonicalNumber(t *testing.T) {
+	f := func(x float64, resultExpected string) {
+		t.Helper()
+		if result := string(appendCanonicalNumber(nil, x)); result != resultExpected {
+			t.Fatalf("unexpected result for %v; got %s; want %s", x, result, resultExpected)
+		}
+	}
+
+	// Examples from RFC 8785, Appendix B.
+	f(math.Float64frombits(0x0000000000000001), "5e-324")
+	f(math.Float64frombits(0x8000000000000001), "-5e-324")
+	f(math.Float64frombits(0x7fefffffffffffff), "1.7976931348623157e+308")
+	f(math.Float64frombits(0x4340000000000000), "9007199254740992")
+	f(math.Float64frombits(0x444b1ae4d6e2ef50), "1e+21")
+	f(math.Float64frombits(0x444b1ae4d6e2ef4f), "999999999999999900000")
+	f(math.Float64frombits(0x3eb0c6f7a0b5ed8d), "0.000001")
+	f(math.Float64frombits(0x3eb0c6f7a0b5ed8c), "9.999999999999997e-7")
+	f(math.Float64frombits(0x41b3de4355555553), "333333333.3333332")
+	f(math.Float64frombits(0xc3e0000000000000), "-9223372036854776000")
+}
@@ -0,0 +1,519 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ParseCBOR parses b containing CBOR data item ( RFC 8949 ).
+//
+// CBOR items are mapped to values in the following way:
+//
+//   - integers, floats and bignums (tags 2 and 3) are mapped to numbers;
+//     NaN and infinities are mapped to nan and inf numbers;
+//   - text strings are mapped to strings;
+//   - byte strings are mapped to base64url-encoded strings without padding;
+//   - undefined is mapped to null;
+//   - map keys are converted to strings, e.g. 1 becomes "1"; arrays and maps
+//     cannot be used as keys;
+//   - other tags are ignored, i.e. the tagged item is returned as is.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseCBOR(b []byte) (*Value, error) {
+	if err := p.Config.checkInputSize(len(b)); err != nil {
+		return nil, fmt.Errorf("cannot parse CBOR: %s", err)
+	}
+	p.b = append(p.b[:0], b...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	cp := &cborParser{
+		b: p.b,
+		c: &p.c,
+	}
+	v, err := cp.parseItem(0)
+	if err == nil && cp.n < len(cp.b) {
+		err = fmt.Errorf("unexpected trailing data at offset %d", cp.n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse CBOR: %s", err)
+	}
+	return v, nil
+}
+
+// ParseCBOR parses b containing CBOR data item.
+//
+// The function is slower than the Parser.ParseCBOR for re-used Parser.
+func ParseCBOR(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseCBOR(b)
+}
+
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+
+	// cborIndefinite is the additional info for indefinite-length items.
+	cborIndefinite = 31
+
+	cborBreak = 0xff
+)
+
+type cborParser struct {
+	b []byte
+
+	// n is the offset of the next item in b.
+	n int
+
+	c *cache
+}
+
+func (cp *cborParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", cp.n, fmt.Sprintf(format, args...))
+}
+
+// readHeader reads the item header and returns major type, additional info
+// and the argument.
+func (cp *cborParser) readHeader() (byte, byte, uint64, error) {
+	if cp.n >= len(cp.b) {
+		return 0, 0, 0, cp.errorf("unexpected end of data")
+	}
+	h := cp.b[cp.n]
+	major, info := h>>5, h&0x1f
+	cp.n++
+	var size int
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == cborIndefinite:
+		if major == cborUint || major == cborNegint || major == cborTag {
+			return 0, 0, 0, cp.errorf("indefinite length isn't allowed for major type %d", major)
+		}
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, cp.errorf("reserved additional info %d", info)
+	}
+	if len(cp.b)-cp.n < size {
+		return 0, 0, 0, cp.errorf("unexpected end of data")
+	}
+	var arg uint64
+	for _, ch := range cp.b[cp.n : cp.n+size] {
+		arg = arg<<8 | uint64(ch)
+	}
+	cp.n += size
+	return major, info, arg, nil
+}
+
+func (cp *cborParser) newNumber(s string) *Value {
+	v := cp.c.getValue()
+	v.t = TypeNumber
+	v.s = s
+	return v
+}
+
+func (cp *cborParser) newString(s string) *Value {
+	v := cp.c.getValue()
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+func (cp *cborParser) parseItem(depth int) (*Value, error) {
+	depth++
+	if maxDepth := cp.c.cfg.maxDepth(); depth > maxDepth {
+		return nil, cp.errorf("too big depth for the nested CBOR; it exceeds %d", maxDepth)
+	}
+	major, info, arg, err := cp.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return cp.newNumber(strconv.FormatUint(arg, 10)), nil
+	case cborNegint:
+		return cp.newNumber(cborNegative(arg)), nil
+	case cborBytes:
+		b, err := cp.readString(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		return cp.newString(base64.RawURLEncoding.EncodeToString(b)), nil
+	case cborText:
+		b, err := cp.readString(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if err := cp.c.cfg.checkStringLen(b2s(b)); err != nil {
+			return nil, err
+		}
+		return cp.newString(b2s(b)), nil
+	case cborArray:
+		return cp.parseArray(info, arg, depth)
+	case cborMap:
+		return cp.parseMap(info, arg, depth)
+	case cborTag:
+		return cp.parseTagged(arg, depth)
+	default:
+		return cp.parseSimple(info, arg)
+	}
+}
+
+func cborNegative(arg uint64) string {
+	if arg < math.MaxInt64 {
+		return strconv.FormatInt(-1-int64(arg), 10)
+	}
+	n := new(big.Int).SetUint64(arg)
+	n.Add(n, big.NewInt(1))
+	return n.Neg(n).String()
+}
+
+// readString reads byte or text string contents.
+func (cp *cborParser) readString(major, info byte, arg uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		if arg > uint64(len(cp.b)-cp.n) {
+			return nil, cp.errorf("too long string; length %d exceeds the remaining %d bytes", arg, len(cp.b)-cp.n)
+		}
+		b := cp.b[cp.n : cp.n+int(arg)]
+		cp.n += int(arg)
+		if major == cborText && !utf8.Valid(b) {
+			return nil, cp.errorf("invalid UTF-8 in text string")
+		}
+		return b, nil
+	}
+
+	// Indefinite-length string is a sequence of definite-length chunks.
+	var b []byte
+	for {
+		if cp.n >= len(cp.b) {
+			return nil, cp.errorf("missing break for indefinite-length string")
+		}
+		if cp.b[cp.n] == cborBreak {
+			cp.n++
+			return b, nil
+		}
+		chunkMajor, chunkInfo, chunkArg, err := cp.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, cp.errorf("invalid chunk in indefinite-length string")
+		}
+		chunk, err := cp.readString(chunkMajor, chunkInfo, chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+// atBreak returns true if the break stop code for indefinite-length
+// container is reached. The break is consumed.
+func (cp *cborParser) atBreak(info byte, i, count uint64) (bool, error) {
+	if info != cborIndefinite {
+		return i >= count, nil
+	}
+	if cp.n >= len(cp.b) {
+		return false, cp.errorf("missing break for indefinite-length container")
+	}
+	if cp.b[cp.n] == cborBreak {
+		cp.n++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (cp *cborParser) parseArray(info byte, count uint64, depth int) (*Value, error) {
+	if info != cborIndefinite && count > uint64(len(cp.b)-cp.n) {
+		return nil, cp.errorf("too many array items: %d", count)
+	}
+	a := cp.c.getValue()
+	a.t = TypeArray
+	a.a = a.a[:0]
+	for i := uint64(0); ; i++ {
+		done, err := cp.atBreak(info, i, count)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return a, nil
+		}
+		if err := cp.c.cfg.checkArrayLen(len(a.a) + 1); err != nil {
+			return nil, err
+		}
+		item, err := cp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		a.a = append(a.a, item)
+	}
+}
+
+func (cp *cborParser) parseMap(info byte, count uint64, depth int) (*Value, error) {
+	if info != cborIndefinite && count > uint64(len(cp.b)-cp.n)/2 {
+		return nil, cp.errorf("too many map entries: %d", count)
+	}
+	o := cp.c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	o.o.keysUnescaped = true
+	for i := uint64(0); ; i++ {
+		done, err := cp.atBreak(info, i, count)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return o, nil
+		}
+		keyOffset := cp.n
+		k, err := cp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		key, err := cborKey(k)
+		if err != nil {
+			cp.n = keyOffset
+			return nil, cp.errorf("%s", err)
+		}
+		if cp.c.cfg != nil && cp.c.cfg.RejectDuplicateKeys && o.o.Get(key) != nil {
+			cp.n = keyOffset
+			return nil, cp.errorf("duplicate map key %q", key)
+		}
+		v, err := cp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		appendObjectKV(&o.o, key, v)
+	}
+}
+
+// cborKey converts map key k to string.
+func cborKey(k *Value) (string, error) {
+	switch k.t {
+	case TypeString, TypeNumber:
+		return k.s, nil
+	case TypeTrue:
+		return "true", nil
+	case TypeFalse:
+		return "false", nil
+	case TypeNull:
+		return "null", nil
+	default:
+		return "", fmt.Errorf("cannot use %s as map key", k.t)
+	}
+}
+
+func (cp *cborParser) parseTagged(tag uint64, depth int) (*Value, error) {
+	if tag != 2 && tag != 3 {
+		// Ignore unknown tags.
+		return cp.parseItem(depth)
+	}
+
+	// Bignum.
+	major, info, arg, err := cp.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	if major != cborBytes {
+		return nil, cp.errorf("bignum must contain byte string; got major type %d", major)
+	}
+	b, err := cp.readString(major, info, arg)
+	if err != nil {
+		return nil, err
+	}
+	n := new(big.Int).SetBytes(b)
+	if tag == 3 {
+		n.Add(n, big.NewInt(1))
+		n.Neg(n)
+	}
+	return cp.newNumber(n.String()), nil
+}
+
+func (cp *cborParser) parseSimple(info byte, arg uint64) (*Value, error) {
+	var f float64
+	switch info {
+	case 20:
+		return valueFalse, nil
+	case 21:
+		return valueTrue, nil
+	case 22, 23:
+		return valueNull, nil
+	case 25:
+		f = float16ToFloat64(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	case cborIndefinite:
+		return nil, cp.errorf("unexpected break")
+	default:
+		return nil, cp.errorf("unsupported simple value %d", arg)
+	}
+	return cp.newNumber(formatCBORFloat(f)), nil
+}
+
+func formatCBORFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
+
+// MarshalCBORTo appends CBOR representation of v to dst and returns the result.
+//
+// Integers are encoded as CBOR integers or bignums if they don't fit 64 bits,
+// while other numbers are encoded as 64-bit floats. Objects are encoded
+// as maps with text string keys.
+//
+// An error is returned if v contains raw JSON values or invalid numbers.
+func (v *Value) MarshalCBORTo(dst []byte) ([]byte, error) {
+	var err error
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		dst = appendCBORHeader(dst, cborMap, uint64(len(v.o.kvs)))
+		for _, kv := range v.o.kvs {
+			dst = appendCBORHeader(dst, cborText, uint64(len(kv.k)))
+			dst = append(dst, kv.k...)
+			if dst, err = kv.v.MarshalCBORTo(dst); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	case TypeArray:
+		dst = appendCBORHeader(dst, cborArray, uint64(len(v.a)))
+		for _, item := range v.a {
+			if dst, err = item.MarshalCBORTo(dst); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	case TypeString:
+		dst = appendCBORHeader(dst, cborText, uint64(len(v.s)))
+		return append(dst, v.s...), nil
+	case TypeNumber:
+		return appendCBORNumber(dst, v.s)
+	case TypeFalse:
+		return append(dst, cborSimple<<5|20), nil
+	case TypeTrue:
+		return append(dst, cborSimple<<5|21), nil
+	case TypeNull:
+		return append(dst, cborSimple<<5|22), nil
+	default:
+		return dst, fmt.Errorf("cannot marshal %s to CBOR", v.Type())
+	}
+}
+
+func appendCBORHeader(dst []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(dst, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(dst, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), arg)
+	}
+}
+
+func appendCBORNumber(dst []byte, s string) ([]byte, error) {
+	if n, ok := parseBigIntToken(s); ok {
+		if n.Sign() >= 0 {
+			if n.IsUint64() {
+				return appendCBORHeader(dst, cborUint, n.Uint64()), nil
+			}
+			b := n.Bytes()
+			dst = appendCBORHeader(dst, cborTag, 2)
+			dst = appendCBORHeader(dst, cborBytes, uint64(len(b)))
+			return append(dst, b...), nil
+		}
+		// Negative integers are encoded as -1-n.
+		m := new(big.Int).Neg(n)
+		m.Sub(m, big.NewInt(1))
+		if m.IsUint64() {
+			return appendCBORHeader(dst, cborNegint, m.Uint64()), nil
+		}
+		b := m.Bytes()
+		dst = appendCBORHeader(dst, cborTag, 3)
+		dst = appendCBORHeader(dst, cborBytes, uint64(len(b)))
+		return append(dst, b...), nil
+	}
+	f, err := parseFloatToken(s)
+	if err != nil {
+		return dst, fmt.Errorf("cannot marshal number %q to CBOR: %s", s, err)
+	}
+	dst = append(dst, cborSimple<<5|27)
+	return binary.BigEndian.AppendUint64(dst, math.Float64bits(f)), nil
+}
@@ -0,0 +1,129 @@
+package libconfig
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseCBOR(t *testing.T) {
+	f := func(hexData, resultExpected string) {
+		t.Helper()
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		v, err := ParseCBOR(data)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %s: %s", hexData, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", hexData, result, resultExpected)
+		}
+	}
+
+	// Examples from RFC 8949, Appendix A.
+	f("00", "0")
+	f("17", "23")
+	f("1818", "24")
+	f("1903e8", "1000")
+	f("1bffffffffffffffff", "18446744073709551615")
+	f("c249010000000000000000", "18446744073709551616")
+	f("3bffffffffffffffff", "-18446744073709551616")
+	f("c349010000000000000000", "-18446744073709551617")
+	f("20", "-1")
+	f("3903e7", "-1000")
+	f("f93c00", "1")
+	f("f93e00", "1.5")
+	f("f90001", "5.960464477539063e-08")
+	f("fa47c35000", "100000")
+	f("fb3ff199999999999a", "1.1")
+	f("f97c00", "inf")
+	f("f97e00", "nan")
+	f("f9fc00", "-inf")
+	f("f4", "false")
+	f("f5", "true")
+	f("f6", "null")
+	f("f7", "null")
+	f("c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`)
+	f("c11a514b67b0", "1363896240")
+	f("4401020304", `"AQIDBA"`)
+	f("60", `""`)
+	f("6449455446", `"IETF"`)
+	f("62c3bc", `"ü"`)
+	f("80", "[]")
+	f("83010203", "[1,2,3]")
+	f("8301820203820405", "[1,[2,3],[4,5]]")
+	f("a0", "{}")
+	f("a201020304", `{"1":2,"3":4}`)
+	f("a26161016162820203", `{"a":1,"b":[2,3]}`)
+	f("a3f56161206162f66163", `{"true":"a","-1":"b","null":"c"}`)
+
+	// Indefinite-length items.
+	f("5f42010243030405ff", `"AQIDBAU"`)
+	f("7f657374726561646d696e67ff", `"streaming"`)
+	f("9fff", "[]")
+	f("9f018202039f0405ffff", "[1,[2,3],[4,5]]")
+	f("bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`)
+
+	// Errors
+	for _, hexData := range []string{
+		"",
+		"18",
+		"1c",
+		"62c3",
+		"62c328",
+		"8301",
+		"a1810101",
+		"9f01",
+		"ff",
+		"0001",
+		"f8ff",
+		"5f6161ff",
+		"c26161",
+	} {
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		if _, err := ParseCBOR(data); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", hexData)
+		}
+	}
+}
+
+func TestMarshalCBORTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse("x = " + s + ";").Get("x")
+		b, err := v.MarshalCBORTo(nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result := hex.EncodeToString(b); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", s, result, resultExpected)
+		}
+
+		// Verify the result is read back to the same value.
+		v2, err := ParseCBOR(b)
+		if err != nil {
+			t.Fatalf("cannot parse the marshaled CBOR %x: %s", b, err)
+		}
+		if !EqualExcept(v, v2) {
+			t.Fatalf("unexpected value after round trip; got %s; want %s", v2, v)
+		}
+	}
+
+	f("0", "00")
+	f("1000", "1903e8")
+	f("-1000", "3903e7")
+	f("0x1F", "181f")
+	f("18446744073709551616L", "c249010000000000000000")
+	f("-18446744073709551617L", "c349010000000000000000")
+	f("1.1", "fb3ff199999999999a")
+	f(".5", "fb3fe0000000000000")
+	f("5.", "fb4014000000000000")
+	f(`"IETF"`, "6449455446")
+	f("true", "f5")
+	f("[1, [2, 3]]", "8201820203")
+	f(`{a = 1; b = ["x"];}`, "a26161016162816178")
+}
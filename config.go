@@ -0,0 +1,84 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Config holds the current configuration snapshot.
+//
+// Snapshots returned from Config are frozen: they don't reference Parser
+// or Arena buffers and they are never modified, so they may be read and
+// iterated from concurrent goroutines while other goroutines swap
+// the configuration via Store or Update.
+//
+// Config may be used from concurrent goroutines.
+type Config struct {
+	// mu serializes Store and Update calls.
+	mu sync.Mutex
+
+	v atomic.Value
+}
+
+// Load returns the current snapshot.
+//
+// nil is returned if no snapshot has been stored yet.
+//
+// The returned value must not be modified. It remains valid and unchanged
+// after subsequent Store and Update calls.
+func (c *Config) Load() *Value {
+	v, _ := c.v.Load().(*Value)
+	return v
+}
+
+// Store stores a frozen copy of v as the current snapshot.
+//
+// v isn't referenced by c after the call, so the Parser returned v
+// may be re-used.
+func (c *Config) Store(v *Value) {
+	fv := v.Freeze()
+	c.mu.Lock()
+	c.v.Store(fv)
+	c.mu.Unlock()
+}
+
+// Update atomically replaces the current snapshot with the value returned by f.
+//
+// f is called with a copy of the current snapshot, which may be freely
+// modified with values allocated in a. The current snapshot is nil if no
+// snapshot has been stored yet. Readers continue observing the previous
+// snapshot until f returns.
+func (c *Config) Update(f func(a *Arena, v *Value) *Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var a Arena
+	cur := c.Load()
+	v := f(&a, copyContainers(&a, cur))
+	c.v.Store(v.Freeze())
+}
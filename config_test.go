@@ -0,0 +1,90 @@
+package libconfig
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConfigStoreLoad(t *testing.T) {
+	var c Config
+	if v := c.Load(); v != nil {
+		t.Fatalf("expecting nil snapshot; got %s", v)
+	}
+
+	var p Parser
+	v, err := p.Parse(`foo = "b\\ar"; baz = [1, 2]; obj = { x = 1; };`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Store(v)
+
+	// Re-using the parser mustn't affect the stored snapshot.
+	if _, err := p.Parse(`foo = "xxxxxxxx"; baz = [3];`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s := c.Load().String()
+	sExpected := `{"foo":"b\\ar","baz":[1,2],"obj":{"x":1}}`
+	if s != sExpected {
+		t.Fatalf("unexpected snapshot\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+}
+
+func TestConfigUpdate(t *testing.T) {
+	var c Config
+	c.Store(MustParse(`foo = 1; bar = { baz = "x"; };`))
+	old := c.Load()
+
+	c.Update(func(a *Arena, v *Value) *Value {
+		v.Set("foo", a.NewNumberInt(2))
+		v.Get("bar").Set("baz", a.NewString("y"))
+		return v
+	})
+
+	s := old.String()
+	sExpected := `{"foo":1,"bar":{"baz":"x"}}`
+	if s != sExpected {
+		t.Fatalf("old snapshot must be unchanged\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+	s = c.Load().String()
+	sExpected = `{"foo":2,"bar":{"baz":"y"}}`
+	if s != sExpected {
+		t.Fatalf("unexpected snapshot\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+}
+
+func TestConfigConcurrentIteration(t *testing.T) {
+	var c Config
+	c.Store(MustParse(`items = (1, 2, 3); obj = { a = "x"; b = "y"; };`))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				v := c.Load()
+				n := len(v.GetArray("items"))
+				for k, item := range v.GetArray("items") {
+					if item.GetInt() != k+1 {
+						panic(fmt.Errorf("unexpected item #%d: %s", k, item))
+					}
+				}
+				v.GetObject("obj").Visit(func(key []byte, v *Value) {
+					_ = v.GetStringBytes()
+				})
+				if len(v.GetArray("items")) != n {
+					panic(fmt.Errorf("torn snapshot"))
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		c.Update(func(a *Arena, v *Value) *Value {
+			v.Get("items").SetArrayItem(len(v.GetArray("items")), a.NewNumberInt(len(v.GetArray("items"))+1))
+			v.Get("obj").Set(fmt.Sprintf("k%d", i), a.NewString("z"))
+			return v
+		})
+	}
+	wg.Wait()
+}
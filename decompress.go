@@ -0,0 +1,160 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// NewDecompressorFunc must return a reader for decompressed data read from r.
+type NewDecompressorFunc func(r io.Reader) (io.ReadCloser, error)
+
+type decompressor struct {
+	name      string
+	magic     string
+	newReader NewDecompressorFunc
+}
+
+var (
+	decompressorsLock sync.RWMutex
+	decompressors     []decompressor
+)
+
+// RegisterDecompressor registers decompressor for the data starting
+// with the given magic bytes.
+//
+// LoadFile and Parser.ParseReader transparently decompress the data
+// with registered decompressors. gzip is registered by default, including
+// multi-member gzip streams. Other formats may be registered without
+// adding hard dependencies to this package, e.g. for zstd:
+//
+//	libconfig.RegisterDecompressor("zstd", "\x28\xb5\x2f\xfd", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+//
+// Decompressors registered later take precedence over the previously
+// registered decompressors with the same name.
+func RegisterDecompressor(name, magic string, newReader NewDecompressorFunc) {
+	decompressorsLock.Lock()
+	defer decompressorsLock.Unlock()
+
+	for i := range decompressors {
+		if decompressors[i].name == name {
+			decompressors[i] = decompressor{name, magic, newReader}
+			return
+		}
+	}
+	decompressors = append(decompressors, decompressor{name, magic, newReader})
+}
+
+func init() {
+	RegisterDecompressor("gzip", "\x1f\x8b", func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+}
+
+func findDecompressor(data []byte) *decompressor {
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+
+	for i := range decompressors {
+		d := &decompressors[i]
+		if len(d.magic) > 0 && bytes.HasPrefix(data, s2b(d.magic)) {
+			dCopy := *d
+			return &dCopy
+		}
+	}
+	return nil
+}
+
+// decompress returns decompressed data if data is compressed
+// with one of the registered decompressors.
+//
+// data is returned as is otherwise. An error is returned if decompressed
+// data exceeds cfg.MaxInputSize.
+func decompress(data []byte, cfg *ParserConfig) ([]byte, error) {
+	d := findDecompressor(data)
+	if d == nil {
+		return data, nil
+	}
+	zr, err := d.newReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize %s decompressor: %s", d.name, err)
+	}
+	result, err := readAll(zr, cfg)
+	if err != nil {
+		_ = zr.Close()
+		return nil, fmt.Errorf("cannot decompress %s data: %s", d.name, err)
+	}
+	if err := zr.Close(); err != nil {
+		return nil, fmt.Errorf("cannot close %s decompressor: %s", d.name, err)
+	}
+	return result, nil
+}
+
+// readAll reads all the data from r.
+//
+// It stops reading as soon as the data exceeds cfg.MaxInputSize,
+// so huge inputs aren't buffered in memory before being rejected.
+func readAll(r io.Reader, cfg *ParserConfig) ([]byte, error) {
+	if cfg == nil || cfg.MaxInputSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(cfg.MaxInputSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > cfg.MaxInputSize {
+		return nil, fmt.Errorf("too big input size: more than %d bytes; it exceeds MaxInputSize=%d", cfg.MaxInputSize, cfg.MaxInputSize)
+	}
+	return data, nil
+}
+
+// ParseReader reads all the data from r and parses it.
+//
+// The data is transparently decompressed if it is compressed with one of
+// the decompressors registered via RegisterDecompressor. Both the read
+// and the decompressed data are limited by p.Config.MaxInputSize.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseReader(r io.Reader) (*Value, error) {
+	data, err := readAll(r, &p.Config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read data: %s", err)
+	}
+	if data, err = decompress(data, &p.Config); err != nil {
+		return nil, err
+	}
+	return p.ParseBytes(data)
+}
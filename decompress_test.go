@@ -0,0 +1,164 @@
+package libconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gzipData(t *testing.T, ss ...string) []byte {
+	t.Helper()
+	var bb bytes.Buffer
+	for _, s := range ss {
+		zw := gzip.NewWriter(&bb)
+		if _, err := zw.Write([]byte(s)); err != nil {
+			t.Fatalf("cannot compress data: %s", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("cannot close gzip writer: %s", err)
+		}
+	}
+	return bb.Bytes()
+}
+
+func TestParserParseReader(t *testing.T) {
+	var p Parser
+	f := func(data []byte, resultExpected string) {
+		t.Helper()
+		v, err := p.ParseReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+		}
+	}
+	f([]byte(`a = 1;`), `{"a":1}`)
+	f(gzipData(t, `a = 1;`), `{"a":1}`)
+
+	// Concatenated gzip members
+	f(gzipData(t, `a = 1; `, `b = 2;`), `{"a":1,"b":2}`)
+
+	// Broken gzip data
+	data := gzipData(t, `a = 1;`)
+	if _, err := p.ParseReader(bytes.NewReader(data[:len(data)/2])); err == nil {
+		t.Fatalf("expecting non-nil error for truncated gzip data")
+	}
+}
+
+func TestParserParseReaderMaxInputSize(t *testing.T) {
+	var p Parser
+	p.Config.MaxInputSize = 4096
+	f := func(r io.Reader, errExpected string) {
+		t.Helper()
+		_, err := p.ParseReader(r)
+		if err == nil || !strings.Contains(err.Error(), errExpected) {
+			t.Fatalf("unexpected error; got %v; want %q", err, errExpected)
+		}
+	}
+
+	// The reader must not be drained past the limit.
+	f(io.MultiReader(strings.NewReader("a = "), zeroReader{}), "it exceeds MaxInputSize=4096")
+
+	// Highly compressed data must be rejected without decompressing it fully.
+	bomb := gzipData(t, "a = \""+strings.Repeat("x", 1<<20)+"\";")
+	if len(bomb) > p.Config.MaxInputSize {
+		t.Fatalf("too big compressed data: %d bytes", len(bomb))
+	}
+	f(bytes.NewReader(bomb), "cannot decompress gzip data: too big input size")
+
+	v, err := p.ParseReader(bytes.NewReader(gzipData(t, `a = 1;`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != `{"a":1}` {
+		t.Fatalf("unexpected result; got %s; want %s", s, `{"a":1}`)
+	}
+}
+
+// zeroReader is an endless reader of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	RegisterDecompressor("test-upper", "UPPER:", func(r io.Reader) (io.ReadCloser, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(strings.TrimPrefix(string(data), "UPPER:"))
+		return io.NopCloser(strings.NewReader(s)), nil
+	})
+
+	path := filepath.Join(t.TempDir(), "test.cfg.upper")
+	if err := os.WriteFile(path, []byte(`UPPER:FOO = "BAR";`), 0644); err != nil {
+		t.Fatalf("cannot write file: %s", err)
+	}
+	v, err := LoadFile(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes("foo")); s != "bar" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "bar")
+	}
+}
+
+func TestLoadFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cfg.gz")
+	if err := os.WriteFile(path, gzipData(t, `port = 8080;`), 0644); err != nil {
+		t.Fatalf("cannot write file: %s", err)
+	}
+	v, err := LoadFile(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+	}
+}
+
+func TestLoadFileMaxInputSize(t *testing.T) {
+	bomb := gzipData(t, "a = \""+strings.Repeat("x", 1<<20)+"\";")
+	path := filepath.Join(t.TempDir(), "bomb.cfg.gz")
+	if err := os.WriteFile(path, bomb, 0644); err != nil {
+		t.Fatalf("cannot write file: %s", err)
+	}
+	f := func(name string, err error) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), "it exceeds MaxInputSize=4096") {
+			t.Fatalf("%s: unexpected error; got %v; want MaxInputSize error", name, err)
+		}
+	}
+	opts := &LoadOptions{
+		MaxInputSize: 4096,
+	}
+	_, err := LoadFile(path, opts)
+	f("LoadFile", err)
+	_, err = LoadBundle(path, opts)
+	f("LoadBundle", err)
+	_, err = Watch(path, &WatchOptions{MaxInputSize: 4096})
+	f("Watch", err)
+
+	// Files are limited before decompression too.
+	opts.MaxInputSize = len(bomb) - 1
+	if _, err := LoadFile(path, opts); err == nil || !strings.Contains(err.Error(), "too big input size") {
+		t.Fatalf("unexpected error; got %v; want too big input size error", err)
+	}
+
+	// The default limit is big enough for the decompressed data.
+	v, err := LoadFile(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := len(v.GetStringBytes("a")); n != 1<<20 {
+		t.Fatalf("unexpected string length; got %d; want %d", n, 1<<20)
+	}
+}
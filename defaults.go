@@ -0,0 +1,114 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// GetStringOr returns string value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned for non-existing keys path or for invalid value type.
+// The returned string is a copy, so it remains valid after Parse
+// is called on the Parser returned v.
+func (v *Value) GetStringOr(def string, keys ...string) string {
+	v = v.Get(keys...)
+	if v == nil || v.Type() != TypeString {
+		return def
+	}
+	return string(s2b(v.s))
+}
+
+// GetIntOr returns int value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned for non-existing keys path, for invalid value type
+// or if the value doesn't fit int.
+func (v *Value) GetIntOr(def int, keys ...string) int {
+	v = v.Get(keys...)
+	if v == nil || v.Type() != TypeNumber || !isIntToken(v.s) {
+		return def
+	}
+	n, err := parseIntToken(v.s)
+	if err != nil || int64(int(n)) != n {
+		return def
+	}
+	return int(n)
+}
+
+// GetInt64Or returns int64 value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned for non-existing keys path, for invalid value type
+// or if the value doesn't fit int64.
+func (v *Value) GetInt64Or(def int64, keys ...string) int64 {
+	v = v.Get(keys...)
+	if v == nil || v.Type() != TypeNumber || !isIntToken(v.s) {
+		return def
+	}
+	n, err := parseIntToken(v.s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// GetFloat64Or returns float64 value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned for non-existing keys path or for invalid value type.
+func (v *Value) GetFloat64Or(def float64, keys ...string) float64 {
+	v = v.Get(keys...)
+	if v == nil || v.Type() != TypeNumber {
+		return def
+	}
+	f, err := parseFloatToken(v.s)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// GetBoolOr returns bool value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned for non-existing keys path or for invalid value type.
+func (v *Value) GetBoolOr(def bool, keys ...string) bool {
+	v = v.Get(keys...)
+	if v == nil {
+		return def
+	}
+	switch v.Type() {
+	case TypeTrue:
+		return true
+	case TypeFalse:
+		return false
+	default:
+		return def
+	}
+}
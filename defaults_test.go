@@ -0,0 +1,89 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueGetOr(t *testing.T) {
+	v := MustParse(`s = "foo"; e = ""; i = 0; h = 0x10; big = 100000000000000000000L; fl = 1.5; b = false; a = [1, "x"];`)
+
+	if s := v.GetStringOr("def", "s"); s != "foo" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "foo")
+	}
+	if s := v.GetStringOr("def", "e"); s != "" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "")
+	}
+	if s := v.GetStringOr("def", "i"); s != "def" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "def")
+	}
+	if s := v.GetStringOr("def", "a", "1"); s != "x" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "x")
+	}
+
+	f := func(key string, nExpected int) {
+		t.Helper()
+		if n := v.GetIntOr(42, key); n != nExpected {
+			t.Fatalf("unexpected int for %q; got %d; want %d", key, n, nExpected)
+		}
+	}
+	f("i", 0)
+	f("h", 16)
+	f("big", 42)
+	f("fl", 42)
+	f("s", 42)
+	f("missing", 42)
+
+	if n := v.GetInt64Or(-1, "h"); n != 16 {
+		t.Fatalf("unexpected int64; got %d; want %d", n, 16)
+	}
+	if n := v.GetInt64Or(-1, "big"); n != -1 {
+		t.Fatalf("unexpected int64; got %d; want %d", n, -1)
+	}
+	if x := v.GetFloat64Or(2.5, "fl"); x != 1.5 {
+		t.Fatalf("unexpected float64; got %v; want %v", x, 1.5)
+	}
+	if x := v.GetFloat64Or(2.5, "h"); x != 16 {
+		t.Fatalf("unexpected float64; got %v; want %v", x, 16)
+	}
+	if x := v.GetFloat64Or(2.5, "b"); x != 2.5 {
+		t.Fatalf("unexpected float64; got %v; want %v", x, 2.5)
+	}
+	if b := v.GetBoolOr(true, "b"); b {
+		t.Fatalf("unexpected bool; got %v; want %v", b, false)
+	}
+	if b := v.GetBoolOr(true, "missing"); !b {
+		t.Fatalf("unexpected bool; got %v; want %v", b, true)
+	}
+	if b := v.GetBoolOr(true, "s"); !b {
+		t.Fatalf("unexpected bool; got %v; want %v", b, true)
+	}
+}
+
+func TestHandyGetOr(t *testing.T) {
+	data := []byte(`port = 0; host = "localhost"; debug = false; ratio = 0.5;`)
+
+	if n := GetIntOr(data, 8080, "port"); n != 0 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 0)
+	}
+	if n := GetIntOr(data, 8080, "missing"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+	}
+	if n := GetIntOr([]byte("invalid"), 8080, "port"); n != 8080 {
+		t.Fatalf("unexpected port for invalid data; got %d; want %d", n, 8080)
+	}
+	if s := GetStringOr(data, "0.0.0.0", "host"); s != "localhost" {
+		t.Fatalf("unexpected host; got %q; want %q", s, "localhost")
+	}
+	if s := GetStringOr(data, "0.0.0.0", "port"); s != "0.0.0.0" {
+		t.Fatalf("unexpected host; got %q; want %q", s, "0.0.0.0")
+	}
+	if b := GetBoolOr(data, true, "debug"); b {
+		t.Fatalf("unexpected debug; got %v; want %v", b, false)
+	}
+	if x := GetFloat64Or(data, 1, "ratio"); x != 0.5 {
+		t.Fatalf("unexpected ratio; got %v; want %v", x, 0.5)
+	}
+	if x := GetFloat64Or(data, 1, "host"); x != 1 {
+		t.Fatalf("unexpected ratio; got %v; want %v", x, 1)
+	}
+}
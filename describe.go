@@ -0,0 +1,277 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Description contains structural statistics for a Value tree.
+//
+// It is returned by Describe and helps understanding and trimming bloated
+// configs.
+type Description struct {
+	// Values is the total number of values in the tree including the root.
+	Values int
+
+	// Keys is the total number of object keys in the tree.
+	Keys int
+
+	// Depths contains the number of values at every depth.
+	//
+	// Depths[0] is always 1 for the root value.
+	Depths []int
+
+	// Types contains the number of values per type.
+	Types map[Type]int
+
+	// Size is the serialized size of the tree in bytes.
+	Size int
+
+	// Largest contains the largest subtrees by serialized size
+	// in descending order. The root value isn't included.
+	//
+	// At most DescribeMaxLargest subtrees are collected.
+	Largest []SubtreeSize
+}
+
+// SubtreeSize is the serialized size of the subtree at Path.
+type SubtreeSize struct {
+	Path Path
+	Size int
+}
+
+// DescribeMaxLargest is the maximum number of subtrees collected
+// in Description.Largest.
+const DescribeMaxLargest = 10
+
+// Describe returns structural statistics for v.
+//
+// The serialized sizes are calculated for the output of Value.MarshalTo.
+func Describe(v *Value) *Description {
+	d := &Description{
+		Types: make(map[Type]int),
+	}
+	if v == nil {
+		return d
+	}
+	var ds describer
+	d.Size = ds.describe(d, v, nil)
+	return d
+}
+
+// MaxDepth returns the maximum depth of values in the tree.
+func (d *Description) MaxDepth() int {
+	if len(d.Depths) == 0 {
+		return 0
+	}
+	return len(d.Depths) - 1
+}
+
+// String returns human-readable report for d.
+func (d *Description) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "values: %d\nkeys: %d\nsize: %d bytes\nmax depth: %d\n", d.Values, d.Keys, d.Size, d.MaxDepth())
+	sb.WriteString("depths:\n")
+	for depth, n := range d.Depths {
+		fmt.Fprintf(&sb, "  %d: %d\n", depth, n)
+	}
+	types := make([]Type, 0, len(d.Types))
+	for t := range d.Types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	sb.WriteString("types:\n")
+	for _, t := range types {
+		fmt.Fprintf(&sb, "  %s: %d\n", t, d.Types[t])
+	}
+	if len(d.Largest) > 0 {
+		sb.WriteString("largest subtrees:\n")
+		for _, st := range d.Largest {
+			fmt.Fprintf(&sb, "  %s: %d bytes\n", st.Path, st.Size)
+		}
+	}
+	return sb.String()
+}
+
+type describer struct {
+	buf []byte
+}
+
+// describe collects statistics for v at path into d and returns
+// the serialized size of v.
+func (ds *describer) describe(d *Description, v *Value, path []string) int {
+	depth := len(path)
+	for len(d.Depths) <= depth {
+		d.Depths = append(d.Depths, 0)
+	}
+	d.Depths[depth]++
+	d.Values++
+	t := v.Type()
+	d.Types[t]++
+
+	var size int
+	switch t {
+	case TypeObject:
+		v.o.unescapeKeys()
+		size = 2
+		for i, kv := range v.o.kvs {
+			d.Keys++
+			ds.buf = escapeString(ds.buf[:0], kv.k)
+			size += len(ds.buf) + 1
+			size += ds.describe(d, kv.v, append(path, kv.k))
+			if i > 0 {
+				size++
+			}
+		}
+	case TypeArray:
+		size = 2
+		for i, item := range v.a {
+			size += ds.describe(d, item, append(path, strconv.Itoa(i)))
+			if i > 0 {
+				size++
+			}
+		}
+	default:
+		ds.buf = v.MarshalTo(ds.buf[:0])
+		size = len(ds.buf)
+	}
+	if depth > 0 {
+		d.addLargest(path, size)
+	}
+	return size
+}
+
+func (d *Description) addLargest(path []string, size int) {
+	n := sort.Search(len(d.Largest), func(i int) bool {
+		return d.Largest[i].Size < size
+	})
+	if n >= DescribeMaxLargest {
+		return
+	}
+	if len(d.Largest) < DescribeMaxLargest {
+		d.Largest = append(d.Largest, SubtreeSize{})
+	}
+	copy(d.Largest[n+1:], d.Largest[n:])
+	// Keys may point into the Parser buffer, so copy them.
+	p := make(Path, len(path))
+	for i, k := range path {
+		p[i] = strings.Clone(k)
+	}
+	d.Largest[n] = SubtreeSize{
+		Path: p,
+		Size: size,
+	}
+}
+
+// ValueStats contains cheap counters for a Value tree.
+//
+// Unlike Describe, Value.Stats doesn't serialize the tree, so it may be used
+// for rejecting pathological inputs right after parsing.
+type ValueStats struct {
+	// Objects is the number of objects in the tree.
+	Objects int
+
+	// Arrays is the number of arrays in the tree.
+	Arrays int
+
+	// Strings is the number of string values in the tree.
+	Strings int
+
+	// Numbers is the number of number values in the tree.
+	Numbers int
+
+	// Bools is the number of true and false values in the tree.
+	Bools int
+
+	// Nulls is the number of null values in the tree.
+	Nulls int
+
+	// Keys is the total number of object keys in the tree.
+	Keys int
+
+	// MaxDepth is the maximum depth of values in the tree.
+	//
+	// The depth of the root value is 0.
+	MaxDepth int
+
+	// LongestString is the length in bytes of the longest string value
+	// or object key in the tree.
+	LongestString int
+
+	// MemorySize is the approximate number of bytes occupied by the tree
+	// in memory.
+	MemorySize int
+}
+
+// Stats returns counters for v and its descendants.
+func (v *Value) Stats() ValueStats {
+	var vs ValueStats
+	if v != nil {
+		vs.collect(v, 0)
+		vs.MemorySize = liveSize(v)
+	}
+	return vs
+}
+
+func (vs *ValueStats) collect(v *Value, depth int) {
+	if depth > vs.MaxDepth {
+		vs.MaxDepth = depth
+	}
+	switch v.Type() {
+	case TypeObject:
+		vs.Objects++
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			vs.Keys++
+			if len(kv.k) > vs.LongestString {
+				vs.LongestString = len(kv.k)
+			}
+			vs.collect(kv.v, depth+1)
+		}
+	case TypeArray:
+		vs.Arrays++
+		for _, item := range v.a {
+			vs.collect(item, depth+1)
+		}
+	case TypeString:
+		vs.Strings++
+		if len(v.s) > vs.LongestString {
+			vs.LongestString = len(v.s)
+		}
+	case TypeNumber:
+		vs.Numbers++
+	case TypeTrue, TypeFalse:
+		vs.Bools++
+	case TypeNull:
+		vs.Nulls++
+	}
+}
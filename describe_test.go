@@ -0,0 +1,121 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	s := `name = "app"; server = {host = "localhost"; ports = [80, 443]; tls = true;}; tags = ["a\nb"]; empty = {};`
+	v := MustParse(s)
+	d := Describe(v)
+
+	if d.Size != len(v.MarshalTo(nil)) {
+		t.Fatalf("unexpected size; got %d; want %d", d.Size, len(v.MarshalTo(nil)))
+	}
+	if d.Values != 11 {
+		t.Fatalf("unexpected number of values; got %d; want %d", d.Values, 11)
+	}
+	if d.Keys != 7 {
+		t.Fatalf("unexpected number of keys; got %d; want %d", d.Keys, 7)
+	}
+	if d.MaxDepth() != 3 {
+		t.Fatalf("unexpected max depth; got %d; want %d", d.MaxDepth(), 3)
+	}
+	depths := []int{1, 4, 4, 2}
+	for i, n := range depths {
+		if d.Depths[i] != n {
+			t.Fatalf("unexpected number of values at depth %d; got %d; want %d", i, d.Depths[i], n)
+		}
+	}
+	types := map[Type]int{
+		TypeObject: 3,
+		TypeArray:  2,
+		TypeString: 3,
+		TypeNumber: 2,
+		TypeTrue:   1,
+	}
+	for tp, n := range types {
+		if d.Types[tp] != n {
+			t.Fatalf("unexpected number of %s values; got %d; want %d", tp, d.Types[tp], n)
+		}
+	}
+
+	if len(d.Largest) != 10 {
+		t.Fatalf("unexpected number of largest subtrees; got %d; want %d", len(d.Largest), 10)
+	}
+	largest := d.Largest[0]
+	if largest.Path.String() != "server" {
+		t.Fatalf("unexpected largest subtree; got %q; want %q", largest.Path, "server")
+	}
+	if sizeExpected := len(v.Get("server").MarshalTo(nil)); largest.Size != sizeExpected {
+		t.Fatalf("unexpected size for the largest subtree; got %d; want %d", largest.Size, sizeExpected)
+	}
+	for i := 1; i < len(d.Largest); i++ {
+		if d.Largest[i].Size > d.Largest[i-1].Size {
+			t.Fatalf("largest subtrees must be sorted by size; got %d after %d", d.Largest[i].Size, d.Largest[i-1].Size)
+		}
+	}
+
+	report := d.String()
+	for _, line := range []string{"values: 11\n", "max depth: 3\n", "  object: 3\n", "  server: "} {
+		if !strings.Contains(report, line) {
+			t.Fatalf("missing %q in report\n%s", line, report)
+		}
+	}
+
+	// nil value
+	d = Describe(nil)
+	if d.Values != 0 || d.MaxDepth() != 0 {
+		t.Fatalf("unexpected description for nil value: %+v", d)
+	}
+}
+
+func TestDescribeParserReuse(t *testing.T) {
+	var p Parser
+	v, err := p.Parse(`server = { hosts = ["a", "b", "c"]; };`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	d := Describe(v)
+	if _, err := p.Parse(`XXXXXX = { XXXXX = ["x", "y", "z"]; };`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := d.Largest[0].Path.String(); s != "server" {
+		t.Fatalf("unexpected path after parser re-use; got %q; want %q", s, "server")
+	}
+	if s := d.Largest[1].Path.String(); s != "server.hosts" {
+		t.Fatalf("unexpected path after parser re-use; got %q; want %q", s, "server.hosts")
+	}
+}
+
+func TestValueStats(t *testing.T) {
+	v := MustParse(`name = "application"; db = {port = 5432; hosts = ["a", "bb"]; tls = null;}; flags = [true, false, 1.5];`)
+	vs := v.Stats()
+	if vs.MemorySize <= 0 {
+		t.Fatalf("expecting positive MemorySize; got %d", vs.MemorySize)
+	}
+	vs.MemorySize = 0
+	vsExpected := ValueStats{
+		Objects:       2,
+		Arrays:        2,
+		Strings:       3,
+		Numbers:       2,
+		Bools:         2,
+		Nulls:         1,
+		Keys:          6,
+		MaxDepth:      3,
+		LongestString: 11,
+	}
+	if vs != vsExpected {
+		t.Fatalf("unexpected stats\ngot\n%+v\nwant\n%+v", vs, vsExpected)
+	}
+
+	if vs := MustParse(`x = 1;`).Get("x").Stats(); vs.MaxDepth != 0 || vs.Numbers != 1 {
+		t.Fatalf("unexpected stats for scalar value: %+v", vs)
+	}
+	var nv *Value
+	if vs := nv.Stats(); vs != (ValueStats{}) {
+		t.Fatalf("unexpected stats for nil value: %+v", vs)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Editor performs surgical edits on libconfig documents.
+//
+// Only the edited values are rewritten, while comments, spacing and
+// formatting in the rest of the document remain untouched. This is useful
+// for programmatic changes of handcrafted configs.
+//
+// Byte offsets of values are located on every edit, so Editor doesn't retain
+// parsed values between edits. @include directives aren't expanded.
+//
+// Editor cannot be used from concurrent goroutines.
+type Editor struct {
+	// Config contains optional settings for validating the edited document.
+	Config ParserConfig
+
+	b []byte
+	p Parser
+	c cache
+}
+
+// NewEditor returns an Editor for a copy of data.
+//
+// An error is returned if data isn't a valid libconfig document.
+func NewEditor(data []byte) (*Editor, error) {
+	e := &Editor{
+		b: append([]byte(nil), data...),
+	}
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Bytes returns the edited document.
+//
+// The returned bytes are valid until the next edit.
+func (e *Editor) Bytes() []byte {
+	return e.b
+}
+
+// Value parses the edited document and returns it.
+//
+// The returned value is valid until the next call to Value.
+func (e *Editor) Value() (*Value, error) {
+	e.p.Config = e.Config
+	return e.p.ParseBytes(e.b)
+}
+
+// Offsets returns byte offsets for the start and the end of the value
+// at the given keys path in the edited document.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (e *Editor) Offsets(keys ...string) (int, int, error) {
+	if len(keys) == 0 {
+		return 0, len(e.b), nil
+	}
+	raw, end, err := e.locate(keys)
+	if err != nil {
+		return 0, 0, err
+	}
+	return e.offset(raw), e.valueEnd(raw, end), nil
+}
+
+// Replace replaces the value at the given keys path with raw libconfig
+// value such as `42`, `"foo"` or `[1, 2]`.
+//
+// The rest of the document remains untouched.
+func (e *Editor) Replace(raw string, keys ...string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("cannot replace the root value")
+	}
+	if err := checkEditorValue(raw); err != nil {
+		return err
+	}
+	start, end, err := e.Offsets(keys...)
+	if err != nil {
+		return err
+	}
+	return e.splice(start, end, raw)
+}
+
+// SetString replaces the value at the given keys path with string s.
+//
+// See Replace for details.
+func (e *Editor) SetString(s string, keys ...string) error {
+	return e.Replace(string(escapeString(nil, s)), keys...)
+}
+
+// SetInt replaces the value at the given keys path with n.
+//
+// See Replace for details.
+func (e *Editor) SetInt(n int, keys ...string) error {
+	return e.Replace(strconv.Itoa(n), keys...)
+}
+
+// Insert adds `key = raw;` entry to the end of the object at the given
+// keys path. The root object is used if keys are empty.
+//
+// The indentation of the new entry is copied from the last entry
+// in the object. An error is returned if the object already contains key.
+func (e *Editor) Insert(key, raw string, keys ...string) error {
+	if err := checkEditorEntry(key, raw); err != nil {
+		return err
+	}
+
+	src := b2s(e.b)
+	var o lazyObject
+	var closing int
+	open := -1
+	if len(keys) == 0 {
+		if _, err := o.index(e.cache(), src, true); err != nil {
+			return fmt.Errorf("cannot index the document: %s", err)
+		}
+		closing = len(strings.TrimRight(src, " \t\r\n"))
+	} else {
+		rawObj, _, err := e.locate(keys)
+		if err != nil {
+			return err
+		}
+		if len(rawObj) == 0 || rawObj[0] != '{' {
+			return fmt.Errorf("cannot insert key %q into non-object at %q", key, Path(keys))
+		}
+		tail, err := o.index(e.cache(), rawObj[1:], false)
+		if err != nil {
+			return fmt.Errorf("cannot index object at %q: %s", Path(keys), err)
+		}
+		open = e.offset(rawObj)
+		closing = e.offset(tail) - 1
+	}
+	if o.find(key) != nil {
+		return fmt.Errorf("key %q already exists at %q", key, Path(keys))
+	}
+
+	entry := key + " = " + raw + ";"
+	if len(o.entries) == 0 {
+		if open < 0 && closing > 0 {
+			entry = "\n" + entry
+		}
+		return e.splice(closing, closing, entry)
+	}
+
+	// Insert the entry after the last entry.
+	last := &o.entries[len(o.entries)-1]
+	lastStart := e.offset(last.raw)
+	pos := e.valueEnd(last.raw, last.end)
+	prefix := ""
+	if s := skipWSOnly(src[pos:]); len(s) > 0 && s[0] == ';' {
+		pos = len(src) - len(s) + 1
+	} else {
+		prefix = ";"
+	}
+	if open >= 0 && strings.IndexByte(src[open:lastStart], '\n') < 0 {
+		// Single-line object.
+		return e.splice(pos, pos, prefix+" "+entry)
+	}
+	if prefix == "" {
+		// Keep the comment after the last entry on its line.
+		lineEnd := strings.IndexByte(src[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(src) - pos
+		}
+		if rest := strings.TrimLeft(src[pos:pos+lineEnd], " \t\r"); rest == "" || rest[0] == '#' || strings.HasPrefix(rest, "//") {
+			pos += len(strings.TrimRight(src[pos:pos+lineEnd], "\r"))
+		}
+	}
+	return e.splice(pos, pos, prefix+"\n"+lineIndent(src, lastStart)+entry)
+}
+
+// locate returns the raw value and the tail after it for the given keys path.
+func (e *Editor) locate(keys []string) (string, string, error) {
+	src := b2s(e.b)
+	var o lazyObject
+	if _, err := o.index(e.cache(), src, true); err != nil {
+		return "", "", fmt.Errorf("cannot index the document: %s", err)
+	}
+	ent := o.find(keys[0])
+	if ent == nil {
+		return "", "", fmt.Errorf("missing key %q", keys[0])
+	}
+	raw, end := ent.raw, ent.end
+	for i, key := range keys[1:] {
+		prefix := Path(keys[:i+1])
+		switch {
+		case len(raw) > 0 && raw[0] == '{':
+			var child lazyObject
+			if _, err := child.index(e.cache(), raw[1:], false); err != nil {
+				return "", "", fmt.Errorf("cannot index object at %q: %s", prefix, err)
+			}
+			ent := child.find(key)
+			if ent == nil {
+				return "", "", fmt.Errorf("missing key %q at %q", key, prefix)
+			}
+			raw, end = ent.raw, ent.end
+		case len(raw) > 0 && (raw[0] == '[' || raw[0] == '('):
+			items, err := scanArrayItems(nil, raw[1:])
+			if err != nil {
+				return "", "", fmt.Errorf("cannot index array at %q: %s", prefix, err)
+			}
+			n, err := strconv.Atoi(key)
+			if err != nil || n < 0 || n >= len(items) {
+				return "", "", fmt.Errorf("invalid array index %q at %q; array length is %d", key, prefix, len(items))
+			}
+			raw, end = items[n].raw, items[n].end
+		default:
+			return "", "", fmt.Errorf("cannot lookup key %q in scalar value at %q", key, prefix)
+		}
+	}
+	return raw, end, nil
+}
+
+func (e *Editor) cache() *cache {
+	e.c.reset()
+	e.c.cfg = &e.Config
+	e.c.src = b2s(e.b)
+	e.c.unwrapped = true
+	return &e.c
+}
+
+// offset returns the offset of the tail s in e.b.
+func (e *Editor) offset(s string) int {
+	return len(e.b) - len(s)
+}
+
+// valueEnd returns the offset of the end of the value starting at raw
+// with the tail end, excluding trailing whitespace.
+func (e *Editor) valueEnd(raw, end string) int {
+	start := e.offset(raw)
+	n := e.offset(end)
+	for n > start && isWS(e.b[n-1]) {
+		n--
+	}
+	return n
+}
+
+// splice replaces e.b[start:end] with s and validates the result.
+//
+// The previous document is restored if the result is invalid.
+func (e *Editor) splice(start, end int, s string) error {
+	b := make([]byte, 0, len(e.b)-(end-start)+len(s))
+	b = append(b, e.b[:start]...)
+	b = append(b, s...)
+	b = append(b, e.b[end:]...)
+	prev := e.b
+	e.b = b
+	if err := e.validate(); err != nil {
+		e.b = prev
+		return fmt.Errorf("the edit results in invalid document: %s", err)
+	}
+	return nil
+}
+
+func (e *Editor) validate() error {
+	_, err := e.Value()
+	return err
+}
+
+// checkEditorValue verifies raw is a single libconfig value.
+func checkEditorValue(raw string) error {
+	if err := checkEditorEntry("x", raw); err != nil {
+		return fmt.Errorf("invalid value %q", raw)
+	}
+	return nil
+}
+
+// checkEditorEntry verifies `key = raw;` is a single libconfig entry.
+func checkEditorEntry(key, raw string) error {
+	var p Parser
+	v, err := p.Parse(key + " = " + raw + ";")
+	if err != nil || strings.TrimSpace(raw) == "" {
+		return fmt.Errorf("invalid entry for key %q with value %q", key, raw)
+	}
+	o := v.GetObject()
+	if o.Len() != 1 || o.Get(key) == nil {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+// skipWSOnly skips whitespace in s without skipping comments.
+func skipWSOnly(s string) string {
+	for len(s) > 0 && isWS(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+// lineIndent returns leading whitespace for the line containing src[n].
+func lineIndent(src string, n int) string {
+	start := strings.LastIndexByte(src[:n], '\n') + 1
+	end := start
+	for end < n && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
@@ -0,0 +1,148 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestEditorReplace(t *testing.T) {
+	const doc = `# server settings
+server = {
+    host = "localhost";   // the host
+    port    = 8080;
+    tags = [ "a", "b" ];
+};
+debug = false;
+`
+	f := func(raw string, keys []string, resultExpected string) {
+		t.Helper()
+		e, err := NewEditor([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := e.Replace(raw, keys...); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := string(e.Bytes())
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f("9090", []string{"server", "port"}, `# server settings
+server = {
+    host = "localhost";   // the host
+    port    = 9090;
+    tags = [ "a", "b" ];
+};
+debug = false;
+`)
+	f(`"x"`, []string{"server", "tags", "1"}, `# server settings
+server = {
+    host = "localhost";   // the host
+    port    = 8080;
+    tags = [ "a", "x" ];
+};
+debug = false;
+`)
+	f("true", []string{"debug"}, `# server settings
+server = {
+    host = "localhost";   // the host
+    port    = 8080;
+    tags = [ "a", "b" ];
+};
+debug = true;
+`)
+
+	e, err := NewEditor([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.SetString("example.com", "server", "host"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.SetInt(1, "server", "port"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	v, err := e.Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes("server", "host")); s != "example.com" {
+		t.Fatalf("unexpected host; got %q; want %q", s, "example.com")
+	}
+	if n := v.GetInt("server", "port"); n != 1 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 1)
+	}
+
+	start, end, err := e.Offsets("server", "port")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(e.Bytes()[start:end]); s != "1" {
+		t.Fatalf("unexpected value at offsets; got %q; want %q", s, "1")
+	}
+
+	// Invalid edits must leave the document untouched.
+	prev := string(e.Bytes())
+	for _, raw := range []string{"", "[1,", "1; foo = 2", "}"} {
+		if err := e.Replace(raw, "debug"); err == nil {
+			t.Fatalf("expecting non-nil error for raw %q", raw)
+		}
+	}
+	if err := e.Replace("1", "missing"); err == nil {
+		t.Fatalf("expecting non-nil error for missing key")
+	}
+	if err := e.Replace("1", "server", "tags", "2"); err == nil {
+		t.Fatalf("expecting non-nil error for out of range index")
+	}
+	if s := string(e.Bytes()); s != prev {
+		t.Fatalf("unexpected document after failed edits; got\n%s\nwant\n%s", s, prev)
+	}
+
+	if _, err := NewEditor([]byte("foo = ")); err == nil {
+		t.Fatalf("expecting non-nil error for invalid document")
+	}
+}
+
+func TestEditorInsert(t *testing.T) {
+	f := func(doc, key, raw string, keys []string, resultExpected string) {
+		t.Helper()
+		e, err := NewEditor([]byte(doc))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := e.Insert(key, raw, keys...); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := string(e.Bytes())
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f("a = {\n  b = 1; # comment\n};\n", "c", "2", []string{"a"}, "a = {\n  b = 1; # comment\n  c = 2;\n};\n")
+	f("a = {\n\tb = 1\n};\n", "c", "2", []string{"a"}, "a = {\n\tb = 1;\n\tc = 2;\n};\n")
+	f("a = { b = 1; };\n", "c", `"x"`, []string{"a"}, "a = { b = 1; c = \"x\"; };\n")
+	f("a = {};\n", "c", "2", []string{"a"}, "a = {c = 2;};\n")
+	f("a = 1;\n// end\n", "b", "[1, 2]", nil, "a = 1;\nb = [1, 2];\n// end\n")
+	f("a = 1;", "b", "2", nil, "a = 1;\nb = 2;")
+	f("", "b", "2", nil, "b = 2;")
+	f("# only comment\n", "b", "2", nil, "# only comment\nb = 2;\n")
+
+	e, err := NewEditor([]byte("a = { b = 1; };\nc = 2;\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.Insert("b", "2", "a"); err == nil {
+		t.Fatalf("expecting non-nil error for duplicate key")
+	}
+	if err := e.Insert("d", "2", "c"); err == nil {
+		t.Fatalf("expecting non-nil error for non-object")
+	}
+	if err := e.Insert("x = 1; y", "2"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid key")
+	}
+	if err := e.Insert("d", "2; e = 3"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid value")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"io"
+)
+
+// Encoder writes a stream of JSON values to W.
+//
+// Values are written as JSON lines ( http://jsonlines.org/ ) by default.
+//
+// Encoder cannot be used from concurrent goroutines.
+type Encoder struct {
+	// W is the destination for the encoded values.
+	W io.Writer
+
+	// JSONSeq enables writing JSON text sequences ( https://tools.ietf.org/html/rfc7464 ).
+	//
+	// Every value is preceded by RS (0x1E) char and followed by LF char
+	// in this mode, which matches application/json-seq media type.
+	JSONSeq bool
+
+	// Options contains optional settings for marshaling values.
+	Options MarshalOptions
+
+	b []byte
+}
+
+// Encode writes v to e.W.
+func (e *Encoder) Encode(v *Value) error {
+	b := e.b[:0]
+	if e.JSONSeq {
+		b = append(b, recordSeparator)
+	}
+	b = v.MarshalToOptions(b, &e.Options)
+	b = append(b, '\n')
+	e.b = b
+	_, err := e.W.Write(b)
+	return err
+}
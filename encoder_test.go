@@ -0,0 +1,74 @@
+package libconfig
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	f := func(jsonSeq bool, resultExpected string) {
+		t.Helper()
+		var bb bytes.Buffer
+		e := Encoder{
+			W:       &bb,
+			JSONSeq: jsonSeq,
+		}
+		v := MustParse(`a = [1, "x"]; b = { c = true; };`)
+		if err := e.Encode(v.Get("a")); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if err := e.Encode(v.Get("b")); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := bb.String(); result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+	f(false, "[1,\"x\"]\n{\"c\":true}\n")
+	f(true, "\x1e[1,\"x\"]\n\x1e{\"c\":true}\n")
+}
+
+func TestEncoderOptions(t *testing.T) {
+	var bb bytes.Buffer
+	e := Encoder{
+		W: &bb,
+		Options: MarshalOptions{
+			ASCIIOnly: true,
+		},
+	}
+	if err := e.Encode(MustParse(`a = "ü";`)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result := bb.String(); result != "{\"a\":\"\\u00fc\"}\n" {
+		t.Fatalf("unexpected result; got %q; want %q", result, "{\"a\":\"\\u00fc\"}\n")
+	}
+}
+
+func TestEncoderScannerJSONSeq(t *testing.T) {
+	var bb bytes.Buffer
+	e := Encoder{
+		W:       &bb,
+		JSONSeq: true,
+	}
+	v := MustParse(`a = [1, [2, 3]]; b = "foo";`)
+	for _, key := range []string{"a", "b"} {
+		if err := e.Encode(v.Get(key)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	sc := Scanner{
+		JSONSeq: true,
+	}
+	sc.InitBytes(bb.Bytes())
+	var result []string
+	for sc.Next() {
+		result = append(result, sc.Value().String())
+	}
+	if err := sc.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(result) != 2 || result[0] != `[1,[2,3]]` || result[1] != `"foo"` {
+		t.Fatalf("unexpected values; got %q", result)
+	}
+}
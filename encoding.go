@@ -0,0 +1,119 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const bomUTF8 = "\xEF\xBB\xBF"
+
+// inputEncoding describes the detected encoding of the input.
+type inputEncoding struct {
+	name      string
+	bomLen    int
+	unitSize  int
+	byteOrder binary.ByteOrder
+}
+
+// detectEncoding detects UTF-16 and UTF-32 encoded input.
+//
+// BOM is used for the detection if present. Otherwise the encoding
+// is detected by zero bytes among the first four bytes, since the input
+// usually starts with ASCII char. nil is returned for UTF-8 input.
+func detectEncoding(s string) *inputEncoding {
+	switch {
+	case strings.HasPrefix(s, "\x00\x00\xFE\xFF"):
+		return &inputEncoding{"UTF-32BE", 4, 4, binary.BigEndian}
+	case strings.HasPrefix(s, "\xFF\xFE\x00\x00"):
+		return &inputEncoding{"UTF-32LE", 4, 4, binary.LittleEndian}
+	case strings.HasPrefix(s, "\xFE\xFF"):
+		return &inputEncoding{"UTF-16BE", 2, 2, binary.BigEndian}
+	case strings.HasPrefix(s, "\xFF\xFE"):
+		return &inputEncoding{"UTF-16LE", 2, 2, binary.LittleEndian}
+	}
+	if len(s) < 4 {
+		return nil
+	}
+	switch {
+	case s[0] == 0 && s[1] == 0 && s[2] == 0 && s[3] != 0:
+		return &inputEncoding{"UTF-32BE", 0, 4, binary.BigEndian}
+	case s[0] != 0 && s[1] == 0 && s[2] == 0 && s[3] == 0:
+		return &inputEncoding{"UTF-32LE", 0, 4, binary.LittleEndian}
+	case s[0] == 0 && s[1] != 0:
+		return &inputEncoding{"UTF-16BE", 0, 2, binary.BigEndian}
+	case s[0] != 0 && s[1] == 0:
+		return &inputEncoding{"UTF-16LE", 0, 2, binary.LittleEndian}
+	}
+	return nil
+}
+
+// decodeInput strips UTF-8 BOM from s.
+//
+// UTF-16 and UTF-32 input is transcoded to UTF-8 if transcode is set.
+// Otherwise an error is returned for such input.
+func decodeInput(s string, transcode bool) (string, error) {
+	if strings.HasPrefix(s, bomUTF8) {
+		return s[len(bomUTF8):], nil
+	}
+	enc := detectEncoding(s)
+	if enc == nil {
+		return s, nil
+	}
+	if !transcode {
+		return s, fmt.Errorf("the input looks like %s encoded; set ParserConfig.TranscodeInput for transcoding it to UTF-8", enc.name)
+	}
+	return enc.decode(s[enc.bomLen:])
+}
+
+func (enc *inputEncoding) decode(s string) (string, error) {
+	if len(s)%enc.unitSize != 0 {
+		return "", fmt.Errorf("cannot decode %s input: its length %d isn't multiple of %d", enc.name, len(s), enc.unitSize)
+	}
+	b := make([]byte, 0, len(s))
+	if enc.unitSize == 4 {
+		for i := 0; i < len(s); i += 4 {
+			r := rune(enc.byteOrder.Uint32(s2b(s[i : i+4])))
+			if !utf8.ValidRune(r) {
+				return "", fmt.Errorf("cannot decode %s input: invalid code point 0x%X at offset %d", enc.name, uint32(r), i)
+			}
+			b = utf8.AppendRune(b, r)
+		}
+		return b2s(b), nil
+	}
+	u := make([]uint16, len(s)/2)
+	for i := range u {
+		u[i] = enc.byteOrder.Uint16(s2b(s[2*i : 2*i+2]))
+	}
+	for _, r := range utf16.Decode(u) {
+		b = utf8.AppendRune(b, r)
+	}
+	return b2s(b), nil
+}
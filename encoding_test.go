@@ -0,0 +1,84 @@
+package libconfig
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, order binary.ByteOrder, bom bool) string {
+	u := utf16.Encode([]rune(s))
+	if bom {
+		u = append([]uint16{0xFEFF}, u...)
+	}
+	b := make([]byte, 2*len(u))
+	for i, x := range u {
+		order.PutUint16(b[2*i:], x)
+	}
+	return string(b)
+}
+
+func encodeUTF32(s string, order binary.ByteOrder, bom bool) string {
+	rs := []rune(s)
+	if bom {
+		rs = append([]rune{0xFEFF}, rs...)
+	}
+	b := make([]byte, 4*len(rs))
+	for i, r := range rs {
+		order.PutUint32(b[4*i:], uint32(r))
+	}
+	return string(b)
+}
+
+func TestParserBOM(t *testing.T) {
+	var p Parser
+	v, err := p.Parse(bomUTF8 + `a = "foo";`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes("a")); s != "foo" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "foo")
+	}
+
+	_, err = p.Parse(encodeUTF16(`a = "foo";`, binary.LittleEndian, true))
+	if err == nil {
+		t.Fatalf("expecting non-nil error for UTF-16 input")
+	}
+	if !strings.Contains(err.Error(), "UTF-16LE") {
+		t.Fatalf("unexpected error; got %q; want it to mention UTF-16LE", err)
+	}
+}
+
+func TestParserTranscodeInput(t *testing.T) {
+	p := Parser{
+		Config: ParserConfig{
+			TranscodeInput: true,
+		},
+	}
+	doc := `a = "привет 😀"; b = [1, 2];`
+	f := func(s string) {
+		t.Helper()
+		v, err := p.Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result, resultExpected := v.String(), `{"a":"привет 😀","b":[1,2]}`; result != resultExpected {
+			t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+		}
+	}
+	for _, bom := range []bool{true, false} {
+		f(encodeUTF16(doc, binary.LittleEndian, bom))
+		f(encodeUTF16(doc, binary.BigEndian, bom))
+		f(encodeUTF32(doc, binary.LittleEndian, bom))
+		f(encodeUTF32(doc, binary.BigEndian, bom))
+	}
+	f(doc)
+	f(bomUTF8 + doc)
+
+	// Truncated input
+	s := encodeUTF16(doc, binary.LittleEndian, true)
+	if _, err := p.Parse(s[:len(s)-1]); err == nil {
+		t.Fatalf("expecting non-nil error for truncated UTF-16 input")
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EncryptedKey is the object key for encrypted values.
+//
+// Encrypted values are stored in objects with the single key such as
+// {"$enc": "AES256:prod:..."}, where the string value contains the algorithm,
+// optional key id and base64-encoded nonce followed by the ciphertext.
+// Only AES256 algorithm is supported, which stands for AES-256-GCM.
+//
+// The plaintext is libconfig representation of the encrypted value, so values
+// of any type may be encrypted. The keys path of the encrypted value
+// is authenticated as additional data, so encrypted values cannot be moved
+// to other keys. Use Arena.NewEncrypted for creating encrypted values
+// and DecryptValues for decrypting them.
+const EncryptedKey = "$enc"
+
+// KeyProvider provides keys for decrypting values.
+type KeyProvider interface {
+	// Key must return 32-byte key for the given key id.
+	//
+	// id is empty for encrypted values without key id.
+	Key(id string) ([]byte, error)
+}
+
+// KeyProviderFunc is a function implementing KeyProvider.
+type KeyProviderFunc func(id string) ([]byte, error)
+
+// Key calls f(id).
+func (f KeyProviderFunc) Key(id string) ([]byte, error) {
+	return f(id)
+}
+
+// StaticKey is KeyProvider returning the same key for all the key ids.
+type StaticKey []byte
+
+// Key returns sk.
+func (sk StaticKey) Key(id string) ([]byte, error) {
+	return sk, nil
+}
+
+// DecryptValues replaces encrypted values in v with the decrypted values.
+//
+// Keys for decryption are obtained from kp. See EncryptedKey for the format
+// of encrypted values. v mustn't be frozen, since the values are replaced
+// in place.
+//
+// Decrypted values are allocated in a.
+func DecryptValues(v *Value, a *Arena, kp KeyProvider) error {
+	if v.IsFrozen() {
+		return fmt.Errorf("cannot decrypt values in frozen value; use Value.Clone for obtaining a modifiable copy")
+	}
+	if isEncryptedValue(v) {
+		return fmt.Errorf("cannot replace the root value with the decrypted value")
+	}
+	return decryptValues(a, v, kp, nil)
+}
+
+func decryptValues(a *Arena, v *Value, kp KeyProvider, path Path) error {
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		for i := range v.o.kvs {
+			kv := &v.o.kvs[i]
+			x, err := decryptChild(a, kv.v, kp, append(path[:len(path):len(path)], kv.k))
+			if err != nil {
+				return err
+			}
+			kv.v = x
+		}
+	case TypeArray:
+		for i, item := range v.a {
+			x, err := decryptChild(a, item, kp, append(path[:len(path):len(path)], strconv.Itoa(i)))
+			if err != nil {
+				return err
+			}
+			v.a[i] = x
+		}
+	}
+	return nil
+}
+
+func decryptChild(a *Arena, v *Value, kp KeyProvider, path Path) (*Value, error) {
+	if !isEncryptedValue(v) {
+		return v, decryptValues(a, v, kp, path)
+	}
+	x, err := decryptValue(a, v.o.kvs[0].v.s, kp, path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt value at %q: %s", path, err)
+	}
+	return x, nil
+}
+
+// isEncryptedValue returns true if v is an object with the single EncryptedKey
+// containing a string.
+func isEncryptedValue(v *Value) bool {
+	if v.Type() != TypeObject || v.o.Len() != 1 {
+		return false
+	}
+	kv := &v.o.kvs[0]
+	return kv.k == EncryptedKey && kv.v.Type() == TypeString
+}
+
+func decryptValue(a *Arena, s string, kp KeyProvider, path Path) (*Value, error) {
+	n := strings.IndexByte(s, ':')
+	if n < 0 || s[:n] != "AES256" {
+		return nil, fmt.Errorf("unsupported encryption algorithm in %q; supported algorithm: AES256", startEndString(s))
+	}
+	rest := s[n+1:]
+	var id string
+	if n := strings.LastIndexByte(rest, ':'); n >= 0 {
+		id, rest = rest[:n], rest[n+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(rest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode base64 ciphertext: %s", err)
+	}
+	aead, err := newEncryptionAEAD(kp, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("too short ciphertext: %d bytes", len(data))
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(path.String()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt ciphertext with key %q: %s", id, err)
+	}
+
+	var p Parser
+	x, err := p.Parse("v = " + b2s(plaintext) + ";")
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse decrypted value: %s", err)
+	}
+	return a.CopyValue(x.Get("v")), nil
+}
+
+func newEncryptionAEAD(kp KeyProvider, id string) (cipher.AEAD, error) {
+	key, err := kp.Key(id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain key %q: %s", id, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("unexpected length for key %q; got %d bytes; want 32 bytes", id, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewEncrypted returns new object containing v encrypted with the key
+// for the given key id obtained from kp.
+//
+// keys must contain the keys path where the returned object is going
+// to be stored. The object cannot be decrypted at other keys paths.
+// Array indexes may be represented as decimal numbers in keys.
+//
+// id mustn't contain ':'. See EncryptedKey for details.
+//
+// The returned object is valid until Reset is called on a.
+func (a *Arena) NewEncrypted(v *Value, kp KeyProvider, id string, keys ...string) (*Value, error) {
+	if strings.IndexByte(id, ':') >= 0 {
+		return nil, fmt.Errorf("key id %q mustn't contain ':'", id)
+	}
+	if err := checkLibconfigKeys(v); err != nil {
+		return nil, fmt.Errorf("cannot encrypt value: %s", err)
+	}
+	aead, err := newEncryptionAEAD(kp, id)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate nonce: %s", err)
+	}
+	plaintext := appendLibconfigValue(nil, v)
+	data := aead.Seal(nonce, nonce, plaintext, []byte(Path(keys).String()))
+
+	s := "AES256:"
+	if id != "" {
+		s += id + ":"
+	}
+	s += base64.StdEncoding.EncodeToString(data)
+	o := a.NewObject()
+	o.Set(EncryptedKey, a.NewString(s))
+	return o, nil
+}
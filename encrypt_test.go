@@ -0,0 +1,145 @@
+package libconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecryptValues(t *testing.T) {
+	prodKey := bytes.Repeat([]byte("p"), 32)
+	defaultKey := bytes.Repeat([]byte("d"), 32)
+	kp := KeyProviderFunc(func(id string) ([]byte, error) {
+		switch id {
+		case "prod":
+			return prodKey, nil
+		case "":
+			return defaultKey, nil
+		default:
+			return nil, fmt.Errorf("unknown key")
+		}
+	})
+
+	f := func(plaintext, id string) {
+		t.Helper()
+		var a Arena
+		x := MustParse(plaintext).Get("x")
+		enc, err := a.NewEncrypted(x, kp, id, "secret")
+		if err != nil {
+			t.Fatalf("cannot encrypt value: %s", err)
+		}
+		// Short numbers may accidentally occur in the base64-encoded ciphertext.
+		if x.Type() != TypeNumber && strings.Contains(enc.String(), x.String()) {
+			t.Fatalf("the encrypted value mustn't contain the plaintext; got %s", enc)
+		}
+		encItem, err := a.NewEncrypted(x, kp, id, "list", "1")
+		if err != nil {
+			t.Fatalf("cannot encrypt value: %s", err)
+		}
+		v := a.NewObject()
+		v.Set("secret", enc)
+		v.Set("list", MustParse(`x = [1, 2];`).Get("x"))
+		v.Get("list").SetArrayItem(1, encItem)
+
+		if err := DecryptValues(v, &a, kp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !Equal(v.Get("secret"), x) {
+			t.Fatalf("unexpected decrypted value; got %s; want %s", v.Get("secret"), x)
+		}
+		if !Equal(v.Get("list", "1"), x) {
+			t.Fatalf("unexpected decrypted array item; got %s; want %s", v.Get("list", "1"), x)
+		}
+	}
+	f(`x = "s3cr3t";`, "prod")
+	f(`x = "\"quoted\"\n\té ";`, "")
+	f(`x = "x\xffy";`, "")
+	f(`x = 42;`, "")
+	f(`x = 0x1F;`, "")
+	f(`x = 5L;`, "")
+	f(`x = +1;`, "")
+	f(`x = [0x1F, +1, "x\xffy", {}, []];`, "")
+	f(`x = { user = "admin"; password = "p@ss"; ports = [1, 2]; "quoted key" = 1; };`, "prod")
+
+	fErr := func(s string) {
+		t.Helper()
+		var a Arena
+		if err := DecryptValues(MustParse(s).Clone(), &a, kp); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+	}
+	fErr(`x = { $enc = "AES128:abcd"; };`)
+	fErr(`x = { $enc = "AES256:!!!"; };`)
+	fErr(`x = { $enc = "AES256:unknown:YWJjZA=="; };`)
+	fErr(`x = { $enc = "AES256:YWJjZA=="; };`)
+	fErr(`x = { $enc = "AES256:YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXo="; };`)
+
+	// Tampered ciphertext
+	var a Arena
+	enc, err := a.NewEncrypted(a.NewString("secret"), kp, "prod", "x")
+	if err != nil {
+		t.Fatalf("cannot encrypt value: %s", err)
+	}
+	s := enc.Get(EncryptedKey).String()
+	fErr(`x = { $enc = ` + strings.Replace(s, "AES256:prod:", "AES256::", 1) + `; };`)
+
+	// Encrypted values cannot be moved to other keys.
+	v := MustParse(`x = { $enc = ` + s + `; };`).Clone()
+	if err := DecryptValues(v, &a, kp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fErr(`y = { $enc = ` + s + `; };`)
+	fErr(`x = [{ $enc = ` + s + `; }];`)
+
+	// Objects with other keys aren't encrypted values.
+	v = MustParse(`x = { $enc = "AES128:abcd"; other = 1; };`).Clone()
+	if err := DecryptValues(v, &a, kp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := DecryptValues(MustParse(`a = 1;`).Freeze(), &a, kp); err == nil {
+		t.Fatalf("expecting non-nil error for frozen value")
+	}
+	if _, err := a.NewEncrypted(a.NewString("x"), StaticKey("short"), ""); err == nil {
+		t.Fatalf("expecting non-nil error for short key")
+	}
+	if _, err := a.NewEncrypted(a.NewString("x"), kp, "a:b"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid key id")
+	}
+	x := a.NewObject()
+	x.Set("a=b", a.NewString("x"))
+	if _, err := a.NewEncrypted(x, kp, ""); err == nil {
+		t.Fatalf("expecting non-nil error for key, which cannot be represented in libconfig")
+	}
+}
+
+func TestLoadFileDecrypt(t *testing.T) {
+	key := StaticKey(bytes.Repeat([]byte("k"), 32))
+	var a Arena
+	enc, err := a.NewEncrypted(a.NewString("s3cr3t"), key, "", "db", "password")
+	if err != nil {
+		t.Fatalf("cannot encrypt value: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "app.cfg")
+	data := `db = { user = "admin"; password = { $enc = ` + enc.Get(EncryptedKey).String() + `; }; };`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("cannot write config file: %s", err)
+	}
+
+	v, err := LoadFile(path, &LoadOptions{
+		KeyProvider: key,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes("db", "password")); s != "s3cr3t" {
+		t.Fatalf("unexpected password; got %q; want %q", s, "s3cr3t")
+	}
+
+	if _, err := LoadFile(path, &LoadOptions{KeyProvider: StaticKey(bytes.Repeat([]byte("x"), 32))}); err == nil {
+		t.Fatalf("expecting non-nil error for invalid key")
+	}
+}
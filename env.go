@@ -0,0 +1,301 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envAppendSuffix is the suffix for env vars appending items to arrays.
+const envAppendSuffix = "__APPEND"
+
+// EnvOverlay overrides config values from environment variables.
+//
+// Variable names are mapped to keys paths by stripping Prefix and splitting
+// the rest by Separator, e.g. APP_DB_PORT overrides db.port. Keys are matched
+// case-insensitively, and keys containing Separator are matched against
+// the existing keys, so APP_DB_MAX_CONNS overrides db.max_conns if it exists.
+// Missing keys are created in lower case.
+//
+// Array items are addressed by decimal indexes, e.g. APP_SERVERS_0_HOST
+// overrides servers.0.host. The index equal to the array length appends
+// a new item. Variables ending with __APPEND append their value to the array,
+// e.g. APP_SERVERS__APPEND.
+//
+// Values starting with '{', '[' or '(' are parsed as libconfig values.
+// Values replacing existing strings remain strings. Values replacing
+// existing numbers must be numbers in any form accepted by the parser,
+// e.g. 0x1F, 100L or +1, and values replacing existing bools must be bools
+// accepted by strconv.ParseBool. Apply returns an error for other values,
+// so a mistyped env var doesn't silently change the type of a setting.
+// The rest are converted to numbers and bools where possible.
+type EnvOverlay struct {
+	// Prefix is the prefix for env var names, e.g. "APP_".
+	//
+	// All the env vars are applied if Prefix is empty.
+	Prefix string
+
+	// Separator separates keys in env var names, e.g. "__" for
+	// APP_SERVER__PORT.
+	//
+	// "_" is used by default.
+	Separator string
+
+	// Environ returns env vars in "KEY=value" form.
+	//
+	// os.Environ is used by default.
+	Environ func() []string
+}
+
+type envVar struct {
+	name   string
+	keys   []string
+	value  string
+	append bool
+}
+
+// Apply applies env vars to v, which must be an object.
+//
+// An error is returned if an env var value cannot be converted
+// to the type of the value it replaces. New values are allocated in a.
+func (eo *EnvOverlay) Apply(a *Arena, v *Value) error {
+	if v.Type() != TypeObject {
+		return fmt.Errorf("cannot apply env vars to %s; object is required", v.Type())
+	}
+	environ := eo.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+	sep := eo.Separator
+	if sep == "" {
+		sep = "_"
+	}
+	var vars []envVar
+	for _, kv := range environ() {
+		n := strings.IndexByte(kv, '=')
+		if n <= 0 || !strings.HasPrefix(kv[:n], eo.Prefix) {
+			continue
+		}
+		ev := envVar{
+			name:  kv[:n],
+			value: kv[n+1:],
+		}
+		name := ev.name[len(eo.Prefix):]
+		if strings.HasSuffix(name, envAppendSuffix) {
+			ev.append = true
+			name = name[:len(name)-len(envAppendSuffix)]
+		}
+		if name == "" {
+			continue
+		}
+		ev.keys = strings.Split(name, sep)
+		vars = append(vars, ev)
+	}
+
+	// Apply overrides before appends, and lower array indexes first,
+	// so items may be added sequentially via APP_LIST_0, APP_LIST_1, etc.
+	sort.SliceStable(vars, func(i, j int) bool {
+		if vars[i].append != vars[j].append {
+			return !vars[i].append
+		}
+		return lessEnvKeys(vars[i].keys, vars[j].keys)
+	})
+	for _, ev := range vars {
+		if err := applyEnvVar(a, v, &ev, sep); err != nil {
+			return fmt.Errorf("cannot apply env var %s: %s", ev.name, err)
+		}
+	}
+	return nil
+}
+
+func lessEnvKeys(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(a[i])
+		nb, errB := strconv.Atoi(b[i])
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+func applyEnvVar(a *Arena, v *Value, ev *envVar, sep string) error {
+	keys := ev.keys
+	for len(keys) > 0 {
+		var key string
+		var n int
+		var child *Value
+		switch v.Type() {
+		case TypeObject:
+			key, n = matchEnvKey(&v.o, keys, sep)
+			child = v.o.Get(key)
+		case TypeArray:
+			idx, err := strconv.Atoi(keys[0])
+			if err != nil || idx < 0 {
+				return fmt.Errorf("invalid array index %q", keys[0])
+			}
+			if idx > len(v.a) {
+				return fmt.Errorf("array index %d is out of range; array length is %d", idx, len(v.a))
+			}
+			key, n = keys[0], 1
+			if idx < len(v.a) {
+				child = v.a[idx]
+			}
+		default:
+			return fmt.Errorf("cannot set key %q in %s", keys[0], v.Type())
+		}
+		keys = keys[n:]
+
+		if len(keys) == 0 && !ev.append {
+			x, err := envValue(a, ev.value, child)
+			if err != nil {
+				return err
+			}
+			v.Set(key, x)
+			return nil
+		}
+		if child == nil {
+			if len(keys) == 0 {
+				child = a.NewArray()
+			} else {
+				child = a.NewObject()
+			}
+			v.Set(key, child)
+		}
+		v = child
+	}
+
+	if v.Type() != TypeArray {
+		return fmt.Errorf("cannot append to %s; array is required", v.Type())
+	}
+	x, err := envValue(a, ev.value, nil)
+	if err != nil {
+		return err
+	}
+	v.SetArrayItem(len(v.a), x)
+	return nil
+}
+
+// matchEnvKey returns the key in o matching the longest prefix of keys
+// joined with sep and the number of the matched keys.
+//
+// The first key in lower case is returned if there is no match.
+func matchEnvKey(o *Object, keys []string, sep string) (string, int) {
+	o.unescapeKeys()
+	for n := len(keys); n > 0; n-- {
+		name := strings.Join(keys[:n], sep)
+		for _, kv := range o.kvs {
+			if strings.EqualFold(kv.k, name) {
+				return kv.k, n
+			}
+		}
+	}
+	return strings.ToLower(keys[0]), 1
+}
+
+// envValue converts env var value s to Value.
+//
+// prev is the value replaced by s. It may be nil.
+func envValue(a *Arena, s string, prev *Value) (*Value, error) {
+	if s != "" && strings.IndexByte("{[(", s[0]) >= 0 {
+		var p Parser
+		x, err := p.Parse("x = " + s + ";")
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse value %q: %s", s, err)
+		}
+		return x.Get("x").Clone(), nil
+	}
+	if prev == nil {
+		prev = valueNull
+	}
+	switch prev.Type() {
+	case TypeString:
+		return a.NewString(s), nil
+	case TypeNumber:
+		if !isNumberToken(s) {
+			return nil, fmt.Errorf("cannot convert %q to number", s)
+		}
+		return a.NewNumberString(s), nil
+	case TypeTrue, TypeFalse:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to bool", s)
+		}
+		if b {
+			return valueTrue, nil
+		}
+		return valueFalse, nil
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return valueTrue, nil
+	case "false":
+		return valueFalse, nil
+	}
+	if isFlatNumber(s) {
+		return a.NewNumberString(s), nil
+	}
+	return a.NewString(s), nil
+}
+
+// isNumberToken returns true if s is a number accepted by the parser.
+func isNumberToken(s string) bool {
+	var p Parser
+	x, err := p.Parse("x = " + s + ";")
+	if err != nil {
+		return false
+	}
+	n := x.Get("x")
+	return x.o.Len() == 1 && n.Type() == TypeNumber && n.s == s
+}
+
+// BindEnv overrides values in v with env vars starting with prefix
+// followed by '_', where keys are separated by "__".
+//
+// For example, APP_SERVER__PORT=9090 overrides server.port for "APP" prefix,
+// while APP_SERVER__MAX_CONNS overrides server.max_conns.
+// All the env vars are applied if prefix is empty.
+// New values are allocated in a.
+//
+// See EnvOverlay for details on type coercion and arrays handling.
+func BindEnv(v *Value, a *Arena, prefix string) error {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	eo := &EnvOverlay{
+		Prefix:    prefix,
+		Separator: "__",
+	}
+	return eo.Apply(a, v)
+}
@@ -0,0 +1,146 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestEnvOverlay(t *testing.T) {
+	f := func(s string, env []string, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(s).Clone()
+		eo := &EnvOverlay{
+			Prefix: "APP_",
+			Environ: func() []string {
+				return env
+			},
+		}
+		if err := eo.Apply(&a, v); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	// Scalars
+	f(`db = {port = 5432; max_conns = 10; name = "x";};`, []string{
+		"APP_DB_PORT=6543",
+		"APP_DB_MAX_CONNS=20",
+		"APP_DB_NAME=123",
+		"APP_DB_SSL=true",
+		"OTHER_DB_PORT=1",
+		"APP_NEW_KEY=value",
+	}, `{"db":{"port":6543,"max_conns":20,"name":"123","ssl":true},"new":{"key":"value"}}`)
+
+	// Array indexes
+	f(`servers = ({host = "a"; port = 1;}, {host = "b"; port = 2;});`, []string{
+		"APP_SERVERS_1_HOST=bb",
+		"APP_SERVERS_0_PORT=10",
+		"APP_SERVERS_2_HOST=c",
+	}, `{"servers":[{"host":"a","port":10},{"host":"bb","port":2},{"host":"c"}]}`)
+
+	// Indexes are applied in numeric order.
+	var env []string
+	for _, n := range []string{"10", "2", "0", "1", "9", "8", "7", "6", "5", "4", "3"} {
+		env = append(env, "APP_LIST_"+n+"="+n)
+	}
+	f(`list = [];`, env, `{"list":[0,1,2,3,4,5,6,7,8,9,10]}`)
+
+	// Append
+	f(`servers = ({host = "a";}); tags = ["x"];`, []string{
+		`APP_SERVERS__APPEND={host = "b";}`,
+		"APP_TAGS__APPEND=y",
+		"APP_TAGS_0=z",
+		"APP_EMPTY__APPEND=1",
+	}, `{"servers":[{"host":"a"},{"host":"b"}],"tags":["z","y"],"empty":[1]}`)
+
+	// Errors
+	fErr := func(s string, env ...string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(s).Clone()
+		eo := &EnvOverlay{
+			Prefix: "APP_",
+			Environ: func() []string {
+				return env
+			},
+		}
+		if err := eo.Apply(&a, v); err == nil {
+			t.Fatalf("expecting non-nil error for %q", env)
+		}
+	}
+	fErr(`list = [1];`, "APP_LIST_5=1")
+	fErr(`list = [1];`, "APP_LIST_X=1")
+	fErr(`name = "x";`, "APP_NAME_FOO=1")
+	fErr(`name = "x";`, "APP_NAME__APPEND=1")
+	fErr(`a = 1;`, "APP_B={x = ;}")
+}
+
+func TestEnvOverlayCoercion(t *testing.T) {
+	f := func(env, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(`port = 80; debug = false; name = "x";`).Clone()
+		eo := &EnvOverlay{
+			Prefix: "APP_",
+			Environ: func() []string {
+				return []string{env}
+			},
+		}
+		if err := eo.Apply(&a, v); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+	f("APP_PORT=1.5e3", `{"port":1.5e3,"debug":false,"name":"x"}`)
+	f("APP_PORT=0x1F90", `{"port":0x1F90,"debug":false,"name":"x"}`)
+	f("APP_PORT=0X1f90", `{"port":0X1f90,"debug":false,"name":"x"}`)
+	f("APP_PORT=100L", `{"port":100L,"debug":false,"name":"x"}`)
+	f("APP_PORT=+1", `{"port":+1,"debug":false,"name":"x"}`)
+	f("APP_DEBUG=1", `{"port":80,"debug":true,"name":"x"}`)
+	f("APP_DEBUG=F", `{"port":80,"debug":false,"name":"x"}`)
+	f("APP_NAME=true", `{"port":80,"debug":false,"name":"true"}`)
+
+	fErr := func(env string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(`port = 80; debug = false;`).Clone()
+		eo := &EnvOverlay{
+			Prefix: "APP_",
+			Environ: func() []string {
+				return []string{env}
+			},
+		}
+		if err := eo.Apply(&a, v); err == nil {
+			t.Fatalf("expecting non-nil error for %q", env)
+		}
+	}
+	// Values which cannot be converted to the replaced type are rejected
+	// instead of silently changing the type.
+	fErr("APP_PORT=http")
+	fErr("APP_PORT=8080; debug = true")
+	fErr("APP_PORT=80 # comment")
+	fErr("APP_PORT=")
+	fErr("APP_DEBUG=maybe")
+}
+
+func TestBindEnv(t *testing.T) {
+	t.Setenv("LIBCONFIGTEST_SERVER__PORT", "9090")
+	t.Setenv("LIBCONFIGTEST_SERVER__MAX_CONNS", "20")
+	t.Setenv("LIBCONFIGTEST_DB__HOST", "db.local")
+	t.Setenv("LIBCONFIGTEST_SERVER__TAGS__APPEND", "b")
+
+	var a Arena
+	v := MustParse(`server = { port = 8080; max_conns = 10; tags = ["a"]; };`).Clone()
+	if err := BindEnv(v, &a, "LIBCONFIGTEST"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	result := v.String()
+	resultExpected := `{"server":{"port":9090,"max_conns":20,"tags":["a","b"]},"db":{"host":"db.local"}}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Equal returns true if a and b are deeply equal.
+//
+// Objects are compared regardless of key order, arrays are compared
+// item by item, numbers are compared by value, e.g. 0x10 equals 16.
+// See also Hash.
+func Equal(a, b *Value) bool {
+	return equalValue(a, b, nil, nil)
+}
+
+// EqualExcept returns true if a and b are deeply equal, ignoring
+// the values at ignorePaths.
+//
+// Each path in ignorePaths contains dot-separated keys such as
+// "metadata.revision". Array indexes may be represented as decimal numbers,
+// while "*" matches any key or index, e.g. "servers.*.last_seen".
+// The ignored values may be missing in a or b.
+//
+// Objects are compared regardless of key order, arrays are compared
+// item by item, numbers are compared by value.
+func EqualExcept(a, b *Value, ignorePaths ...string) bool {
+	ignore := make([][]string, len(ignorePaths))
+	for i, path := range ignorePaths {
+		ignore[i] = strings.Split(path, ".")
+	}
+	return equalValue(a, b, nil, ignore)
+}
+
+func equalValue(a, b *Value, path []string, ignore [][]string) bool {
+	if isIgnoredPath(path, ignore) {
+		return true
+	}
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.t {
+	case TypeObject:
+		a.o.unescapeKeys()
+		b.o.unescapeKeys()
+		for _, kv := range a.o.kvs {
+			if !equalValue(kv.v, b.o.Get(kv.k), append(path, kv.k), ignore) {
+				return false
+			}
+		}
+		// Verify b has no extra keys.
+		for _, kv := range b.o.kvs {
+			if a.o.Get(kv.k) == nil && !isIgnoredPath(append(path, kv.k), ignore) {
+				return false
+			}
+		}
+		return true
+	case TypeArray:
+		if len(a.a) != len(b.a) {
+			return false
+		}
+		for i := range a.a {
+			if !equalValue(a.a[i], b.a[i], append(path, strconv.Itoa(i)), ignore) {
+				return false
+			}
+		}
+		return true
+	case TypeString:
+		return a.s == b.s
+	case TypeNumber:
+		return equalNumber(a.s, b.s)
+	case TypeRawJSON:
+		return a.s == b.s
+	default:
+		return true
+	}
+}
+
+func isIgnoredPath(path []string, ignore [][]string) bool {
+	for _, ip := range ignore {
+		if len(ip) != len(path) {
+			continue
+		}
+		matched := true
+		for i, key := range ip {
+			if key != "*" && key != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func equalNumber(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if isIntToken(a) && isIntToken(b) {
+		na, errA := parseIntToken(a)
+		nb, errB := parseIntToken(b)
+		if errA == nil && errB == nil {
+			return na == nb
+		}
+		return jsonNumber(a) == jsonNumber(b)
+	}
+	// fastfloat cannot be used here, since it rejects .5 and 5. numbers.
+	fa, _ := parseFloatToken(jsonNumber(a))
+	fb, _ := parseFloatToken(jsonNumber(b))
+	return fa == fb
+}
+
+// Hash returns structural hash of v.
+//
+// Values equal according to Equal have equal hashes, so Hash may be used
+// for cheap change detection, e.g. on config reload. Objects are hashed
+// regardless of key order, while arrays are hashed item by item.
+func Hash(v *Value) uint64 {
+	return hashValue(v)
+}
+
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+func hashValue(v *Value) uint64 {
+	if v == nil {
+		return hashMix(0)
+	}
+	h := hashMix(uint64(v.Type()) + 1)
+	switch v.t {
+	case TypeObject:
+		v.o.unescapeKeys()
+		// Sum is used for combining entries, since it doesn't depend on the order.
+		var sum uint64
+		for _, kv := range v.o.kvs {
+			sum += hashMix(hashString(kv.k) ^ hashValue(kv.v)*hashPrime)
+		}
+		return hashMix(h ^ sum)
+	case TypeArray:
+		for _, x := range v.a {
+			h = hashMix(h*hashPrime ^ hashValue(x))
+		}
+		return h
+	case TypeString, TypeRawJSON:
+		return hashMix(h ^ hashString(v.s))
+	case TypeNumber:
+		// Numbers are hashed by float64 value in order to be consistent
+		// with equalNumber.
+		f, _ := parseFloatToken(jsonNumber(v.s))
+		if f == 0 {
+			// Negative zero equals zero.
+			f = 0
+		}
+		return hashMix(h ^ math.Float64bits(f))
+	default:
+		return h
+	}
+}
+
+// hashString returns FNV-1a hash of s.
+func hashString(s string) uint64 {
+	h := uint64(hashOffset)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= hashPrime
+	}
+	return h
+}
+
+// hashMix mixes bits in x with splitmix64 finalizer.
+func hashMix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
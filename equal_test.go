@@ -0,0 +1,89 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestEqualExcept(t *testing.T) {
+	f := func(a, b string, resultExpected bool, ignorePaths ...string) {
+		t.Helper()
+		va := MustParse(a)
+		vb := MustParse(b)
+		if result := EqualExcept(va, vb, ignorePaths...); result != resultExpected {
+			t.Fatalf("unexpected result for EqualExcept(%q, %q, %q); got %v; want %v", a, b, ignorePaths, result, resultExpected)
+		}
+		if result := EqualExcept(vb, va, ignorePaths...); result != resultExpected {
+			t.Fatalf("unexpected result for EqualExcept(%q, %q, %q); got %v; want %v", b, a, ignorePaths, result, resultExpected)
+		}
+	}
+
+	f(`a = 1; b = "x";`, `b = "x"; a = 1;`, true)
+	f(`a = 1;`, `a = 1.0;`, true)
+	f(`a = 0x10;`, `a = 16;`, true)
+	f(`a = 10L;`, `a = 10;`, true)
+	f(`a = .5;`, `a = 0.5;`, true)
+	f(`a = 5.;`, `a = 5;`, true)
+	f(`a = .5;`, `a = 0;`, false)
+	f(`a = "xA";`, `a = "xA";`, true)
+	f(`a = [1, 2];`, `a = (1, 2);`, true)
+	f(`a = [1, 2];`, `a = [2, 1];`, false)
+	f(`a = [1, 2];`, `a = [1];`, false)
+	f(`a = 1;`, `a = "1";`, false)
+	f(`a = 1;`, `a = 1; b = 2;`, false)
+	f(`a = { b = true; };`, `a = { b = false; };`, false)
+
+	// ignored paths
+	f(`a = 1; rev = 1;`, `a = 1; rev = 2;`, true, "rev")
+	f(`a = 1; rev = 1;`, `a = 1;`, true, "rev")
+	f(`a = 1; rev = 1;`, `a = 2; rev = 2;`, false, "rev")
+	f(`meta = { rev = 1; ts = 2; };`, `meta = { rev = 3; ts = 4; };`, true, "meta.rev", "meta.ts")
+	f(`meta = { rev = 1; ts = 2; };`, `meta = { rev = 3; ts = 4; };`, false, "meta.rev")
+	f(`s = ({ ts = 1; n = "a"; }, { ts = 2; n = "b"; });`, `s = ({ ts = 3; n = "a"; }, { n = "b"; });`, true, "s.*.ts")
+	f(`s = ({ ts = 1; n = "a"; });`, `s = ({ ts = 3; n = "b"; });`, false, "s.*.ts")
+	f(`s = [1, 2];`, `s = [1, 3];`, true, "s.1")
+}
+
+func TestEqualHash(t *testing.T) {
+	f := func(a, b string, resultExpected bool) {
+		t.Helper()
+		va := MustParse(a)
+		vb := MustParse(b)
+		if result := Equal(va, vb); result != resultExpected {
+			t.Fatalf("unexpected result for Equal(%q, %q); got %v; want %v", a, b, result, resultExpected)
+		}
+		ha := Hash(va)
+		hb := Hash(vb)
+		if resultExpected && ha != hb {
+			t.Fatalf("unexpected hash mismatch for equal values %q and %q; got %d and %d", a, b, ha, hb)
+		}
+		if !resultExpected && ha == hb {
+			t.Fatalf("unexpected hash collision for %q and %q", a, b)
+		}
+	}
+
+	f(`a = 1; b = "x";`, `b = "x"; a = 1;`, true)
+	f(`a = 1;`, `a = 1.0;`, true)
+	f(`a = 0x10;`, `a = 16;`, true)
+	f(`a = 10L;`, `a = 10;`, true)
+	f(`a = 0.0;`, `a = -0.0;`, true)
+	f(`a = .5;`, `a = 0.5;`, true)
+	f(`a = .5;`, `a = 0;`, false)
+	f(`a = [1, 2];`, `a = (1, 2);`, true)
+	f(`a = { b = { c = [true, null]; d = "x"; }; };`, `a = { b = { d = "x"; c = [true, null]; }; };`, true)
+	f(`a = [1, 2];`, `a = [2, 1];`, false)
+	f(`a = [1, 2];`, `a = [1];`, false)
+	f(`a = 1;`, `a = "1";`, false)
+	f(`a = 1;`, `a = 1; b = 2;`, false)
+	f(`a = true;`, `a = false;`, false)
+	f(`a = 1; b = 2;`, `a = 2; b = 1;`, false)
+	f(`a = [[]];`, `a = [];`, false)
+	f(`a = {};`, `a = [];`, false)
+	f(`a = "";`, `a = null;`, false)
+
+	if !Equal(nil, nil) {
+		t.Fatalf("nil values must be equal")
+	}
+	if Equal(nil, MustParse(``)) {
+		t.Fatalf("nil value cannot be equal to non-nil value")
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExpandRefs replaces references such as "${services.db.host}" inside
+// string values in v with the referenced values from v.
+//
+// References contain dotted paths relative to v, which are parsed
+// with ParsePath. A string consisting of a single reference is replaced
+// with the referenced value of any type, e.g. "${limits}" may be replaced
+// with an object. References inside longer strings such as
+// "${services.db.host}:5432" are replaced with the referenced strings,
+// numbers, booleans or nulls. Referenced values may contain references
+// themselves. Reference cycles result in error.
+//
+// "$${" is replaced with literal "${", so it may be used for escaping.
+//
+// The total size of strings created by expansion is limited
+// by maxExpandedBytes, since chains of references such as
+// b = "${a}${a}"; c = "${b}${b}" may grow exponentially.
+//
+// New values are allocated in a. The referenced containers are shared
+// between all the places referring them. v mustn't be frozen, since
+// references are expanded in place.
+func (v *Value) ExpandRefs(a *Arena) error {
+	if v == nil {
+		return nil
+	}
+	if v.IsFrozen() {
+		return fmt.Errorf("cannot expand references in frozen value; use Value.Clone for obtaining a modifiable copy")
+	}
+	re := refExpander{
+		a:         a,
+		root:      v,
+		expanded:  make(map[*Value]*Value),
+		expanding: make(map[*Value]bool),
+		walked:    make(map[*Value]walkState),
+	}
+	x, err := re.expandValue(v)
+	if err != nil {
+		return err
+	}
+	if x != v {
+		return fmt.Errorf("cannot replace the root value with %q", v.s)
+	}
+	return re.walk(nil, v)
+}
+
+// maxExpandedBytes is the maximum total size of strings, which may be
+// created by reference expansion in a single ExpandRefs call.
+const maxExpandedBytes = 16 << 20
+
+type refExpander struct {
+	a    *Arena
+	root *Value
+
+	// expandedBytes is the total size of strings created by expansion.
+	expandedBytes int
+
+	// expanded contains the expanded values for strings with references.
+	expanded map[*Value]*Value
+
+	// expanding contains strings, which are being expanded.
+	expanding map[*Value]bool
+
+	// stack contains the references being resolved for error messages.
+	stack []string
+
+	// walked contains walk states for containers.
+	walked map[*Value]walkState
+}
+
+// walk expands references in v located at path.
+func (re *refExpander) walk(path Path, v *Value) error {
+	t := v.Type()
+	if t != TypeObject && t != TypeArray || re.walked[v] != walkNone {
+		return nil
+	}
+	re.walked[v] = walkInProgress
+	defer func() {
+		re.walked[v] = walkDone
+	}()
+	switch t {
+	case TypeObject:
+		v.o.unescapeKeys()
+		for i := range v.o.kvs {
+			kv := &v.o.kvs[i]
+			x, err := re.walkChild(path, kv.k, kv.v)
+			if err != nil {
+				return err
+			}
+			kv.v = x
+		}
+	case TypeArray:
+		for i, item := range v.a {
+			x, err := re.walkChild(path, strconv.Itoa(i), item)
+			if err != nil {
+				return err
+			}
+			v.a[i] = x
+		}
+	}
+	return nil
+}
+
+// walkChild expands references in the child v located at path+key
+// and returns the resulting child.
+func (re *refExpander) walkChild(path Path, key string, v *Value) (*Value, error) {
+	childPath := append(path[:len(path):len(path)], key)
+	x, err := re.expandValue(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot expand references at %q: %s", childPath, err)
+	}
+	if err := re.walk(childPath, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// expandValue returns v with expanded references if v is a string.
+func (re *refExpander) expandValue(v *Value) (*Value, error) {
+	if v.Type() != TypeString || strings.IndexByte(v.s, '$') < 0 {
+		return v, nil
+	}
+	if x, ok := re.expanded[v]; ok {
+		return x, nil
+	}
+	if re.expanding[v] {
+		chain := append(re.stack[:len(re.stack):len(re.stack)], re.stack[0])
+		return nil, fmt.Errorf("reference cycle detected: ${%s}", strings.Join(chain, "} -> ${"))
+	}
+	re.expanding[v] = true
+	defer delete(re.expanding, v)
+
+	s := v.s
+	if n := len(s) - 1; strings.HasPrefix(s, "${") && strings.IndexByte(s, '}') == n {
+		// The whole string is a reference.
+		x, err := re.resolve(s[2:n])
+		if err != nil {
+			return nil, err
+		}
+		re.expanded[v] = x
+		return x, nil
+	}
+
+	var b []byte
+	for {
+		n := strings.IndexByte(s, '$')
+		if n < 0 {
+			b = append(b, s...)
+			break
+		}
+		b = append(b, s[:n]...)
+		s = s[n:]
+		switch {
+		case strings.HasPrefix(s, "$${"):
+			b = append(b, "${"...)
+			s = s[len("$${"):]
+		case strings.HasPrefix(s, "${"):
+			m := strings.IndexByte(s, '}')
+			if m < 0 {
+				return nil, fmt.Errorf("missing '}' after %q", s)
+			}
+			ref := s[2:m]
+			x, err := re.resolve(ref)
+			if err != nil {
+				return nil, err
+			}
+			switch x.Type() {
+			case TypeString, TypeNumber:
+				b = append(b, x.s...)
+			case TypeTrue, TypeFalse, TypeNull:
+				b = x.MarshalTo(b)
+			default:
+				return nil, fmt.Errorf("cannot insert %s referenced by ${%s} into string", x.Type(), ref)
+			}
+			if re.expandedBytes+len(b) > maxExpandedBytes {
+				return nil, fmt.Errorf("too big strings created by reference expansion; they exceed %d bytes", maxExpandedBytes)
+			}
+			s = s[m+1:]
+		default:
+			b = append(b, '$')
+			s = s[1:]
+		}
+	}
+	re.expandedBytes += len(b)
+	x := re.a.NewStringBytes(b)
+	re.expanded[v] = x
+	return x, nil
+}
+
+// resolve returns the fully expanded value for the given reference.
+func (re *refExpander) resolve(ref string) (*Value, error) {
+	re.stack = append(re.stack, ref)
+	defer func() {
+		re.stack = re.stack[:len(re.stack)-1]
+	}()
+
+	path, err := ParsePath(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference ${%s}: %s", ref, err)
+	}
+	v := re.root
+	for i, k := range path {
+		// References may go through other references.
+		if v, err = re.expandValue(v); err != nil {
+			return nil, err
+		}
+		if v, err = lookupKey(v, k, path[:i]); err != nil {
+			return nil, fmt.Errorf("cannot resolve ${%s}: %s", ref, err)
+		}
+	}
+	if v, err = re.expandValue(v); err != nil {
+		return nil, err
+	}
+	if re.walked[v] == walkInProgress {
+		return nil, fmt.Errorf("reference cycle detected: ${%s} contains the reference", ref)
+	}
+	if err := re.walk(path, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,62 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValueExpandRefs(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		var a Arena
+		if err := v.ExpandRefs(&a); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+		}
+	}
+
+	f(`a = 1;`, `{"a":1}`)
+	f(`services = { db = { host = "db.local"; port = 5432; }; };
+		dsn = "${services.db.host}:${services.db.port}";`,
+		`{"services":{"db":{"host":"db.local","port":5432}},"dsn":"db.local:5432"}`)
+	f(`port = "${base.port}"; base = { port = 8080; };`, `{"port":8080,"base":{"port":8080}}`)
+	f(`limits = { max = 10; }; a = { limits = "${limits}"; };`, `{"limits":{"max":10},"a":{"limits":{"max":10}}}`)
+	f(`x = "${y}"; y = "${z}-y"; z = "z";`, `{"x":"z-y","y":"z-y","z":"z"}`)
+	f(`hosts = ["a", "b"]; first = "${hosts.0}!";`, `{"hosts":["a","b"],"first":"a!"}`)
+	f(`alias = "${real}"; real = { v = "${name}"; }; name = "n"; x = "${alias.v}";`,
+		`{"alias":{"v":"n"},"real":{"v":"n"},"name":"n","x":"n"}`)
+	f(`a = "$${not.a.ref} costs $5"; b = true; c = "${b}/${n}"; n = null;`,
+		`{"a":"${not.a.ref} costs $5","b":true,"c":"true/null","n":null}`)
+
+	fErr := func(s string) {
+		t.Helper()
+		v := MustParse(s)
+		var a Arena
+		if err := v.ExpandRefs(&a); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+	}
+	fErr(`a = "${a}";`)
+	fErr(`a = "${b}"; b = "x${a}";`)
+	fErr(`a = { b = "${a}"; };`)
+	fErr(`a = "${missing}";`)
+	fErr(`a = "x${b}"; b = [1];`)
+	fErr(`a = "${b";`)
+	fErr(`a = "${}";`)
+
+	// Exponential growth via chains of references.
+	var sb strings.Builder
+	sb.WriteString(`a0 = "xxxxxxxxxxxxxxxx";`)
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&sb, ` a%d = "${a%d}${a%d}";`, i, i-1, i-1)
+	}
+	fErr(sb.String())
+
+	if err := MustParse(`a = 1;`).Freeze().ExpandRefs(nil); err == nil {
+		t.Fatalf("expecting non-nil error for frozen value")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"strconv"
+	"time"
+)
+
+// markExtendedJSON marks the object v if it is MongoDB Extended JSON
+// construct and ParserConfig.ExtendedJSON is set.
+func (c *cache) markExtendedJSON(v *Value) {
+	if c.cfg == nil || !c.cfg.ExtendedJSON || len(v.o.kvs) != 1 {
+		return
+	}
+	switch extendedJSONKey(v.o.kvs[0].k) {
+	case "$date", "$numberLong", "$numberInt":
+		v.o.flags |= flagExtended
+	}
+}
+
+// extendedJSONKey returns the raw object key k without quotes.
+func extendedJSONKey(k string) string {
+	if len(k) >= 2 && k[0] == '"' && k[len(k)-1] == '"' {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
+
+// extended returns the key and the value for MongoDB Extended JSON
+// construct v.
+func (v *Value) extended() (string, *Value, bool) {
+	if v == nil || v.t != TypeObject || v.o.flags&flagExtended == 0 {
+		return "", nil, false
+	}
+	kv := &v.o.kvs[0]
+	return extendedJSONKey(kv.k), kv.v, true
+}
+
+// extendedInt64 returns the value of {"$numberLong": "..."}
+// or {"$numberInt": "..."} construct v.
+func (v *Value) extendedInt64() (int64, bool) {
+	k, x, ok := v.extended()
+	if !ok || (k != "$numberLong" && k != "$numberInt") || x.Type() != TypeString {
+		return 0, false
+	}
+	bitSize := 64
+	if k == "$numberInt" {
+		bitSize = 32
+	}
+	n, err := strconv.ParseInt(x.s, 10, bitSize)
+	return n, err == nil
+}
+
+// GetTime returns time value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// RFC 3339 strings are recognized. {"$date": ...} objects with RFC 3339
+// string, milliseconds since the Unix epoch or {"$numberLong": "..."}
+// milliseconds are recognized too if ParserConfig.ExtendedJSON is set.
+//
+// Zero time is returned for non-existing keys path or for invalid value.
+func (v *Value) GetTime(keys ...string) time.Time {
+	v = v.Get(keys...)
+	if v == nil {
+		return time.Time{}
+	}
+	if k, x, ok := v.extended(); ok && k == "$date" {
+		if ms, ok := x.extendedInt64(); ok {
+			return time.UnixMilli(ms).UTC()
+		}
+		if x.Type() == TypeNumber {
+			ms, err := parseIntToken(x.s)
+			if err != nil {
+				return time.Time{}
+			}
+			return time.UnixMilli(ms).UTC()
+		}
+		v = x
+	}
+	if v.Type() != TypeString {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,87 @@
+package libconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtendedJSON(t *testing.T) {
+	const doc = `{"created": {"$date": "2021-03-04T05:06:07.5Z"}, "ms": {"$date": 1614834367500}, ` +
+		`"long": {"$date": {"$numberLong": "1614834367500"}}, "n": {"$numberLong": "9007199254740993"}, ` +
+		`"i": {"$numberInt": "42"}, "big": {"$numberInt": "4294967296"}, "plain": "2021-03-04T05:06:07.5Z", ` +
+		`"other": {"$date": "x", "y": 1}}`
+	var p Parser
+	p.Config.StrictRFC8259 = true
+	p.Config.ExtendedJSON = true
+	v, err := p.Parse(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tmExpected := time.Date(2021, 3, 4, 5, 6, 7, 500e6, time.UTC)
+	for _, key := range []string{"created", "ms", "long", "plain"} {
+		if tm := v.GetTime(key); !tm.Equal(tmExpected) {
+			t.Fatalf("unexpected time for %q; got %s; want %s", key, tm, tmExpected)
+		}
+	}
+	for _, key := range []string{"other", "n", "missing"} {
+		if tm := v.GetTime(key); !tm.IsZero() {
+			t.Fatalf("expecting zero time for %q; got %s", key, tm)
+		}
+	}
+
+	f := func(key string, nExpected int64) {
+		t.Helper()
+		if n := v.GetInt64(key); n != nExpected {
+			t.Fatalf("unexpected int64 for %q; got %d; want %d", key, n, nExpected)
+		}
+	}
+	f("n", 9007199254740993)
+	f("i", 42)
+	f("big", 0)
+	f("created", 0)
+
+	// Extended JSON constructs must be marshaled back unchanged.
+	sExpected := `{"$date":{"$numberLong":"1614834367500"}}`
+	if s := v.Get("long").String(); s != sExpected {
+		t.Fatalf("unexpected string representation; got %s; want %s", s, sExpected)
+	}
+
+	// Copies keep Extended JSON constructs recognized.
+	var a Arena
+	for name, c := range map[string]*Value{
+		"Freeze":          v.Freeze(),
+		"Clone":           v.Clone(),
+		"Arena.CopyValue": a.CopyValue(v),
+	} {
+		if tm := c.GetTime("long"); !tm.Equal(tmExpected) {
+			t.Fatalf("%s: unexpected time; got %s; want %s", name, tm, tmExpected)
+		}
+		if n := c.GetInt64("n"); n != 9007199254740993 {
+			t.Fatalf("%s: unexpected int64; got %d; want %d", name, n, int64(9007199254740993))
+		}
+	}
+
+	// The recognition works in libconfig mode too.
+	var p2 Parser
+	p2.Config.ExtendedJSON = true
+	v, err = p2.Parse(`n = { "$numberLong" = "123"; }; d = { $date = 0; };`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt64("n"); n != 123 {
+		t.Fatalf("unexpected int64; got %d; want %d", n, 123)
+	}
+	if tm := v.GetTime("d"); !tm.Equal(time.Unix(0, 0)) {
+		t.Fatalf("unexpected time; got %s; want %s", tm, time.Unix(0, 0))
+	}
+
+	// The recognition is disabled by default.
+	v, err = Parse(`n = { "$numberLong" = "123"; };`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt64("n"); n != 0 {
+		t.Fatalf("unexpected int64; got %d; want %d", n, 0)
+	}
+}
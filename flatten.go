@@ -0,0 +1,202 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten returns leaf values in v keyed by their paths joined with sep,
+// e.g. servers.0.port=8080 for sep=".".
+//
+// Array items are keyed by decimal indexes. Strings are stored without
+// quotes, while numbers, bools and null are stored in their textual form.
+// Empty objects and arrays are stored as "{}" and "[]", so they survive
+// Unflatten. Scalar v is stored under an empty key.
+//
+// Keys containing sep aren't escaped, so such keys are split into multiple
+// levels by Unflatten.
+func Flatten(v *Value, sep string) map[string]string {
+	m := make(map[string]string)
+	if v != nil {
+		flattenValue(m, nil, v, sep)
+	}
+	return m
+}
+
+func flattenValue(m map[string]string, prefix []byte, v *Value, sep string) {
+	switch v.Type() {
+	case TypeObject:
+		if v.o.Len() == 0 {
+			m[string(prefix)] = "{}"
+			return
+		}
+		v.o.Visit(func(key []byte, x *Value) {
+			flattenValue(m, appendFlatKey(prefix, key, sep), x, sep)
+		})
+	case TypeArray:
+		if len(v.a) == 0 {
+			m[string(prefix)] = "[]"
+			return
+		}
+		for i, x := range v.a {
+			flattenValue(m, appendFlatKey(prefix, strconv.AppendInt(nil, int64(i), 10), sep), x, sep)
+		}
+	case TypeString, TypeNumber:
+		m[string(prefix)] = strings.Clone(v.s)
+	default:
+		m[string(prefix)] = v.String()
+	}
+}
+
+func appendFlatKey(prefix, key []byte, sep string) []byte {
+	b := make([]byte, 0, len(prefix)+len(sep)+len(key))
+	b = append(b, prefix...)
+	if len(prefix) > 0 {
+		b = append(b, sep...)
+	}
+	return append(b, key...)
+}
+
+// Unflatten builds a Value tree from m obtained via Flatten.
+//
+// Keys are split by sep into paths. Objects whose keys are exactly
+// the decimal indexes 0..n-1 are converted into arrays.
+// Numbers, bools and null are inferred from values, while "{}" and "[]"
+// are converted into empty objects and arrays. The rest of values
+// become strings.
+//
+// An error is returned if a key is both a leaf and a prefix of another key,
+// e.g. for a=1 and a.b=2.
+//
+// The returned value is valid until Reset is called on a.
+func Unflatten(a *Arena, m map[string]string, sep string) (*Value, error) {
+	if sep == "" {
+		return nil, fmt.Errorf("sep cannot be empty")
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	root := &flatNode{}
+	for _, k := range keys {
+		n := root
+		if k != "" {
+			for _, key := range strings.Split(k, sep) {
+				if n.leaf != nil {
+					return nil, fmt.Errorf("key %q conflicts with the value for its prefix", k)
+				}
+				if n.children == nil {
+					n.children = make(map[string]*flatNode)
+				}
+				child := n.children[key]
+				if child == nil {
+					child = &flatNode{}
+					n.children[key] = child
+				}
+				n = child
+			}
+		}
+		if n.children != nil {
+			return nil, fmt.Errorf("key %q conflicts with the values for nested keys", k)
+		}
+		s := m[k]
+		n.leaf = &s
+	}
+	if root.leaf == nil && root.children == nil {
+		return a.NewObject(), nil
+	}
+	return root.value(a), nil
+}
+
+type flatNode struct {
+	leaf     *string
+	children map[string]*flatNode
+}
+
+func (n *flatNode) value(a *Arena) *Value {
+	if n.leaf != nil {
+		return unflattenLeaf(a, *n.leaf)
+	}
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+	if isIndexSequence(keys) {
+		v := a.NewArray()
+		for i := range keys {
+			v.SetArrayItem(i, n.children[strconv.Itoa(i)].value(a))
+		}
+		return v
+	}
+	sort.Strings(keys)
+	v := a.NewObject()
+	for _, k := range keys {
+		v.Set(k, n.children[k].value(a))
+	}
+	return v
+}
+
+// isIndexSequence returns true if keys contain exactly decimal indexes
+// 0..len(keys)-1 in any order.
+func isIndexSequence(keys []string) bool {
+	seen := make([]bool, len(keys))
+	for _, k := range keys {
+		if len(k) > 1 && k[0] == '0' {
+			return false
+		}
+		n, err := strconv.Atoi(k)
+		if err != nil || n < 0 || n >= len(keys) || seen[n] {
+			return false
+		}
+		seen[n] = true
+	}
+	return true
+}
+
+func unflattenLeaf(a *Arena, s string) *Value {
+	switch s {
+	case "{}":
+		return a.NewObject()
+	case "[]":
+		return a.NewArray()
+	case "true":
+		return valueTrue
+	case "false":
+		return valueFalse
+	case "null":
+		return valueNull
+	}
+	if isFlatNumber(s) {
+		return a.NewNumberString(s)
+	}
+	return a.NewString(s)
+}
@@ -0,0 +1,92 @@
+package libconfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	f := func(s, sep, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		m := Flatten(v, sep)
+		a := make([]string, 0, len(m))
+		for k, x := range m {
+			a = append(a, fmt.Sprintf("%s=%s", k, x))
+		}
+		sort.Strings(a)
+		if result := strings.Join(a, " "); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %q; want %q", s, result, resultExpected)
+		}
+
+		// Verify Unflatten restores the original value.
+		var arena Arena
+		v2, err := Unflatten(&arena, m, sep)
+		if err != nil {
+			t.Fatalf("unexpected error in Unflatten: %s", err)
+		}
+		if !EqualExcept(v, v2) {
+			t.Fatalf("unexpected value after Unflatten; got %s; want %s", v2, v)
+		}
+	}
+
+	f(``, ".", "={}")
+	f(`a = 1;`, ".", "a=1")
+	f(`servers = ({host = "foo"; port = 8080;}, {host = "bar"; port = 8081;});`, ".",
+		"servers.0.host=foo servers.0.port=8080 servers.1.host=bar servers.1.port=8081")
+	f(`a = {b = [true, false, null]; c = {}; d = [];};`, "_",
+		"a_b_0=true a_b_1=false a_b_2=null a_c={} a_d=[]")
+	f(`s = "x y"; n = -1.5;`, "/", "n=-1.5 s=x y")
+}
+
+func TestFlattenParserReuse(t *testing.T) {
+	var p Parser
+	v, err := p.Parse(`s = "hello"; n = 12345;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	m := Flatten(v, ".")
+	if _, err := p.Parse(`s = "XXXXX"; n = 99999;`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if m["s"] != "hello" || m["n"] != "12345" {
+		t.Fatalf("unexpected values after parser re-use: %q", m)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	f := func(m map[string]string, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v, err := Unflatten(&a, m, ".")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+		}
+	}
+
+	f(nil, "{}")
+	f(map[string]string{"": "42"}, "42")
+	f(map[string]string{"b": "x", "a.1": "y", "a.0": "007"}, `{"a":["007","y"],"b":"x"}`)
+	f(map[string]string{"a.0": "1", "a.2": "2"}, `{"a":{"0":1,"2":2}}`)
+	f(map[string]string{"a.00": "1"}, `{"a":{"00":1}}`)
+
+	// Conflicts
+	for _, m := range []map[string]string{
+		{"a": "1", "a.b": "2"},
+		{"": "1", "a": "2"},
+	} {
+		var a Arena
+		if _, err := Unflatten(&a, m, "."); err == nil {
+			t.Fatalf("expecting non-nil error for %v", m)
+		}
+	}
+	var a Arena
+	if _, err := Unflatten(&a, map[string]string{"a": "1"}, ""); err == nil {
+		t.Fatalf("expecting non-nil error for empty sep")
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// Clone returns a deep copy of v, which doesn't reference Parser or Arena
+// buffers.
+//
+// The returned value remains valid after the Parser returned v is re-used
+// or the Arena created v is reset. It may be modified via Set* calls.
+func (v *Value) Clone() *Value {
+	return cloneValue(v, false)
+}
+
+// Freeze returns an immutable deep copy of v, which doesn't reference
+// Parser or Arena buffers.
+//
+// The returned value may be read from concurrent goroutines, since all
+// the strings and object keys in it are unescaped in advance, so reading
+// it doesn't modify it. Set*, Del and similar calls on the returned value
+// and its descendants panic. Use Clone for obtaining a modifiable copy.
+//
+// v is returned as is if it is already frozen.
+func (v *Value) Freeze() *Value {
+	if v == nil || v.o.flags&flagFrozen != 0 {
+		return v
+	}
+	return cloneValue(v, true)
+}
+
+// IsFrozen returns true if v has been obtained via Freeze.
+func (v *Value) IsFrozen() bool {
+	return v != nil && v.o.flags&flagFrozen != 0
+}
+
+func cloneValue(v *Value, frozen bool) *Value {
+	if v == nil {
+		return nil
+	}
+	fz := freezer{
+		frozen: frozen,
+	}
+	fz.count(v)
+	fz.vs = make([]Value, 0, fz.n)
+	fz.b = make([]byte, 0, fz.bLen)
+	return fz.copy(v)
+}
+
+type freezer struct {
+	frozen bool
+
+	n    int
+	bLen int
+
+	vs []Value
+	b  []byte
+}
+
+func (fz *freezer) count(v *Value) {
+	switch v.Type() {
+	case TypeObject:
+		fz.n++
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			fz.bLen += len(kv.k)
+			fz.count(kv.v)
+		}
+	case TypeArray:
+		fz.n++
+		for _, item := range v.a {
+			fz.count(item)
+		}
+	case TypeString, TypeNumber, TypeRawJSON:
+		fz.n++
+		fz.bLen += len(v.s)
+	}
+}
+
+func (fz *freezer) copy(v *Value) *Value {
+	switch v.t {
+	case TypeObject:
+		fv := fz.getValue(TypeObject)
+		fv.o.kvs = make([]kv, len(v.o.kvs))
+		for i, kv := range v.o.kvs {
+			fv.o.kvs[i].k = fz.copyString(kv.k)
+			fv.o.kvs[i].v = fz.copy(kv.v)
+		}
+		fv.o.keysUnescaped = true
+		fv.o.sorted = v.o.sorted
+		fv.o.flags |= v.o.flags & flagExtended
+		return fv
+	case TypeArray:
+		fv := fz.getValue(TypeArray)
+		fv.a = make([]*Value, len(v.a))
+		for i, item := range v.a {
+			fv.a[i] = fz.copy(item)
+		}
+		return fv
+	case TypeString, TypeNumber, TypeRawJSON:
+		fv := fz.getValue(v.t)
+		fv.s = fz.copyString(v.s)
+		fv.o.flags |= v.o.flags & flagGenerated
+		return fv
+	case TypeTrue:
+		return valueTrue
+	case TypeFalse:
+		return valueFalse
+	default:
+		return valueNull
+	}
+}
+
+func (fz *freezer) getValue(t Type) *Value {
+	fz.vs = fz.vs[:len(fz.vs)+1]
+	v := &fz.vs[len(fz.vs)-1]
+	v.t = t
+	if fz.frozen {
+		v.o.flags = flagFrozen
+	}
+	return v
+}
+
+func (fz *freezer) copyString(s string) string {
+	bLen := len(fz.b)
+	fz.b = append(fz.b, s...)
+	return b2s(fz.b[bLen:])
+}
@@ -0,0 +1,89 @@
+package libconfig
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestValueClone(t *testing.T) {
+	var p Parser
+	v, err := p.Parse(`foo = "b\\ar"; obj = { x = [1, 2, true]; };`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c := v.Clone()
+	if c.IsFrozen() {
+		t.Fatalf("clone mustn't be frozen")
+	}
+	if _, err := p.Parse(`foo = "xxxxxxxxxxxxxx"; obj = 1;`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s := c.String()
+	sExpected := `{"foo":"b\\ar","obj":{"x":[1,2,true]}}`
+	if s != sExpected {
+		t.Fatalf("unexpected clone\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+
+	var a Arena
+	c.Get("obj").Set("y", a.NewString("z"))
+	c.Del("foo")
+	s = c.String()
+	sExpected = `{"obj":{"x":[1,2,true],"y":"z"}}`
+	if s != sExpected {
+		t.Fatalf("unexpected modified clone\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+
+	if v := (*Value)(nil).Clone(); v != nil {
+		t.Fatalf("expecting nil clone; got %s", v)
+	}
+}
+
+func TestValueFreeze(t *testing.T) {
+	v := MustParse(`foo = "bar"; obj = { x = [1, 2]; };`)
+	fv := v.Freeze()
+	if !fv.IsFrozen() || !fv.Get("obj", "x").IsFrozen() {
+		t.Fatalf("value must be frozen")
+	}
+	if fv.Freeze() != fv {
+		t.Fatalf("Freeze must return frozen values as is")
+	}
+
+	f := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatalf("%s: expecting panic on frozen value modification", name)
+			}
+		}()
+		fn()
+	}
+	var a Arena
+	f("Value.Set", func() { fv.Set("foo", a.NewNull()) })
+	f("Value.Del", func() { fv.Del("foo") })
+	f("Object.Set", func() { fv.GetObject("obj").Set("y", a.NewNull()) })
+	f("Object.Del", func() { fv.GetObject("obj").Del("x") })
+	f("SetArrayItem", func() { fv.Get("obj", "x").SetArrayItem(0, a.NewNull()) })
+
+	// Clone of a frozen value is modifiable.
+	c := fv.Clone()
+	c.Set("foo", a.NewString("baz"))
+	if s := string(c.GetStringBytes("foo")); s != "baz" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "baz")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if s := string(fv.GetStringBytes("foo")); s != "bar" {
+					panic("unexpected value")
+				}
+				_ = fv.GetInt("obj", "x", "1")
+				_ = fv.String()
+			}
+		}()
+	}
+	wg.Wait()
+}
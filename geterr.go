@@ -0,0 +1,216 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMissing is returned by *Err getters such as Value.IntErr
+// if the value at the given keys path doesn't exist.
+var ErrMissing = errors.New("missing value")
+
+// ErrWrongType is returned by *Err getters such as Value.IntErr
+// if the value at the given keys path has unexpected type.
+var ErrWrongType = errors.New("unexpected value type")
+
+// ErrOutOfRange is returned by *Err getters such as Value.IntErr
+// if the number at the given keys path doesn't fit the requested type.
+var ErrOutOfRange = errors.New("value out of range")
+
+// lookupErr returns the value at the given keys path in v.
+//
+// The returned error wraps ErrMissing for non-existing keys path
+// and ErrWrongType if the value type differs from t.
+func (v *Value) lookupErr(t Type, keys []string) (*Value, error) {
+	x, err := Path(keys).LookupIn(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissing, err)
+	}
+	typ := x.Type()
+	if typ == t || t == TypeTrue && typ == TypeFalse {
+		return x, nil
+	}
+	want := t.String()
+	if t == TypeTrue {
+		want = "bool"
+	}
+	return nil, fmt.Errorf("%w at %q: got %s; want %s", ErrWrongType, Path(keys), typ, want)
+}
+
+// StringErr returns string value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned error wraps ErrMissing for non-existing keys path
+// and ErrWrongType for non-string value, so it may be checked with errors.Is.
+//
+// The returned string is a copy, so it remains valid after Parse
+// is called on the Parser returned v.
+func (v *Value) StringErr(keys ...string) (string, error) {
+	x, err := v.lookupErr(TypeString, keys)
+	if err != nil {
+		return "", err
+	}
+	return string(s2b(x.s)), nil
+}
+
+// GetEnum returns string value by the given keys path if it is one
+// of the allowed values.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned error lists the allowed values if the value isn't among them.
+// It wraps ErrMissing for non-existing keys path and ErrWrongType
+// for non-string value, so it may be checked with errors.Is.
+//
+// The returned string is a copy, so it remains valid after Parse
+// is called on the Parser returned v.
+func (v *Value) GetEnum(allowed []string, keys ...string) (string, error) {
+	x, err := v.lookupErr(TypeString, keys)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range allowed {
+		if x.s == s {
+			return s, nil
+		}
+	}
+	quoted := make([]string, len(allowed))
+	for i, s := range allowed {
+		quoted[i] = strconv.Quote(s)
+	}
+	return "", fmt.Errorf("unexpected value %q at %q; allowed values: %s", x.s, Path(keys), strings.Join(quoted, ", "))
+}
+
+// IntErr returns int value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned error wraps ErrMissing for non-existing keys path,
+// ErrWrongType for non-integer value and ErrOutOfRange if the value
+// doesn't fit int, so it may be checked with errors.Is.
+func (v *Value) IntErr(keys ...string) (int, error) {
+	n, err := v.intErr(strconv.IntSize, keys)
+	return int(n), err
+}
+
+// Int64Err returns int64 value by the given keys path.
+//
+// See IntErr for details.
+func (v *Value) Int64Err(keys ...string) (int64, error) {
+	return v.intErr(64, keys)
+}
+
+// Uint64Err returns uint64 value by the given keys path.
+//
+// See IntErr for details. Negative numbers result in ErrOutOfRange.
+func (v *Value) Uint64Err(keys ...string) (uint64, error) {
+	x, err := v.lookupErr(TypeNumber, keys)
+	if err != nil {
+		return 0, err
+	}
+	if !isIntToken(x.s) {
+		return 0, fmt.Errorf("%w at %q: got non-integer number %s", ErrWrongType, Path(keys), x.s)
+	}
+	if strings.HasPrefix(x.s, "-") {
+		return 0, fmt.Errorf("%w at %q: number %s is negative", ErrOutOfRange, Path(keys), x.s)
+	}
+	digits, base := intTokenDigits(x.s)
+	n, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return 0, numberTokenErr(err, x.s, "uint64", keys)
+	}
+	return n, nil
+}
+
+// Float64Err returns float64 value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned error wraps ErrMissing for non-existing keys path,
+// ErrWrongType for non-number value and ErrOutOfRange if the value
+// doesn't fit float64, so it may be checked with errors.Is.
+func (v *Value) Float64Err(keys ...string) (float64, error) {
+	x, err := v.lookupErr(TypeNumber, keys)
+	if err != nil {
+		return 0, err
+	}
+	f, err := parseFloatToken(x.s)
+	if err != nil {
+		return 0, numberTokenErr(err, x.s, "float64", keys)
+	}
+	return f, nil
+}
+
+// BoolErr returns bool value by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned error wraps ErrMissing for non-existing keys path
+// and ErrWrongType for non-bool value, so it may be checked with errors.Is.
+func (v *Value) BoolErr(keys ...string) (bool, error) {
+	x, err := v.lookupErr(TypeTrue, keys)
+	if err != nil {
+		return false, err
+	}
+	return x.t == TypeTrue, nil
+}
+
+func (v *Value) intErr(bitSize int, keys []string) (int64, error) {
+	x, err := v.lookupErr(TypeNumber, keys)
+	if err != nil {
+		return 0, err
+	}
+	if !isIntToken(x.s) {
+		return 0, fmt.Errorf("%w at %q: got non-integer number %s", ErrWrongType, Path(keys), x.s)
+	}
+	digits, base := intTokenDigits(x.s)
+	n, err := strconv.ParseInt(digits, base, bitSize)
+	if err != nil {
+		return 0, numberTokenErr(err, x.s, "int"+strconv.Itoa(bitSize), keys)
+	}
+	return n, nil
+}
+
+// intTokenDigits returns digits and base for libconfig integer token s.
+func intTokenDigits(s string) (string, int) {
+	if isHexToken(s) {
+		return s[2:], 16
+	}
+	return strings.TrimSuffix(s, "L"), 10
+}
+
+// numberTokenErr converts strconv error for number token s into *Err getter error.
+func numberTokenErr(err error, s, typ string, keys []string) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%w at %q: number %s doesn't fit %s", ErrOutOfRange, Path(keys), s, typ)
+	}
+	return fmt.Errorf("cannot parse number %s at %q: %s", s, Path(keys), err)
+}
@@ -0,0 +1,116 @@
+package libconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueGetErr(t *testing.T) {
+	v := MustParse(`s = "foo"; i = -42; h = 0x10; big = 100000000000000000000L; fl = 1.5; huge = 1e400; b = false; o = {a = [1, "x"];};`)
+
+	s, err := v.StringErr("o", "a", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s != "x" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "x")
+	}
+	n, err := v.IntErr("i")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n != -42 {
+		t.Fatalf("unexpected int; got %d; want %d", n, -42)
+	}
+	n64, err := v.Int64Err("h")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n64 != 16 {
+		t.Fatalf("unexpected int64; got %d; want %d", n64, 16)
+	}
+	u64, err := v.Uint64Err("o", "a", "0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if u64 != 1 {
+		t.Fatalf("unexpected uint64; got %d; want %d", u64, 1)
+	}
+	f, err := v.Float64Err("fl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if f != 1.5 {
+		t.Fatalf("unexpected float64; got %v; want %v", f, 1.5)
+	}
+	b, err := v.BoolErr("b")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if b {
+		t.Fatalf("unexpected bool; got %v; want %v", b, false)
+	}
+
+	fe := func(name string, err, errExpected error) {
+		t.Helper()
+		if !errors.Is(err, errExpected) {
+			t.Fatalf("unexpected error for %s; got %v; want %v", name, err, errExpected)
+		}
+	}
+	_, err = v.StringErr("missing")
+	fe("StringErr(missing)", err, ErrMissing)
+	_, err = v.StringErr("o", "a", "5")
+	fe("StringErr(o.a.5)", err, ErrMissing)
+	_, err = v.StringErr("s", "x")
+	fe("StringErr(s.x)", err, ErrMissing)
+	_, err = v.StringErr("i")
+	fe("StringErr(i)", err, ErrWrongType)
+	_, err = v.IntErr("fl")
+	fe("IntErr(fl)", err, ErrWrongType)
+	_, err = v.IntErr("s")
+	fe("IntErr(s)", err, ErrWrongType)
+	_, err = v.Int64Err("big")
+	fe("Int64Err(big)", err, ErrOutOfRange)
+	_, err = v.Uint64Err("i")
+	fe("Uint64Err(i)", err, ErrOutOfRange)
+	_, err = v.Float64Err("huge")
+	fe("Float64Err(huge)", err, ErrOutOfRange)
+	_, err = v.Float64Err("b")
+	fe("Float64Err(b)", err, ErrWrongType)
+	_, err = v.BoolErr("o")
+	fe("BoolErr(o)", err, ErrWrongType)
+
+	_, err = v.IntErr("s")
+	if msg, msgExpected := err.Error(), `unexpected value type at "s": got string; want number`; msg != msgExpected {
+		t.Fatalf("unexpected error message; got %q; want %q", msg, msgExpected)
+	}
+}
+
+func TestValueGetEnum(t *testing.T) {
+	v := MustParse(`mode = "fast"; level = "verbose"; n = 1;`)
+	allowed := []string{"fast", "safe"}
+
+	s, err := v.GetEnum(allowed, "mode")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s != "fast" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "fast")
+	}
+
+	_, err = v.GetEnum(allowed, "level")
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	errExpected := `unexpected value "verbose" at "level"; allowed values: "fast", "safe"`
+	if err.Error() != errExpected {
+		t.Fatalf("unexpected error; got %q; want %q", err, errExpected)
+	}
+
+	if _, err := v.GetEnum(allowed, "n"); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("expecting ErrWrongType; got %v", err)
+	}
+	if _, err := v.GetEnum(allowed, "missing"); !errors.Is(err, ErrMissing) {
+		t.Fatalf("expecting ErrMissing; got %v", err)
+	}
+}
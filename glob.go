@@ -0,0 +1,108 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// GetAll returns values matching dotted pattern such as "services.*.port".
+//
+// Values are returned in depth-first order, so matches at shallower levels
+// of "**" expansion precede matches inside their descendants.
+//
+// The "*" pattern segment matches any object key or array index, while
+// the "**" segment matches zero or more levels of nesting, e.g. "**.port"
+// matches port values at any depth. Other segments are matched as in ParsePath.
+//
+// nil is returned if nothing matches or if pattern is invalid.
+//
+// The returned values are valid until Parse is called on the Parser returned v.
+func GetAll(v *Value, pattern string) []*Value {
+	p, err := ParsePath(pattern)
+	if err != nil || v == nil {
+		return nil
+	}
+	g := globMatcher{}
+	for i, key := range p {
+		if key == "**" {
+			if i > 0 && p[i-1] == "**" {
+				// Consecutive "**" segments are equivalent to a single one.
+				continue
+			}
+			g.seen = make(map[*Value]struct{})
+		}
+		g.p = append(g.p, key)
+	}
+	g.match(v, g.p)
+	return g.dst
+}
+
+type globMatcher struct {
+	p   Path
+	dst []*Value
+
+	// seen is used for deduplicating matches if the pattern contains "**",
+	// since the same value may be reached via distinct expansions.
+	seen map[*Value]struct{}
+}
+
+func (g *globMatcher) match(v *Value, p Path) {
+	if len(p) == 0 {
+		if g.seen != nil {
+			if _, ok := g.seen[v]; ok {
+				return
+			}
+			g.seen[v] = struct{}{}
+		}
+		g.dst = append(g.dst, v)
+		return
+	}
+	switch key := p[0]; key {
+	case "*":
+		g.visitChildren(v, func(x *Value) {
+			g.match(x, p[1:])
+		})
+	case "**":
+		g.match(v, p[1:])
+		g.visitChildren(v, func(x *Value) {
+			g.match(x, p)
+		})
+	default:
+		if x := v.Get(key); x != nil {
+			g.match(x, p[1:])
+		}
+	}
+}
+
+func (g *globMatcher) visitChildren(v *Value, f func(x *Value)) {
+	switch v.Type() {
+	case TypeObject:
+		v.o.Visit(func(_ []byte, x *Value) {
+			f(x)
+		})
+	case TypeArray:
+		for _, x := range v.a {
+			f(x)
+		}
+	}
+}
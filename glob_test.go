@@ -0,0 +1,42 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetAll(t *testing.T) {
+	v := MustParse(`
+		services = {
+			web = {port = 80; tls = {port = 443;};};
+			db = {port = 5432;};
+			cache = {host = "x";};
+		};
+		list = ({port = 1;}, {port = 2;});
+		port = 0;
+	`)
+
+	f := func(pattern, resultExpected string) {
+		t.Helper()
+		var a []string
+		for _, x := range GetAll(v, pattern) {
+			a = append(a, x.String())
+		}
+		if result := strings.Join(a, ","); result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %q; want %q", pattern, result, resultExpected)
+		}
+	}
+
+	f("services.*.port", "80,5432")
+	f("list.*.port", "1,2")
+	f("list.1.port", "2")
+	f("*.*.port", "80,5432,1,2")
+	f("**.port", "0,80,443,5432,1,2")
+	f("services.**.port", "80,443,5432")
+	f("services.**.**.port", "80,443,5432")
+	f("**.tls.**.port", "443")
+	f("**.web.**", `{"port":80,"tls":{"port":443}},80,{"port":443},443`)
+	f("services.*.missing", "")
+	f("port.*", "")
+	f(`services\`, "")
+}
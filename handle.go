@@ -0,0 +1,160 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import "math/big"
+
+// Handle holds a scratch Parser for calling many handy getters in a row
+// without pool round-trips.
+//
+// The Parser is lazily obtained from the handy pool on the first use
+// and is returned back by Release. Store Handle per goroutine, e.g. in
+// per-request state:
+//
+//	var h libconfig.Handle
+//	defer h.Release()
+//	for _, data := range docs {
+//		port := h.GetInt(data, "server", "port")
+//		...
+//	}
+//
+// Handle cannot be used from concurrent goroutines.
+type Handle struct {
+	p *Parser
+}
+
+// Parser returns the scratch Parser held by h.
+//
+// The returned Parser mustn't be used after h.Release call.
+// Changes to its Config are reset by h.Release.
+func (h *Handle) Parser() *Parser {
+	if h.p == nil {
+		h.p = handyPool.Get()
+	}
+	return h.p
+}
+
+// Release returns the scratch Parser to the handy pool.
+//
+// h may be used again after Release.
+func (h *Handle) Release() {
+	if h.p == nil {
+		return
+	}
+	handyPool.Put(h.p)
+	h.p = nil
+}
+
+// GetString returns string value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetString, but reuses the Parser held by h.
+func (h *Handle) GetString(data []byte, keys ...string) string {
+	return h.Parser().GetString(data, keys...)
+}
+
+// GetBytes returns string value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetBytes, but reuses the Parser held by h.
+func (h *Handle) GetBytes(data []byte, keys ...string) []byte {
+	return h.Parser().GetBytes(data, keys...)
+}
+
+// GetInt returns int value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetInt, but reuses the Parser held by h.
+func (h *Handle) GetInt(data []byte, keys ...string) int {
+	return h.Parser().GetInt(data, keys...)
+}
+
+// GetHex returns hex value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetHex, but reuses the Parser held by h.
+func (h *Handle) GetHex(data []byte, keys ...string) string {
+	return h.Parser().GetHex(data, keys...)
+}
+
+// GetBigint returns big int value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetBigint, but reuses the Parser held by h.
+func (h *Handle) GetBigint(data []byte, keys ...string) *big.Int {
+	return h.Parser().GetBigint(data, keys...)
+}
+
+// GetFloat64 returns float64 value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetFloat64, but reuses the Parser held by h.
+func (h *Handle) GetFloat64(data []byte, keys ...string) float64 {
+	return h.Parser().GetFloat64(data, keys...)
+}
+
+// GetBool returns bool value for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetBool, but reuses the Parser held by h.
+func (h *Handle) GetBool(data []byte, keys ...string) bool {
+	return h.Parser().GetBool(data, keys...)
+}
+
+// GetStringSlice returns string slice for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetStringSlice, but reuses the Parser held by h.
+func (h *Handle) GetStringSlice(data []byte, keys ...string) []string {
+	return h.Parser().GetStringSlice(data, keys...)
+}
+
+// GetIntSlice returns int slice for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetIntSlice, but reuses the Parser held by h.
+func (h *Handle) GetIntSlice(data []byte, keys ...string) []int {
+	return h.Parser().GetIntSlice(data, keys...)
+}
+
+// GetFloat64Slice returns float64 slice for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetFloat64Slice, but reuses the Parser held by h.
+func (h *Handle) GetFloat64Slice(data []byte, keys ...string) []float64 {
+	return h.Parser().GetFloat64Slice(data, keys...)
+}
+
+// GetBoolSlice returns bool slice for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetBoolSlice, but reuses the Parser held by h.
+func (h *Handle) GetBoolSlice(data []byte, keys ...string) []bool {
+	return h.Parser().GetBoolSlice(data, keys...)
+}
+
+// GetStringMap returns string map for the field identified by keys path in JSON data.
+//
+// It is equivalent to GetStringMap, but reuses the Parser held by h.
+func (h *Handle) GetStringMap(data []byte, keys ...string) map[string]string {
+	return h.Parser().GetStringMap(data, keys...)
+}
+
+// Exists returns true if the field identified by keys path exists in JSON data.
+//
+// It is equivalent to Exists, but reuses the Parser held by h.
+func (h *Handle) Exists(data []byte, keys ...string) bool {
+	return h.Parser().Exists(data, keys...)
+}
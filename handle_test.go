@@ -0,0 +1,54 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestHandle(t *testing.T) {
+	pp := &ParserPool{}
+	SetHandyPool(pp)
+	defer SetHandyPool(nil)
+
+	data := []byte(`foo = "bar"; n = 123; a = [1, 2];`)
+	var h Handle
+	for i := 0; i < 10; i++ {
+		if s := h.GetString(data, "foo"); s != "bar" {
+			t.Fatalf("unexpected string; got %q; want %q", s, "bar")
+		}
+		if n := h.GetInt(data, "n"); n != 123 {
+			t.Fatalf("unexpected int; got %d; want %d", n, 123)
+		}
+		if a := h.GetIntSlice(data, "a"); len(a) != 2 || a[1] != 2 {
+			t.Fatalf("unexpected int slice; got %v; want [1 2]", a)
+		}
+		if h.Exists(data, "missing") {
+			t.Fatalf("unexpected existing field")
+		}
+	}
+	h.Release()
+	h.Release()
+
+	stats := pp.Stats()
+	if stats.Gets != 1 || stats.Puts != 1 {
+		t.Fatalf("unexpected stats; got %+v; want 1 get and 1 put", stats)
+	}
+}
+
+func TestHandleReleaseResetsConfig(t *testing.T) {
+	pp := &ParserPool{
+		MaxParsers: 1,
+	}
+	SetHandyPool(pp)
+	defer SetHandyPool(nil)
+
+	var h Handle
+	h.Parser().Config.MaxInputSize = 3
+	h.Release()
+
+	if n := GetInt([]byte(`a = 42;`), "a"); n != 42 {
+		t.Fatalf("unexpected int; got %d; want %d", n, 42)
+	}
+	if stats := pp.Stats(); stats.News != 1 {
+		t.Fatalf("the released parser must be re-used; got %+v", stats)
+	}
+}
@@ -25,9 +25,70 @@
  */
 package libconfig
 
-import "math/big"
+import (
+	"math/big"
+	"sync/atomic"
+)
 
-var handyPool ParserPool
+// HandyParserPool is a pool of Parsers used by handy functions such as
+// GetString, GetInt, Exists, Unmarshal and LoadFile.
+//
+// *ParserPool implements HandyParserPool.
+type HandyParserPool interface {
+	// Get returns a Parser from the pool.
+	Get() *Parser
+
+	// Put returns p to the pool.
+	Put(p *Parser)
+}
+
+var defaultHandyPool ParserPool
+
+var handyPoolValue atomic.Value
+
+type handyPoolHolder struct {
+	pp HandyParserPool
+}
+
+// SetHandyPool sets the pool used by handy functions.
+//
+// This allows bounding memory retained by handy functions, e.g.
+//
+//	libconfig.SetHandyPool(&libconfig.ParserPool{
+//		MaxParsers:    16,
+//		MaxBufferSize: 1 << 20,
+//	})
+//
+// The default pool is restored if pp is nil. It is safe calling SetHandyPool
+// concurrently with handy functions.
+func SetHandyPool(pp HandyParserPool) {
+	handyPoolValue.Store(handyPoolHolder{pp: pp})
+}
+
+// GetHandyPool returns the pool used by handy functions.
+//
+// The default pool is *ParserPool, so its Stats may be inspected
+// with a type assertion.
+func GetHandyPool() HandyParserPool {
+	h, _ := handyPoolValue.Load().(handyPoolHolder)
+	if h.pp == nil {
+		return &defaultHandyPool
+	}
+	return h.pp
+}
+
+// handyPool forwards Get and Put calls to the current handy pool.
+var handyPool handyPoolProxy
+
+type handyPoolProxy struct{}
+
+func (handyPoolProxy) Get() *Parser {
+	return GetHandyPool().Get()
+}
+
+func (handyPoolProxy) Put(p *Parser) {
+	GetHandyPool().Put(p)
+}
 
 // GetString returns string value for the field identified by keys path
 // in JSON data.
@@ -39,15 +100,9 @@ var handyPool ParserPool
 // Parser is faster for obtaining multiple fields from JSON.
 func GetString(data []byte, keys ...string) string {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return ""
-	}
-	sb := v.GetStringBytes(keys...)
-	str := string(sb)
+	x := p.GetString(data, keys...)
 	handyPool.Put(p)
-	return str
+	return x
 }
 
 // GetBytes returns string value for the field identified by keys path
@@ -60,21 +115,9 @@ func GetString(data []byte, keys ...string) string {
 // Parser is faster for obtaining multiple fields from JSON.
 func GetBytes(data []byte, keys ...string) []byte {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return nil
-	}
-	sb := v.GetStringBytes(keys...)
-
-	// Make a copy of sb, since sb belongs to p.
-	var b []byte
-	if sb != nil {
-		b = append(b, sb...)
-	}
-
+	x := p.GetBytes(data, keys...)
 	handyPool.Put(p)
-	return b
+	return x
 }
 
 // GetInt returns int value for the field identified by keys path
@@ -87,38 +130,38 @@ func GetBytes(data []byte, keys ...string) []byte {
 // Parser is faster for obtaining multiple fields from JSON.
 func GetInt(data []byte, keys ...string) int {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return 0
-	}
-	n := v.GetInt(keys...)
+	x := p.GetInt(data, keys...)
 	handyPool.Put(p)
-	return n
+	return x
 }
 
 func GetHex(data []byte, keys ...string) string {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return ""
-	}
-	n := v.GetHex(keys...)
+	x := p.GetHex(data, keys...)
 	handyPool.Put(p)
-	return n
+	return x
 }
 
 func GetBigint(data []byte, keys ...string) *big.Int {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return big.NewInt(0)
-	}
-	n := v.GetBigint(keys...)
+	x := p.GetBigint(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetNumberString returns the exact number token for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// An empty string is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetNumberString(data []byte, keys ...string) string {
+	p := handyPool.Get()
+	x := p.GetNumberString(data, keys...)
 	handyPool.Put(p)
-	return n
+	return x
 }
 
 // GetFloat64 returns float64 value for the field identified by keys path
@@ -131,14 +174,9 @@ func GetBigint(data []byte, keys ...string) *big.Int {
 // Parser is faster for obtaining multiple fields from JSON.
 func GetFloat64(data []byte, keys ...string) float64 {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return 0
-	}
-	f := v.GetFloat64(keys...)
+	x := p.GetFloat64(data, keys...)
 	handyPool.Put(p)
-	return f
+	return x
 }
 
 // GetBool returns boolean value for the field identified by keys path
@@ -151,14 +189,154 @@ func GetFloat64(data []byte, keys ...string) float64 {
 // Parser is faster for obtaining multiple fields from JSON.
 func GetBool(data []byte, keys ...string) bool {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return false
-	}
-	b := v.GetBool(keys...)
+	x := p.GetBool(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetStringOr returns string value for the field identified by keys path
+// in libconfig data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+// Use Parser for proper error handling.
+func GetStringOr(data []byte, def string, keys ...string) string {
+	p := handyPool.Get()
+	x := p.GetStringOr(data, def, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetIntOr returns int value for the field identified by keys path
+// in libconfig data, e.g. GetIntOr(data, 8080, "port").
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+// Use Parser for proper error handling.
+func GetIntOr(data []byte, def int, keys ...string) int {
+	p := handyPool.Get()
+	x := p.GetIntOr(data, def, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetFloat64Or returns float64 value for the field identified by keys path
+// in libconfig data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+// Use Parser for proper error handling.
+func GetFloat64Or(data []byte, def float64, keys ...string) float64 {
+	p := handyPool.Get()
+	x := p.GetFloat64Or(data, def, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetBoolOr returns boolean value for the field identified by keys path
+// in libconfig data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+// Use Parser for proper error handling.
+func GetBoolOr(data []byte, def bool, keys ...string) bool {
+	p := handyPool.Get()
+	x := p.GetBoolOr(data, def, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetSampledBool returns a stable pseudo-random decision for identity
+// according to the percentage at percentKey in data.
+//
+// This is useful for canary-style rollouts gated by config. See
+// Value.GetSampledBool for details.
+//
+// False is returned on error. Use Parser for proper error handling.
+func GetSampledBool(data []byte, seedKey, percentKey, identity string) bool {
+	p := handyPool.Get()
+	x := p.GetSampledBool(data, seedKey, percentKey, identity)
+	handyPool.Put(p)
+	return x
+}
+
+// GetStringSlice returns a slice of strings for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetStringSlice(data []byte, keys ...string) []string {
+	p := handyPool.Get()
+	x := p.GetStringSlice(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetIntSlice returns a slice of ints for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetIntSlice(data []byte, keys ...string) []int {
+	p := handyPool.Get()
+	x := p.GetIntSlice(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetFloat64Slice returns a slice of float64 numbers for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetFloat64Slice(data []byte, keys ...string) []float64 {
+	p := handyPool.Get()
+	x := p.GetFloat64Slice(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetBoolSlice returns a slice of bools for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetBoolSlice(data []byte, keys ...string) []bool {
+	p := handyPool.Get()
+	x := p.GetBoolSlice(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetStringMap returns a map of strings for the field identified
+// by keys path in JSON data.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parser for proper error handling.
+//
+// Parser is faster for obtaining multiple fields from JSON.
+func GetStringMap(data []byte, keys ...string) map[string]string {
+	p := handyPool.Get()
+	x := p.GetStringMap(data, keys...)
 	handyPool.Put(p)
-	return b
+	return x
 }
 
 // Exists returns true if the field identified by keys path exists in JSON data.
@@ -170,14 +348,70 @@ func GetBool(data []byte, keys ...string) bool {
 // Parser is faster when multiple fields must be checked in the JSON.
 func Exists(data []byte, keys ...string) bool {
 	p := handyPool.Get()
-	v, err := p.ParseBytes(data)
-	if err != nil {
-		handyPool.Put(p)
-		return false
-	}
-	ok := v.Exists(keys...)
+	x := p.Exists(data, keys...)
+	handyPool.Put(p)
+	return x
+}
+
+// GetByPath returns the value for the field identified by dotted path
+// such as "server.hosts.0.name" in libconfig data.
+//
+// Dots inside keys must be escaped with backslash. See ParsePath for details.
+//
+// nil is returned on error. Use Parser for proper error handling.
+// The returned value doesn't reference internal buffers, so it remains valid
+// after subsequent calls.
+func GetByPath(data []byte, path string) *Value {
+	p := handyPool.Get()
+	x := p.GetByPath(data, path)
+	handyPool.Put(p)
+	return x
+}
+
+// Set sets value at the field identified by keys path in data
+// and returns the result.
+//
+// See Parser.Set for details.
+//
+// Parser is faster for multiple edits.
+func Set(data []byte, value interface{}, keys ...string) ([]byte, error) {
+	p := handyPool.Get()
+	b, err := p.Set(data, value, keys...)
+	handyPool.Put(p)
+	return b, err
+}
+
+// SetString sets string s at the field identified by keys path in data
+// and returns the result.
+//
+// See Parser.Set for details.
+func SetString(data []byte, s string, keys ...string) ([]byte, error) {
+	p := handyPool.Get()
+	b, err := p.SetString(data, s, keys...)
+	handyPool.Put(p)
+	return b, err
+}
+
+// Delete deletes the field identified by keys path in data
+// and returns the result.
+//
+// See Parser.Delete for details.
+func Delete(data []byte, keys ...string) ([]byte, error) {
+	p := handyPool.Get()
+	b, err := p.Delete(data, keys...)
+	handyPool.Put(p)
+	return b, err
+}
+
+// AppendRaw appends the value from rawJSON to the array at the field
+// identified by keys path in data and returns the result.
+//
+// See Parser.AppendRaw for details.
+func AppendRaw(data, rawJSON []byte, keys ...string) ([]byte, error) {
+	p := handyPool.Get()
+	b, err := p.AppendRaw(data, rawJSON, keys...)
 	handyPool.Put(p)
-	return ok
+	return b, err
 }
 
 // Parse parses json string s.
@@ -0,0 +1,475 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// GetString parses data with p and returns string value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// An empty string is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetString(data []byte, keys ...string) string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return ""
+	}
+	return string(v.GetStringBytes(keys...))
+}
+
+// GetBytes parses data with p and returns string value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetBytes(data []byte, keys ...string) []byte {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	sb := v.GetStringBytes(keys...)
+
+	// Make a copy of sb, since sb belongs to p.
+	var b []byte
+	if sb != nil {
+		b = append(b, sb...)
+	}
+	return b
+}
+
+// GetInt parses data with p and returns int value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// 0 is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetInt(data []byte, keys ...string) int {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return 0
+	}
+	return v.GetInt(keys...)
+}
+
+// GetHex parses data with p and returns hex representation of int value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// An empty string is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetHex(data []byte, keys ...string) string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return ""
+	}
+	return strings.Clone(v.GetHex(keys...))
+}
+
+// GetBigint parses data with p and returns big int value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// Zero is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetBigint(data []byte, keys ...string) *big.Int {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return big.NewInt(0)
+	}
+	return v.GetBigint(keys...)
+}
+
+// GetNumberString parses data with p and returns the exact number token
+// for the field identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// An empty string is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetNumberString(data []byte, keys ...string) string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return ""
+	}
+	return string(v.GetRawNumber(keys...))
+}
+
+// GetFloat64 parses data with p and returns float64 value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// 0 is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetFloat64(data []byte, keys ...string) float64 {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return 0
+	}
+	return v.GetFloat64(keys...)
+}
+
+// GetBool parses data with p and returns boolean value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// False is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetBool(data []byte, keys ...string) bool {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return false
+	}
+	return v.GetBool(keys...)
+}
+
+// GetStringOr parses data with p and returns string value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetStringOr(data []byte, def string, keys ...string) string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return def
+	}
+	return v.GetStringOr(def, keys...)
+}
+
+// GetIntOr parses data with p and returns int value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) GetIntOr(data []byte, def int, keys ...string) int {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return def
+	}
+	return v.GetIntOr(def, keys...)
+}
+
+// GetFloat64Or parses data with p and returns float64 value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) GetFloat64Or(data []byte, def float64, keys ...string) float64 {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return def
+	}
+	return v.GetFloat64Or(def, keys...)
+}
+
+// GetBoolOr parses data with p and returns boolean value for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// def is returned on error, for missing field or for invalid value type.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) GetBoolOr(data []byte, def bool, keys ...string) bool {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return def
+	}
+	return v.GetBoolOr(def, keys...)
+}
+
+// GetSampledBool parses data with p and returns a stable pseudo-random decision
+// for identity according to the percentage at percentKey.
+//
+// See Value.GetSampledBool for details.
+//
+// False is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) GetSampledBool(data []byte, seedKey, percentKey, identity string) bool {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return false
+	}
+	return v.GetSampledBool(seedKey, percentKey, identity)
+}
+
+// GetStringSlice parses data with p and returns a slice of strings for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetStringSlice(data []byte, keys ...string) []string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	return v.GetStringSlice(keys...)
+}
+
+// GetIntSlice parses data with p and returns a slice of ints for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetIntSlice(data []byte, keys ...string) []int {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	return v.GetIntSlice(keys...)
+}
+
+// GetFloat64Slice parses data with p and returns a slice of float64 numbers for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetFloat64Slice(data []byte, keys ...string) []float64 {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	return v.GetFloat64Slice(keys...)
+}
+
+// GetBoolSlice parses data with p and returns a slice of bools for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetBoolSlice(data []byte, keys ...string) []bool {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	return v.GetBoolSlice(keys...)
+}
+
+// GetStringMap parses data with p and returns a map of strings for the field
+// identified by keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetStringMap(data []byte, keys ...string) map[string]string {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	return v.GetStringMap(keys...)
+}
+
+// Exists parses data with p and returns true if the field identified
+// by keys path exists.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// False is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) Exists(data []byte, keys ...string) bool {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return false
+	}
+	return v.Exists(keys...)
+}
+
+// GetByPath parses data with p and returns a copy of the value for the field
+// identified by dotted path such as "server.hosts.0.name".
+//
+// See Value.GetByPath for details.
+//
+// nil is returned on error. Use Parse* for proper error handling.
+//
+// Values previously obtained from p cannot be used after the call.
+// The returned value doesn't reference p.
+func (p *Parser) GetByPath(data []byte, path string) *Value {
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil
+	}
+	x := v.GetByPath(path)
+	if x == nil {
+		return nil
+	}
+	return x.Clone()
+}
+
+// Set parses data with p, sets value at the field identified by keys path
+// and returns the marshaled result appended to nil.
+//
+// value is converted via Arena.Marshal. *Value is used as is. Missing
+// intermediate objects are created. Array items may be replaced or appended
+// by setting the index equal to the array length.
+//
+// The result is marshaled via Value.MarshalLibconfigTo, so comments and
+// formatting of data aren't preserved. Use Editor for format-preserving edits.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) Set(data []byte, value interface{}, keys ...string) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("cannot set the root value")
+	}
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var a Arena
+	x, ok := value.(*Value)
+	if !ok {
+		if x, err = a.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	if err := Path(keys).SetIn(&a, v, x); err != nil {
+		return nil, err
+	}
+	return p.marshalChecked(v)
+}
+
+// SetString parses data with p, sets string s at the field identified
+// by keys path and returns the marshaled result appended to nil.
+//
+// See Parser.Set for details.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) SetString(data []byte, s string, keys ...string) ([]byte, error) {
+	return p.Set(data, s, keys...)
+}
+
+// Delete parses data with p, deletes the field identified by keys path
+// and returns the marshaled result appended to nil.
+//
+// Array items are deleted by decimal indexes. data is returned re-marshaled
+// if the field is missing. See Parser.Set for details.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) Delete(data []byte, keys ...string) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("cannot delete the root value")
+	}
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	v.Get(keys[:len(keys)-1]...).Del(keys[len(keys)-1])
+	return p.marshalChecked(v)
+}
+
+// AppendRaw parses data with p, appends the value from rawJSON to the array
+// at the field identified by keys path and returns the marshaled result
+// appended to nil.
+//
+// The array is created if the field is missing. See Parser.Set for details.
+//
+// Values previously obtained from p cannot be used after the call.
+func (p *Parser) AppendRaw(data, rawJSON []byte, keys ...string) ([]byte, error) {
+	var item Value
+	if err := item.UnmarshalJSON(rawJSON); err != nil {
+		return nil, err
+	}
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var a Arena
+	if err := v.ArrayAppend(&a, &item, keys...); err != nil {
+		return nil, err
+	}
+	return p.marshalChecked(v)
+}
+
+// marshalChecked marshals v and verifies the result may be parsed back,
+// e.g. it doesn't contain invalid keys.
+func (p *Parser) marshalChecked(v *Value) ([]byte, error) {
+	if err := checkLibconfigKeys(v); err != nil {
+		return nil, fmt.Errorf("cannot marshal the result: %s", err)
+	}
+	b := v.MarshalLibconfigTo(nil)
+	if _, err := p.ParseBytes(b); err != nil {
+		return nil, fmt.Errorf("cannot marshal the result: %s", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,65 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParserHandyGetters(t *testing.T) {
+	var p Parser
+	data := []byte(`foo = "bar"; n = 0x1F; f = 1.5; b = true; big = 123L; list = ["a", "b"]; ints = [1, 2];
+		floats = [0.5]; bools = [false]; env = { K = "v"; };`)
+
+	if s := p.GetString(data, "foo"); s != "bar" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "bar")
+	}
+	if b := p.GetBytes(data, "foo"); string(b) != "bar" {
+		t.Fatalf("unexpected bytes; got %q; want %q", b, "bar")
+	}
+	if n := p.GetInt(data, "n"); n != 31 {
+		t.Fatalf("unexpected int; got %d; want %d", n, 31)
+	}
+	hex := p.GetHex(data, "n")
+	if f := p.GetFloat64(data, "f"); f != 1.5 {
+		t.Fatalf("unexpected float; got %v; want %v", f, 1.5)
+	}
+	if !p.GetBool(data, "b") {
+		t.Fatalf("unexpected bool")
+	}
+	if n := p.GetBigint(data, "big"); n.Int64() != 123 {
+		t.Fatalf("unexpected big int; got %s; want %d", n, 123)
+	}
+	if ss := p.GetStringSlice(data, "list"); len(ss) != 2 || ss[1] != "b" {
+		t.Fatalf("unexpected strings: %q", ss)
+	}
+	if ns := p.GetIntSlice(data, "ints"); len(ns) != 2 || ns[1] != 2 {
+		t.Fatalf("unexpected ints: %v", ns)
+	}
+	if fs := p.GetFloat64Slice(data, "floats"); len(fs) != 1 || fs[0] != 0.5 {
+		t.Fatalf("unexpected floats: %v", fs)
+	}
+	if bs := p.GetBoolSlice(data, "bools"); len(bs) != 1 || bs[0] {
+		t.Fatalf("unexpected bools: %v", bs)
+	}
+	if m := p.GetStringMap(data, "env"); len(m) != 1 || m["K"] != "v" {
+		t.Fatalf("unexpected map: %q", m)
+	}
+	if !p.Exists(data, "env", "K") || p.Exists(data, "env", "X") {
+		t.Fatalf("unexpected Exists result")
+	}
+
+	// Results mustn't reference the parser.
+	if s := p.GetString([]byte(`foo = "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx";`), "foo"); s == "" {
+		t.Fatalf("unexpected empty string")
+	}
+	if hex != "0x1F" {
+		t.Fatalf("unexpected hex after parser re-use; got %q; want %q", hex, "0x1F")
+	}
+
+	// invalid data
+	if s := p.GetString([]byte("invalid json"), "foo"); s != "" {
+		t.Fatalf("unexpected non-empty string: %q", s)
+	}
+	if p.Exists([]byte("invalid json"), "foo") {
+		t.Fatalf("unexpected Exists result for invalid data")
+	}
+}
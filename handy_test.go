@@ -116,6 +116,37 @@ func TestGetFloat64(t *testing.T) {
 	}
 }
 
+func TestGetNumberString(t *testing.T) {
+	data := []byte(`amount = 1234.50; id = 340282366920938463463374607431768211455; s = "1"; h = 0x1F;`)
+
+	f := func(key, resultExpected string) {
+		t.Helper()
+		if s := GetNumberString(data, key); s != resultExpected {
+			t.Fatalf("unexpected value for %q; got %q; want %q", key, s, resultExpected)
+		}
+	}
+	f("amount", "1234.50")
+	f("id", "340282366920938463463374607431768211455")
+	f("h", "0x1F")
+	f("s", "")
+	f("missing", "")
+
+	if s := GetNumberString([]byte("invalid"), "amount"); s != "" {
+		t.Fatalf("unexpected non-empty value obtained: %q", s)
+	}
+
+	v := MustParse(string(data))
+	if b := v.GetRawNumber("amount"); string(b) != "1234.50" {
+		t.Fatalf("unexpected raw number; got %q; want %q", b, "1234.50")
+	}
+	if b := v.Get("s").RawNumber(); b != nil {
+		t.Fatalf("expecting nil raw number for string; got %q", b)
+	}
+	if b := v.GetRawNumber("missing"); b != nil {
+		t.Fatalf("expecting nil raw number for missing key; got %q", b)
+	}
+}
+
 func TestGetBool(t *testing.T) {
 	data := []byte(`foo="bar"; baz=true;`)
 
@@ -175,6 +206,88 @@ func TestExists(t *testing.T) {
 	}
 }
 
+func TestSetDelete(t *testing.T) {
+	data := []byte(`# comment
+a = 1;
+b = { c = "x"; d = [1, 2]; };`)
+
+	f := func(b []byte, err error, resultExpected string) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(b) != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", b, resultExpected)
+		}
+	}
+
+	b, err := Set(data, 42, "a")
+	f(b, err, `a = 42; b = {c = "x"; d = [1, 2];};`)
+	b, err = Set(data, []string{"y", "z"}, "b", "e", "f")
+	f(b, err, `a = 1; b = {c = "x"; d = [1, 2]; e = {f = ["y", "z"];};};`)
+	b, err = Set(data, map[string]interface{}{"k": true}, "b", "d", "2")
+	f(b, err, `a = 1; b = {c = "x"; d = [1, 2, {k = true;}];};`)
+	b, err = Set(data, MustParse(`x = 1;`), "b")
+	f(b, err, `a = 1; b = {x = 1;};`)
+	b, err = SetString(data, `q"w`, "b", "c")
+	f(b, err, `a = 1; b = {c = "q\"w"; d = [1, 2];};`)
+	b, err = Delete(data, "b", "c")
+	f(b, err, `a = 1; b = {d = [1, 2];};`)
+	b, err = Delete(data, "b", "d", "0")
+	f(b, err, `a = 1; b = {c = "x"; d = [2];};`)
+	b, err = Delete(data, "missing")
+	f(b, err, `a = 1; b = {c = "x"; d = [1, 2];};`)
+
+	// errors
+	if _, err := Set(data, 1); err == nil {
+		t.Fatalf("expecting non-nil error for empty keys")
+	}
+	if _, err := Set(data, 1, "a", "b"); err == nil {
+		t.Fatalf("expecting non-nil error for setting key in number")
+	}
+	if _, err := Set(data, 1, "b", "d", "5"); err == nil {
+		t.Fatalf("expecting non-nil error for out of range index")
+	}
+	if _, err := Set(data, map[string]int{"x = 1; y": 1}, "a"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid key")
+	}
+	if _, err := Set(data, make(chan int), "a"); err == nil {
+		t.Fatalf("expecting non-nil error for unsupported type")
+	}
+	if _, err := Delete([]byte("invalid"), "a"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid data")
+	}
+}
+
+func TestAppendRaw(t *testing.T) {
+	data := []byte(`allowed_hosts = ["a"]; n = 1;`)
+
+	f := func(rawJSON string, keys []string, resultExpected string) {
+		t.Helper()
+		b, err := AppendRaw(data, []byte(rawJSON), keys...)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if string(b) != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", b, resultExpected)
+		}
+	}
+
+	f(`"b"`, []string{"allowed_hosts"}, `allowed_hosts = ["a", "b"]; n = 1;`)
+	f(`{"host": "c", "port": 80}`, []string{"allowed_hosts"}, `allowed_hosts = ["a", {host = "c"; port = 80;}]; n = 1;`)
+	f(`[1]`, []string{"x", "y"}, `allowed_hosts = ["a"]; n = 1; x = {y = [[1]];};`)
+
+	if _, err := AppendRaw(data, []byte(`{`), "allowed_hosts"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid rawJSON")
+	}
+	if _, err := AppendRaw(data, []byte(`1`), "n"); err == nil {
+		t.Fatalf("expecting non-nil error for non-array")
+	}
+	if _, err := AppendRaw([]byte(`invalid`), []byte(`1`), "n"); err == nil {
+		t.Fatalf("expecting non-nil error for invalid data")
+	}
+}
+
 func TestParse(t *testing.T) {
 	v, err := Parse(`foo="bar";`)
 	if err != nil {
@@ -228,3 +341,91 @@ func causesPanic(fn func()) (p bool) {
 	fn()
 	return
 }
+
+func TestGetSlices(t *testing.T) {
+	data := []byte(`hosts = ["a", "b"]; ports = [80, 0x1BB]; ratios = (0.5, 1); flags = [true, false]; mixed = [1, "x"];`)
+
+	ss := GetStringSlice(data, "hosts")
+	if len(ss) != 2 || ss[0] != "a" || ss[1] != "b" {
+		t.Fatalf("unexpected strings: %q", ss)
+	}
+	ns := GetIntSlice(data, "ports")
+	if len(ns) != 2 || ns[0] != 80 || ns[1] != 443 {
+		t.Fatalf("unexpected ints: %v", ns)
+	}
+	fs := GetFloat64Slice(data, "ratios")
+	if len(fs) != 2 || fs[0] != 0.5 || fs[1] != 1 {
+		t.Fatalf("unexpected floats: %v", fs)
+	}
+	bs := GetBoolSlice(data, "flags")
+	if len(bs) != 2 || !bs[0] || bs[1] {
+		t.Fatalf("unexpected bools: %v", bs)
+	}
+
+	// invalid item types
+	if ss := GetStringSlice(data, "mixed"); ss != nil {
+		t.Fatalf("unexpected non-nil strings: %q", ss)
+	}
+	if ns := GetIntSlice(data, "ratios"); ns != nil {
+		t.Fatalf("unexpected non-nil ints: %v", ns)
+	}
+	if bs := GetBoolSlice(data, "ports"); bs != nil {
+		t.Fatalf("unexpected non-nil bools: %v", bs)
+	}
+
+	// non-existing path
+	if fs := GetFloat64Slice(data, "foo"); fs != nil {
+		t.Fatalf("unexpected non-nil floats: %v", fs)
+	}
+
+	// invalid json
+	if ss := GetStringSlice([]byte("invalid json"), "hosts"); ss != nil {
+		t.Fatalf("unexpected non-nil strings: %q", ss)
+	}
+}
+
+func TestGetStringMap(t *testing.T) {
+	data := []byte(`env = { HOST = "localhost"; MODE = "dev"; MODE = "prod"; }; mixed = { a = "x"; b = 1; };`)
+
+	m := GetStringMap(data, "env")
+	if len(m) != 2 || m["HOST"] != "localhost" || m["MODE"] != "dev" {
+		t.Fatalf("unexpected map: %q", m)
+	}
+
+	// invalid value types
+	if m := GetStringMap(data, "mixed"); m != nil {
+		t.Fatalf("unexpected non-nil map: %q", m)
+	}
+	if m := GetStringMap(data, "env", "HOST"); m != nil {
+		t.Fatalf("unexpected non-nil map: %q", m)
+	}
+
+	// invalid json
+	if m := GetStringMap([]byte("invalid json"), "env"); m != nil {
+		t.Fatalf("unexpected non-nil map: %q", m)
+	}
+}
+
+func TestSetHandyPool(t *testing.T) {
+	pp := &ParserPool{
+		MaxParsers: 1,
+	}
+	SetHandyPool(pp)
+	defer SetHandyPool(nil)
+
+	if GetHandyPool() != HandyParserPool(pp) {
+		t.Fatalf("unexpected handy pool")
+	}
+	if s := GetString([]byte(`foo = "bar";`), "foo"); s != "bar" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "bar")
+	}
+	stats := pp.Stats()
+	if stats.Gets != 1 || stats.Puts != 1 {
+		t.Fatalf("unexpected stats; got %+v; want 1 get and 1 put", stats)
+	}
+
+	SetHandyPool(nil)
+	if _, ok := GetHandyPool().(*ParserPool); !ok {
+		t.Fatalf("unexpected default handy pool type %T", GetHandyPool())
+	}
+}
@@ -0,0 +1,703 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseHCL parses s containing HCL, e.g. Terraform-style config.
+//
+// Attributes are mapped to object entries. Blocks are mapped to nested
+// objects keyed by block type and labels, so
+//
+//	resource "aws_instance" "web" { ami = "abc" }
+//
+// becomes {"resource":{"aws_instance":{"web":{"ami":"abc"}}}}.
+// Repeated blocks with the same type and labels are collected into an array.
+//
+// Strings, numbers, bools, null, lists, objects and heredocs are converted
+// to the corresponding values. Other expressions such as references,
+// function calls and operators are stored as strings containing
+// the expression source. Template interpolations inside strings are kept as is.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseHCL(s string) (*Value, error) {
+	s, err := decodeInput(s, p.Config.TranscodeInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse HCL: %s", err)
+	}
+	p.b = append(p.b[:0], s...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	hp := &hclParser{
+		src: b2s(p.b),
+		s:   b2s(p.b),
+		c:   &p.c,
+	}
+	v, err := hp.parse()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse HCL: %s", err)
+	}
+	return v, nil
+}
+
+// ParseHCLBytes parses b containing HCL.
+//
+// See Parser.ParseHCL for details.
+func (p *Parser) ParseHCLBytes(b []byte) (*Value, error) {
+	return p.ParseHCL(b2s(b))
+}
+
+// ParseHCL parses s containing HCL.
+//
+// The function is slower than the Parser.ParseHCL for re-used Parser.
+func ParseHCL(s string) (*Value, error) {
+	var p Parser
+	return p.ParseHCL(s)
+}
+
+// ParseHCLBytes parses b containing HCL.
+//
+// The function is slower than the Parser.ParseHCLBytes for re-used Parser.
+func ParseHCLBytes(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseHCLBytes(b)
+}
+
+type hclParser struct {
+	// src is the whole input. It is used for error positions.
+	src string
+
+	// s is the unparsed tail of src.
+	s string
+
+	c *cache
+
+	// blocks contains block bodies and arrays of block bodies.
+	blocks map[*Value]bool
+
+	// depth is the current nesting depth.
+	depth int
+}
+
+func (hp *hclParser) errorf(format string, args ...interface{}) error {
+	prefix := hp.src[:len(hp.src)-len(hp.s)]
+	line := strings.Count(prefix, "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (hp *hclParser) newObject() *Value {
+	v := hp.c.getValue()
+	v.t = TypeObject
+	v.o.reset()
+	v.o.keysUnescaped = true
+	return v
+}
+
+func (hp *hclParser) newString(s string) *Value {
+	v := hp.c.getValue()
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+func (hp *hclParser) parse() (*Value, error) {
+	hp.blocks = make(map[*Value]bool)
+	root := hp.newObject()
+	if err := hp.parseBody(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// parseBody parses attributes and blocks into o until the closing '}'
+// if inBlock is set or until the end of input otherwise.
+func (hp *hclParser) parseBody(o *Value, inBlock bool) error {
+	hp.depth++
+	defer func() {
+		hp.depth--
+	}()
+	if maxDepth := hp.c.cfg.maxDepth(); hp.depth > maxDepth {
+		return hp.errorf("too big depth for the nested HCL; it exceeds %d", maxDepth)
+	}
+	for {
+		hp.skipBlank(true)
+		if len(hp.s) == 0 {
+			if inBlock {
+				return hp.errorf("missing '}' at the end of block")
+			}
+			return nil
+		}
+		if hp.s[0] == '}' {
+			if !inBlock {
+				return hp.errorf("unexpected '}'")
+			}
+			hp.s = hp.s[1:]
+			return nil
+		}
+		name := hp.parseIdent()
+		if name == "" {
+			return hp.errorf("expecting attribute or block name; got %q", startEndString(hp.s))
+		}
+		hp.skipBlank(false)
+		if len(hp.s) > 0 && hp.s[0] == '=' && !strings.HasPrefix(hp.s, "==") {
+			hp.s = hp.s[1:]
+			if err := hp.parseAttribute(o, name); err != nil {
+				return err
+			}
+		} else if err := hp.parseBlock(o, name); err != nil {
+			return err
+		}
+		if err := hp.parseLineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+func (hp *hclParser) parseAttribute(o *Value, name string) error {
+	if o.o.Get(name) != nil {
+		return hp.errorf("duplicate attribute or block %q", name)
+	}
+	hp.skipBlank(false)
+	v, err := hp.parseExpr()
+	if err != nil {
+		return err
+	}
+	appendObjectKV(&o.o, name, v)
+	return nil
+}
+
+func (hp *hclParser) parseBlock(o *Value, blockType string) error {
+	keys := []string{blockType}
+	for {
+		hp.skipBlank(false)
+		if len(hp.s) == 0 {
+			return hp.errorf("missing '{' after block %q", blockType)
+		}
+		if hp.s[0] == '{' {
+			hp.s = hp.s[1:]
+			break
+		}
+		var label string
+		if hp.s[0] == '"' {
+			var err error
+			if label, err = hp.parseString(); err != nil {
+				return err
+			}
+		} else if label = hp.parseIdent(); label == "" {
+			return hp.errorf("expecting block label or '{' after block %q; got %q", blockType, startEndString(hp.s))
+		}
+		keys = append(keys, label)
+	}
+
+	// Walk labels.
+	parent := o
+	for i, key := range keys[:len(keys)-1] {
+		child := parent.o.Get(key)
+		if child == nil {
+			child = hp.newObject()
+			hp.blocks[child] = true
+			appendObjectKV(&parent.o, key, child)
+		} else if child.t != TypeObject || !hp.blocks[child] {
+			return hp.errorf("cannot define block %q, since %q is already defined", strings.Join(keys, " "), strings.Join(keys[:i+1], " "))
+		}
+		parent = child
+	}
+
+	body := hp.newObject()
+	if err := hp.parseBody(body, true); err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	prev := parent.o.Get(last)
+	switch {
+	case prev == nil:
+		hp.blocks[body] = true
+		appendObjectKV(&parent.o, last, body)
+	case !hp.blocks[prev]:
+		return hp.errorf("cannot define block %q, since attribute %q is already defined", strings.Join(keys, " "), last)
+	case prev.t == TypeArray:
+		prev.a = append(prev.a, body)
+	default:
+		// Repeated block - convert it to array of blocks.
+		a := hp.c.getValue()
+		a.t = TypeArray
+		a.a = append(a.a[:0], prev, body)
+		hp.blocks[a] = true
+		for i := range parent.o.kvs {
+			if parent.o.kvs[i].k == last {
+				parent.o.kvs[i].v = a
+			}
+		}
+	}
+	return nil
+}
+
+// skipBlank skips whitespace and comments. Newlines are skipped if newlines is set.
+func (hp *hclParser) skipBlank(newlines bool) {
+	s := hp.s
+	for len(s) > 0 {
+		switch {
+		case s[0] == ' ' || s[0] == '\t':
+			s = s[1:]
+		case s[0] == '\r' || s[0] == '\n':
+			if !newlines {
+				hp.s = s
+				return
+			}
+			s = s[1:]
+		case s[0] == '#' || strings.HasPrefix(s, "//"):
+			n := strings.IndexByte(s, '\n')
+			if n < 0 {
+				n = len(s)
+			}
+			s = s[n:]
+		case strings.HasPrefix(s, "/*"):
+			n := strings.Index(s[2:], "*/")
+			if n < 0 {
+				hp.s = s
+				return
+			}
+			s = s[n+4:]
+		default:
+			hp.s = s
+			return
+		}
+	}
+	hp.s = s
+}
+
+func (hp *hclParser) parseLineEnd() error {
+	hp.skipBlank(false)
+	if len(hp.s) == 0 || hp.s[0] == '\n' || hp.s[0] == '\r' || hp.s[0] == '}' {
+		return nil
+	}
+	if strings.HasPrefix(hp.s, "/*") {
+		return hp.errorf("missing '*/' at the end of comment")
+	}
+	return hp.errorf("unexpected data after attribute or block: %q", startEndString(hp.s))
+}
+
+func (hp *hclParser) parseIdent() string {
+	n := 0
+	for n < len(hp.s) && isHCLIdentChar(hp.s[n], n == 0) {
+		n++
+	}
+	ident := hp.s[:n]
+	hp.s = hp.s[n:]
+	return ident
+}
+
+func isHCLIdentChar(ch byte, first bool) bool {
+	if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || ch >= 0x80 {
+		return true
+	}
+	return !first && (isDigit(ch) || ch == '-')
+}
+
+// parseExpr parses expression until the end of line, ',' or closing bracket.
+func (hp *hclParser) parseExpr() (*Value, error) {
+	start := hp.s
+	v, err := hp.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		hp.skipBlank(false)
+		if hp.atExprEnd() {
+			return v, nil
+		}
+	}
+	// The expression isn't a literal or the literal is a part of bigger expression.
+	hp.s = start
+	expr, err := hp.scanExpr()
+	if err != nil {
+		return nil, err
+	}
+	if expr == "" {
+		return nil, hp.errorf("missing expression")
+	}
+	return hp.newString(expr), nil
+}
+
+func (hp *hclParser) atExprEnd() bool {
+	if len(hp.s) == 0 {
+		return true
+	}
+	switch hp.s[0] {
+	case '\n', '\r', ',', ']', '}', ')':
+		return true
+	}
+	return hp.s[0] == '#' || strings.HasPrefix(hp.s, "//") || strings.HasPrefix(hp.s, "/*")
+}
+
+// parseLiteral parses literal value at the start of hp.s.
+//
+// nil is returned if hp.s doesn't start with literal value.
+func (hp *hclParser) parseLiteral() (*Value, error) {
+	if len(hp.s) == 0 {
+		return nil, hp.errorf("missing expression")
+	}
+	switch ch := hp.s[0]; {
+	case ch == '"':
+		s, err := hp.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return hp.newString(s), nil
+	case (ch == '[' || ch == '{') && isHCLForExpr(hp.s[1:]):
+		return nil, nil
+	case ch == '[':
+		return hp.parseList()
+	case ch == '{':
+		return hp.parseObject()
+	case strings.HasPrefix(hp.s, "<<"):
+		s, err := hp.parseHeredoc()
+		if err != nil {
+			return nil, err
+		}
+		return hp.newString(s), nil
+	case ch == '-' || isDigit(ch):
+		n := 1
+		for n < len(hp.s) && (isDigit(hp.s[n]) || strings.IndexByte(".eE+-", hp.s[n]) >= 0) {
+			n++
+		}
+		ns := hp.s[:n]
+		if _, err := strconv.ParseFloat(ns, 64); err != nil {
+			return nil, nil
+		}
+		hp.s = hp.s[n:]
+		v := hp.c.getValue()
+		v.t = TypeNumber
+		v.s = ns
+		return v, nil
+	}
+	tail := hp.s
+	switch hp.parseIdent() {
+	case "true":
+		return valueTrue, nil
+	case "false":
+		return valueFalse, nil
+	case "null":
+		return valueNull, nil
+	}
+	hp.s = tail
+	return nil, nil
+}
+
+// isHCLForExpr returns true if s starts with for expression body.
+func isHCLForExpr(s string) bool {
+	s = strings.TrimLeft(s, " \t\r\n")
+	return strings.HasPrefix(s, "for ") || strings.HasPrefix(s, "for\t")
+}
+
+// scanExpr returns the source of the expression at the start of hp.s.
+func (hp *hclParser) scanExpr() (string, error) {
+	s := hp.s
+	depth := 0
+	i := 0
+loop:
+	for i < len(s) {
+		switch ch := s[i]; ch {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				break loop
+			}
+			depth--
+		case ',', '\n', '\r', '#':
+			if depth == 0 {
+				break loop
+			}
+		case '/':
+			if depth == 0 && i+1 < len(s) && (s[i+1] == '/' || s[i+1] == '*') {
+				break loop
+			}
+		case '=':
+			if isHCLAssignment(s, i) && depth == 0 {
+				hp.s = s[i:]
+				return "", hp.errorf("unexpected '=' in expression %q; missing newline before the next attribute?", startEndString(strings.TrimSpace(s[:i])))
+			}
+		case '"':
+			hp.s = s[i:]
+			if _, err := hp.parseString(); err != nil {
+				return "", err
+			}
+			i = len(s) - len(hp.s)
+			continue
+		}
+		i++
+	}
+	if depth > 0 {
+		hp.s = s[i:]
+		return "", hp.errorf("unclosed bracket in expression %q", startEndString(s[:i]))
+	}
+	hp.s = s[i:]
+	return strings.TrimSpace(s[:i]), nil
+}
+
+// isHCLAssignment returns true if s[i] is '=' not belonging to comparison
+// or arrow operators.
+func isHCLAssignment(s string, i int) bool {
+	if i > 0 && strings.IndexByte("=!<>", s[i-1]) >= 0 {
+		return false
+	}
+	return i+1 >= len(s) || (s[i+1] != '=' && s[i+1] != '>')
+}
+
+func (hp *hclParser) parseList() (*Value, error) {
+	hp.depth++
+	defer func() {
+		hp.depth--
+	}()
+	if maxDepth := hp.c.cfg.maxDepth(); hp.depth > maxDepth {
+		return nil, hp.errorf("too big depth for the nested HCL; it exceeds %d", maxDepth)
+	}
+	hp.s = hp.s[1:]
+	a := hp.c.getValue()
+	a.t = TypeArray
+	a.a = a.a[:0]
+	for {
+		hp.skipBlank(true)
+		if len(hp.s) == 0 {
+			return nil, hp.errorf("missing ']' at the end of list")
+		}
+		if hp.s[0] == ']' {
+			hp.s = hp.s[1:]
+			return a, nil
+		}
+		v, err := hp.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		a.a = append(a.a, v)
+		hp.skipBlank(true)
+		if len(hp.s) > 0 && hp.s[0] == ',' {
+			hp.s = hp.s[1:]
+			continue
+		}
+		if len(hp.s) == 0 || hp.s[0] != ']' {
+			return nil, hp.errorf("missing ',' or ']' after list item")
+		}
+	}
+}
+
+func (hp *hclParser) parseObject() (*Value, error) {
+	hp.depth++
+	defer func() {
+		hp.depth--
+	}()
+	if maxDepth := hp.c.cfg.maxDepth(); hp.depth > maxDepth {
+		return nil, hp.errorf("too big depth for the nested HCL; it exceeds %d", maxDepth)
+	}
+	hp.s = hp.s[1:]
+	o := hp.newObject()
+	for {
+		hp.skipBlank(true)
+		if len(hp.s) == 0 {
+			return nil, hp.errorf("missing '}' at the end of object")
+		}
+		if hp.s[0] == '}' {
+			hp.s = hp.s[1:]
+			return o, nil
+		}
+		var key string
+		if hp.s[0] == '"' {
+			var err error
+			if key, err = hp.parseString(); err != nil {
+				return nil, err
+			}
+		} else if key = hp.parseIdent(); key == "" {
+			return nil, hp.errorf("expecting object key; got %q", startEndString(hp.s))
+		}
+		hp.skipBlank(false)
+		if len(hp.s) == 0 || (hp.s[0] != '=' && hp.s[0] != ':') {
+			return nil, hp.errorf("missing '=' or ':' after object key %q", key)
+		}
+		hp.s = hp.s[1:]
+		hp.skipBlank(false)
+		if o.o.Get(key) != nil {
+			return nil, hp.errorf("duplicate object key %q", key)
+		}
+		v, err := hp.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		appendObjectKV(&o.o, key, v)
+		hp.skipBlank(false)
+		if len(hp.s) > 0 && hp.s[0] == ',' {
+			hp.s = hp.s[1:]
+		}
+	}
+}
+
+// parseString parses quoted string at the start of hp.s.
+//
+// Template sequences such as ${var.name} are kept as is.
+func (hp *hclParser) parseString() (string, error) {
+	s := hp.s[1:]
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '"':
+			hp.s = s[i+1:]
+			return string(b), nil
+		case '\n':
+			hp.s = s[i:]
+			return "", hp.errorf("missing closing '\"' in string")
+		case '$', '%':
+			if i+1 < len(s) && s[i+1] == '{' {
+				// Copy template sequence as is.
+				n := strings.IndexByte(s[i:], '}')
+				if n < 0 {
+					hp.s = s[i:]
+					return "", hp.errorf("missing '}' in template sequence")
+				}
+				b = append(b, s[i:i+n+1]...)
+				i += n
+				continue
+			}
+			b = append(b, ch)
+		case '\\':
+			if i+1 >= len(s) {
+				hp.s = s[i:]
+				return "", hp.errorf("missing closing '\"' in string")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case '"':
+				b = append(b, '"')
+			case '\\':
+				b = append(b, '\\')
+			case 'u', 'U':
+				n := 4
+				if s[i] == 'U' {
+					n = 8
+				}
+				if i+n >= len(s) {
+					hp.s = s[i:]
+					return "", hp.errorf("too short escape sequence")
+				}
+				x, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(x)) {
+					hp.s = s[i:]
+					return "", hp.errorf("invalid escape sequence %q", s[i-1:i+1+n])
+				}
+				b = utf8.AppendRune(b, rune(x))
+				i += n
+			default:
+				hp.s = s[i:]
+				return "", hp.errorf("invalid escape sequence %q", s[i-1:i+1])
+			}
+		default:
+			b = append(b, ch)
+		}
+	}
+	hp.s = ""
+	return "", hp.errorf("missing closing '\"' in string")
+}
+
+// parseHeredoc parses <<MARKER or <<-MARKER heredoc at the start of hp.s.
+func (hp *hclParser) parseHeredoc() (string, error) {
+	s := hp.s[2:]
+	indented := strings.HasPrefix(s, "-")
+	if indented {
+		s = s[1:]
+	}
+	hp.s = s
+	marker := hp.parseIdent()
+	if marker == "" {
+		return "", hp.errorf("missing heredoc marker")
+	}
+	hp.skipBlank(false)
+	if len(hp.s) > 0 && hp.s[0] == '\r' {
+		hp.s = hp.s[1:]
+	}
+	if len(hp.s) == 0 || hp.s[0] != '\n' {
+		return "", hp.errorf("missing newline after heredoc marker %q", marker)
+	}
+	hp.s = hp.s[1:]
+
+	var lines []string
+	for len(hp.s) > 0 {
+		n := strings.IndexByte(hp.s, '\n')
+		line := hp.s
+		if n >= 0 {
+			line = hp.s[:n]
+		}
+		if strings.TrimSpace(line) == marker {
+			hp.s = hp.s[len(line):]
+			return joinHeredocLines(lines, indented), nil
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+		if n < 0 {
+			break
+		}
+		hp.s = hp.s[n+1:]
+	}
+	return "", hp.errorf("missing heredoc end marker %q", marker)
+}
+
+func joinHeredocLines(lines []string, indented bool) string {
+	if indented {
+		// Remove the common leading whitespace.
+		indent := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= indent && indent > 0 {
+				lines[i] = line[indent:]
+			} else if strings.TrimSpace(line) == "" {
+				lines[i] = ""
+			}
+		}
+	}
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHCL(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseHCL(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`# comment
+// comment
+/* multi
+   line */
+name = "app" # trailing comment
+count = 3
+ratio = -1.5e3
+enabled = true
+missing = null
+`, `{"name":"app","count":3,"ratio":-1.5e3,"enabled":true,"missing":null}`)
+
+	// Blocks
+	f(`resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = var.instance_type
+  tags = {
+    Name = "web-${var.env}"
+    "kubernetes.io/role": "node",
+  }
+}
+
+resource "aws_instance" "db" { ami = "ami-456" }
+`, `{"resource":{"aws_instance":{"web":{"ami":"ami-123","instance_type":"var.instance_type","tags":{"Name":"web-${var.env}","kubernetes.io/role":"node"}},"db":{"ami":"ami-456"}}}}`)
+
+	// Repeated blocks
+	f(`ingress {
+  port = 80
+}
+ingress {
+  port = 443
+}
+ingress {
+  port = 8080
+}`, `{"ingress":[{"port":80},{"port":443},{"port":8080}]}`)
+
+	// Expressions are stored as strings
+	f(`a = 1 + 2
+b = length(var.list) > 0 ? "yes" : "no"
+c = [for s in var.list : upper(s)]
+d = "x" == local.y
+e = [1, "two", var.three,]
+f = -var.x
+g = { for k, v in var.m : k => v if v != "" }`, `{"a":"1 + 2","b":"length(var.list) > 0 ? \"yes\" : \"no\"","c":"[for s in var.list : upper(s)]","d":"\"x\" == local.y","e":[1,"two","var.three"],"f":"-var.x","g":"{ for k, v in var.m : k => v if v != \"\" }"}`)
+
+	// Escapes and heredocs
+	f(`s = "a\tb \"q\" é %{if x}y%{endif}"
+h = <<EOT
+line 1
+  line 2
+EOT
+i = <<-EOT
+    indented
+      more
+    EOT
+`, `{"s":"a\tb \"q\" é %{if x}y%{endif}","h":"line 1\n  line 2\n","i":"indented\n  more\n"}`)
+
+	// Errors
+	for _, s := range []string{
+		`a = `,
+		`a = 1 b = 2`,
+		`a = 1
+a = 2`,
+		`a = "unclosed`,
+		`a = "bad \q escape"`,
+		`block {`,
+		`}`,
+		`a = (1 + 2`,
+		`a = { b = 1 b = 2 }`,
+		`h = <<EOT
+no end`,
+		`a = 1
+a {
+}`,
+		`x "y" {
+}
+x = 1`,
+		"a = " + strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1),
+		"a = " + strings.Repeat("{b = ", MaxDepth+1) + "1" + strings.Repeat("}", MaxDepth+1),
+		"a = " + strings.Repeat("[", 1e6),
+	} {
+		if _, err := ParseHCL(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPLoader fetches configs from HTTP(S) URL.
+//
+// Responses are cached and revalidated via ETag and If-None-Match headers,
+// so unchanged configs aren't transferred again.
+//
+// JSON configs are parsed as RFC 8259 JSON, while other configs are parsed
+// as libconfig. See WatchHTTP for watching the URL for changes.
+//
+// HTTPLoader may be used from concurrent goroutines.
+type HTTPLoader struct {
+	// URL is the config URL.
+	URL string
+
+	// Client is the client for fetching the config.
+	//
+	// http.DefaultClient is used by default.
+	Client *http.Client
+
+	// Timeout is the timeout for a single request.
+	//
+	// 30 seconds are used by default.
+	Timeout time.Duration
+
+	// Header contains optional headers for requests, e.g. Authorization.
+	Header http.Header
+
+	// MaxInputSize is the maximum size in bytes of the fetched config.
+	//
+	// It limits both the response body and the decompressed data.
+	// 32MiB is used by default.
+	MaxInputSize int
+
+	mu   sync.Mutex
+	etag string
+	data []byte
+}
+
+// Fetch returns the config data from hl.URL.
+//
+// The cached data is returned if the server responds with 304 Not Modified.
+// Fetch may be used as RemoteSource.Fetch for retries and health reporting,
+// since RemoteSource parses JSON configs the same way as Load does.
+func (hl *HTTPLoader) Fetch(ctx context.Context) ([]byte, error) {
+	data, _, err := hl.fetch(ctx)
+	return data, err
+}
+
+// Load fetches and parses the config from hl.URL.
+//
+// Compressed data is transparently decompressed; see RegisterDecompressor.
+// The returned value doesn't reference any Parser, so it remains valid
+// for arbitrary long time.
+func (hl *HTTPLoader) Load(ctx context.Context) (*Value, error) {
+	data, err := hl.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseRemoteConfig(hl.URL, data, hl.parserConfig())
+}
+
+// parserConfig returns the config for parsing data fetched by hl.
+func (hl *HTTPLoader) parserConfig() *ParserConfig {
+	n := hl.MaxInputSize
+	if n <= 0 {
+		n = defaultMaxInputSize
+	}
+	return &ParserConfig{
+		MaxInputSize: n,
+	}
+}
+
+// fetch returns the config data from hl.URL and whether it has been
+// modified since the previous fetch.
+func (hl *HTTPLoader) fetch(ctx context.Context) ([]byte, bool, error) {
+	timeout := durationOrDefault(hl.Timeout, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hl.URL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot create request for %q: %s", hl.URL, err)
+	}
+	for k, vs := range hl.Header {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+	hl.mu.Lock()
+	etag, cached := hl.etag, hl.data
+	hl.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	client := hl.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot fetch config from %q: %s", hl.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return cached, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status code when fetching config from %q; got %d; want %d", hl.URL, resp.StatusCode, http.StatusOK)
+	}
+	data, err := readAll(resp.Body, hl.parserConfig())
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read config from %q: %s", hl.URL, err)
+	}
+
+	hl.mu.Lock()
+	hl.etag = resp.Header.Get("ETag")
+	hl.data = data
+	hl.mu.Unlock()
+	return data, true, nil
+}
+
+// WatchHTTP loads the config from hl.URL and starts polling it for changes
+// every opts.Interval.
+//
+// The returned watcher delivers new snapshots in the same way as Watch does.
+// Unchanged configs are detected via ETag revalidation and via checksums,
+// so they don't result in new snapshots. Polling is disabled if
+// opts.Interval is zero, so snapshots are updated only by Reload calls.
+// opts.LazyFallback is ignored.
+//
+// ctx is used for all the requests. Polling stops when ctx is canceled
+// or Stop is called. opts may be nil.
+func WatchHTTP(ctx context.Context, hl *HTTPLoader, opts *WatchOptions) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		path:   hl.URL,
+		remote: hl,
+		ch:     make(chan *Value, 1),
+		stopCh: make(chan struct{}),
+	}
+	if opts != nil {
+		w.opts = *opts
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+	if _, err := w.reload(true); err != nil {
+		w.cancel()
+		return nil, err
+	}
+
+	if w.opts.Interval > 0 {
+		w.wg.Add(1)
+		go w.watch()
+	}
+	return w, nil
+}
+
+// reloadRemote fetches the config from w.remote and activates it
+// if it has been changed.
+//
+// true is returned if a new snapshot has been activated.
+func (w *ConfigWatcher) reloadRemote() (bool, error) {
+	stats := ReloadStats{}
+	startTime := time.Now()
+	activated, err := w.loadRemote(&stats)
+	stats.Total = time.Since(startTime)
+	stats.Err = err
+	w.reportReload(&stats)
+	return activated, err
+}
+
+func (w *ConfigWatcher) loadRemote(stats *ReloadStats) (bool, error) {
+	startTime := time.Now()
+	data, modified, err := w.remote.fetch(w.ctx)
+	if err != nil {
+		emitAudit(w.opts.AuditSink, AuditLoadFailed, w.path, "", err)
+		return false, err
+	}
+	checksum := sha256Hex(data)
+	if !modified || checksum == w.checksum {
+		return false, nil
+	}
+	if w.opts.Verifier != nil {
+		if err := w.opts.Verifier.Verify(w.path, data); err != nil {
+			err = fmt.Errorf("cannot verify config from %q: %s", w.path, err)
+			emitAudit(w.opts.AuditSink, AuditLoadFailed, w.path, checksum, err)
+			return false, err
+		}
+	}
+	v, err := parseRemoteConfig(w.path, data, w.remote.parserConfig())
+	stats.Parse = time.Since(startTime)
+	if err != nil {
+		emitAudit(w.opts.AuditSink, AuditLoadFailed, w.path, checksum, err)
+		return false, err
+	}
+	emitAudit(w.opts.AuditSink, AuditLoaded, w.path, checksum, nil)
+
+	if w.opts.Validate != nil {
+		startTime := time.Now()
+		err := w.opts.Validate(v)
+		stats.Validate = time.Since(startTime)
+		if err != nil {
+			err = fmt.Errorf("invalid config from %q: %s", w.path, err)
+			emitAudit(w.opts.AuditSink, AuditValidationFailed, w.path, checksum, err)
+			return false, err
+		}
+	}
+	w.activate(v.Freeze(), checksum, AuditActivated)
+	return true, nil
+}
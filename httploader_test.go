@@ -0,0 +1,171 @@
+package libconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testConfigServer struct {
+	mu       sync.Mutex
+	data     string
+	requests int
+	notMod   int
+}
+
+func (cs *testConfigServer) set(data string) {
+	cs.mu.Lock()
+	cs.data = data
+	cs.mu.Unlock()
+}
+
+func (cs *testConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.requests++
+	etag := fmt.Sprintf(`"%s"`, sha256Hex([]byte(cs.data)))
+	if r.Header.Get("If-None-Match") == etag {
+		cs.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if cs.data == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(cs.data))
+}
+
+func TestHTTPLoader(t *testing.T) {
+	cs := &testConfigServer{}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+	hl := &HTTPLoader{
+		URL: srv.URL,
+	}
+	ctx := context.Background()
+
+	cs.set(`{"port": 8080, "hosts": ["a", "b"]}`)
+	for i := 0; i < 2; i++ {
+		v, err := hl.Load(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s := v.String(); s != `{"port":8080,"hosts":["a","b"]}` {
+			t.Fatalf("unexpected config; got %s", s)
+		}
+	}
+	if cs.notMod != 1 {
+		t.Fatalf("unexpected number of revalidated requests; got %d; want 1", cs.notMod)
+	}
+
+	// libconfig data
+	cs.set(`port = 9090;`)
+	v, err := hl.Load(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("port"); n != 9090 {
+		t.Fatalf("unexpected port; got %d; want 9090", n)
+	}
+
+	// Errors
+	cs.set("")
+	if _, err := hl.Load(ctx); err == nil {
+		t.Fatalf("expecting non-nil error for missing config")
+	}
+	cs.set(`{"port": }`)
+	if _, err := hl.Load(ctx); err == nil {
+		t.Fatalf("expecting non-nil error for invalid config")
+	}
+
+	// Too big config
+	hl.MaxInputSize = 16
+	cs.set(`{"hosts": ["a", "b", "c", "d"]}`)
+	if _, err := hl.Load(ctx); err == nil || !strings.Contains(err.Error(), "MaxInputSize=16") {
+		t.Fatalf("unexpected error for too big config: %v", err)
+	}
+}
+
+func TestHTTPLoaderRemoteSource(t *testing.T) {
+	cs := &testConfigServer{}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+	hl := &HTTPLoader{
+		URL: srv.URL,
+	}
+	rs := &RemoteSource{
+		Name:  srv.URL,
+		Fetch: hl.Fetch,
+	}
+
+	cs.set(`{"port": 8080, "hosts": ["a", "b"]}`)
+	v, err := rs.Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != `{"port":8080,"hosts":["a","b"]}` {
+		t.Fatalf("unexpected config; got %s", s)
+	}
+}
+
+func TestWatchHTTP(t *testing.T) {
+	cs := &testConfigServer{}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	cs.set(`{"port": 8080}`)
+	w, err := WatchHTTP(context.Background(), &HTTPLoader{URL: srv.URL}, &WatchOptions{
+		Interval: 5 * time.Millisecond,
+		Validate: func(v *Value) error {
+			if v.GetInt("port") <= 0 {
+				return fmt.Errorf("port must be positive")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer w.Stop()
+	if n := w.Load().GetInt("port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want 8080", n)
+	}
+	<-w.Changes()
+
+	cs.set(`{"port": 9090}`)
+	select {
+	case v := <-w.Changes():
+		if n := v.GetInt("port"); n != 9090 {
+			t.Fatalf("unexpected port; got %d; want 9090", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for a new snapshot")
+	}
+
+	// Invalid configs aren't activated.
+	cs.set(`{"port": -1}`)
+	if err := w.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error for invalid config")
+	}
+	if n := w.Load().GetInt("port"); n != 9090 {
+		t.Fatalf("unexpected port after invalid reload; got %d; want 9090", n)
+	}
+
+	// Unchanged configs aren't activated.
+	cs.set(`{"port": 9090}`)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	select {
+	case v := <-w.Changes():
+		t.Fatalf("unexpected snapshot for unchanged config: %s", v)
+	default:
+	}
+}
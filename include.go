@@ -0,0 +1,149 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMaxIncludeDepth is the default limit for @include nesting.
+const defaultMaxIncludeDepth = 16
+
+// IncludeRecord describes a single file pulled via @include directive.
+type IncludeRecord struct {
+	// Pattern is the path from @include directive.
+	Pattern string
+
+	// File is the included file.
+	File string
+
+	// Parent is the file containing the @include directive.
+	//
+	// It is empty if the directive is located in the document passed
+	// to Parse or ParseBytes.
+	Parent string
+
+	// Depth is the @include nesting depth. It starts from 1.
+	Depth int
+
+	// parent is the index of the parent record or -1.
+	parent int
+
+	// start and end are the bounds of the included contents in the parsed
+	// input. They are measured from the end of the input, so they remain
+	// valid when new contents are included.
+	start int
+	end   int
+}
+
+// Includes returns files pulled via @include during the last Parse* call.
+//
+// Files are returned in the order they were included.
+func (p *Parser) Includes() []IncludeRecord {
+	return append([]IncludeRecord(nil), p.c.includes...)
+}
+
+// Explain returns human-readable @include trace for the last Parse* call.
+//
+// Every included file is printed on a separate line below the file
+// containing the @include directive.
+func (p *Parser) Explain() string {
+	var sb strings.Builder
+	sb.WriteString(rootFileName(p.c.rootFile))
+	sb.WriteString("\n")
+	for _, r := range p.c.includes {
+		fmt.Fprintf(&sb, "%s%s (@include %q)\n", strings.Repeat("  ", r.Depth), r.File, r.Pattern)
+	}
+	return sb.String()
+}
+
+func rootFileName(rootFile string) string {
+	if rootFile == "" {
+		return "<input>"
+	}
+	return rootFile
+}
+
+// includeAt returns the index of the innermost include record containing
+// the position identified by the tail length n.
+//
+// -1 is returned if the position belongs to the root document.
+func (c *cache) includeAt(n int) int {
+	for i := len(c.includes) - 1; i >= 0; i-- {
+		r := &c.includes[i]
+		if n > r.end && n <= r.start {
+			return i
+		}
+	}
+	return -1
+}
+
+// includeChain returns human-readable include chain ending at the record i.
+func (c *cache) includeChain(i int) string {
+	var files []string
+	for i >= 0 {
+		r := &c.includes[i]
+		files = append(files, r.File)
+		i = r.parent
+	}
+	files = append(files, rootFileName(c.rootFile))
+	for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+		files[i], files[j] = files[j], files[i]
+	}
+	return strings.Join(files, " -> ")
+}
+
+// isIncluding returns true if file is located in the include chain ending
+// at the record i.
+func (c *cache) isIncluding(i int, file string) bool {
+	file = filepath.Clean(file)
+	for i >= 0 {
+		r := &c.includes[i]
+		if filepath.Clean(r.File) == file {
+			return true
+		}
+		i = r.parent
+	}
+	return c.rootFile != "" && filepath.Clean(c.rootFile) == file
+}
+
+// includeError adds include trace to err if the tail belongs to included file.
+func (c *cache) includeError(err error, tail string) error {
+	i := c.includeAt(len(tail))
+	if i < 0 {
+		return err
+	}
+	return fmt.Errorf("%s; include trace: %s", err, c.includeChain(i))
+}
+
+func (cfg *ParserConfig) maxIncludeDepth() int {
+	if cfg == nil || cfg.MaxIncludeDepth <= 0 {
+		return defaultMaxIncludeDepth
+	}
+	return cfg.MaxIncludeDepth
+}
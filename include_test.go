@@ -0,0 +1,116 @@
+package libconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIncludeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("cannot write %q: %s", name, err)
+		}
+	}
+	return dir
+}
+
+func TestParserIncludes(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"root.cfg": `a = 1;
+@include "a.cfg"
+`,
+		"a.cfg": `b = 2;
+@include "b.cfg"
+c = 3;`,
+		"b.cfg": `d = 4;`,
+	})
+
+	var p Parser
+	root := dir + "/root.cfg"
+	v, err := p.ParseFile(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != `{"a":1,"b":2,"d":4,"c":3}` {
+		t.Fatalf("unexpected value; got %s; want %s", s, `{"a":1,"b":2,"d":4,"c":3}`)
+	}
+
+	includes := p.Includes()
+	if len(includes) != 2 {
+		t.Fatalf("unexpected number of includes; got %d; want %d", len(includes), 2)
+	}
+	if r := includes[1]; r.Pattern != "b.cfg" || r.File != dir+"/b.cfg" || r.Parent != dir+"/a.cfg" || r.Depth != 2 {
+		t.Fatalf("unexpected include record: %+v", r)
+	}
+
+	explanation := p.Explain()
+	explanationExpected := root + "\n" +
+		"  " + dir + "/a.cfg (@include \"a.cfg\")\n" +
+		"    " + dir + "/b.cfg (@include \"b.cfg\")\n"
+	if explanation != explanationExpected {
+		t.Fatalf("unexpected explanation\ngot\n%s\nwant\n%s", explanation, explanationExpected)
+	}
+}
+
+func TestParserIncludeErrors(t *testing.T) {
+	f := func(files map[string]string, errExpected string) {
+		t.Helper()
+		dir := writeIncludeFiles(t, files)
+		var p Parser
+		_, err := p.ParseFile(dir + "/root.cfg")
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+		errExpected = strings.ReplaceAll(errExpected, "DIR", dir)
+		if !strings.Contains(err.Error(), errExpected) {
+			t.Fatalf("unexpected error\ngot\n%s\nwant it to contain\n%s", err, errExpected)
+		}
+	}
+
+	// Syntax error in nested include
+	f(map[string]string{
+		"root.cfg": `@include "a.cfg"`,
+		"a.cfg":    `x = 1; @include "b.cfg"`,
+		"b.cfg":    `y = [1 2];`,
+	}, "include trace: DIR/root.cfg -> DIR/a.cfg -> DIR/b.cfg")
+
+	// Include cycle
+	f(map[string]string{
+		"root.cfg": `@include "a.cfg"`,
+		"a.cfg":    `@include "b.cfg"`,
+		"b.cfg":    `@include "a.cfg"`,
+	}, `@include cycle detected for "a.cfg"; include trace: DIR/root.cfg -> DIR/a.cfg -> DIR/b.cfg -> DIR/a.cfg`)
+
+	// Self include of the root file
+	f(map[string]string{
+		"root.cfg": `@include "root.cfg"`,
+	}, `@include cycle detected for "root.cfg"`)
+}
+
+func TestParserMaxIncludeDepth(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"root.cfg": `@include "a.cfg"`,
+		"a.cfg":    `@include "b.cfg"`,
+		"b.cfg":    `x = 1;`,
+	})
+	p := Parser{
+		Config: ParserConfig{
+			MaxIncludeDepth: 1,
+		},
+	}
+	_, err := p.ParseFile(dir + "/root.cfg")
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if !strings.Contains(err.Error(), "too deep @include nesting") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	p.Config.MaxIncludeDepth = 2
+	if _, err := p.ParseFile(dir + "/root.cfg"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,398 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseINI parses s containing INI file.
+//
+// Sections are mapped to objects. Dotted section names such as [server.tls]
+// are mapped to nested objects. Keys ending with [] are collected into arrays.
+// Lines starting with ';' or '#' are comments. Unquoted values may contain
+// inline comments starting with " ;" or " #".
+//
+// Numbers and bools (true and false) are inferred from unquoted values,
+// while quoted values are always strings. Later values override earlier
+// values for duplicate keys.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseINI(s string) (*Value, error) {
+	fp, err := p.initFlatParser(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse INI: %s", err)
+	}
+	v, err := fp.parseINI()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse INI: %s", err)
+	}
+	return v, nil
+}
+
+// ParseINIBytes parses b containing INI file.
+//
+// See Parser.ParseINI for details.
+func (p *Parser) ParseINIBytes(b []byte) (*Value, error) {
+	return p.ParseINI(b2s(b))
+}
+
+// ParseProperties parses s containing Java-style .properties file.
+//
+// Dotted keys such as server.port are mapped to nested objects.
+// Key and value may be delimited by '=', ':' or whitespace. Lines starting
+// with '#' or '!' are comments. Escape sequences and line continuations
+// ending with '\' are supported.
+//
+// Numbers and bools (true and false) are inferred from values.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseProperties(s string) (*Value, error) {
+	fp, err := p.initFlatParser(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse properties: %s", err)
+	}
+	v, err := fp.parseProperties()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse properties: %s", err)
+	}
+	return v, nil
+}
+
+// ParsePropertiesBytes parses b containing Java-style .properties file.
+//
+// See Parser.ParseProperties for details.
+func (p *Parser) ParsePropertiesBytes(b []byte) (*Value, error) {
+	return p.ParseProperties(b2s(b))
+}
+
+// ParseINI parses s containing INI file.
+//
+// The function is slower than the Parser.ParseINI for re-used Parser.
+func ParseINI(s string) (*Value, error) {
+	var p Parser
+	return p.ParseINI(s)
+}
+
+// ParseProperties parses s containing Java-style .properties file.
+//
+// The function is slower than the Parser.ParseProperties for re-used Parser.
+func ParseProperties(s string) (*Value, error) {
+	var p Parser
+	return p.ParseProperties(s)
+}
+
+type flatParser struct {
+	s string
+	c *cache
+}
+
+func (p *Parser) initFlatParser(s string) (*flatParser, error) {
+	s, err := decodeInput(s, p.Config.TranscodeInput)
+	if err != nil {
+		return nil, err
+	}
+	p.b = append(p.b[:0], s...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+	return &flatParser{
+		s: b2s(p.b),
+		c: &p.c,
+	}, nil
+}
+
+func (fp *flatParser) newObject() *Value {
+	v := fp.c.getValue()
+	v.t = TypeObject
+	v.o.reset()
+	v.o.keysUnescaped = true
+	return v
+}
+
+// inferValue returns Value for unquoted s with inferred type.
+func (fp *flatParser) inferValue(s string) *Value {
+	switch strings.ToLower(s) {
+	case "true":
+		return valueTrue
+	case "false":
+		return valueFalse
+	}
+	v := fp.c.getValue()
+	if isFlatNumber(s) {
+		v.t = TypeNumber
+		v.s = s
+		return v
+	}
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+func isFlatNumber(s string) bool {
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		_, err := strconv.ParseUint(s[2:], 16, 64)
+		return err == nil
+	}
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || !isDigit(digits[0]) || !isDigit(digits[len(digits)-1]) {
+		// Reject inf, nan, .5 and 1. forms.
+		return false
+	}
+	if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		// Leading zeros usually mean identifiers such as zip codes.
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// getObject returns the object for the given keys path starting at o.
+//
+// Missing objects are created.
+func (fp *flatParser) getObject(o *Value, keys []string) (*Value, error) {
+	for i, key := range keys {
+		v := o.o.Get(key)
+		if v == nil {
+			v = fp.newObject()
+			appendObjectKV(&o.o, key, v)
+		} else if v.t != TypeObject {
+			return nil, fmt.Errorf("cannot use %q as object, since it contains %s", strings.Join(keys[:i+1], "."), v.t)
+		}
+		o = v
+	}
+	return o, nil
+}
+
+// setValue sets key to v in o. The previous value is overridden.
+func setValue(o *Value, key string, v *Value) {
+	for i := range o.o.kvs {
+		if o.o.kvs[i].k == key {
+			o.o.kvs[i].v = v
+			return
+		}
+	}
+	appendObjectKV(&o.o, key, v)
+}
+
+func (fp *flatParser) parseINI() (*Value, error) {
+	root := fp.newObject()
+	section := root
+	for n, line := range strings.Split(fp.s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: missing ']' at the end of section name", n+1)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", n+1)
+			}
+			var err error
+			section, err = fp.getObject(root, strings.Split(name, "."))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+			continue
+		}
+
+		n1 := strings.IndexAny(line, "=:")
+		if n1 <= 0 {
+			return nil, fmt.Errorf("line %d: missing '=' after key", n+1)
+		}
+		key := strings.TrimSpace(line[:n1])
+		v, err := fp.parseINIValue(strings.TrimSpace(line[n1+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n+1, err)
+		}
+		if strings.HasSuffix(key, "[]") {
+			key = strings.TrimSpace(key[:len(key)-2])
+			a := section.o.Get(key)
+			if a == nil {
+				a = fp.c.getValue()
+				a.t = TypeArray
+				a.a = a.a[:0]
+				appendObjectKV(&section.o, key, a)
+			} else if a.t != TypeArray {
+				return nil, fmt.Errorf("line %d: cannot append to %q, since it contains %s", n+1, key, a.t)
+			}
+			a.a = append(a.a, v)
+			continue
+		}
+		setValue(section, key, v)
+	}
+	return root, nil
+}
+
+func (fp *flatParser) parseINIValue(s string) (*Value, error) {
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		n := strings.LastIndexByte(s, s[0])
+		if n == 0 {
+			return nil, fmt.Errorf("missing closing %c", s[0])
+		}
+		if tail := strings.TrimSpace(s[n+1:]); tail != "" && tail[0] != ';' && tail[0] != '#' {
+			return nil, fmt.Errorf("unexpected data after quoted value: %q", tail)
+		}
+		str := s[1:n]
+		if s[0] == '"' {
+			str = unescapeStringBestEffort(strings.Clone(str))
+		}
+		v := fp.c.getValue()
+		v.t = TypeString
+		v.s = str
+		return v, nil
+	}
+	for _, comment := range []string{" ;", " #", "\t;", "\t#"} {
+		if n := strings.Index(s, comment); n >= 0 {
+			s = strings.TrimSpace(s[:n])
+		}
+	}
+	return fp.inferValue(s), nil
+}
+
+func (fp *flatParser) parseProperties() (*Value, error) {
+	root := fp.newObject()
+	lines := strings.Split(fp.s, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// Join continuation lines.
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+		}
+		if endsWithContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNum)
+		}
+		keys := strings.Split(key, ".")
+		o, err := fp.getObject(root, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		last := keys[len(keys)-1]
+		if prev := o.o.Get(last); prev != nil && prev.t == TypeObject {
+			return nil, fmt.Errorf("line %d: cannot set %q, since it contains object", lineNum, key)
+		}
+		setValue(o, last, fp.inferValue(value))
+	}
+	return root, nil
+}
+
+func endsWithContinuation(s string) bool {
+	n := 0
+	for n < len(s) && s[len(s)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits .properties line into unescaped key and value.
+func splitProperty(line string) (string, string, error) {
+	i := 0
+	for i < len(line) {
+		ch := line[i]
+		if ch == '\\' {
+			i += 2
+			continue
+		}
+		if ch == '=' || ch == ':' || ch == ' ' || ch == '\t' || ch == '\f' {
+			break
+		}
+		i++
+	}
+	if i > len(line) {
+		i = len(line)
+	}
+	key := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t\f")
+	if len(rest) > 0 && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	key, err := unescapeProperty(key)
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch != '\\' || i+1 >= len(s) {
+			b = append(b, ch)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b = append(b, '\t')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 'f':
+			b = append(b, '\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("too short escape sequence %q", s[i-1:])
+			}
+			x, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence %q", s[i-1:i+5])
+			}
+			b = utf8.AppendRune(b, rune(x))
+			i += 4
+		default:
+			// Other escaped chars stand for themselves.
+			b = append(b, s[i])
+		}
+	}
+	return string(b), nil
+}
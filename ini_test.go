@@ -0,0 +1,94 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseINI(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`; comment
+# comment
+name = app
+debug = true
+
+[server]
+host = localhost ; inline comment
+port = 8080
+ratio: 0.5
+zip = 01234
+quoted = "8080"
+escaped = "a\tb"
+single = 'x ; y'
+empty =
+
+[server.tls]
+enabled = FALSE
+hex = 0x1F
+
+[hosts]
+list[] = a
+list[] = b
+`, `{"name":"app","debug":true,"server":{"host":"localhost","port":8080,"ratio":0.5,"zip":"01234","quoted":"8080","escaped":"a\tb","single":"x ; y","empty":"","tls":{"enabled":false,"hex":0x1F}},"hosts":{"list":["a","b"]}}`)
+	f(`a = 1
+a = 2`, `{"a":2}`)
+
+	// Errors
+	for _, s := range []string{"[section", "[]", "novalue", "a = \"unclosed", "a = 1\n[a]", "a = 1\na[] = 2"} {
+		if _, err := ParseINI(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseProperties(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`# comment
+! comment
+server.host = localhost
+server.port: 8080
+server.debug true
+app.name=My App
+app.path = C:\\dir\\file
+app.unicode = \u00e9t\u00e9
+key\ with\ spaces = value
+multi = first, \
+        second
+tab\=key = x`, `{"server":{"host":"localhost","port":8080,"debug":true},"app":{"name":"My App","path":"C:\\dir\\file","unicode":"été"},"key with spaces":"value","multi":"first, second","tab=key":"x"}`)
+
+	v, err := ParseProperties("db.port = 5432")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("db", "port"); n != 5432 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 5432)
+	}
+
+	// Errors
+	for _, s := range []string{"a = 1\na.b = 2", "a.b = 1\na = 2", `a = \u12`, "= value"} {
+		if _, err := ParseProperties(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+}
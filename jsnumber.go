@@ -0,0 +1,144 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	// MaxSafeInteger is the maximum integer, which may be represented
+	// in JavaScript without precision loss, i.e. Number.MAX_SAFE_INTEGER.
+	MaxSafeInteger = 1<<53 - 1
+
+	// MinSafeInteger is the minimum integer, which may be represented
+	// in JavaScript without precision loss, i.e. Number.MIN_SAFE_INTEGER.
+	MinSafeInteger = -MaxSafeInteger
+)
+
+// JSNumber returns the underlying number for v as JavaScript number.
+//
+// An error is returned if v contains an integer outside
+// [MinSafeInteger ... MaxSafeInteger] range, since it would silently
+// lose precision in JavaScript. Hex and big int (L suffix) numbers are
+// supported. Inf and NaN result in error, since they cannot be represented
+// in JSON.
+//
+// Use GetJSNumber if you don't need error handling.
+func (v *Value) JSNumber() (float64, error) {
+	if v.Type() != TypeNumber {
+		return 0, fmt.Errorf("value doesn't contain number; it contains %s", v.Type())
+	}
+	if n, ok := parseBigIntToken(v.s); ok {
+		if !isSafeInteger(n) {
+			return 0, fmt.Errorf("integer %s is outside JavaScript safe integer range [%d ... %d]", n, int64(MinSafeInteger), int64(MaxSafeInteger))
+		}
+		return float64(n.Int64()), nil
+	}
+	f, err := parseFloatToken(v.s)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("number %q cannot be represented in JSON", v.s)
+	}
+	return f, nil
+}
+
+// GetJSNumber returns JavaScript number by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// 0 is returned for non-existing keys path, for invalid value type
+// or for integers outside [MinSafeInteger ... MaxSafeInteger] range.
+// Use Value.JSNumber for proper error handling.
+func (v *Value) GetJSNumber(keys ...string) float64 {
+	v = v.Get(keys...)
+	if v == nil {
+		return 0
+	}
+	f, err := v.JSNumber()
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// NewJSNumber returns a copy of number v, which is safe for JavaScript
+// consumers.
+//
+// Integers outside [MinSafeInteger ... MaxSafeInteger] range are returned
+// as strings with decimal representation, so browsers don't silently corrupt
+// them. Hex and big int numbers are converted to decimal. An error is returned
+// if v isn't a number or if it cannot be represented in JSON.
+//
+// The returned value is valid until Reset is called on a.
+func (a *Arena) NewJSNumber(v *Value) (*Value, error) {
+	if v.Type() != TypeNumber {
+		return nil, fmt.Errorf("value doesn't contain number; it contains %s", v.Type())
+	}
+	if n, ok := parseBigIntToken(v.s); ok {
+		if !isSafeInteger(n) {
+			return a.NewString(n.String()), nil
+		}
+		return a.NewNumberString(n.String()), nil
+	}
+	f, err := v.JSNumber()
+	if err != nil {
+		return nil, err
+	}
+	return a.NewNumberFloat64(f), nil
+}
+
+// parseBigIntToken parses integer number token into big.Int.
+//
+// Unlike parseIntToken, it supports integers of arbitrary size
+// and signed hex numbers.
+func parseBigIntToken(s string) (*big.Int, bool) {
+	s = trimBigintSuffix(s)
+	sign := ""
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		sign = s[:1]
+		s = s[1:]
+	}
+	base := 10
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		base = 16
+		s = s[2:]
+	} else if strings.ContainsAny(s, ".eE") {
+		return nil, false
+	}
+	return new(big.Int).SetString(sign+s, base)
+}
+
+var (
+	bigMaxSafeInteger = big.NewInt(MaxSafeInteger)
+	bigMinSafeInteger = big.NewInt(MinSafeInteger)
+)
+
+func isSafeInteger(n *big.Int) bool {
+	return n.Cmp(bigMinSafeInteger) >= 0 && n.Cmp(bigMaxSafeInteger) <= 0
+}
@@ -0,0 +1,83 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueJSNumber(t *testing.T) {
+	f := func(s string, resultExpected float64) {
+		t.Helper()
+		v := MustParse("x = " + s + ";").Get("x")
+		result, err := v.JSNumber()
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %v; want %v", s, result, resultExpected)
+		}
+	}
+	f("0", 0)
+	f("-12", -12)
+	f("1.5e3", 1500)
+	f("0x1F", 31)
+	f("100L", 100)
+	f(".5", 0.5)
+	f("5.", 5)
+	f("9007199254740991", MaxSafeInteger)
+	f("-9007199254740991", MinSafeInteger)
+
+	fErr := func(s string) {
+		t.Helper()
+		v := MustParse("x = " + s + ";")
+		if _, err := v.Get("x").JSNumber(); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+		if n := v.GetJSNumber("x"); n != 0 {
+			t.Fatalf("unexpected GetJSNumber result for %s; got %v; want 0", s, n)
+		}
+	}
+	fErr("9007199254740992")
+	fErr("-9007199254740992")
+	fErr("9223372036854775807L")
+	fErr("0x20000000000000")
+	fErr("inf")
+	fErr("1e400")
+	fErr(`"123"`)
+
+	v := MustParse(`a = {b = 42;};`)
+	if n := v.GetJSNumber("a", "b"); n != 42 {
+		t.Fatalf("unexpected GetJSNumber result; got %v; want 42", n)
+	}
+	if n := v.GetJSNumber("missing"); n != 0 {
+		t.Fatalf("unexpected GetJSNumber result for missing key; got %v; want 0", n)
+	}
+}
+
+func TestArenaNewJSNumber(t *testing.T) {
+	var a Arena
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse("x = " + s + ";").Get("x")
+		jv, err := a.NewJSNumber(v)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result := jv.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", s, result, resultExpected)
+		}
+	}
+	f("123", "123")
+	f("0x1F", "31")
+	f("1.25", "1.25")
+	f("9007199254740991", "9007199254740991")
+	f("9007199254740992", `"9007199254740992"`)
+	f("9223372036854775807L", `"9223372036854775807"`)
+	f("0xFFFFFFFFFFFFFFFF", `"18446744073709551615"`)
+
+	for _, s := range []string{`"str"`, "nan", "true"} {
+		v := MustParse("x = " + s + ";").Get("x")
+		if _, err := a.NewJSNumber(v); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MarshalJSON implements json.Marshaler.
+//
+// Hex and big int numbers are converted to plain decimal numbers.
+// An error is returned if v contains NaN or Inf numbers, since they
+// cannot be represented in JSON.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	b := marshalJSON(nil, v)
+	if !json.Valid(b) {
+		return nil, fmt.Errorf("cannot marshal %s to JSON: it contains NaN, Inf or invalid raw JSON", startEndString(b2s(b)))
+	}
+	return b, nil
+}
+
+// RawMessage returns JSON representation of v.
+//
+// Hex and big int numbers are converted to plain decimal numbers.
+// The result isn't valid JSON if v contains NaN or Inf numbers.
+// Use MarshalJSON if the result must be validated.
+func (v *Value) RawMessage() json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return marshalJSON(nil, v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// v is replaced with the value parsed from b. Object key order and number
+// literals are preserved. The resulting value doesn't reference b, so it
+// remains valid after b is modified.
+//
+// Parsed true, false and null values are shared between all the parsed
+// documents, so they cannot be replaced.
+func (v *Value) UnmarshalJSON(b []byte) error {
+	if v.o.flags&flagFrozen != 0 {
+		return fmt.Errorf("cannot unmarshal JSON into frozen value")
+	}
+	if v == valueTrue || v == valueFalse || v == valueNull {
+		return fmt.Errorf("cannot unmarshal JSON into shared %s value; use a new Value instead", v.t)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	x, err := decodeJSONValue(d, 0)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal JSON: %s", err)
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("cannot unmarshal JSON: unexpected trailing data at offset %d", d.InputOffset())
+	}
+	*v = *x
+	return nil
+}
+
+func decodeJSONValue(d *json.Decoder, depth int) (*Value, error) {
+	depth++
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("too big depth for the nested JSON; it exceeds %d", MaxDepth)
+	}
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			o := &Value{t: TypeObject}
+			o.o.keysUnescaped = true
+			for d.More() {
+				tok, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				item, err := decodeJSONValue(d, depth)
+				if err != nil {
+					return nil, err
+				}
+				appendObjectKV(&o.o, tok.(string), item)
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return o, nil
+		}
+		a := &Value{t: TypeArray}
+		for d.More() {
+			item, err := decodeJSONValue(d, depth)
+			if err != nil {
+				return nil, err
+			}
+			a.a = append(a.a, item)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return a, nil
+	case string:
+		return &Value{t: TypeString, s: t}, nil
+	case json.Number:
+		return &Value{t: TypeNumber, s: string(t)}, nil
+	case bool:
+		if t {
+			return valueTrue, nil
+		}
+		return valueFalse, nil
+	default:
+		return valueNull, nil
+	}
+}
@@ -0,0 +1,109 @@
+package libconfig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValueMarshalJSON(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result := string(b); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", s, result, resultExpected)
+		}
+		if result := string(v.RawMessage()); result != resultExpected {
+			t.Fatalf("unexpected RawMessage for %s; got %s; want %s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`a = 1; b = [0x10, 1.5e3]; l = 5L; c = {d = "x\ty"; e = null;};`, `{"a":1,"b":[16,1.5e3],"l":5,"c":{"d":"x\ty","e":null}}`)
+
+	// NaN and Inf cannot be represented in JSON.
+	v := MustParse(`a = nan;`)
+	if _, err := json.Marshal(v); err == nil {
+		t.Fatalf("expecting non-nil error for NaN")
+	}
+
+	// nil value
+	var nv *Value
+	if result := string(nv.RawMessage()); result != "null" {
+		t.Fatalf("unexpected RawMessage for nil value; got %s; want null", result)
+	}
+}
+
+func TestValueUnmarshalJSON(t *testing.T) {
+	type config struct {
+		Name  string
+		Extra *Value
+		Raw   json.RawMessage
+	}
+
+	data := []byte(`{"Name":"x","Extra":{"b":[1,2.50,"y",true,false,null],"a":{}},"Raw":{"z":1}}`)
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Verify the value doesn't reference data.
+	for i := range data {
+		data[i] = ' '
+	}
+	resultExpected := `{"b":[1,2.50,"y",true,false,null],"a":{}}`
+	if result := c.Extra.String(); result != resultExpected {
+		t.Fatalf("unexpected value; got %s; want %s", result, resultExpected)
+	}
+	if n := c.Extra.GetInt("b", "0"); n != 1 {
+		t.Fatalf("unexpected b.0; got %d; want 1", n)
+	}
+
+	// Round trip
+	b, err := json.Marshal(&c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	bExpected := `{"Name":"x","Extra":{"b":[1,2.50,"y",true,false,null],"a":{}},"Raw":{"z":1}}`
+	if string(b) != bExpected {
+		t.Fatalf("unexpected round trip result; got %s; want %s", b, bExpected)
+	}
+
+	// Shared true, false and null values mustn't be modified.
+	for _, x := range []string{"null", "true", "false"} {
+		parsed := MustParse("x = " + x + ";")
+		dst := struct {
+			X *Value
+		}{
+			X: parsed.Get("x"),
+		}
+		if err := json.Unmarshal([]byte(`{"X":{"evil":1}}`), &dst); err == nil {
+			t.Fatalf("expecting non-nil error when unmarshaling into %s", x)
+		}
+		if s := MustParse("y = " + x + ";").String(); s != `{"y":`+x+`}` {
+			t.Fatalf("unexpected value after unmarshaling into %s; got %s", x, s)
+		}
+	}
+
+	// Escaped keys and strings
+	var v Value
+	if err := v.UnmarshalJSON([]byte(`{"a\"b":"cd"}`)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes(`a"b`)); s != "cd" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "cd")
+	}
+
+	// Errors
+	for _, s := range []string{``, `{`, `[1,]`, `{"a":1}x`, `1 2`, `nan`} {
+		if err := v.UnmarshalJSON([]byte(s)); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	frozen := MustParse(`a = 1;`).Freeze()
+	if err := frozen.UnmarshalJSON([]byte(`1`)); err == nil {
+		t.Fatalf("expecting non-nil error for frozen value")
+	}
+}
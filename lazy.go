@@ -0,0 +1,325 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LazyParser parses huge documents on demand.
+//
+// Parse only indexes top-level keys, while nested objects are indexed
+// and values are parsed on the first access via Get. This dramatically
+// reduces parse cost when only a few paths of a multi-MB document are read.
+//
+// Syntax errors inside values are reported on the first access to them.
+// @include directives aren't supported.
+//
+// LazyParser may be re-used for subsequent parsing.
+// LazyParser cannot be used from concurrent goroutines.
+type LazyParser struct {
+	// Config contains optional parser settings.
+	Config ParserConfig
+
+	b    []byte
+	c    cache
+	root lazyObject
+	v    *Value
+}
+
+type lazyObject struct {
+	entries []lazyEntry
+	depth   int
+}
+
+type lazyEntry struct {
+	key string
+
+	// raw is the input tail starting from the value.
+	raw string
+
+	// end is the input tail after the value.
+	end string
+
+	// v is the parsed value.
+	v *Value
+
+	// o is the index for the object value if it has been indexed.
+	o *lazyObject
+}
+
+// Parse indexes top-level keys in s.
+//
+// Values obtained via lp are valid until the next call to Parse.
+func (lp *LazyParser) Parse(s string) error {
+	if lp.Config.StrictRFC8259 {
+		return fmt.Errorf("cannot parse libconfig: StrictRFC8259 isn't supported by LazyParser")
+	}
+	if err := lp.Config.checkInputSize(len(s)); err != nil {
+		return fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	s, err := decodeInput(s, lp.Config.TranscodeInput)
+	if err != nil {
+		return fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	lp.b = append(lp.b[:0], s...)
+	lp.c.reset()
+	lp.c.cfg = &lp.Config
+	lp.c.src = b2s(lp.b)
+	lp.c.unwrapped = true
+	lp.v = nil
+
+	lp.root = lazyObject{}
+	tail, err := lp.root.index(&lp.c, b2s(lp.b), true)
+	if err != nil {
+		return fmt.Errorf("cannot parse libconfig: %s; unparsed tail: %q", err, startEndString(tail))
+	}
+	return nil
+}
+
+// ParseBytes indexes top-level keys in b.
+//
+// Values obtained via lp are valid until the next call to Parse.
+func (lp *LazyParser) ParseBytes(b []byte) error {
+	return lp.Parse(b2s(b))
+}
+
+// Keys returns top-level keys in the order they are located in the document.
+func (lp *LazyParser) Keys() []string {
+	keys := make([]string, len(lp.root.entries))
+	for i := range lp.root.entries {
+		keys[i] = lp.root.entries[i].key
+	}
+	return keys
+}
+
+// Get returns the value by the given keys path.
+//
+// Only objects on the path are indexed and only the value at the path
+// is parsed. Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path. An error is returned
+// if the document contains syntax error at the path.
+func (lp *LazyParser) Get(keys ...string) (*Value, error) {
+	if len(keys) == 0 {
+		return lp.Value()
+	}
+	v, err := lp.root.get(&lp.c, keys)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig at %q: %s", Path(keys), err)
+	}
+	return v, nil
+}
+
+// Value parses the whole document and returns it.
+func (lp *LazyParser) Value() (*Value, error) {
+	if lp.v != nil {
+		return lp.v, nil
+	}
+	v, err := lp.root.materialize(&lp.c)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	lp.v = v
+	return v, nil
+}
+
+// index indexes object entries in s.
+//
+// s must start after the opening '{' unless root is set.
+// The tail after the object is returned.
+func (lo *lazyObject) index(c *cache, s string, root bool) (string, error) {
+	for {
+		s = skipJunk(s)
+		if len(s) == 0 {
+			if root {
+				return s, nil
+			}
+			return s, fmt.Errorf("missing '}'")
+		}
+		if !root && s[0] == '}' {
+			return s[1:], nil
+		}
+		if strings.HasPrefix(s, "@include") {
+			return s, fmt.Errorf("@include isn't supported by LazyParser")
+		}
+
+		keyStart := s
+		key, tail, err := parseRawKey(s)
+		if err != nil {
+			return s, fmt.Errorf("cannot parse object key: %s", err)
+		}
+		if err := c.cfg.checkStringLen(key); err != nil {
+			return keyStart, err
+		}
+		if key, err = c.cfg.normalizeString(key); err != nil {
+			return keyStart, fmt.Errorf("cannot parse object key: %s", err)
+		}
+		if c.cfg.RejectDuplicateKeys && lo.find(key) != nil {
+			return keyStart, fmt.Errorf("duplicate key %q at %s", key, c.position(keyStart))
+		}
+
+		raw := skipJunk(tail[1:])
+		end, err := skipLazyValue(raw)
+		if err != nil {
+			return raw, fmt.Errorf("cannot parse object value: %s", err)
+		}
+		lo.entries = append(lo.entries, lazyEntry{
+			key: key,
+			raw: raw,
+			end: end,
+		})
+
+		s = skipJunk(end)
+		if len(s) > 0 && s[0] == ';' {
+			s = s[1:]
+			continue
+		}
+		if len(s) == 0 && root || len(s) > 0 && s[0] == '}' {
+			continue
+		}
+		return s, fmt.Errorf("missing ';' after object value")
+	}
+}
+
+// find returns the first entry with the given key.
+func (lo *lazyObject) find(key string) *lazyEntry {
+	for i := range lo.entries {
+		if lo.entries[i].key == key {
+			return &lo.entries[i]
+		}
+	}
+	return nil
+}
+
+func (lo *lazyObject) get(c *cache, keys []string) (*Value, error) {
+	e := lo.find(keys[0])
+	if e == nil {
+		return nil, nil
+	}
+	if len(keys) > 1 && e.v == nil && len(e.raw) > 0 && e.raw[0] == '{' {
+		// Index the nested object instead of parsing it.
+		if e.o == nil {
+			o := &lazyObject{
+				depth: lo.depth + 1,
+			}
+			if _, err := o.index(c, e.raw[1:], false); err != nil {
+				return nil, err
+			}
+			e.o = o
+		}
+		return e.o.get(c, keys[1:])
+	}
+	v, err := lo.parseEntry(c, e)
+	if err != nil {
+		return nil, err
+	}
+	return v.Get(keys[1:]...), nil
+}
+
+func (lo *lazyObject) parseEntry(c *cache, e *lazyEntry) (*Value, error) {
+	if e.v != nil {
+		return e.v, nil
+	}
+	if e.o != nil {
+		v, err := e.o.materialize(c)
+		if err != nil {
+			return nil, err
+		}
+		e.v = v
+		return v, nil
+	}
+	v, tail, err := parseValue(e.raw, c, "", lo.depth)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse value for key %q at %s: %s", e.key, c.position(tail), err)
+	}
+	if len(skipJunk(tail)) != len(skipJunk(e.end)) {
+		return nil, fmt.Errorf("unexpected tail after value for key %q at %s", e.key, c.position(tail))
+	}
+	e.v = v
+	return v, nil
+}
+
+// materialize parses all the entries in lo and returns the resulting object.
+func (lo *lazyObject) materialize(c *cache) (*Value, error) {
+	o := c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	for i := range lo.entries {
+		e := &lo.entries[i]
+		v, err := lo.parseEntry(c, e)
+		if err != nil {
+			return nil, err
+		}
+		appendObjectKV(&o.o, e.key, v)
+	}
+	return o, nil
+}
+
+// skipLazyValue skips the value at the start of s without parsing it
+// and returns the tail after the value.
+func skipLazyValue(s string) (string, error) {
+	depth := 0
+	for len(s) > 0 {
+		switch s[0] {
+		case '"':
+			_, tail, err := parseRawString(s[1:])
+			if err != nil {
+				return s, err
+			}
+			s = tail
+		case '{', '[', '(':
+			depth++
+			s = s[1:]
+		case '}', ']', ')':
+			if depth == 0 {
+				return s, nil
+			}
+			depth--
+			s = s[1:]
+		case ';', ',':
+			if depth == 0 {
+				return s, nil
+			}
+			s = s[1:]
+		case '#':
+			s = skipComment(s)
+		case '/':
+			if len(s) > 1 && (s[1] == '/' || s[1] == '*') {
+				s = skipComment(s)
+			} else {
+				s = s[1:]
+			}
+		default:
+			s = s[1:]
+		}
+	}
+	if depth > 0 {
+		return s, fmt.Errorf("unexpected end of value")
+	}
+	return s, nil
+}
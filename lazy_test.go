@@ -0,0 +1,112 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLazyParser(t *testing.T) {
+	s := `
+# comment
+a = 1;
+b = {c = "x;}]"; d = [1, {e = 2;}, "f"]; g = {h = true;};};
+i = [1, 2 /* ] */, 3];
+j : "k" // comment with }
+;
+l = {};
+`
+	var lp LazyParser
+	if err := lp.Parse(s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if keys := strings.Join(lp.Keys(), ","); keys != "a,b,i,j,l" {
+		t.Fatalf("unexpected keys; got %q; want %q", keys, "a,b,i,j,l")
+	}
+
+	f := func(path, resultExpected string) {
+		t.Helper()
+		v, err := lp.Get(strings.Split(path, ".")...)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", path, err)
+		}
+		result := "<nil>"
+		if v != nil {
+			result = v.String()
+		}
+		if result != resultExpected {
+			t.Fatalf("unexpected value for %q; got %s; want %s", path, result, resultExpected)
+		}
+	}
+
+	f("b.c", `"x;}]"`)
+	f("b.d.1.e", `2`)
+	f("b.g", `{"h":true}`)
+	f("b.missing", `<nil>`)
+	f("b.c.missing", `<nil>`)
+	f("missing", `<nil>`)
+	f("a", `1`)
+	f("i", `[1,2,3]`)
+	f("i.2", `3`)
+	f("j", `"k"`)
+	f("l", `{}`)
+	f("b", `{"c":"x;}]","d":[1,{"e":2},"f"],"g":{"h":true}}`)
+
+	v, err := lp.Value()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resultExpected := MustParse(s).String()
+	if result := v.String(); result != resultExpected {
+		t.Fatalf("unexpected value; got %s; want %s", result, resultExpected)
+	}
+
+	// Values obtained before must remain valid.
+	x, _ := lp.Get("b", "d")
+	for i := 0; i < 100; i++ {
+		if _, err := lp.Get("a"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if result := x.String(); result != `[1,{"e":2},"f"]` {
+		t.Fatalf("unexpected value; got %s; want %s", result, `[1,{"e":2},"f"]`)
+	}
+}
+
+func TestLazyParserErrors(t *testing.T) {
+	// Index errors
+	f := func(s string) {
+		t.Helper()
+		var lp LazyParser
+		if err := lp.Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f(`a`)
+	f(`a = {b = 1;`)
+	f(`a = "b;`)
+	f(`@include "x.cfg"`)
+
+	// Errors on access
+	var lp LazyParser
+	if err := lp.Parse(`a = {b = ;}; c = 1 2; d = 3;`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := lp.Get("a", "b"); err == nil {
+		t.Fatalf("expecting non-nil error for a.b")
+	}
+	if _, err := lp.Get("c"); err == nil {
+		t.Fatalf("expecting non-nil error for c")
+	}
+	if v, err := lp.Get("d"); err != nil || v.String() != "3" {
+		t.Fatalf("unexpected result for d: %v, %v", v, err)
+	}
+	if _, err := lp.Value(); err == nil {
+		t.Fatalf("expecting non-nil error for the whole document")
+	}
+
+	// Duplicate keys
+	lp.Config.RejectDuplicateKeys = true
+	if err := lp.Parse(`a = 1; a = 2;`); err == nil {
+		t.Fatalf("expecting non-nil error for duplicate keys")
+	}
+}
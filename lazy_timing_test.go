@@ -0,0 +1,29 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func BenchmarkLazyParser(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "key%d = {a = [1, 2, 3]; b = \"value\"; c = {d = %d;};};\n", i, i)
+	}
+	s := sb.String()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(s)))
+	b.RunParallel(func(pb *testing.PB) {
+		var lp LazyParser
+		for pb.Next() {
+			if err := lp.Parse(s); err != nil {
+				panic(fmt.Errorf("unexpected error: %s", err))
+			}
+			v, err := lp.Get("key500", "c", "d")
+			if err != nil || v.GetInt() != 500 {
+				panic(fmt.Errorf("unexpected result: %v, %v", v, err))
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"strings"
+)
+
+// ParseLibconfig parses s in the classic libconfig format used by
+// the hyperrealm/libconfig C/C++ library.
+//
+// In addition to the syntax accepted by Parse, the following is supported:
+//
+//   - settings terminated by ',' or without terminator;
+//   - adjacent string literals, which are concatenated, e.g. "foo" "bar";
+//   - case-insensitive booleans such as TRUE and False.
+//
+// Setting names must match [A-Za-z*][-A-Za-z0-9_*]*. Groups are mapped
+// to objects, while arrays and lists are mapped to arrays.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseLibconfig(s string) (*Value, error) {
+	return p.parse(s, true)
+}
+
+// ParseLibconfigBytes parses b in the classic libconfig format.
+//
+// See Parser.ParseLibconfig for details.
+func (p *Parser) ParseLibconfigBytes(b []byte) (*Value, error) {
+	return p.ParseLibconfig(b2s(b))
+}
+
+// ParseLibconfig parses s in the classic libconfig format.
+//
+// The function is slower than the Parser.ParseLibconfig for re-used Parser.
+func ParseLibconfig(s string) (*Value, error) {
+	var p Parser
+	return p.ParseLibconfig(s)
+}
+
+// isClassicName returns true if name is a valid libconfig setting name.
+func isClassicName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '*' {
+			continue
+		}
+		if i > 0 && (isDigit(ch) || ch == '-' || ch == '_') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// parseClassicBool parses case-insensitive boolean at the start of s.
+func parseClassicBool(s string) (*Value, string, bool) {
+	if len(s) >= len("true") && strings.EqualFold(s[:len("true")], "true") {
+		return valueTrue, s[len("true"):], true
+	}
+	if len(s) >= len("false") && strings.EqualFold(s[:len("false")], "false") {
+		return valueFalse, s[len("false"):], true
+	}
+	return nil, s, false
+}
+
+// concatRawStrings appends adjacent string literals from tail to ss.
+func concatRawStrings(ss, tail string) (string, string, error) {
+	for {
+		s := skipJunk(tail)
+		if len(s) == 0 || s[0] != '"' {
+			return ss, tail, nil
+		}
+		next, t, err := parseRawString(s[1:])
+		if err != nil {
+			return ss, t, err
+		}
+		ss += next
+		tail = t
+	}
+}
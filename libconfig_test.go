@@ -0,0 +1,83 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParseLibconfig(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseLibconfig(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`a = 1, b = 2`, `{"a":1,"b":2}`)
+	f("a = 1\nb = \"x\"\n", `{"a":1,"b":"x"}`)
+	f(`s = "foo" /* comment */ "bar"
+  "baz";`, `{"s":"foobarbaz"}`)
+	f(`t = TRUE; f = False;`, `{"t":true,"f":false}`)
+	f(`g = {}; a = []; l = (); x = 1;`, `{"g":{},"a":[],"l":[],"x":1}`)
+	f(`*name-1_x = 1;`, `{"*name-1_x":1}`)
+
+	// Example from the libconfig manual.
+	f(`# Example application configuration file
+
+version = "1.0";
+
+application:
+{
+  window:
+  {
+    title = "My Application";
+    size = { w = 640; h = 480; };
+    pos = { x = 350; y = 250; };
+  };
+
+  list = ( ( "abc", 123, true ), 1.234, ( /* an empty list */ ) );
+
+  books = ( { title  = "Treasure Island";
+              author = "Robert Louis Stevenson";
+              price  = 29.95;
+              qty    = 5; },
+            { title  = "Snow Crash";
+              author = "Neal Stephenson";
+              price  = 9.99;
+              qty    = 8; } );
+
+  misc:
+  {
+    pi = 3.141592654;
+    bigint = 9223372036854775807L;
+    columns = [ "Last Name", "First Name", "MI" ];
+    bitmask = 0x1FC3;
+    umask = 0027;
+  };
+};
+`, `{"version":"1.0","application":{"window":{"title":"My Application","size":{"w":640,"h":480},"pos":{"x":350,"y":250}},"list":[["abc",123,true],1.234,[]],"books":[{"title":"Treasure Island","author":"Robert Louis Stevenson","price":29.95,"qty":5},{"title":"Snow Crash","author":"Neal Stephenson","price":9.99,"qty":8}],"misc":{"pi":3.141592654,"bigint":9223372036854775807L,"columns":["Last Name","First Name","MI"],"bitmask":0x1FC3,"umask":0027}}}`)
+
+	v, err := ParseLibconfig(`s = "\x41\x62c";`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(v.GetStringBytes("s")); s != "Abc" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "Abc")
+	}
+
+	// Errors
+	for _, s := range []string{"1a = 2;", "a.b = 1", `s = "foo" "bar`, "a = 1 ;; b"} {
+		if _, err := ParseLibconfig(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+
+	// Parse keeps requiring setting terminators.
+	if _, err := Parse("a = 1, b = 2"); err == nil {
+		t.Fatalf("expecting non-nil error when parsing settings separated by ',' via Parse")
+	}
+}
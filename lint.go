@@ -0,0 +1,262 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintFinding is a single finding reported by Linter.
+type LintFinding struct {
+	// Rule is the name of the rule, which reported the finding.
+	Rule string
+
+	// Path is the dotted path to the value the finding refers to.
+	//
+	// Array items are identified by decimal indexes.
+	Path string
+
+	// Message describes the finding.
+	Message string
+}
+
+// String returns human-readable representation for f.
+func (f *LintFinding) String() string {
+	path := f.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, f.Message, f.Rule)
+}
+
+// LintRule checks values for a single kind of problems.
+type LintRule interface {
+	// Name returns the rule name used in findings.
+	Name() string
+
+	// Check is called by Linter for every value in the linted tree,
+	// including the root value with an empty path.
+	//
+	// path contains keys leading to v. Check must call report for every
+	// found problem.
+	Check(path []string, v *Value, report func(message string))
+}
+
+// Linter checks Values with the given rules.
+//
+// Linter may be used from concurrent goroutines.
+type Linter struct {
+	// Rules contains rules applied to every linted value.
+	Rules []LintRule
+}
+
+// Lint returns findings for v reported by l.Rules.
+//
+// Findings are ordered by the value location in v.
+func (l *Linter) Lint(v *Value) []LintFinding {
+	var findings []LintFinding
+	l.lintValue(nil, v, &findings)
+	return findings
+}
+
+func (l *Linter) lintValue(path []string, v *Value, findings *[]LintFinding) {
+	for _, rule := range l.Rules {
+		rule.Check(path, v, func(message string) {
+			*findings = append(*findings, LintFinding{
+				Rule:    rule.Name(),
+				Path:    strings.Join(path, "."),
+				Message: message,
+			})
+		})
+	}
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			l.lintValue(append(path, kv.k), kv.v, findings)
+		}
+	case TypeArray:
+		for i, item := range v.a {
+			l.lintValue(append(path, strconv.Itoa(i)), item, findings)
+		}
+	}
+}
+
+// lintPath splits dotted path pattern into keys.
+func lintPath(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ".")
+}
+
+// matchLintPath returns true if path matches pattern.
+//
+// "*" in pattern matches any key.
+func matchLintPath(path, pattern []string) bool {
+	if len(path) != len(pattern) {
+		return false
+	}
+	return isIgnoredPath(path, [][]string{pattern})
+}
+
+// UnknownKeysRule reports object keys missing in Known.
+type UnknownKeysRule struct {
+	// Known contains dotted paths for the known keys.
+	//
+	// "*" matches any key, so "servers.*.host" matches host key in every
+	// item of servers array. Values below the known path aren't checked,
+	// so "labels" allows arbitrary keys inside labels object.
+	Known []string
+}
+
+// Name implements LintRule.
+func (r *UnknownKeysRule) Name() string {
+	return "unknown-keys"
+}
+
+// Check implements LintRule.
+func (r *UnknownKeysRule) Check(path []string, v *Value, report func(message string)) {
+	if len(path) == 0 || r.isKnown(path) {
+		return
+	}
+	// Report only the topmost unknown key.
+	if len(path) > 1 && !r.isKnown(path[:len(path)-1]) {
+		return
+	}
+	report(fmt.Sprintf("unknown key %q", path[len(path)-1]))
+}
+
+func (r *UnknownKeysRule) isKnown(path []string) bool {
+	for _, s := range r.Known {
+		pattern := lintPath(s)
+		n := len(path)
+		if n > len(pattern) {
+			n = len(pattern)
+		}
+		// Both ancestors and descendants of the known path are known.
+		if matchLintPath(path[:n], pattern[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeprecatedKeysRule reports deprecated keys.
+type DeprecatedKeysRule struct {
+	// Keys maps dotted paths for deprecated keys to hints shown in findings,
+	// e.g. "use server.listen instead".
+	//
+	// "*" in paths matches any key.
+	Keys map[string]string
+}
+
+// Name implements LintRule.
+func (r *DeprecatedKeysRule) Name() string {
+	return "deprecated-keys"
+}
+
+// Check implements LintRule.
+func (r *DeprecatedKeysRule) Check(path []string, v *Value, report func(message string)) {
+	if len(path) == 0 {
+		return
+	}
+	// Iterate over sorted keys in order to get stable findings.
+	patterns := make([]string, 0, len(r.Keys))
+	for s := range r.Keys {
+		patterns = append(patterns, s)
+	}
+	sort.Strings(patterns)
+	for _, s := range patterns {
+		if !matchLintPath(path, lintPath(s)) {
+			continue
+		}
+		message := fmt.Sprintf("key %q is deprecated", path[len(path)-1])
+		if hint := r.Keys[s]; hint != "" {
+			message += "; " + hint
+		}
+		report(message)
+		return
+	}
+}
+
+// SuspiciousTypesRule reports strings containing bool or numeric values,
+// e.g. "true" or "8080", which usually means superfluous quotes.
+type SuspiciousTypesRule struct{}
+
+// Name implements LintRule.
+func (r *SuspiciousTypesRule) Name() string {
+	return "suspicious-types"
+}
+
+// Check implements LintRule.
+func (r *SuspiciousTypesRule) Check(path []string, v *Value, report func(message string)) {
+	if v.Type() != TypeString {
+		return
+	}
+	s := strings.TrimSpace(v.s)
+	switch {
+	case s == "true" || s == "false":
+		report(fmt.Sprintf("string %q looks like bool; remove the quotes if bool is expected", v.s))
+	case s == "":
+	default:
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			report(fmt.Sprintf("string %q looks like number; remove the quotes if number is expected", v.s))
+		}
+	}
+}
+
+// UnresolvedPlaceholdersRule reports strings containing unresolved
+// placeholders such as ${VAR} or {{ .Var }}.
+type UnresolvedPlaceholdersRule struct{}
+
+// Name implements LintRule.
+func (r *UnresolvedPlaceholdersRule) Name() string {
+	return "unresolved-placeholders"
+}
+
+// Check implements LintRule.
+func (r *UnresolvedPlaceholdersRule) Check(path []string, v *Value, report func(message string)) {
+	if v.Type() != TypeString {
+		return
+	}
+	for _, delims := range [][2]string{{"${", "}"}, {"{{", "}}"}} {
+		n := strings.Index(v.s, delims[0])
+		if n < 0 {
+			continue
+		}
+		m := strings.Index(v.s[n+len(delims[0]):], delims[1])
+		if m < 0 {
+			continue
+		}
+		placeholder := v.s[n : n+len(delims[0])+m+len(delims[1])]
+		report(fmt.Sprintf("unresolved placeholder %s", placeholder))
+		return
+	}
+}
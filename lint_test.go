@@ -0,0 +1,74 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinter(t *testing.T) {
+	l := &Linter{
+		Rules: []LintRule{
+			&UnknownKeysRule{
+				Known: []string{"server.host", "server.port", "servers.*.host", "labels", "debug", "listen"},
+			},
+			&DeprecatedKeysRule{
+				Keys: map[string]string{
+					"listen": "use server.host and server.port instead",
+				},
+			},
+			&SuspiciousTypesRule{},
+			&UnresolvedPlaceholdersRule{},
+		},
+	}
+	v := MustParse(`
+		server = { host = "${HOST}"; port = "8080"; tls = { cert = "x"; }; };
+		servers = ( { host = "a"; }, { host = "b"; typo = 1; } );
+		labels = { any = "thing"; };
+		debug = "true";
+		listen = "{{ .Addr }}";
+	`)
+	var result []string
+	for _, f := range l.Lint(v) {
+		result = append(result, f.String())
+	}
+	resultExpected := []string{
+		`server.host: unresolved placeholder ${HOST} (unresolved-placeholders)`,
+		`server.port: string "8080" looks like number; remove the quotes if number is expected (suspicious-types)`,
+		`server.tls: unknown key "tls" (unknown-keys)`,
+		`servers.1.typo: unknown key "typo" (unknown-keys)`,
+		`debug: string "true" looks like bool; remove the quotes if bool is expected (suspicious-types)`,
+		`listen: key "listen" is deprecated; use server.host and server.port instead (deprecated-keys)`,
+		`listen: unresolved placeholder {{ .Addr }} (unresolved-placeholders)`,
+	}
+	if strings.Join(result, "\n") != strings.Join(resultExpected, "\n") {
+		t.Fatalf("unexpected findings\ngot\n%s\nwant\n%s", strings.Join(result, "\n"), strings.Join(resultExpected, "\n"))
+	}
+}
+
+type testLintRule struct{}
+
+func (r testLintRule) Name() string {
+	return "root"
+}
+
+func (r testLintRule) Check(path []string, v *Value, report func(message string)) {
+	if len(path) == 0 && v.Get("version") == nil {
+		report("missing version")
+	}
+}
+
+func TestLinterCustomRule(t *testing.T) {
+	l := &Linter{
+		Rules: []LintRule{testLintRule{}},
+	}
+	findings := l.Lint(MustParse(`a = 1;`))
+	if len(findings) != 1 {
+		t.Fatalf("unexpected number of findings; got %d; want %d", len(findings), 1)
+	}
+	if s := findings[0].String(); s != "<root>: missing version (root)" {
+		t.Fatalf("unexpected finding; got %q; want %q", s, "<root>: missing version (root)")
+	}
+	if findings := l.Lint(MustParse(`version = 1;`)); len(findings) != 0 {
+		t.Fatalf("unexpected findings: %v", findings)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Verifier verifies raw config data before it is parsed.
+type Verifier interface {
+	// Verify must return an error if data obtained from the given source
+	// must be rejected.
+	Verify(source string, data []byte) error
+}
+
+// VerifierFunc is an adapter allowing ordinary functions to be used as Verifier.
+type VerifierFunc func(source string, data []byte) error
+
+// Verify calls f(source, data).
+func (f VerifierFunc) Verify(source string, data []byte) error {
+	return f(source, data)
+}
+
+// SHA256Sidecar verifies config data against a sha256 checksum stored
+// in a sidecar file next to the config file.
+//
+// The sidecar file must contain hex-encoded checksum optionally followed
+// by whitespace and file name, i.e. the sha256sum output format.
+type SHA256Sidecar struct {
+	// Suffix is appended to the config source for obtaining sidecar file path.
+	//
+	// ".sha256" is used by default.
+	Suffix string
+
+	// ReadSidecar reads the sidecar file.
+	//
+	// os.ReadFile is used by default.
+	ReadSidecar func(name string) ([]byte, error)
+}
+
+// Verify verifies data against the checksum from the sidecar file for source.
+func (sv *SHA256Sidecar) Verify(source string, data []byte) error {
+	sidecar, err := readSidecar(source, sv.Suffix, ".sha256", sv.ReadSidecar)
+	if err != nil {
+		return err
+	}
+	fields := bytes.Fields(sidecar)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum file for %q", source)
+	}
+	return verifySHA256(source, data, string(fields[0]))
+}
+
+// SHA256Sum verifies config data against the given hex-encoded sha256 checksum.
+//
+// This is useful when checksums are delivered out of band, e.g. in
+// a deployment manifest.
+type SHA256Sum string
+
+// Verify verifies data against sum.
+func (sum SHA256Sum) Verify(source string, data []byte) error {
+	return verifySHA256(source, data, string(sum))
+}
+
+// DetachedSignature verifies config data against a detached signature
+// stored in a sidecar file next to the config file.
+type DetachedSignature struct {
+	// Suffix is appended to the config source for obtaining signature file path.
+	//
+	// ".sig" is used by default.
+	Suffix string
+
+	// Check must return an error if sig isn't a valid signature for data.
+	//
+	// Verify fails if Check isn't set.
+	Check func(data, sig []byte) error
+
+	// ReadSidecar reads the signature file.
+	//
+	// os.ReadFile is used by default.
+	ReadSidecar func(name string) ([]byte, error)
+}
+
+// Verify verifies data against the signature from the sidecar file for source.
+func (ds *DetachedSignature) Verify(source string, data []byte) error {
+	if ds.Check == nil {
+		return fmt.Errorf("cannot verify signature for %q: DetachedSignature.Check isn't set", source)
+	}
+	sig, err := readSidecar(source, ds.Suffix, ".sig", ds.ReadSidecar)
+	if err != nil {
+		return err
+	}
+	if err := ds.Check(data, sig); err != nil {
+		return fmt.Errorf("invalid signature for %q: %s", source, err)
+	}
+	return nil
+}
+
+func readSidecar(source, suffix, defaultSuffix string, readFile func(name string) ([]byte, error)) ([]byte, error) {
+	if suffix == "" {
+		suffix = defaultSuffix
+	}
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	name := source + suffix
+	b, err := readFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read sidecar file %q: %s", name, err)
+	}
+	return b, nil
+}
+
+func verifySHA256(source string, data []byte, sum string) error {
+	expected, err := hex.DecodeString(sum)
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("invalid sha256 checksum %q for %q", sum, source)
+	}
+	actual := sha256.Sum256(data)
+	if subtle.ConstantTimeCompare(actual[:], expected) != 1 {
+		return fmt.Errorf("sha256 checksum mismatch for %q; got %x; want %x", source, actual, expected)
+	}
+	return nil
+}
+
+// LoadOptions contains options for LoadFile.
+type LoadOptions struct {
+	// Verifier is an optional verifier for the loaded data.
+	//
+	// The data is rejected before parsing if verification fails.
+	// Only the root config file is verified; files pulled via @include
+	// must be protected by other means.
+	Verifier Verifier
+
+	// AuditSink is an optional sink for AuditLoaded and AuditLoadFailed events.
+	AuditSink AuditSink
+
+	// KeyProvider is an optional provider of keys for decrypting
+	// encrypted values. See EncryptedKey for details.
+	//
+	// Encrypted values are left as is if KeyProvider is nil.
+	KeyProvider KeyProvider
+
+	// MaxInputSize is the maximum size in bytes of the loaded file.
+	//
+	// It limits both the file size and the decompressed data,
+	// so small compressed files cannot expand into huge inputs.
+	// 32MiB is used by default.
+	MaxInputSize int
+}
+
+// defaultMaxInputSize is the default limit for the size of loaded configs.
+const defaultMaxInputSize = 32 << 20
+
+// parserConfig returns the config for reading files loaded with opts.
+func (opts *LoadOptions) parserConfig() *ParserConfig {
+	n := opts.MaxInputSize
+	if n <= 0 {
+		n = defaultMaxInputSize
+	}
+	return &ParserConfig{
+		MaxInputSize: n,
+	}
+}
+
+// LoadFile loads and parses the config file at path.
+//
+// @include directives are resolved relative to the config file directory.
+// Compressed files are transparently decompressed; see RegisterDecompressor.
+//
+// opts may be nil. The returned value doesn't reference any Parser,
+// so it remains valid for arbitrary long time.
+func LoadFile(path string, opts *LoadOptions) (*Value, error) {
+	p := handyPool.Get()
+	defer handyPool.Put(p)
+	v, _, err := loadFile(p, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.Clone(), nil
+}
+
+// loadFile loads and parses the config file at path with p.
+//
+// The returned checksum is hex-encoded sha256 of the file contents.
+func loadFile(p *Parser, path string, opts *LoadOptions) (*Value, string, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	v, checksum, err := loadFileInternal(p, path, opts)
+	if err != nil {
+		emitAudit(opts.AuditSink, AuditLoadFailed, path, checksum, err)
+		return nil, checksum, err
+	}
+	emitAudit(opts.AuditSink, AuditLoaded, path, checksum, nil)
+	return v, checksum, nil
+}
+
+func loadFileInternal(p *Parser, path string, opts *LoadOptions) (*Value, string, error) {
+	data, checksum, err := readConfigFile(path, opts)
+	if err != nil {
+		return nil, checksum, err
+	}
+	v, err := parseConfigFile(p, path, data)
+	if err != nil {
+		return nil, checksum, err
+	}
+	if opts.KeyProvider != nil {
+		var a Arena
+		if err := DecryptValues(v, &a, opts.KeyProvider); err != nil {
+			return nil, checksum, fmt.Errorf("cannot load config file %q: %s", path, err)
+		}
+	}
+	return v, checksum, nil
+}
+
+// readConfigFile reads, verifies and decompresses the config file at path.
+func readConfigFile(path string, opts *LoadOptions) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read config file error: %s", err.Error())
+	}
+	checksum := sha256Hex(data)
+	cfg := opts.parserConfig()
+	if err := cfg.checkInputSize(len(data)); err != nil {
+		return nil, checksum, fmt.Errorf("cannot load config file %q: %s", path, err)
+	}
+	if opts.Verifier != nil {
+		if err := opts.Verifier.Verify(path, data); err != nil {
+			return nil, checksum, fmt.Errorf("cannot verify config file %q: %s", path, err)
+		}
+	}
+
+	if data, err = decompress(data, cfg); err != nil {
+		return nil, checksum, fmt.Errorf("cannot load config file %q: %s", path, err)
+	}
+	return data, checksum, nil
+}
+
+// parseConfigFile parses data read from the config file at path with p.
+func parseConfigFile(p *Parser, path string, data []byte) (*Value, error) {
+	p.d = filepath.Dir(path)
+	p.f = path
+	defer func() {
+		p.d = ""
+		p.f = ""
+	}()
+	return p.ParseBytes(data)
+}
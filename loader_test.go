@@ -0,0 +1,85 @@
+package libconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	v, err := LoadFile("testdata/example4.cfg", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if title := string(v.GetStringBytes("books", "0", "title")); title == "" {
+		t.Fatalf("expecting non-empty title from included file")
+	}
+
+	if _, err := LoadFile("testdata/missing.cfg", nil); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestLoadFileVerified(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.cfg")
+	data := []byte(`port = 8080;`)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("cannot write config: %s", err)
+	}
+	sum := sha256.Sum256(data)
+	sumHex := hex.EncodeToString(sum[:])
+
+	f := func(verifier Verifier, okExpected bool) {
+		t.Helper()
+		v, err := LoadFile(path, &LoadOptions{
+			Verifier: verifier,
+		})
+		if okExpected {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if n := v.GetInt("port"); n != 8080 {
+				t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+			}
+		} else if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+
+	// missing sidecar
+	f(&SHA256Sidecar{}, false)
+
+	if err := os.WriteFile(path+".sha256", []byte(sumHex+"  app.cfg\n"), 0644); err != nil {
+		t.Fatalf("cannot write checksum: %s", err)
+	}
+	f(&SHA256Sidecar{}, true)
+	f(SHA256Sum(sumHex), true)
+	f(SHA256Sum("abcd"), false)
+
+	// tampered file
+	if err := os.WriteFile(path+".sha256", []byte(fmt.Sprintf("%064x\n", 1)), 0644); err != nil {
+		t.Fatalf("cannot write checksum: %s", err)
+	}
+	f(&SHA256Sidecar{}, false)
+
+	// detached signature
+	if err := os.WriteFile(path+".sig", []byte("good"), 0644); err != nil {
+		t.Fatalf("cannot write signature: %s", err)
+	}
+	check := func(data, sig []byte) error {
+		if string(sig) != "good" {
+			return fmt.Errorf("bad signature")
+		}
+		return nil
+	}
+	f(&DetachedSignature{Check: check}, true)
+	f(&DetachedSignature{Suffix: ".missing", Check: check}, false)
+	f(&DetachedSignature{}, false)
+	f(VerifierFunc(func(source string, data []byte) error {
+		return fmt.Errorf("rejected")
+	}), false)
+}
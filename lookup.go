@@ -0,0 +1,120 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sort sorts o items by key, so subsequent Get calls use binary search.
+//
+// This speeds up lookups in objects with thousands of keys. Items with
+// duplicate keys retain their relative order, so Get returns the same
+// value as before sorting. Visit, Range and At iterate items in sorted
+// order after the call. New keys added via Set are inserted at their
+// sorted positions.
+func (o *Object) Sort() {
+	if o == nil || o.sorted {
+		return
+	}
+	o.mustNotBeFrozen()
+	o.unescapeKeys()
+	sort.SliceStable(o.kvs, func(i, j int) bool {
+		return o.kvs[i].k < o.kvs[j].k
+	})
+	o.sorted = true
+}
+
+// IsSorted returns true if Sort has been called on o.
+func (o *Object) IsSorted() bool {
+	return o != nil && o.sorted
+}
+
+// sortedIndex returns the index of the first item with the key
+// equal or greater than the given key in the sorted o.
+func (o *Object) sortedIndex(key string) int {
+	return sort.Search(len(o.kvs), func(i int) bool {
+		return o.kvs[i].k >= key
+	})
+}
+
+// GetCaseInsensitive returns the value for the given key in the o,
+// matching object keys case-insensitively.
+//
+// The value for exactly matching key is preferred. Otherwise the first value
+// with case-insensitively matching key is returned.
+//
+// Returns nil if the value for the given key isn't found.
+//
+// The returned value is valid until Parse is called on the Parser returned o.
+func (o *Object) GetCaseInsensitive(key string) *Value {
+	if o == nil {
+		return nil
+	}
+	if v := o.Get(key); v != nil {
+		return v
+	}
+	o.unescapeKeys()
+	for _, kv := range o.kvs {
+		if strings.EqualFold(kv.k, key) {
+			return kv.v
+		}
+	}
+	return nil
+}
+
+// GetCaseInsensitive returns value by the given keys path, matching
+// object keys case-insensitively.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path.
+//
+// The returned value is valid until Parse is called on the Parser returned v.
+func (v *Value) GetCaseInsensitive(keys ...string) *Value {
+	if v == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if v.t == TypeObject {
+			v = v.o.GetCaseInsensitive(key)
+			if v == nil {
+				return nil
+			}
+		} else if v.t == TypeArray {
+			n, err := strconv.Atoi(key)
+			if err != nil || n < 0 || n >= len(v.a) {
+				return nil
+			}
+			v = v.a[n]
+		} else {
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestObjectSort(t *testing.T) {
+	v := MustParse(`c = 3; a = 1; b = 2; a = 4;`)
+	o := v.GetObject()
+	o.Sort()
+	if !o.IsSorted() {
+		t.Fatalf("object must be sorted")
+	}
+	s := v.String()
+	sExpected := `{"a":1,"a":4,"b":2,"c":3}`
+	if s != sExpected {
+		t.Fatalf("unexpected sorted object\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+	if n := v.GetInt("a"); n != 1 {
+		t.Fatalf("duplicate keys must keep first-wins semantics; got %d; want 1", n)
+	}
+	if v.Get("d") != nil || v.Get("0") != nil || v.Get("zzz") != nil {
+		t.Fatalf("unexpected value for missing key")
+	}
+
+	var a Arena
+	o.Set("bb", a.NewNumberInt(5))
+	o.Set("0", a.NewNumberInt(6))
+	o.Set("z", a.NewNumberInt(7))
+	o.Set("b", a.NewNumberInt(8))
+	s = v.String()
+	sExpected = `{"0":6,"a":1,"a":4,"b":8,"bb":5,"c":3,"z":7}`
+	if s != sExpected {
+		t.Fatalf("unexpected object after Set\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+	for _, key := range []string{"0", "a", "b", "bb", "c", "z"} {
+		if v.Get(key) == nil {
+			t.Fatalf("cannot find key %q", key)
+		}
+	}
+	o.Del("bb")
+	if v.Get("bb") != nil || v.GetInt("c") != 3 {
+		t.Fatalf("unexpected object after Del: %s", v)
+	}
+
+	// Sorting survives freezing.
+	if !v.Freeze().GetObject().IsSorted() {
+		t.Fatalf("frozen object must remain sorted")
+	}
+}
+
+func TestObjectSortBig(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&b, "key_%d = %d;", (i*7919)%5000, i)
+	}
+	v := MustParse(b.String())
+	o := v.GetObject()
+	o.Sort()
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("key_%d", (i*7919)%5000)
+		if n := o.Get(key).GetInt(); n != i {
+			t.Fatalf("unexpected value for %q; got %d; want %d", key, n, i)
+		}
+	}
+}
+
+func TestGetCaseInsensitive(t *testing.T) {
+	v := MustParse(`Server = { Port = 80; port = 81; Hosts = ["a"]; };`)
+	if n := v.GetCaseInsensitive("server", "PORT").GetInt(); n != 80 {
+		t.Fatalf("unexpected value; got %d; want 80", n)
+	}
+	if n := v.GetCaseInsensitive("SERVER", "port").GetInt(); n != 81 {
+		t.Fatalf("exact match must be preferred; got %d; want 81", n)
+	}
+	if s := string(v.GetCaseInsensitive("server", "hosts", "0").GetStringBytes()); s != "a" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "a")
+	}
+	if v.GetCaseInsensitive("server", "missing") != nil || v.GetCaseInsensitive("server", "hosts", "1") != nil {
+		t.Fatalf("unexpected value for missing key")
+	}
+	if v.Get("server") != nil {
+		t.Fatalf("Get must remain case-sensitive")
+	}
+}
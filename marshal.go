@@ -0,0 +1,386 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FloatFormat defines how MarshalOptions formats non-integer numbers.
+type FloatFormat int
+
+const (
+	// FloatVerbatim marshals number tokens as is.
+	FloatVerbatim FloatFormat = iota
+
+	// FloatShortest marshals the shortest representation, which converts
+	// back to the same float64, in the same way as encoding/json does.
+	// For example, 1.50 is marshaled as 1.5, while 1e-7 is marshaled as 1e-7.
+	FloatShortest
+
+	// FloatFixed marshals numbers with MarshalOptions.FloatPrecision digits
+	// after the decimal point, e.g. 1.5 is marshaled as 1.50
+	// for FloatPrecision=2.
+	FloatFixed
+)
+
+// String returns string representation for f.
+func (f FloatFormat) String() string {
+	switch f {
+	case FloatVerbatim:
+		return "verbatim"
+	case FloatShortest:
+		return "shortest"
+	case FloatFixed:
+		return "fixed"
+	default:
+		return fmt.Sprintf("FloatFormat(%d)", int(f))
+	}
+}
+
+// MarshalOptions contains optional settings for Value.MarshalToOptions.
+//
+// The zero MarshalOptions marshals values in the same way as Value.MarshalTo.
+type MarshalOptions struct {
+	// ASCIIOnly makes the output ASCII-only by escaping non-ASCII chars
+	// in strings and object keys as \uXXXX. Chars outside the Basic
+	// Multilingual Plane are escaped as UTF-16 surrogate pairs, while
+	// invalid UTF-8 is replaced with \ufffd.
+	//
+	// This is useful for passing the output to legacy systems.
+	// Values created via Arena.NewRawJSON are marshaled as is.
+	ASCIIOnly bool
+
+	// EscapeHTML makes escaping '<', '>' and '&' in strings and object keys
+	// as \u003c, \u003e and \u0026 in the same way as encoding/json does,
+	// so the output may be safely embedded into HTML <script> tags.
+	// U+2028 and U+2029 are escaped as \u2028 and \u2029 too, since they
+	// are line terminators in JavaScript.
+	//
+	// Values created via Arena.NewRawJSON are marshaled as is.
+	EscapeHTML bool
+
+	// FloatFormat defines how non-integer numbers such as 1.5 or 2e-3
+	// are marshaled. Integer tokens, hex numbers, NaN and Inf are always
+	// marshaled as is.
+	//
+	// Parsed numbers with fraction or exponent are never marshaled
+	// as integers, e.g. 2.0 is marshaled as 2.0 for FloatShortest.
+	// This doesn't apply to numbers created from Go numbers via Arena.
+	FloatFormat FloatFormat
+
+	// FloatPrecision is the number of digits after the decimal point
+	// for FloatFixed.
+	FloatPrecision int
+
+	// IntegersWithoutExponent makes marshaling integer numbers with exponent
+	// such as 1e6 or 1.5E3 as plain digits, e.g. 1000000 or 1500.
+	// This applies only to numbers with absolute values up to 2^53,
+	// which are represented exactly by float64.
+	IntegersWithoutExponent bool
+
+	// PreserveNumberTokens makes marshaling number tokens obtained
+	// from the parsed input as is, so FloatFormat and IntegersWithoutExponent
+	// apply only to numbers created from Go numbers via Arena, e.g. via
+	// Arena.NewNumberFloat64 or Arena.Marshal.
+	PreserveNumberTokens bool
+}
+
+// MarshalToOptions appends marshaled v to dst according to opts
+// and returns the result.
+//
+// MarshalTo is used if opts is nil.
+func (v *Value) MarshalToOptions(dst []byte, opts *MarshalOptions) []byte {
+	if opts == nil || *opts == (MarshalOptions{}) {
+		return v.MarshalTo(dst)
+	}
+	return opts.marshal(dst, v)
+}
+
+func (opts *MarshalOptions) marshal(dst []byte, v *Value) []byte {
+	switch v.t {
+	case typeRawString:
+		if !opts.needsEscape(v.s) {
+			return v.MarshalTo(dst)
+		}
+		// Unescape a copy of the string, so v remains unchanged.
+		return opts.appendString(dst, unescapeStringCopy(v.s))
+	case TypeString:
+		return opts.appendString(dst, v.s)
+	case TypeObject:
+		dst = append(dst, '{')
+		for i, kv := range v.o.kvs {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			switch {
+			case v.o.keysUnescaped:
+				dst = opts.appendString(dst, kv.k)
+			case opts.needsEscape(kv.k):
+				dst = opts.appendString(dst, unescapeStringCopy(kv.k))
+			default:
+				dst = append(dst, '"')
+				dst = append(dst, kv.k...)
+				dst = append(dst, '"')
+			}
+			dst = append(dst, ':')
+			dst = opts.marshal(dst, kv.v)
+		}
+		return append(dst, '}')
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, item := range v.a {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = opts.marshal(dst, item)
+		}
+		return append(dst, ']')
+	case TypeNumber:
+		if opts.PreserveNumberTokens && v.o.flags&flagGenerated == 0 {
+			return append(dst, v.s...)
+		}
+		return opts.appendNumber(dst, v.s, v.o.flags&flagGenerated == 0)
+	default:
+		return v.MarshalTo(dst)
+	}
+}
+
+// appendNumber appends number token s to dst according to opts.
+//
+// Parsed tokens with fraction or exponent remain non-integer after
+// formatting, e.g. 2.0 isn't marshaled as 2, so integer and float numbers
+// remain distinguishable for the consumers of the output. This doesn't apply
+// if integer output is explicitly requested via IntegersWithoutExponent
+// or via FloatFixed with zero FloatPrecision.
+func (opts *MarshalOptions) appendNumber(dst []byte, s string, parsed bool) []byte {
+	if !isFloatToken(s) || isHexToken(s) {
+		return append(dst, s...)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, s...)
+	}
+	n := len(dst)
+	switch {
+	case opts.IntegersWithoutExponent && strings.IndexAny(s, "eE") >= 0 && f == math.Trunc(f) && math.Abs(f) <= 1<<53:
+		dst = strconv.AppendInt(dst, int64(f), 10)
+	case opts.FloatFormat == FloatShortest:
+		dst = appendShortestFloat(dst, f)
+	case opts.FloatFormat == FloatFixed:
+		dst = strconv.AppendFloat(dst, f, 'f', opts.FloatPrecision, 64)
+	default:
+		return append(dst, s...)
+	}
+	if parsed && !opts.integerOutput() && !isFloatToken(b2s(dst[n:])) {
+		dst = append(dst, ".0"...)
+	}
+	return dst
+}
+
+// integerOutput returns true if opts explicitly request marshaling
+// integer-valued floats as integers.
+func (opts *MarshalOptions) integerOutput() bool {
+	return opts.IntegersWithoutExponent || (opts.FloatFormat == FloatFixed && opts.FloatPrecision == 0)
+}
+
+// isFloatToken returns true if the number token s contains fraction
+// or exponent.
+func isFloatToken(s string) bool {
+	return !isHexToken(s) && strings.IndexAny(s, ".eE") >= 0
+}
+
+// appendShortestFloat appends the shortest representation of f to dst
+// in the same way as encoding/json does.
+func appendShortestFloat(dst []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	n := len(dst)
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if m := len(dst) - n; m >= 4 && dst[len(dst)-4] == 'e' && dst[len(dst)-3] == '-' && dst[len(dst)-2] == '0' {
+			dst[len(dst)-2] = dst[len(dst)-1]
+			dst = dst[:len(dst)-1]
+		}
+	}
+	return dst
+}
+
+// needsEscape returns true if the raw string s must be re-escaped
+// according to opts.
+//
+// Strings with escape sequences are always re-escaped, since they may
+// contain escape sequences, which are invalid in JSON, such as \x7f.
+func (opts *MarshalOptions) needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch == '\\' || ch == 0x7f || (opts.ASCIIOnly && ch >= utf8.RuneSelf) {
+			return true
+		}
+		if opts.EscapeHTML && (ch == '<' || ch == '>' || ch == '&' || ch == 0xe2) {
+			// 0xe2 is the first byte of U+2028 and U+2029.
+			return true
+		}
+	}
+	return false
+}
+
+// appendString appends JSON-quoted s to dst according to opts.
+func (opts *MarshalOptions) appendString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	appendU := func(dst []byte, r rune) []byte {
+		return append(dst, '\\', 'u', hex[(r>>12)&0xf], hex[(r>>8)&0xf], hex[(r>>4)&0xf], hex[r&0xf])
+	}
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		ch := s[i]
+		if ch < utf8.RuneSelf {
+			switch {
+			case ch == '"' || ch == '\\':
+				dst = append(dst, '\\', ch)
+			case ch == '\n':
+				dst = append(dst, `\n`...)
+			case ch == '\r':
+				dst = append(dst, `\r`...)
+			case ch == '\t':
+				dst = append(dst, `\t`...)
+			case ch < 0x20 || ch == 0x7f:
+				dst = appendU(dst, rune(ch))
+			case opts.EscapeHTML && (ch == '<' || ch == '>' || ch == '&'):
+				dst = appendU(dst, rune(ch))
+			default:
+				dst = append(dst, ch)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case opts.EscapeHTML && (r == '\u2028' || r == '\u2029'):
+			dst = appendU(dst, r)
+		case !opts.ASCIIOnly && (r != utf8.RuneError || size > 1):
+			dst = append(dst, s[i:i+size]...)
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, `\ufffd`...)
+		case r >= 0x10000:
+			r1, r2 := utf16.EncodeRune(r)
+			dst = appendU(dst, r1)
+			dst = appendU(dst, r2)
+		default:
+			dst = appendU(dst, r)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
+
+// MarshalLibconfigTo appends libconfig representation of v to dst
+// and returns the result.
+//
+// Objects are marshaled as `{key = value; ...}`, while their entries
+// are marshaled without the enclosing braces if v is an object, so the result
+// may be parsed back with Parser.Parse. Comments and formatting of the
+// original input aren't preserved. Use Editor for format-preserving edits.
+func (v *Value) MarshalLibconfigTo(dst []byte) []byte {
+	if v.t == TypeObject {
+		return appendLibconfigEntries(dst, &v.o)
+	}
+	return appendLibconfigValue(dst, v)
+}
+
+func appendLibconfigEntries(dst []byte, o *Object) []byte {
+	for i, kv := range o.kvs {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, kv.k...)
+		dst = append(dst, " = "...)
+		dst = appendLibconfigValue(dst, kv.v)
+		dst = append(dst, ';')
+	}
+	return dst
+}
+
+func appendLibconfigValue(dst []byte, v *Value) []byte {
+	switch v.t {
+	case TypeObject:
+		dst = append(dst, '{')
+		dst = appendLibconfigEntries(dst, &v.o)
+		return append(dst, '}')
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, item := range v.a {
+			if i > 0 {
+				dst = append(dst, ", "...)
+			}
+			dst = appendLibconfigValue(dst, item)
+		}
+		return append(dst, ']')
+	default:
+		return v.MarshalTo(dst)
+	}
+}
+
+// checkLibconfigKeys returns an error if v contains object keys, which
+// cannot be represented in libconfig, e.g. keys containing '=' or ';'.
+func checkLibconfigKeys(v *Value) error {
+	switch v.t {
+	case TypeObject:
+		for _, kv := range v.o.kvs {
+			if !isLibconfigKey(kv.k) {
+				return fmt.Errorf("cannot represent key %q in libconfig", kv.k)
+			}
+			if err := checkLibconfigKeys(kv.v); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		for _, item := range v.a {
+			if err := checkLibconfigKeys(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isLibconfigKey(k string) bool {
+	if k == "" || k != strings.TrimSpace(k) {
+		return false
+	}
+	if strings.Contains(k, "//") || strings.Contains(k, "/*") {
+		return false
+	}
+	return !strings.ContainsAny(k, ":=;,{}[]()#\r\n")
+}
@@ -0,0 +1,143 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestMarshalLibconfigTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := string(v.MarshalLibconfigTo(nil))
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+
+		// The result must be parsed back into the same value.
+		v2, err := Parse(result)
+		if err != nil {
+			t.Fatalf("cannot parse the result: %s", err)
+		}
+		if s1, s2 := v.String(), v2.String(); s1 != s2 {
+			t.Fatalf("unexpected value after round trip; got %s; want %s", s2, s1)
+		}
+	}
+
+	f("", "")
+	f("a = 1;", "a = 1;")
+	f("a : \"x\"; // comment\nb = ( 1, 2.5, true, null );", `a = "x"; b = [1, 2.5, true, null];`)
+	f("a = { b = { c = [ {d = []; }, {} ]; }; };", "a = {b = {c = [{d = [];}, {}];};};")
+
+	var a Arena
+	v := a.NewArray()
+	v.SetArrayItem(0, a.NewString("x\ny"))
+	v.SetArrayItem(1, a.NewNumberInt(-3))
+	if s := string(v.MarshalLibconfigTo(nil)); s != `["x\ny", -3]` {
+		t.Fatalf("unexpected result; got %q; want %q", s, `["x\ny", -3]`)
+	}
+}
+
+func TestMarshalToOptions(t *testing.T) {
+	f := func(s string, opts *MarshalOptions, resultExpected string) {
+		t.Helper()
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		result := string(v.MarshalToOptions(nil, opts))
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+		// The value must remain valid after marshaling.
+		if s1, s2 := v.String(), MustParse(s).String(); s1 != s2 {
+			t.Fatalf("unexpected value after marshaling; got %s; want %s", s1, s2)
+		}
+	}
+
+	ascii := &MarshalOptions{
+		ASCIIOnly: true,
+	}
+	f(`a = "привет";`, nil, `{"a":"привет"}`)
+	f(`a = "привет";`, &MarshalOptions{}, `{"a":"привет"}`)
+	f(`a = "x\ny";`, ascii, `{"a":"x\ny"}`)
+	f(`a = "привет";`, ascii, `{"a":"\u043f\u0440\u0438\u0432\u0435\u0442"}`)
+	f(`a = ["é\t\"q\"", "😀"];`, ascii, `{"a":["\u00e9\t\"q\"","\ud83d\ude00"]}`)
+	f(`ключ = { b = 1; };`, ascii, `{"\u043a\u043b\u044e\u0447":{"b":1}}`)
+
+	html := &MarshalOptions{
+		EscapeHTML: true,
+	}
+	f(`a = "<script>alert(1) && x</script>";`, html, `{"a":"\u003cscript\u003ealert(1) \u0026\u0026 x\u003c/script\u003e"}`)
+	f(`"<k>" = "é\u2028";`, html, `{"\"\u003ck\u003e\"":"é\u2028"}`)
+	f("a = \"x\u2029y\";", html, `{"a":"x\u2029y"}`)
+	f(`a = "<é>";`, &MarshalOptions{ASCIIOnly: true, EscapeHTML: true}, `{"a":"\u003c\u00e9\u003e"}`)
+	f(`a = "plain";`, html, `{"a":"plain"}`)
+
+	var a Arena
+	v := a.NewObject()
+	v.Set("k", a.NewString("a\xffb\x7f\x01"))
+	if s := string(v.MarshalToOptions(nil, ascii)); s != `{"k":"a\ufffdb\u007f\u0001"}` {
+		t.Fatalf("unexpected result; got %q; want %q", s, `{"k":"a\ufffdb\u007f\u0001"}`)
+	}
+}
+
+func TestMarshalToOptionsNumbers(t *testing.T) {
+	f := func(opts *MarshalOptions, resultExpected string) {
+		t.Helper()
+		v := MustParse(`a = [1, 1.50, 2.0, 1e6, 1.5E3, -2.5e-7, 0x1F, 1e30, 12345678901234567890];`)
+		var a Arena
+		arr := v.Get("a")
+		arr.SetArrayItem(len(arr.a), a.NewNumberFloat64(1e6))
+		arr.SetArrayItem(len(arr.a), a.NewNumberFloat64(0.125))
+		result := string(v.MarshalToOptions(nil, opts))
+		if result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+
+	f(nil, `{"a":[1,1.50,2.0,1e6,1.5E3,-2.5e-7,0x1F,1e30,12345678901234567890,1e+06,0.125]}`)
+	f(&MarshalOptions{
+		FloatFormat: FloatShortest,
+	}, `{"a":[1,1.5,2.0,1000000.0,1500.0,-2.5e-7,0x1F,1e+30,12345678901234567890,1000000,0.125]}`)
+	f(&MarshalOptions{
+		FloatFormat:    FloatFixed,
+		FloatPrecision: 2,
+	}, `{"a":[1,1.50,2.00,1000000.00,1500.00,-0.00,0x1F,1000000000000000019884624838656.00,12345678901234567890,1000000.00,0.12]}`)
+	f(&MarshalOptions{
+		IntegersWithoutExponent: true,
+	}, `{"a":[1,1.50,2.0,1000000,1500,-2.5e-7,0x1F,1e30,12345678901234567890,1000000,0.125]}`)
+	f(&MarshalOptions{
+		FloatFormat:             FloatShortest,
+		IntegersWithoutExponent: true,
+		PreserveNumberTokens:    true,
+	}, `{"a":[1,1.50,2.0,1e6,1.5E3,-2.5e-7,0x1F,1e30,12345678901234567890,1000000,0.125]}`)
+
+	f(&MarshalOptions{
+		FloatFormat:    FloatFixed,
+		FloatPrecision: 0,
+	}, `{"a":[1,2,2,1000000,1500,-0,0x1F,1000000000000000019884624838656,12345678901234567890,1000000,0]}`)
+
+	if s := FloatFixed.String(); s != "fixed" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "fixed")
+	}
+}
+
+func TestNumberTokensRoundTrip(t *testing.T) {
+	v := MustParse(`a = [1, 1.0, 1e0, -0.0];`)
+	const resultExpected = `{"a":[1,1.0,1e0,-0.0]}`
+	var a Arena
+	for _, x := range []*Value{v, v.Clone(), v.Freeze(), a.CopyValue(v)} {
+		if s := x.String(); s != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", s, resultExpected)
+		}
+		opts := &MarshalOptions{
+			FloatFormat: FloatShortest,
+		}
+		if s := string(x.MarshalToOptions(nil, opts)); s != `{"a":[1,1.0,1.0,-0.0]}` {
+			t.Fatalf("unexpected result; got %q; want %q", s, `{"a":[1,1.0,1.0,-0.0]}`)
+		}
+	}
+}
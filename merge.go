@@ -0,0 +1,175 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// ArrayMergeStrategy determines how arrays are merged by Merger.
+type ArrayMergeStrategy int
+
+const (
+	// ArrayReplace replaces base array with overlay array.
+	ArrayReplace ArrayMergeStrategy = 0
+
+	// ArrayAppend appends overlay array items to base array items.
+	ArrayAppend ArrayMergeStrategy = 1
+
+	// ArrayMergeByIndex deep-merges array items with identical indexes.
+	// Extra overlay items are appended.
+	ArrayMergeByIndex ArrayMergeStrategy = 2
+
+	// ArrayMergeByKey deep-merges object items with identical values
+	// for the Merger.Key field. Other overlay items are appended.
+	ArrayMergeByKey ArrayMergeStrategy = 3
+)
+
+// Merger deep-merges Values.
+//
+// Objects are merged recursively. Overlay values for other types
+// replace base values. Arrays are merged according to Arrays strategy.
+type Merger struct {
+	// Arrays is the strategy for merging arrays.
+	Arrays ArrayMergeStrategy
+
+	// Key is the object key used for matching array items
+	// when Arrays is ArrayMergeByKey.
+	Key string
+}
+
+// MergeValues deep-merges overlays into base in the given order and returns
+// the result allocated in a. Later overlays take precedence.
+//
+// Arrays from overlays replace arrays from base. Use Merger
+// for other array merge strategies.
+//
+// See Merger.Merge for details.
+func MergeValues(a *Arena, base *Value, overlays ...*Value) *Value {
+	var m Merger
+	return m.Merge(a, base, overlays...)
+}
+
+// Merge deep-merges overlays into base in the given order and returns
+// the result allocated in a. Later overlays take precedence.
+//
+// base and overlays aren't modified. nil overlays are skipped.
+//
+// Objects and arrays in the result are allocated in a, while scalar values
+// are shared with base and overlays, so the result is valid until
+// a is reset or the Parsers returned base and overlays are re-used.
+func (m *Merger) Merge(a *Arena, base *Value, overlays ...*Value) *Value {
+	v := copyContainers(a, base)
+	for _, overlay := range overlays {
+		if overlay == nil {
+			continue
+		}
+		v = m.mergeValue(a, v, overlay)
+	}
+	return v
+}
+
+func (m *Merger) mergeValue(a *Arena, dst, src *Value) *Value {
+	if dst == nil || dst.t != src.t || (src.t != TypeObject && src.t != TypeArray) {
+		return copyContainers(a, src)
+	}
+	if src.t == TypeObject {
+		src.o.unescapeKeys()
+		dst.o.unescapeKeys()
+		for _, kv := range src.o.kvs {
+			dst.o.Set(kv.k, m.mergeValue(a, dst.o.Get(kv.k), kv.v))
+		}
+		return dst
+	}
+
+	switch m.Arrays {
+	case ArrayAppend:
+		for _, item := range src.a {
+			dst.a = append(dst.a, copyContainers(a, item))
+		}
+	case ArrayMergeByIndex:
+		for i, item := range src.a {
+			if i < len(dst.a) {
+				dst.a[i] = m.mergeValue(a, dst.a[i], item)
+			} else {
+				dst.a = append(dst.a, copyContainers(a, item))
+			}
+		}
+	case ArrayMergeByKey:
+		for _, item := range src.a {
+			n := m.indexByKey(dst.a, item)
+			if n >= 0 {
+				dst.a[n] = m.mergeValue(a, dst.a[n], item)
+			} else {
+				dst.a = append(dst.a, copyContainers(a, item))
+			}
+		}
+	default:
+		return copyContainers(a, src)
+	}
+	return dst
+}
+
+func (m *Merger) indexByKey(items []*Value, item *Value) int {
+	k := item.Get(m.Key)
+	if item.t != TypeObject || k == nil {
+		return -1
+	}
+	ks := k.String()
+	for i, v := range items {
+		if v.t != TypeObject {
+			continue
+		}
+		if vk := v.Get(m.Key); vk != nil && vk.String() == ks {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyContainers returns a copy of v with all the objects and arrays
+// allocated in a. Scalar values are shared with v.
+func copyContainers(a *Arena, v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	switch v.t {
+	case TypeObject:
+		o := a.NewObject()
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			okv := o.o.getKV()
+			okv.k = kv.k
+			okv.v = copyContainers(a, kv.v)
+		}
+		o.o.keysUnescaped = true
+		return o
+	case TypeArray:
+		arr := a.NewArray()
+		for i, item := range v.a {
+			arr.SetArrayItem(i, copyContainers(a, item))
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,52 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestMergeValues(t *testing.T) {
+	base := MustParse(`server = { host = "localhost"; port = 8080; }; hosts = ["a", "b"]; debug = true;`)
+	dev := MustParse(`server = { port = 9090; }; hosts = ["c"];`)
+	prod := MustParse(`debug = false; extra = "x";`)
+	baseStr := base.String()
+
+	var a Arena
+	v := MergeValues(&a, base, dev, prod)
+	s := v.String()
+	sExpected := `{"server":{"host":"localhost","port":9090},"hosts":["c"],"debug":false,"extra":"x"}`
+	if s != sExpected {
+		t.Fatalf("unexpected merge result\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+	if base.String() != baseStr {
+		t.Fatalf("base must be unchanged; got\n%s\nwant\n%s", base.String(), baseStr)
+	}
+
+	// Mutating the result mustn't affect base.
+	v.Get("server").Set("host", a.NewString("example.com"))
+	if base.String() != baseStr {
+		t.Fatalf("base must be unchanged after result mutation; got\n%s\nwant\n%s", base.String(), baseStr)
+	}
+}
+
+func TestMergerArrays(t *testing.T) {
+	f := func(m *Merger, base, overlay, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v := m.Merge(&a, MustParse(base), MustParse(overlay))
+		result := v.String()
+		if result != resultExpected {
+			t.Fatalf("unexpected merge result\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f(&Merger{}, `a = [1, 2];`, `a = [3];`, `{"a":[3]}`)
+	f(&Merger{Arrays: ArrayAppend}, `a = [1, 2];`, `a = [3];`, `{"a":[1,2,3]}`)
+	f(&Merger{Arrays: ArrayMergeByIndex}, `a = ({x = 1; y = 2;}, 2);`, `a = ({y = 3;}, 4, 5);`, `{"a":[{"x":1,"y":3},4,5]}`)
+	f(&Merger{Arrays: ArrayMergeByKey, Key: "name"},
+		`a = ({name = "x"; port = 1;}, {name = "y"; port = 2;});`,
+		`a = ({name = "y"; port = 3;}, {name = "z"; port = 4;});`,
+		`{"a":[{"name":"x","port":1},{"name":"y","port":3},{"name":"z","port":4}]}`)
+
+	// Type mismatch results in replacement.
+	f(&Merger{Arrays: ArrayAppend}, `a = [1];`, `a = { b = 1; };`, `{"a":{"b":1}}`)
+}
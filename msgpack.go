@@ -0,0 +1,437 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseMsgpack parses b containing MessagePack object.
+//
+// MessagePack objects are mapped to values in the following way:
+//
+//   - integers and floats are mapped to numbers; NaN and infinities
+//     are mapped to nan and inf numbers;
+//   - str is mapped to strings;
+//   - bin is mapped to base64url-encoded strings without padding;
+//   - timestamp extension is mapped to RFC 3339 strings in UTC;
+//   - map keys are converted to strings, e.g. 1 becomes "1"; arrays and maps
+//     cannot be used as keys.
+//
+// Other extension types result in error.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseMsgpack(b []byte) (*Value, error) {
+	if err := p.Config.checkInputSize(len(b)); err != nil {
+		return nil, fmt.Errorf("cannot parse MessagePack: %s", err)
+	}
+	p.b = append(p.b[:0], b...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	mp := &msgpackParser{
+		b: p.b,
+		c: &p.c,
+	}
+	v, err := mp.parseItem(0)
+	if err == nil && mp.n < len(mp.b) {
+		err = fmt.Errorf("unexpected trailing data at offset %d", mp.n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse MessagePack: %s", err)
+	}
+	return v, nil
+}
+
+// ParseMsgpack parses b containing MessagePack object.
+//
+// The function is slower than the Parser.ParseMsgpack for re-used Parser.
+func ParseMsgpack(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseMsgpack(b)
+}
+
+// msgpackTimestampExt is the extension type for timestamps.
+const msgpackTimestampExt = -1
+
+type msgpackParser struct {
+	b []byte
+
+	// n is the offset of the next object in b.
+	n int
+
+	c *cache
+}
+
+func (mp *msgpackParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", mp.n, fmt.Sprintf(format, args...))
+}
+
+// read returns the next n bytes.
+func (mp *msgpackParser) read(n uint64) ([]byte, error) {
+	if n > uint64(len(mp.b)-mp.n) {
+		return nil, mp.errorf("unexpected end of data; cannot read %d bytes", n)
+	}
+	b := mp.b[mp.n : mp.n+int(n)]
+	mp.n += int(n)
+	return b, nil
+}
+
+// readUint reads big-endian unsigned integer with the given size in bytes.
+func (mp *msgpackParser) readUint(size int) (uint64, error) {
+	b, err := mp.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, ch := range b {
+		n = n<<8 | uint64(ch)
+	}
+	return n, nil
+}
+
+func (mp *msgpackParser) newNumber(s string) *Value {
+	v := mp.c.getValue()
+	v.t = TypeNumber
+	v.s = s
+	return v
+}
+
+func (mp *msgpackParser) newString(s string) *Value {
+	v := mp.c.getValue()
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+func (mp *msgpackParser) parseItem(depth int) (*Value, error) {
+	depth++
+	if maxDepth := mp.c.cfg.maxDepth(); depth > maxDepth {
+		return nil, mp.errorf("too big depth for the nested MessagePack; it exceeds %d", maxDepth)
+	}
+	if mp.n >= len(mp.b) {
+		return nil, mp.errorf("unexpected end of data")
+	}
+	h := mp.b[mp.n]
+	mp.n++
+	switch {
+	case h <= 0x7f:
+		return mp.newNumber(strconv.Itoa(int(h))), nil
+	case h <= 0x8f:
+		return mp.parseMap(uint64(h&0x0f), depth)
+	case h <= 0x9f:
+		return mp.parseArray(uint64(h&0x0f), depth)
+	case h <= 0xbf:
+		return mp.parseStr(uint64(h & 0x1f))
+	case h >= 0xe0:
+		return mp.newNumber(strconv.Itoa(int(int8(h)))), nil
+	}
+
+	switch h {
+	case 0xc0:
+		return valueNull, nil
+	case 0xc2:
+		return valueFalse, nil
+	case 0xc3:
+		return valueTrue, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := mp.readUint(1 << (h - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := mp.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return mp.newString(base64.RawURLEncoding.EncodeToString(b)), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := mp.readUint(1 << (h - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return mp.parseExt(n)
+	case 0xca:
+		n, err := mp.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return mp.newNumber(formatCBORFloat(float64(math.Float32frombits(uint32(n))))), nil
+	case 0xcb:
+		n, err := mp.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return mp.newNumber(formatCBORFloat(math.Float64frombits(n))), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := mp.readUint(1 << (h - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return mp.newNumber(strconv.FormatUint(n, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (h - 0xd0)
+		n, err := mp.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend n.
+		shift := 64 - 8*size
+		return mp.newNumber(strconv.FormatInt(int64(n<<shift)>>shift, 10)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return mp.parseExt(1 << (h - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := mp.readUint(1 << (h - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return mp.parseStr(n)
+	case 0xdc, 0xdd:
+		n, err := mp.readUint(2 << (h - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return mp.parseArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := mp.readUint(2 << (h - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return mp.parseMap(n, depth)
+	default:
+		mp.n--
+		return nil, mp.errorf("unsupported type 0x%02x", h)
+	}
+}
+
+func (mp *msgpackParser) parseStr(n uint64) (*Value, error) {
+	b, err := mp.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, mp.errorf("invalid UTF-8 in str")
+	}
+	if err := mp.c.cfg.checkStringLen(b2s(b)); err != nil {
+		return nil, err
+	}
+	return mp.newString(b2s(b)), nil
+}
+
+func (mp *msgpackParser) parseExt(n uint64) (*Value, error) {
+	b, err := mp.read(n + 1)
+	if err != nil {
+		return nil, err
+	}
+	typ, data := int8(b[0]), b[1:]
+	if typ != msgpackTimestampExt {
+		return nil, mp.errorf("unsupported extension type %d", typ)
+	}
+	var t time.Time
+	switch len(data) {
+	case 4:
+		t = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+	case 8:
+		x := binary.BigEndian.Uint64(data)
+		t = time.Unix(int64(x&(1<<34-1)), int64(x>>34))
+	case 12:
+		t = time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data)))
+	default:
+		return nil, mp.errorf("invalid timestamp length %d", len(data))
+	}
+	return mp.newString(t.UTC().Format(time.RFC3339Nano)), nil
+}
+
+func (mp *msgpackParser) parseArray(count uint64, depth int) (*Value, error) {
+	if count > uint64(len(mp.b)-mp.n) {
+		return nil, mp.errorf("too many array items: %d", count)
+	}
+	if err := mp.c.cfg.checkArrayLen(int(count)); err != nil {
+		return nil, err
+	}
+	a := mp.c.getValue()
+	a.t = TypeArray
+	a.a = a.a[:0]
+	for i := uint64(0); i < count; i++ {
+		item, err := mp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		a.a = append(a.a, item)
+	}
+	return a, nil
+}
+
+func (mp *msgpackParser) parseMap(count uint64, depth int) (*Value, error) {
+	if count > uint64(len(mp.b)-mp.n)/2 {
+		return nil, mp.errorf("too many map entries: %d", count)
+	}
+	o := mp.c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	o.o.keysUnescaped = true
+	for i := uint64(0); i < count; i++ {
+		keyOffset := mp.n
+		k, err := mp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		key, err := cborKey(k)
+		if err != nil {
+			mp.n = keyOffset
+			return nil, mp.errorf("%s", err)
+		}
+		if mp.c.cfg != nil && mp.c.cfg.RejectDuplicateKeys && o.o.Get(key) != nil {
+			mp.n = keyOffset
+			return nil, mp.errorf("duplicate map key %q", key)
+		}
+		v, err := mp.parseItem(depth)
+		if err != nil {
+			return nil, err
+		}
+		appendObjectKV(&o.o, key, v)
+	}
+	return o, nil
+}
+
+// MarshalMsgpackTo appends MessagePack representation of v to dst
+// and returns the result.
+//
+// Integers are encoded in the smallest possible format, while other numbers
+// are encoded as float64.
+//
+// An error is returned if v contains integers not fitting 64 bits,
+// raw JSON values or invalid numbers.
+func (v *Value) MarshalMsgpackTo(dst []byte) ([]byte, error) {
+	var err error
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		dst = appendMsgpackHeader(dst, len(v.o.kvs), 0x80, 0xde)
+		for _, kv := range v.o.kvs {
+			dst = appendMsgpackStr(dst, kv.k)
+			if dst, err = kv.v.MarshalMsgpackTo(dst); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	case TypeArray:
+		dst = appendMsgpackHeader(dst, len(v.a), 0x90, 0xdc)
+		for _, item := range v.a {
+			if dst, err = item.MarshalMsgpackTo(dst); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	case TypeString:
+		return appendMsgpackStr(dst, v.s), nil
+	case TypeNumber:
+		return appendMsgpackNumber(dst, v.s)
+	case TypeFalse:
+		return append(dst, 0xc2), nil
+	case TypeTrue:
+		return append(dst, 0xc3), nil
+	case TypeNull:
+		return append(dst, 0xc0), nil
+	default:
+		return dst, fmt.Errorf("cannot marshal %s to MessagePack", v.Type())
+	}
+}
+
+// appendMsgpackHeader appends array or map header with the given fixed
+// format prefix and 16-bit format code. The 32-bit format code follows it.
+func appendMsgpackHeader(dst []byte, n int, fix, code16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, code16+1), uint32(n))
+	}
+}
+
+func appendMsgpackStr(dst []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+	return append(dst, s...)
+}
+
+func appendMsgpackNumber(dst []byte, s string) ([]byte, error) {
+	if n, ok := parseBigIntToken(s); ok {
+		switch {
+		case n.IsInt64():
+			return appendMsgpackInt(dst, n.Int64()), nil
+		case n.IsUint64():
+			return binary.BigEndian.AppendUint64(append(dst, 0xcf), n.Uint64()), nil
+		default:
+			return dst, fmt.Errorf("cannot marshal number %s to MessagePack: it doesn't fit 64 bits", s)
+		}
+	}
+	f, err := parseFloatToken(s)
+	if err != nil {
+		return dst, fmt.Errorf("cannot marshal number %q to MessagePack: %s", s, err)
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(f)), nil
+}
+
+func appendMsgpackInt(dst []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		return append(dst, byte(n))
+	case n < 0 && n >= -32:
+		return append(dst, byte(n))
+	case n >= 0 && n <= math.MaxUint8:
+		return append(dst, 0xcc, byte(n))
+	case n >= 0 && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(n))
+	case n >= 0:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), uint64(n))
+	case n >= math.MinInt8:
+		return append(dst, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(n))
+	}
+}
@@ -0,0 +1,132 @@
+package libconfig
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+func TestParseMsgpack(t *testing.T) {
+	f := func(hexData, resultExpected string) {
+		t.Helper()
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		v, err := ParseMsgpack(data)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %s: %s", hexData, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", hexData, result, resultExpected)
+		}
+	}
+
+	f("00", "0")
+	f("7f", "127")
+	f("ff", "-1")
+	f("e0", "-32")
+	f("cc80", "128")
+	f("cd0100", "256")
+	f("ce00010000", "65536")
+	f("cfffffffffffffffff", "18446744073709551615")
+	f("d080", "-128")
+	f("d1ff7f", "-129")
+	f("d2ffff7fff", "-32769")
+	f("d38000000000000000", "-9223372036854775808")
+	f("ca3fc00000", "1.5")
+	f("cb3ff199999999999a", "1.1")
+	f("cb7ff0000000000000", "inf")
+	f("c0", "null")
+	f("c2", "false")
+	f("c3", "true")
+	f("a0", `""`)
+	f("a3616263", `"abc"`)
+	f("d903616263", `"abc"`)
+	f("c40401020304", `"AQIDBA"`)
+	f("90", "[]")
+	f("93010203", "[1,2,3]")
+	f("dc0002a161a162", `["a","b"]`)
+	f("80", "{}")
+	f("82a161010102", `{"a":1,"1":2}`)
+	f("81a1619202a162", `{"a":[2,"b"]}`)
+	f("d6ff5a4d6e80", `"2018-01-04T00:00:00Z"`)
+	f("d7ff000000045a4d6e80", `"2018-01-04T00:00:00.000000001Z"`)
+
+	// Errors
+	for _, hexData := range []string{
+		"",
+		"c1",
+		"cc",
+		"a3616263ff",
+		"a2c328",
+		"93",
+		"81900001",
+		"d401ff",
+		"c70100",
+		"0000",
+	} {
+		data, err := hex.DecodeString(hexData)
+		if err != nil {
+			t.Fatalf("cannot decode hex %q: %s", hexData, err)
+		}
+		if _, err := ParseMsgpack(data); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", hexData)
+		}
+	}
+
+	// Random input must not result in panic.
+	r := rand.New(rand.NewSource(1))
+	var p Parser
+	for i := 0; i < 10000; i++ {
+		b := make([]byte, r.Intn(16))
+		r.Read(b)
+		_, _ = p.ParseMsgpack(b)
+	}
+}
+
+func TestMarshalMsgpackTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse("x = " + s + ";").Get("x")
+		b, err := v.MarshalMsgpackTo(nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", s, err)
+		}
+		if result := hex.EncodeToString(b); result != resultExpected {
+			t.Fatalf("unexpected result for %s; got %s; want %s", s, result, resultExpected)
+		}
+
+		// Verify the result is read back to the same value.
+		v2, err := ParseMsgpack(b)
+		if err != nil {
+			t.Fatalf("cannot parse the marshaled MessagePack %x: %s", b, err)
+		}
+		if !EqualExcept(v, v2) {
+			t.Fatalf("unexpected value after round trip; got %s; want %s", v2, v)
+		}
+	}
+
+	f("0", "00")
+	f("-1", "ff")
+	f("-33", "d0df")
+	f("200", "ccc8")
+	f("1000", "cd03e8")
+	f("-1000", "d1fc18")
+	f("0x1F", "1f")
+	f("18446744073709551615L", "cfffffffffffffffff")
+	f("1.1", "cb3ff199999999999a")
+	f(".5", "cb3fe0000000000000")
+	f("5.", "cb4014000000000000")
+	f(`"abc"`, "a3616263")
+	f("true", "c3")
+	f("false", "c2")
+	f("[1, [2, 3]]", "9201920203")
+	f(`{a = 1; b = ["x"];}`, "82a16101a16291a178")
+
+	// Errors
+	v := MustParse("x = 18446744073709551616L;").Get("x")
+	if _, err := v.MarshalMsgpackTo(nil); err == nil {
+		t.Fatalf("expecting non-nil error for too big integer")
+	}
+}
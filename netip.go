@@ -0,0 +1,154 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Addr returns the underlying IP address for the v.
+//
+// The address is parsed with netip.ParseAddr, so IPv4 addresses
+// with leading zeros and surrounding whitespace are rejected.
+//
+// Use GetAddr if you don't need error handling.
+func (v *Value) Addr() (netip.Addr, error) {
+	if v.Type() != TypeString {
+		return netip.Addr{}, fmt.Errorf("value doesn't contain IP address; it contains %s", v.Type())
+	}
+	return netip.ParseAddr(v.s)
+}
+
+// AddrPort returns the underlying IP address and port for the v.
+//
+// The value must have ip:port or [ip]:port form.
+//
+// Use GetAddrPort if you don't need error handling.
+func (v *Value) AddrPort() (netip.AddrPort, error) {
+	if v.Type() != TypeString {
+		return netip.AddrPort{}, fmt.Errorf("value doesn't contain IP address with port; it contains %s", v.Type())
+	}
+	return netip.ParseAddrPort(v.s)
+}
+
+// Prefix returns the underlying IP prefix for the v.
+//
+// The value must have CIDR notation such as 10.0.0.0/8 or 2001:db8::/32.
+//
+// Use GetPrefix if you don't need error handling.
+func (v *Value) Prefix() (netip.Prefix, error) {
+	if v.Type() != TypeString {
+		return netip.Prefix{}, fmt.Errorf("value doesn't contain IP prefix; it contains %s", v.Type())
+	}
+	return netip.ParsePrefix(v.s)
+}
+
+// GetAddr returns IP address by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// Invalid netip.Addr is returned for non-existing keys path
+// or for invalid value.
+func (v *Value) GetAddr(keys ...string) netip.Addr {
+	v = v.Get(keys...)
+	if v == nil {
+		return netip.Addr{}
+	}
+	a, err := v.Addr()
+	if err != nil {
+		return netip.Addr{}
+	}
+	return a
+}
+
+// GetAddrPort returns IP address with port by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// Invalid netip.AddrPort is returned for non-existing keys path
+// or for invalid value.
+func (v *Value) GetAddrPort(keys ...string) netip.AddrPort {
+	v = v.Get(keys...)
+	if v == nil {
+		return netip.AddrPort{}
+	}
+	ap, err := v.AddrPort()
+	if err != nil {
+		return netip.AddrPort{}
+	}
+	return ap
+}
+
+// GetPrefix returns IP prefix by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// Invalid netip.Prefix is returned for non-existing keys path
+// or for invalid value.
+func (v *Value) GetPrefix(keys ...string) netip.Prefix {
+	v = v.Get(keys...)
+	if v == nil {
+		return netip.Prefix{}
+	}
+	p, err := v.Prefix()
+	if err != nil {
+		return netip.Prefix{}
+	}
+	return p
+}
+
+// NewAddr returns new string value containing ip.
+//
+// The returned string is valid until Reset is called on a.
+func (a *Arena) NewAddr(ip netip.Addr) *Value {
+	return a.newAppendedString(ip.AppendTo)
+}
+
+// NewAddrPort returns new string value containing ap.
+//
+// The returned string is valid until Reset is called on a.
+func (a *Arena) NewAddrPort(ap netip.AddrPort) *Value {
+	return a.newAppendedString(ap.AppendTo)
+}
+
+// NewPrefix returns new string value containing p.
+//
+// The returned string is valid until Reset is called on a.
+func (a *Arena) NewPrefix(p netip.Prefix) *Value {
+	return a.newAppendedString(p.AppendTo)
+}
+
+// newAppendedString returns new string value containing the bytes appended
+// by appendTo, which mustn't require escaping.
+func (a *Arena) newAppendedString(appendTo func(dst []byte) []byte) *Value {
+	v := a.c.getValue()
+	v.t = TypeString
+	bLen := len(a.b)
+	a.b = appendTo(a.b)
+	v.s = b2s(a.b[bLen:])
+	return v
+}
@@ -0,0 +1,67 @@
+package libconfig
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestValueNetip(t *testing.T) {
+	v := MustParse(`
+		ip4 = "10.1.2.3";
+		ip6 = "2001:db8::1";
+		ap = "[::1]:8080";
+		prefix = "10.0.0.0/8";
+		bad_ip = "010.1.2.3";
+		spaced = " 10.1.2.3";
+		bad_prefix = "10.0.0.0";
+		num = 123;
+	`)
+
+	if a := v.GetAddr("ip4"); a != netip.MustParseAddr("10.1.2.3") {
+		t.Fatalf("unexpected addr: %s", a)
+	}
+	if a := v.GetAddr("ip6"); a != netip.MustParseAddr("2001:db8::1") {
+		t.Fatalf("unexpected addr: %s", a)
+	}
+	if ap := v.GetAddrPort("ap"); ap != netip.MustParseAddrPort("[::1]:8080") {
+		t.Fatalf("unexpected addr port: %s", ap)
+	}
+	if p := v.GetPrefix("prefix"); p != netip.MustParsePrefix("10.0.0.0/8") {
+		t.Fatalf("unexpected prefix: %s", p)
+	}
+
+	for _, key := range []string{"bad_ip", "spaced", "num", "missing"} {
+		if a := v.GetAddr(key); a.IsValid() {
+			t.Fatalf("expecting invalid addr for %q; got %s", key, a)
+		}
+	}
+	if _, err := v.Get("bad_ip").Addr(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if _, err := v.Get("num").AddrPort(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if _, err := v.Get("bad_prefix").Prefix(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if ap := v.GetAddrPort("ip4"); ap.IsValid() {
+		t.Fatalf("expecting invalid addr port; got %s", ap)
+	}
+}
+
+func TestArenaNetip(t *testing.T) {
+	var a Arena
+	o := a.NewObject()
+	o.Set("ip", a.NewAddr(netip.MustParseAddr("fe80::1")))
+	o.Set("ap", a.NewAddrPort(netip.MustParseAddrPort("1.2.3.4:80")))
+	o.Set("prefix", a.NewPrefix(netip.MustParsePrefix("192.168.0.0/16")))
+
+	s := o.String()
+	sExpected := `{"ip":"fe80::1","ap":"1.2.3.4:80","prefix":"192.168.0.0/16"}`
+	if s != sExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", s, sExpected)
+	}
+	if ip := o.GetAddr("ip"); ip != netip.MustParseAddr("fe80::1") {
+		t.Fatalf("unexpected addr: %s", ip)
+	}
+}
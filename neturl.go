@@ -0,0 +1,136 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// IP returns the underlying IP address for the v.
+//
+// Use GetIP if you don't need error handling. See also Value.Addr.
+func (v *Value) IP() (net.IP, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain IP address; it contains %s", v.Type())
+	}
+	ip := net.ParseIP(v.s)
+	if ip == nil {
+		return nil, fmt.Errorf("cannot parse IP address %q", v.s)
+	}
+	return ip, nil
+}
+
+// CIDR returns the underlying IP network for the v.
+//
+// The value must have CIDR notation such as 10.0.0.0/8 or 2001:db8::/32.
+// Host bits are cleared in the returned network.
+//
+// Use GetCIDR if you don't need error handling. See also Value.Prefix.
+func (v *Value) CIDR() (*net.IPNet, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain CIDR; it contains %s", v.Type())
+	}
+	_, ipNet, err := net.ParseCIDR(v.s)
+	if err != nil {
+		return nil, err
+	}
+	return ipNet, nil
+}
+
+// URL returns the underlying absolute URL for the v.
+//
+// An error is returned if the URL has no scheme, e.g. for "example.com/path".
+//
+// Use GetURL if you don't need error handling.
+func (v *Value) URL() (*url.URL, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain URL; it contains %s", v.Type())
+	}
+	u, err := url.Parse(v.s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("missing scheme in URL %q", v.s)
+	}
+	if u.Host == "" && u.Opaque == "" && u.Path == "" {
+		return nil, fmt.Errorf("missing host in URL %q", v.s)
+	}
+	return u, nil
+}
+
+// GetIP returns IP address by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path or for invalid value.
+func (v *Value) GetIP(keys ...string) net.IP {
+	v = v.Get(keys...)
+	if v == nil {
+		return nil
+	}
+	ip, err := v.IP()
+	if err != nil {
+		return nil
+	}
+	return ip
+}
+
+// GetCIDR returns IP network by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path or for invalid value.
+func (v *Value) GetCIDR(keys ...string) *net.IPNet {
+	v = v.Get(keys...)
+	if v == nil {
+		return nil
+	}
+	ipNet, err := v.CIDR()
+	if err != nil {
+		return nil
+	}
+	return ipNet
+}
+
+// GetURL returns absolute URL by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path or for invalid value.
+func (v *Value) GetURL(keys ...string) *url.URL {
+	v = v.Get(keys...)
+	if v == nil {
+		return nil
+	}
+	u, err := v.URL()
+	if err != nil {
+		return nil
+	}
+	return u
+}
@@ -0,0 +1,57 @@
+package libconfig
+
+import (
+	"net"
+	"testing"
+)
+
+func TestValueNetURL(t *testing.T) {
+	v := MustParse(`
+		ip4 = "10.1.2.3";
+		ip6 = "2001:db8::1";
+		bad_ip = "010.1.2.3";
+		cidr = "10.1.2.3/8";
+		bad_cidr = "10.0.0.0";
+		url = "https://user@example.com:8443/path?q=1";
+		unix = "unix:///var/run/app.sock";
+		relative = "example.com/path";
+		bad_url = "http://[::1";
+		num = 123;
+	`)
+
+	if ip := v.GetIP("ip4"); !ip.Equal(net.IPv4(10, 1, 2, 3)) {
+		t.Fatalf("unexpected ip: %s", ip)
+	}
+	if ip := v.GetIP("ip6"); !ip.Equal(net.ParseIP("2001:db8::1")) {
+		t.Fatalf("unexpected ip: %s", ip)
+	}
+	if ipNet := v.GetCIDR("cidr"); ipNet == nil || ipNet.String() != "10.0.0.0/8" {
+		t.Fatalf("unexpected cidr: %s", ipNet)
+	}
+	u := v.GetURL("url")
+	if u == nil || u.Scheme != "https" || u.Hostname() != "example.com" || u.Port() != "8443" || u.Path != "/path" {
+		t.Fatalf("unexpected url: %s", u)
+	}
+	if u := v.GetURL("unix"); u == nil || u.Path != "/var/run/app.sock" {
+		t.Fatalf("unexpected url: %s", u)
+	}
+
+	for _, key := range []string{"bad_ip", "num", "missing"} {
+		if ip := v.GetIP(key); ip != nil {
+			t.Fatalf("expecting nil ip for %q; got %s", key, ip)
+		}
+	}
+	for _, key := range []string{"bad_cidr", "ip4", "num"} {
+		if ipNet := v.GetCIDR(key); ipNet != nil {
+			t.Fatalf("expecting nil cidr for %q; got %s", key, ipNet)
+		}
+	}
+	for _, key := range []string{"relative", "bad_url", "num"} {
+		if u := v.GetURL(key); u != nil {
+			t.Fatalf("expecting nil url for %q; got %s", key, u)
+		}
+		if _, err := v.Get(key).URL(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumberMode defines how Parser handles numbers.
+//
+// Numbers are always stored as tokens, so they are marshaled back
+// unchanged. The mode only defines which tokens are accepted.
+type NumberMode int
+
+const (
+	// NumberDeferred stores number tokens without conversion. Tokens are
+	// converted on access via Int, Float64, GetInt, etc. This is the fastest
+	// mode, but malformed numbers are detected only on access.
+	NumberDeferred NumberMode = iota
+
+	// NumberFloat64 rejects numbers, which cannot be converted to float64,
+	// including numbers out of float64 range.
+	NumberFloat64
+
+	// NumberInt64 rejects numbers, which cannot be converted exactly to int64
+	// or uint64, including fractional numbers, so integer-heavy documents
+	// cannot lose precision via float64 conversion.
+	NumberInt64
+)
+
+// String returns string representation for m.
+func (m NumberMode) String() string {
+	switch m {
+	case NumberDeferred:
+		return "deferred"
+	case NumberFloat64:
+		return "float64"
+	case NumberInt64:
+		return "int64"
+	default:
+		return fmt.Sprintf("NumberMode(%d)", int(m))
+	}
+}
+
+// checkNumber checks number token s according to cfg.NumberMode.
+func (cfg *ParserConfig) checkNumber(s string) error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.NumberMode {
+	case NumberFloat64:
+		if _, err := parseFloatToken(s); err != nil {
+			return fmt.Errorf("cannot convert %q to float64: %s", s, err)
+		}
+	case NumberInt64:
+		if _, err := parseIntToken(s); err == nil {
+			return nil
+		}
+		if _, err := parseUintToken(s); err != nil {
+			return fmt.Errorf("%q isn't an integer fitting int64 or uint64", s)
+		}
+	}
+	return nil
+}
+
+// parseFloatToken parses libconfig number token s into float64.
+func parseFloatToken(s string) (float64, error) {
+	if isHexToken(s) {
+		n, err := strconv.ParseUint(s[2:], 16, 64)
+		return float64(n), err
+	}
+	return strconv.ParseFloat(strings.TrimSuffix(s, "L"), 64)
+}
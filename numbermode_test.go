@@ -0,0 +1,63 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParserNumberMode(t *testing.T) {
+	f := func(mode NumberMode, s string, ok bool) {
+		t.Helper()
+		p := Parser{
+			Config: ParserConfig{
+				NumberMode: mode,
+			},
+		}
+		v, err := p.Parse("a = " + s + ";")
+		if !ok {
+			if err == nil {
+				t.Fatalf("expecting non-nil error for %q in %s mode", s, mode)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %q in %s mode: %s", s, mode, err)
+		}
+		// Number tokens must be preserved.
+		if result := v.Get("a").String(); s[0] != '[' && result != s {
+			t.Fatalf("unexpected token for %q in %s mode; got %s", s, mode, result)
+		}
+	}
+
+	for _, mode := range []NumberMode{NumberDeferred, NumberFloat64, NumberInt64} {
+		f(mode, "0", true)
+		f(mode, "-123", true)
+		f(mode, "0x1F", true)
+		f(mode, "9223372036854775807", true)
+		f(mode, "18446744073709551615", true)
+		f(mode, "[1, 2, 3]", true)
+	}
+
+	// Deferred mode accepts malformed numbers.
+	f(NumberDeferred, "1-2", true)
+	f(NumberDeferred, "1.5", true)
+	f(NumberDeferred, "1e400", true)
+	f(NumberDeferred, "100000000000000000000L", true)
+
+	f(NumberFloat64, "1.5", true)
+	f(NumberFloat64, "-1.5e10", true)
+	f(NumberFloat64, "nan", true)
+	f(NumberFloat64, "-inf", true)
+	f(NumberFloat64, "100000000000000000000L", true)
+	f(NumberFloat64, "1-2", false)
+	f(NumberFloat64, "1e400", false)
+	f(NumberFloat64, "[1, 2-3]", false)
+
+	f(NumberInt64, "123L", true)
+	f(NumberInt64, "1.5", false)
+	f(NumberInt64, "1e3", false)
+	f(NumberInt64, "nan", false)
+	f(NumberInt64, "18446744073709551616", false)
+	f(NumberInt64, "-9223372036854775809", false)
+	f(NumberInt64, "100000000000000000000L", false)
+	f(NumberInt64, "[1, 2.5]", false)
+}
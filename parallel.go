@@ -0,0 +1,247 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// defaultMinParallelItems is the default ParallelParser.MinParallelItems.
+const defaultMinParallelItems = 1024
+
+// ParallelParser parses documents containing huge arrays on multiple CPU cores.
+//
+// Top-level arrays and lists with at least MinParallelItems items are
+// pre-scanned for item boundaries, and then the items are split across
+// worker goroutines. The remaining values are parsed sequentially.
+// This speeds up parsing of documents such as `records = ( {...}, ... );`.
+//
+// @include directives aren't supported.
+//
+// ParallelParser may be re-used for subsequent parsing.
+// ParallelParser cannot be used from concurrent goroutines.
+type ParallelParser struct {
+	// Config contains optional parser settings.
+	Config ParserConfig
+
+	// Concurrency is the maximum number of worker goroutines per array.
+	//
+	// runtime.GOMAXPROCS(0) is used by default.
+	Concurrency int
+
+	// MinParallelItems is the minimum number of items in the array
+	// for parallel parsing.
+	//
+	// 1024 is used by default.
+	MinParallelItems int
+
+	b       []byte
+	c       cache
+	workers []cache
+	items   []parallelItem
+}
+
+type parallelItem struct {
+	// raw is the input tail starting from the item.
+	raw string
+
+	// end is the input tail after the item.
+	end string
+}
+
+// Parse parses s.
+//
+// The returned value is valid until the next call to Parse.
+func (pp *ParallelParser) Parse(s string) (*Value, error) {
+	if pp.Config.StrictRFC8259 {
+		return nil, fmt.Errorf("cannot parse libconfig: StrictRFC8259 isn't supported by ParallelParser")
+	}
+	if err := pp.Config.checkInputSize(len(s)); err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	s, err := decodeInput(s, pp.Config.TranscodeInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	pp.b = append(pp.b[:0], s...)
+	pp.c.reset()
+	pp.c.cfg = &pp.Config
+	pp.c.src = b2s(pp.b)
+	pp.c.unwrapped = true
+	for i := range pp.workers {
+		// Worker caches are initialized lazily by parseArray.
+		pp.workers[i].reset()
+	}
+
+	var root lazyObject
+	tail, err := root.index(&pp.c, b2s(pp.b), true)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s; unparsed tail: %q", err, startEndString(tail))
+	}
+	o := pp.c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	for i := range root.entries {
+		e := &root.entries[i]
+		var v *Value
+		if len(e.raw) > 0 && (e.raw[0] == '[' || e.raw[0] == '(') {
+			v, err = pp.parseArray(&root, e)
+		} else {
+			v, err = root.parseEntry(&pp.c, e)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+		}
+		appendObjectKV(&o.o, e.key, v)
+	}
+	return o, nil
+}
+
+// ParseBytes parses b.
+//
+// The returned value is valid until the next call to Parse.
+func (pp *ParallelParser) ParseBytes(b []byte) (*Value, error) {
+	return pp.Parse(b2s(b))
+}
+
+func (pp *ParallelParser) parseArray(root *lazyObject, e *lazyEntry) (*Value, error) {
+	workers := pp.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers <= 1 {
+		return root.parseEntry(&pp.c, e)
+	}
+
+	// Locate item boundaries without parsing the items.
+	items, err := scanArrayItems(pp.items[:0], e.raw[1:])
+	pp.items = items
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse array for key %q: %s", e.key, err)
+	}
+	if err := pp.Config.checkArrayLen(len(items)); err != nil {
+		return nil, fmt.Errorf("cannot parse array for key %q: %s", e.key, err)
+	}
+	minItems := pp.MinParallelItems
+	if minItems <= 0 {
+		minItems = defaultMinParallelItems
+	}
+	if len(items) < minItems {
+		return root.parseEntry(&pp.c, e)
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	a := pp.c.getValue()
+	a.t = TypeArray
+	if cap(a.a) < len(items) {
+		a.a = make([]*Value, len(items))
+	}
+	a.a = a.a[:len(items)]
+
+	for len(pp.workers) < workers {
+		pp.workers = append(pp.workers, cache{})
+	}
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		c := &pp.workers[w]
+		if c.cfg == nil {
+			c.cfg = &pp.Config
+			c.src = pp.c.src
+			c.unwrapped = true
+		}
+		start := w * len(items) / workers
+		end := (w + 1) * len(items) / workers
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			errs[w] = parseArrayItems(c, a.a[start:end], items[start:end], start)
+		}(w, start, end)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse array for key %q: %s", e.key, err)
+		}
+	}
+	return a, nil
+}
+
+// parseArrayItems parses items into dst with c.
+//
+// offset is the index of the first item in the array.
+func parseArrayItems(c *cache, dst []*Value, items []parallelItem, offset int) error {
+	for i := range items {
+		item := &items[i]
+		// Items are nested into the top-level array.
+		v, tail, err := parseValue(item.raw, c, "", 1)
+		if err != nil {
+			return fmt.Errorf("cannot parse item #%d at %s: %s", offset+i, c.position(tail), err)
+		}
+		if len(skipJunk(tail)) != len(skipJunk(item.end)) {
+			return fmt.Errorf("unexpected tail after item #%d at %s", offset+i, c.position(tail))
+		}
+		dst[i] = v
+	}
+	return nil
+}
+
+// scanArrayItems appends item boundaries for the array in s to dst
+// and returns the result.
+//
+// s must start after the opening '[' or '('.
+func scanArrayItems(dst []parallelItem, s string) ([]parallelItem, error) {
+	for {
+		s = skipJunk(s)
+		if len(s) == 0 {
+			return dst, fmt.Errorf("unexpected end of array")
+		}
+		if s[0] == ']' || s[0] == ')' {
+			return dst, nil
+		}
+		end, err := skipLazyValue(s)
+		if err != nil {
+			return dst, fmt.Errorf("cannot parse item #%d: %s", len(dst), err)
+		}
+		if len(end) == len(s) {
+			return dst, fmt.Errorf("unexpected char at item #%d: %q", len(dst), s[:1])
+		}
+		dst = append(dst, parallelItem{
+			raw: s,
+			end: end,
+		})
+		s = skipJunk(end)
+		if len(s) > 0 && s[0] == ',' {
+			s = s[1:]
+		} else if len(s) > 0 && s[0] != ']' && s[0] != ')' {
+			return dst, fmt.Errorf("missing ',' after item #%d", len(dst)-1)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParallelParser(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("name = \"records\";\nrecords = (\n")
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `{id = %d; tags = ["a,b", "c)"]; nested = {x = [%d, %d];};}`, i, i, i+1)
+	}
+	sb.WriteString("\n);\nsmall = [1, 2, 3];\nempty = ();\n")
+	s := sb.String()
+
+	vExpected := MustParse(s)
+	for _, concurrency := range []int{1, 2, 3, 8, 200} {
+		pp := &ParallelParser{
+			Concurrency:      concurrency,
+			MinParallelItems: 10,
+		}
+		// Re-use the parser in order to verify worker caches are properly reset.
+		for i := 0; i < 2; i++ {
+			v, err := pp.Parse(s)
+			if err != nil {
+				t.Fatalf("unexpected error for concurrency=%d: %s", concurrency, err)
+			}
+			if !EqualExcept(v, vExpected) {
+				t.Fatalf("unexpected value for concurrency=%d; got %s; want %s", concurrency, v, vExpected)
+			}
+		}
+	}
+}
+
+func TestParallelParserErrors(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		pp := &ParallelParser{
+			Concurrency:      4,
+			MinParallelItems: 2,
+		}
+		if _, err := pp.Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+
+	f(`a = [1, 2, 3`)
+	f(`a = [1, 2 3];`)
+	f(`a = [1, 2, x];`)
+	f(`a = ({b = 1;}, {b = ;}, {b = 3;});`)
+	f(`a = [1, }, 3];`)
+	f(`@include "foo.cfg"`)
+	f(`a = 1 b = 2;`)
+}
+
+func TestParallelParserMaxArrayElements(t *testing.T) {
+	s := "a = [" + strings.Repeat("1, ", 2000) + "1];"
+	pp := &ParallelParser{
+		Concurrency:      4,
+		MinParallelItems: 10,
+		Config: ParserConfig{
+			MaxArrayElements: 100,
+		},
+	}
+	_, err := pp.Parse(s)
+	if err == nil || !strings.Contains(err.Error(), "MaxArrayElements=100") {
+		t.Fatalf("unexpected error; got %v; want MaxArrayElements error", err)
+	}
+
+	pp.Config.MaxArrayElements = 2001
+	v, err := pp.Parse(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := len(v.GetArray("a")); n != 2001 {
+		t.Fatalf("unexpected number of items; got %d; want 2001", n)
+	}
+}
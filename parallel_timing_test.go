@@ -0,0 +1,41 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func BenchmarkParallelParser(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("records = (\n")
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `{id = %d; name = "record %d"; weight = %d.5; tags = ["a", "b"]; enabled = true;}`, i, i, i)
+	}
+	sb.WriteString("\n);\n")
+	s := sb.String()
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(s)))
+		var p Parser
+		for i := 0; i < b.N; i++ {
+			if _, err := p.Parse(s); err != nil {
+				panic(fmt.Errorf("unexpected error: %s", err))
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(s)))
+		var pp ParallelParser
+		for i := 0; i < b.N; i++ {
+			if _, err := pp.Parse(s); err != nil {
+				panic(fmt.Errorf("unexpected error: %s", err))
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseOne parses the first value in s and returns it together with
+// the remaining tail of s.
+//
+// This allows parsing streams of multiple documents such as
+// `{a = 1;} {b = 2;}`. The value may be an object in braces, an array
+// or a scalar. An optional ';' or ',' after the value is skipped together
+// with the following whitespace and comments, so an empty tail means
+// there are no more documents.
+//
+// UTF-8 BOM at the start of s is stripped. If Config.StrictRFC8259 is set,
+// the value must be RFC 8259 JSON, and only whitespace is skipped after it,
+// so s may contain a stream of JSON texts such as `{"a":1} {"b":2}`.
+//
+// The returned value is valid until the next call to Parse*.
+// The returned tail references s. TranscodeInput isn't supported by ParseOne,
+// so an error is returned for UTF-16 and UTF-32 input.
+func (p *Parser) ParseOne(s string) (*Value, string, error) {
+	p.mustNotBeScoped()
+	poisonBytes(p.b[:cap(p.b)])
+
+	if err := p.Config.checkInputSize(len(s)); err != nil {
+		return nil, s, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	// The BOM may be only stripped from the start of s, so the tail
+	// still references s.
+	in, err := decodeInput(s, false)
+	if err != nil {
+		return nil, s, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	p.b = append(p.b[:0], in...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+	p.c.src = b2s(p.b)
+	p.c.rootFile = p.f
+	p.c.bundle = p.bundle
+	p.c.unwrapped = true
+
+	if p.Config.StrictRFC8259 {
+		return p.parseOneStrictJSON(s)
+	}
+
+	src := skipJunk(p.c.src)
+	if len(src) == 0 {
+		return nil, s, p.c.syntaxError(fmt.Errorf("cannot parse empty string"), src)
+	}
+	v, tail, err := parseValue(src, &p.c, p.d, 0)
+	if err != nil {
+		return nil, s, p.c.syntaxError(err, tail)
+	}
+	tail = skipJunk(tail)
+	if len(tail) > 0 && (tail[0] == ';' || tail[0] == ',') {
+		tail = skipJunk(tail[1:])
+	}
+	tail = skipTrailingComment(tail)
+	return v, s[len(s)-len(tail):], nil
+}
+
+func (p *Parser) parseOneStrictJSON(s string) (*Value, string, error) {
+	src := skipJSONWS(p.c.src)
+	if len(src) == 0 {
+		return nil, s, p.c.syntaxError(fmt.Errorf("empty JSON text"), src)
+	}
+	v, tail, err := parseStrictJSONValue(src, &p.c, 0)
+	if err != nil {
+		return nil, s, p.c.syntaxError(err, tail)
+	}
+	switch v.Type() {
+	case TypeNumber, TypeTrue, TypeFalse, TypeNull:
+		// Scalars must be delimited by whitespace, so 0x10 isn't parsed as 0.
+		if len(tail) > 0 && len(skipJSONWS(tail)) == len(tail) {
+			return nil, s, p.c.syntaxError(fmt.Errorf("unexpected data after JSON value"), tail)
+		}
+	}
+	tail = skipJSONWS(tail)
+	return v, s[len(s)-len(tail):], nil
+}
+
+// ParseOneBytes parses the first value in b and returns it together with
+// the remaining tail of b.
+//
+// See Parser.ParseOne for details.
+func (p *Parser) ParseOneBytes(b []byte) (*Value, []byte, error) {
+	v, tail, err := p.ParseOne(b2s(b))
+	return v, b[len(b)-len(tail):], err
+}
+
+// skipTrailingComment returns an empty string if s contains only
+// a line comment without the trailing newline, which isn't skipped by skipJunk.
+func skipTrailingComment(s string) string {
+	if (strings.HasPrefix(s, "#") || strings.HasPrefix(s, "//")) && strings.IndexByte(s, '\n') < 0 {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserParseOne(t *testing.T) {
+	var p Parser
+	s := "{a = 1;} /* doc 2 */ {b = [1, 2];};\n[3, 4], \"x\" # trailing comment"
+	var results []string
+	for len(s) > 0 {
+		v, tail, err := p.ParseOne(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		results = append(results, v.String())
+		s = tail
+	}
+	resultsExpected := []string{`{"a":1}`, `{"b":[1,2]}`, `[3,4]`, `"x"`}
+	if len(results) != len(resultsExpected) {
+		t.Fatalf("unexpected number of documents; got %d; want %d; results: %q", len(results), len(resultsExpected), results)
+	}
+	for i := range results {
+		if results[i] != resultsExpected[i] {
+			t.Fatalf("unexpected document #%d; got %s; want %s", i, results[i], resultsExpected[i])
+		}
+	}
+
+	b := []byte(`{x = 1;} {y = 2;}`)
+	v, tail, err := p.ParseOneBytes(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v.GetInt("x") != 1 {
+		t.Fatalf("unexpected value: %s", v)
+	}
+	if string(tail) != `{y = 2;}` {
+		t.Fatalf("unexpected tail; got %q; want %q", tail, `{y = 2;}`)
+	}
+
+	// Errors
+	for _, s := range []string{``, ` /* */ `, `{a = 1;`, `{a = ;}`} {
+		if _, _, err := p.ParseOne(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	_, _, err = p.ParseOne("{\n  a = ;\n}")
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("expecting *SyntaxError; got %T: %v", err, err)
+	}
+	if se.Line != 2 {
+		t.Fatalf("unexpected error line; got %d; want 2", se.Line)
+	}
+}
+
+func TestParserParseOneDecodeInput(t *testing.T) {
+	var p Parser
+
+	// UTF-8 BOM is stripped.
+	v, tail, err := p.ParseOne("\xef\xbb\xbf{a = 1;} {b = 2;}")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v.GetInt("a") != 1 || tail != "{b = 2;}" {
+		t.Fatalf("unexpected result; got %s, tail %q", v, tail)
+	}
+
+	// UTF-16 isn't supported.
+	if _, _, err := p.ParseOne("\xff\xfe{\x00}\x00"); err == nil {
+		t.Fatalf("expecting non-nil error for UTF-16 input")
+	}
+
+	// RFC 8259 JSON texts.
+	p.Config.StrictRFC8259 = true
+	s := "\xef\xbb\xbf{\"a\":1} [2]\n\"x\""
+	var results []string
+	for len(s) > 0 {
+		v, tail, err := p.ParseOne(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		results = append(results, v.String())
+		s = tail
+	}
+	if r := strings.Join(results, " "); r != `{"a":1} [2] "x"` {
+		t.Fatalf("unexpected results; got %s", r)
+	}
+	for _, s := range []string{`0x10`, `1L`, `{a = 1;}`, `[1,]`, ``, ` `, `nullx`} {
+		if v, _, err := p.ParseOne(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q; got %s", s, v)
+		}
+	}
+}
+
+func TestParserRejectTrailingData(t *testing.T) {
+	f := func(s string, rejectTrailingData, okExpected bool) {
+		t.Helper()
+		p := Parser{
+			Config: ParserConfig{
+				RejectTrailingData: rejectTrailingData,
+			},
+		}
+		_, err := p.Parse(s)
+		if ok := err == nil; ok != okExpected {
+			t.Fatalf("unexpected result for %q with RejectTrailingData=%v; got error %v", s, rejectTrailingData, err)
+		}
+	}
+
+	f(`a = 1;`, true, true)
+	f("a = 1; # comment\n", true, true)
+	f("a = 1; /* comment */ \n\t", true, true)
+	f(`a = 1;};b = 2;`, false, true)
+	f(`a = 1;};b = 2;`, true, false)
+	f(`a = 1;} b = 2;`, false, false)
+	f(`a = 1;} b = 2;`, true, false)
+}
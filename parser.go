@@ -49,8 +49,20 @@ type Parser struct {
 	// the file dir path for parse
 	d string
 
+	// f is the path to the parsed file if known.
+	f string
+
+	// bundle is the bundle for resolving @include directives if set.
+	bundle *Bundle
+
+	// Config contains optional parser settings.
+	Config ParserConfig
+
 	// c is a cache for json values.
 	c cache
+
+	// scoped is set while WithScope callback is running.
+	scoped bool
 }
 
 // Parse parses s containing JSON.
@@ -59,6 +71,26 @@ type Parser struct {
 //
 // Use Scanner if a stream of JSON values must be parsed.
 func (p *Parser) Parse(s string) (*Value, error) {
+	return p.parse(s, false)
+}
+
+func (p *Parser) parse(s string, classic bool) (*Value, error) {
+	p.mustNotBeScoped()
+	// Catch values, which are used after the Parser re-use, in poison mode.
+	poisonBytes(p.b[:cap(p.b)])
+
+	if err := p.Config.checkInputSize(len(s)); err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+
+	s, err := decodeInput(s, p.Config.TranscodeInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse libconfig: %s", err)
+	}
+	if p.Config.StrictRFC8259 {
+		return p.parseStrictJSON(s)
+	}
+
 	// Add root node
 	s = "{" + s + "};"
 
@@ -66,14 +98,24 @@ func (p *Parser) Parse(s string) (*Value, error) {
 	s = skipJunk(s)
 	p.b = append(p.b[:0], s...)
 	p.c.reset()
-
-	v, tail, err := parseValue(b2s(p.b), &p.c, p.d, 0)
+	p.c.cfg = &p.Config
+	p.c.src = b2s(p.b)
+	p.c.rootFile = p.f
+	p.c.bundle = p.bundle
+	p.c.classic = classic
+
+	// The root node doesn't count towards the nesting depth.
+	v, tail, err := parseValue(b2s(p.b), &p.c, p.d, -1)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse libconfig: %s; unparsed tail: %q", err, startEndString(tail))
+		return nil, p.c.syntaxError(err, tail)
 	}
 	//tail = skipWS(tail)
 	tail = skipJunk(tail)
 	tail = strings.TrimSpace(tail)
+	if p.Config.RejectTrailingData && tail != ";" {
+		// Only the ';' added to the root node may follow the document.
+		return nil, p.c.syntaxError(fmt.Errorf("unexpected data after the document"), tail)
+	}
 	if /*len(tail) > 0*/ len(tail) != 1 && tail[0] != ';' {
 		return nil, fmt.Errorf("unexpected tail: %q", startEndString(tail))
 	}
@@ -102,15 +144,55 @@ func (p *Parser) ParseFile(path string) (*Value, error) {
 	}
 
 	p.d = filepath.Dir(path)
+	p.f = path
 	return p.ParseBytes(b)
 }
 
 type cache struct {
 	vs []Value
+
+	// cfg is the config for the current parse. It may be nil.
+	cfg *ParserConfig
+
+	// src is the input for the current parse.
+	src string
+
+	// rootFile is the path to the parsed file if known.
+	rootFile string
+
+	// includes contains files pulled via @include during the current parse.
+	includes []IncludeRecord
+
+	// classic is set when parsing the classic libconfig format
+	// via Parser.ParseLibconfig.
+	classic bool
+
+	// bundle is the bundle for resolving @include directives.
+	// Files are read from disk if bundle is nil.
+	bundle *Bundle
+
+	// unwrapped is set if src isn't wrapped into "{...};" by Parser.Parse,
+	// e.g. when parsing in StrictRFC8259 mode.
+	unwrapped bool
+
+	// ps holds value positions if ParserConfig.TrackPositions is set.
+	ps []position
+
+	// line is the last position located by positionAt.
+	line position
 }
 
 func (c *cache) reset() {
 	c.vs = c.vs[:0]
+	c.cfg = nil
+	c.src = ""
+	c.rootFile = ""
+	c.includes = c.includes[:0]
+	c.classic = false
+	c.bundle = nil
+	c.unwrapped = false
+	c.ps = c.ps[:0]
+	c.line = position{}
 }
 
 func (c *cache) getValue() *Value {
@@ -120,7 +202,12 @@ func (c *cache) getValue() *Value {
 		c.vs = append(c.vs, Value{})
 	}
 	// Do not reset the value, since the caller must properly init it.
-	return &c.vs[len(c.vs)-1]
+	// The position and the flags are reset, since they are set only
+	// for some values.
+	v := &c.vs[len(c.vs)-1]
+	v.pos = nil
+	v.o.flags = 0
+	return v
 }
 
 func skipWS(s string) string {
@@ -136,15 +223,10 @@ func skipWSSlow(s string) string {
 	//0x0A LF    \n	line break
 	//0x09 HT    horizontal list
 	//0x0D CR    \r	enter
-	if len(s) == 0 || s[0] != 0x20 && s[0] != 0x0A && s[0] != 0x09 && s[0] != 0x0D {
+	if len(s) == 0 || !isWS(s[0]) {
 		return s
 	}
-	for i := 1; i < len(s); i++ {
-		if s[i] != 0x20 && s[i] != 0x0A && s[i] != 0x09 && s[i] != 0x0D {
-			return s[i:]
-		}
-	}
-	return ""
+	return s[1+indexNonWS(s[1:]):]
 }
 
 func skipJunk(s string) string {
@@ -157,6 +239,9 @@ func skipJunk(s string) string {
 func skipComment(s string) string {
 startSkip:
 	s = skipWS(s)
+	if len(s) == 0 {
+		return s
+	}
 	if s[0] == '#' {
 		for i := 1; i < len(s); i++ {
 			if s[i] == 0x0A {
@@ -231,16 +316,31 @@ func isArrayEnd(s string) (bool, string) {
 	return isEnd(s, "]")
 }*/
 
-// MaxDepth is the maximum depth for nested JSON.
+// MaxDepth is the maximum nesting depth for groups, arrays and lists.
 const MaxDepth = 300
 
 func parseValue(s string, c *cache, dir string, depth int) (*Value, string, error) {
+	if c.cfg == nil || !c.cfg.TrackPositions || !c.inSource(s) {
+		return parseValueInternal(s, c, dir, depth)
+	}
+	start := c.positionAt(s)
+	v, tail, err := parseValueInternal(s, c, dir, depth)
+	if err != nil {
+		return v, tail, err
+	}
+	return c.setPosition(v, start, tail), tail, nil
+}
+
+func parseValueInternal(s string, c *cache, dir string, depth int) (*Value, string, error) {
 	if len(s) == 0 {
 		return nil, s, fmt.Errorf("cannot parse empty string")
 	}
-	depth++
-	if depth > MaxDepth {
-		return nil, s, fmt.Errorf("too big depth for the nested JSON; it exceeds %d", MaxDepth)
+	if s[0] == '{' || s[0] == '[' || s[0] == '(' {
+		// Only groups, arrays and lists count towards the nesting depth.
+		depth++
+		if maxDepth := c.cfg.maxDepth(); depth > maxDepth {
+			return nil, s, fmt.Errorf("too deep nesting of groups, arrays and lists; it exceeds MaxDepth=%d", maxDepth)
+		}
 	}
 
 	if s[0] == '{' {
@@ -248,6 +348,7 @@ func parseValue(s string, c *cache, dir string, depth int) (*Value, string, erro
 		if err != nil {
 			return nil, tail, fmt.Errorf("cannot parse object: %s", err)
 		}
+		c.markExtendedJSON(v)
 		return v, tail, nil
 	}
 	if s[0] == '[' || s[0] == '(' {
@@ -262,11 +363,27 @@ func parseValue(s string, c *cache, dir string, depth int) (*Value, string, erro
 		if err != nil {
 			return nil, tail, fmt.Errorf("cannot parse string: %s", err)
 		}
+		if c.classic {
+			if ss, tail, err = concatRawStrings(ss, tail); err != nil {
+				return nil, tail, fmt.Errorf("cannot parse string: %s", err)
+			}
+		}
+		if err := c.cfg.checkStringLen(ss); err != nil {
+			return nil, s, err
+		}
+		if ss, err = c.cfg.normalizeString(ss); err != nil {
+			return nil, s, fmt.Errorf("cannot parse string: %s", err)
+		}
 		v := c.getValue()
 		v.t = typeRawString
 		v.s = ss
 		return v, tail, nil
 	}
+	if c.classic {
+		if v, tail, ok := parseClassicBool(s); ok {
+			return v, tail, nil
+		}
+	}
 	if s[0] == 't' {
 		if len(s) < len("true") || s[:len("true")] != "true" {
 			return nil, s, fmt.Errorf("unexpected value found: %q", s)
@@ -283,6 +400,9 @@ func parseValue(s string, c *cache, dir string, depth int) (*Value, string, erro
 		if len(s) < len("null") || s[:len("null")] != "null" {
 			// Try parsing NaN
 			if len(s) >= 3 && strings.EqualFold(s[:3], "nan") {
+				if err := c.cfg.checkNumber(s[:3]); err != nil {
+					return nil, s, fmt.Errorf("cannot parse number: %s", err)
+				}
 				v := c.getValue()
 				v.t = TypeNumber
 				v.s = s[:3]
@@ -294,7 +414,7 @@ func parseValue(s string, c *cache, dir string, depth int) (*Value, string, erro
 	}
 
 	var err error
-	s, err = loadInclude(s, dir)
+	s, err = loadInclude(s, dir, c)
 	if err != nil {
 		return nil, s, err
 	}
@@ -310,6 +430,9 @@ func parseValue(s string, c *cache, dir string, depth int) (*Value, string, erro
 	if err != nil {
 		return nil, tail, fmt.Errorf("cannot parse number: %s", err)
 	}
+	if err := c.cfg.checkNumber(ns); err != nil {
+		return nil, s, fmt.Errorf("cannot parse number: %s", err)
+	}
 	v := c.getValue()
 	v.t = TypeNumber
 	v.s = ns
@@ -340,11 +463,11 @@ func parseArray(s string, c *cache, dir string, depth int) (*Value, string, erro
 		v := c.getValue()
 		v.t = TypeArray
 		v.a = v.a[:0]
-		return v, s[1:], nil
+		return v, s, nil
 	}
 
 	var err error
-	s, err = loadInclude(s, dir)
+	s, err = loadInclude(s, dir, c)
 	if err != nil {
 		return nil, s, err
 	}
@@ -358,6 +481,9 @@ func parseArray(s string, c *cache, dir string, depth int) (*Value, string, erro
 
 		//s = skipWS(s)
 		s = skipJunk(s)
+		if len(s) == 0 {
+			return nil, s, fmt.Errorf("unexpected end of array")
+		}
 		if s[0] == ']' || s[0] == ')' {
 			s = s[1:]
 			return a, s, nil
@@ -368,6 +494,9 @@ func parseArray(s string, c *cache, dir string, depth int) (*Value, string, erro
 			return nil, s, fmt.Errorf("cannot parse array value: %s", err)
 		}
 		a.a = append(a.a, v)
+		if err := c.cfg.checkArrayLen(len(a.a)); err != nil {
+			return nil, s, err
+		}
 
 		//s = skipWS(s)
 		s = skipJunk(s)
@@ -397,18 +526,16 @@ func parseObject(s string, c *cache, dir string, depth int) (*Value, string, err
 		s = s[1:]
 		//s = skipWS(s)
 		s = skipJunk(s)
-		if s[0] != ';' {
-			return nil, s, fmt.Errorf("missing ;")
-		}
 		v := c.getValue()
 		v.t = TypeObject
 		v.o.reset()
-		return v, s[1:], nil
+		return v, s, nil
 	}
 
 	o := c.getValue()
 	o.t = TypeObject
 	o.o.reset()
+	var keys map[string]struct{}
 	for {
 		var err error
 		kv := o.o.getKV()
@@ -419,15 +546,28 @@ func parseObject(s string, c *cache, dir string, depth int) (*Value, string, err
 		/*if len(s) == 0 || s[0] != '"' {
 			return nil, s, fmt.Errorf(`cannot find opening '"" for object key`)
 		}*/
-		s, err = loadInclude(s, dir)
+		s, err = loadInclude(s, dir, c)
 		if err != nil {
 			return nil, s, err
 		}
 
+		keyStart := s
 		kv.k, s, err = parseRawKey(s[0:])
 		if err != nil {
 			return nil, s, fmt.Errorf("cannot parse object key: %s", err)
 		}
+		if err := c.cfg.checkStringLen(kv.k); err != nil {
+			return nil, keyStart, err
+		}
+		if kv.k, err = c.cfg.normalizeString(kv.k); err != nil {
+			return nil, keyStart, fmt.Errorf("cannot parse object key: %s", err)
+		}
+		if c.classic && !isClassicName(kv.k) {
+			return nil, keyStart, fmt.Errorf("invalid setting name %q; it must match [A-Za-z*][-A-Za-z0-9_*]*", kv.k)
+		}
+		if err := c.checkDuplicateKey(&o.o, &keys, keyStart); err != nil {
+			return nil, keyStart, err
+		}
 		//s = skipWS(s)
 		s = skipJunk(s)
 		if len(s) == 0 || (s[0] != ':' && s[0] != '=') {
@@ -447,12 +587,12 @@ func parseObject(s string, c *cache, dir string, depth int) (*Value, string, err
 		if len(s) == 0 {
 			return nil, s, fmt.Errorf("unexpected end of object")
 		}
-		if s[0] == ';' { // ;}
+		if s[0] == ';' || (c.classic && s[0] == ',') { // ;}
 			s = s[1:]
 			//s = skipWS(s)
 			s = skipJunk(s)
 
-			if s[0] == '}' {
+			if len(s) > 0 && s[0] == '}' {
 				s = s[1:]
 				//s = skipWS(s)
 				s = skipJunk(s)
@@ -467,22 +607,43 @@ func parseObject(s string, c *cache, dir string, depth int) (*Value, string, err
 			s = skipJunk(s)
 			return o, s, nil
 		}
+		if c.classic {
+			// Setting terminators are optional in the classic libconfig format.
+			continue
+		}
 		return nil, s, fmt.Errorf("missing ';' after object value, or missing '};' for close object")
 	}
 }
 
-func loadInclude(s string, dir string) (string, error) {
+func loadInclude(s string, dir string, c *cache) (string, error) {
 	if dir == "" {
 		return s, nil
 	}
-	if !(len(s) >= 8 && s[:8] == "@include") {
-		return s, nil
+	// Included files may start with @include directives too.
+	for len(s) >= 8 && s[:8] == "@include" {
+		var err error
+		s, err = loadIncludeDirective(s, dir, c)
+		if err != nil {
+			return s, err
+		}
+		s = skipJunk(s)
+	}
+	return s, nil
+}
+
+func loadIncludeDirective(s string, dir string, c *cache) (string, error) {
+	parent := c.includeAt(len(s))
+	depth := 1
+	parentFile := ""
+	if parent >= 0 {
+		depth = c.includes[parent].Depth + 1
+		parentFile = c.includes[parent].File
 	}
 
 	s = s[8:]
 	s = skipJunk(s)
 
-	if s[0] == '"' {
+	if len(s) > 0 && s[0] == '"' {
 		s = s[1:]
 		cnt := len(s)
 		var path string
@@ -495,15 +656,43 @@ func loadInclude(s string, dir string) (string, error) {
 			}
 		}
 
-		var tmp string
-		files := scanMatch(dir + "/" + path)
+		if maxDepth := c.cfg.maxIncludeDepth(); depth > maxDepth {
+			return s, fmt.Errorf("too deep @include nesting for %q; it exceeds %d; include trace: %s", path, maxDepth, c.includeChain(parent))
+		}
+
+		var chunks []string
+		files := c.scanInclude(dir + "/" + path)
 		for _, file := range files {
-			data, err := ioutil.ReadFile(file)
+			if c.isIncluding(parent, file) {
+				return s, fmt.Errorf("@include cycle detected for %q; include trace: %s -> %s", path, c.includeChain(parent), file)
+			}
+			data, err := c.readInclude(file)
 			if err != nil {
 				return s, fmt.Errorf("read include file path: %s, error: %s", file, err.Error())
 			}
 
-			tmp = tmp + "\r\n" + string(data)
+			transcode := c.cfg != nil && c.cfg.TranscodeInput
+			included, err := decodeInput(string(data), transcode)
+			if err != nil {
+				return s, fmt.Errorf("cannot decode include file path: %s, error: %s", file, err)
+			}
+
+			chunks = append(chunks, "\r\n"+included)
+		}
+
+		tmp := strings.Join(chunks, "")
+		start := len(tmp) + len(s)
+		for i, chunk := range chunks {
+			c.includes = append(c.includes, IncludeRecord{
+				Pattern: path,
+				File:    files[i],
+				Parent:  parentFile,
+				Depth:   depth,
+				parent:  parent,
+				start:   start,
+				end:     start - len(chunk),
+			})
+			start -= len(chunk)
 		}
 
 		s = tmp + s
@@ -568,6 +757,20 @@ func unescapeStringBestEffort(s string) string {
 			b = append(b, '\r')
 		case 't':
 			b = append(b, '\t')
+		case 'x':
+			if len(s) < 2 {
+				// Too short escape sequence. Just store it unchanged.
+				b = append(b, "\\x"...)
+				break
+			}
+			x, err := strconv.ParseUint(s[:2], 16, 8)
+			if err != nil {
+				// Invalid escape sequence. Just store it unchanged.
+				b = append(b, "\\x"...)
+				break
+			}
+			b = append(b, byte(x))
+			s = s[2:]
 		case 'u':
 			if len(s) < 4 {
 				// Too short escape sequence. Just store it unchanged.
@@ -621,15 +824,8 @@ func unescapeStringBestEffort(s string) string {
 // parseRawKey is similar to parseRawString, but is optimized
 // for small-sized keys without escape sequences.
 func parseRawKey(s string) (string, string, error) {
-	for i := 0; i < len(s); i++ {
-		if s[i] == ':' || s[i] == '=' {
-			// Fast path.
-			return strings.TrimSpace(s[:i]), s[i:], nil
-		}
-		/*if s[i] == '\\' {
-			// Slow path.
-			return parseRawString(s)
-		}*/
+	if i := indexKeySep(s); i >= 0 {
+		return strings.TrimSpace(s[:i]), s[i:], nil
 	}
 	return s, "", fmt.Errorf(`missing ':' or '='`)
 }
@@ -716,11 +912,39 @@ func parseRawNumber(s string) (string, string, error) {
 type Object struct {
 	kvs           []kv
 	keysUnescaped bool
+
+	// sorted is set after Sort call, so Get may use binary search.
+	sorted bool
+
+	// flags contains the state of the Value containing o.
+	//
+	// It is stored in Object, since Object is embedded into Value,
+	// so the flags are available for all the Value types without
+	// growing Value.
+	flags valueFlags
 }
 
+// valueFlags contains the state of a Value.
+type valueFlags uint8
+
+const (
+	// flagFrozen is set for values obtained via Value.Freeze.
+	flagFrozen valueFlags = 1 << iota
+
+	// flagGenerated is set for numbers created from Go numbers via Arena,
+	// e.g. for modified numbers.
+	flagGenerated
+
+	// flagExtended is set for objects recognized as MongoDB Extended JSON
+	// constructs such as {"$date": ...} if ParserConfig.ExtendedJSON is set.
+	flagExtended
+)
+
 func (o *Object) reset() {
 	o.kvs = o.kvs[:0]
 	o.keysUnescaped = false
+	o.sorted = false
+	o.flags = 0
 }
 
 // MarshalTo appends marshaled o to dst and returns the result.
@@ -764,6 +988,15 @@ func (o *Object) getKV() *kv {
 	return &o.kvs[len(o.kvs)-1]
 }
 
+// appendObjectKV appends key with v to o without checking for duplicates.
+//
+// The key must be already unescaped if o.keysUnescaped is set.
+func appendObjectKV(o *Object, key string, v *Value) {
+	kv := o.getKV()
+	kv.k = key
+	kv.v = v
+}
+
 func (o *Object) unescapeKeys() {
 	if o.keysUnescaped {
 		return
@@ -787,6 +1020,14 @@ func (o *Object) Len() int {
 //
 // The returned value is valid until Parse is called on the Parser returned o.
 func (o *Object) Get(key string) *Value {
+	if o.sorted {
+		// Fast path for sorted objects - use binary search.
+		n := o.sortedIndex(key)
+		if n < len(o.kvs) && o.kvs[n].k == key {
+			return o.kvs[n].v
+		}
+		return nil
+	}
 	if !o.keysUnescaped && strings.IndexByte(key, '\\') < 0 {
 		// Fast path - try searching for the key without object keys unescaping.
 		for _, kv := range o.kvs {
@@ -823,6 +1064,44 @@ func (o *Object) Visit(f func(key []byte, v *Value)) {
 	}
 }
 
+// Range calls f for each item in the o in the original order
+// of the parsed JSON until f returns stop=true or non-nil error.
+//
+// The error returned by f is returned from Range.
+//
+// f cannot hold key and/or v after returning.
+func (o *Object) Range(f func(key []byte, v *Value) (stop bool, err error)) error {
+	if o == nil {
+		return nil
+	}
+
+	o.unescapeKeys()
+
+	for _, kv := range o.kvs {
+		stop, err := f(s2b(kv.k), kv.v)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// At returns the key and the value for the i-th item in the o
+// in the original order of the parsed JSON.
+//
+// It panics if i is out of [0, o.Len()) range.
+//
+// The returned key and value are valid until Parse is called
+// on the Parser returned o.
+func (o *Object) At(i int) ([]byte, *Value) {
+	o.unescapeKeys()
+	kv := &o.kvs[i]
+	return s2b(kv.k), kv.v
+}
+
 // Value represents any JSON value.
 //
 // Call Type in order to determine the actual type of the JSON value.
@@ -834,6 +1113,10 @@ type Value struct {
 	a []*Value
 	s string
 	t Type
+
+	// pos is the position of v in the parsed input.
+	// It is set only if ParserConfig.TrackPositions is enabled.
+	pos *position
 }
 
 // MarshalTo appends marshaled v to dst and returns the result.
@@ -866,6 +1149,8 @@ func (v *Value) MarshalTo(dst []byte) []byte {
 		return append(dst, "false"...)
 	case TypeNull:
 		return append(dst, "null"...)
+	case TypeRawJSON:
+		return append(dst, v.s...)
 	default:
 		panic(fmt.Errorf("BUG: unexpected Value type: %d", v.t))
 	}
@@ -911,6 +1196,10 @@ const (
 	TypeFalse Type = 6
 
 	typeRawString Type = 7
+
+	// TypeRawJSON is pre-serialized JSON fragment created
+	// via Arena.NewRawJSON. It is marshaled as is.
+	TypeRawJSON Type = 8
 )
 
 // String returns string representation of t.
@@ -930,6 +1219,8 @@ func (t Type) String() string {
 		return "false"
 	case TypeNull:
 		return "null"
+	case TypeRawJSON:
+		return "rawjson"
 
 	// typeRawString is skipped intentionally,
 	// since it shouldn't be visible to user.
@@ -1122,9 +1413,15 @@ func (v *Value) GetUint(keys ...string) uint {
 //
 // Array indexes may be represented as decimal numbers in keys.
 //
+// {"$numberLong": "..."} and {"$numberInt": "..."} objects are recognized
+// if ParserConfig.ExtendedJSON is set.
+//
 // 0 is returned for non-existing keys path or for invalid value type.
 func (v *Value) GetInt64(keys ...string) int64 {
 	v = v.Get(keys...)
+	if n, ok := v.extendedInt64(); ok {
+		return n
+	}
 	if v == nil || v.Type() != TypeNumber {
 		return 0
 	}
@@ -1159,6 +1456,32 @@ func (v *Value) GetStringBytes(keys ...string) []byte {
 	return s2b(v.s)
 }
 
+// GetRawNumber returns the exact number token by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path or for invalid value type.
+//
+// The returned token is valid until Parse is called on the Parser returned v.
+func (v *Value) GetRawNumber(keys ...string) []byte {
+	return v.Get(keys...).RawNumber()
+}
+
+// RawNumber returns the exact number token for v, e.g. 12.50 or 0x1F.
+//
+// The token isn't converted, so numbers such as money amounts or 128-bit IDs
+// may be handled without precision loss, e.g. via math/big.
+//
+// nil is returned if v isn't a number.
+//
+// The returned token is valid until Parse is called on the Parser returned v.
+func (v *Value) RawNumber() []byte {
+	if v == nil || v.t != TypeNumber {
+		return nil
+	}
+	return s2b(v.s)
+}
+
 // GetBool returns bool value by the given keys path.
 //
 // Array indexes may be represented as decimal numbers in keys.
@@ -1172,6 +1495,149 @@ func (v *Value) GetBool(keys ...string) bool {
 	return false
 }
 
+// GetStringSlice returns a slice of strings by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path, for invalid value type
+// or if the array contains non-string items.
+//
+// The returned strings are copies, so they remain valid after Parse
+// is called on the Parser returned v.
+func (v *Value) GetStringSlice(keys ...string) []string {
+	a := v.GetArray(keys...)
+	if a == nil {
+		return nil
+	}
+	ss := make([]string, len(a))
+	for i, item := range a {
+		if item.Type() != TypeString {
+			return nil
+		}
+		ss[i] = strings.Clone(item.s)
+	}
+	return ss
+}
+
+// GetIntSlice returns a slice of ints by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path, for invalid value type
+// or if the array contains items, which cannot be represented as int.
+func (v *Value) GetIntSlice(keys ...string) []int {
+	a := v.GetArray(keys...)
+	if a == nil {
+		return nil
+	}
+	ns := make([]int, len(a))
+	for i, item := range a {
+		if item.Type() != TypeNumber {
+			return nil
+		}
+		n, err := parseIntToken(item.s)
+		if err != nil || int64(int(n)) != n {
+			return nil
+		}
+		ns[i] = int(n)
+	}
+	return ns
+}
+
+// GetFloat64Slice returns a slice of float64 numbers by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path, for invalid value type
+// or if the array contains non-number items.
+func (v *Value) GetFloat64Slice(keys ...string) []float64 {
+	a := v.GetArray(keys...)
+	if a == nil {
+		return nil
+	}
+	fs := make([]float64, len(a))
+	for i, item := range a {
+		if item.Type() != TypeNumber {
+			return nil
+		}
+		fs[i] = fastfloat.ParseBestEffort(item.s)
+	}
+	return fs
+}
+
+// GetBoolSlice returns a slice of bools by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path, for invalid value type
+// or if the array contains non-bool items.
+func (v *Value) GetBoolSlice(keys ...string) []bool {
+	a := v.GetArray(keys...)
+	if a == nil {
+		return nil
+	}
+	bs := make([]bool, len(a))
+	for i, item := range a {
+		b, err := item.Bool()
+		if err != nil {
+			return nil
+		}
+		bs[i] = b
+	}
+	return bs
+}
+
+// GetStringMap returns a map of strings by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path, for invalid value type
+// or if the object contains non-string values. The first value wins
+// for duplicate keys.
+//
+// The returned keys and strings are copies, so they remain valid after
+// Parse is called on the Parser returned v.
+func (v *Value) GetStringMap(keys ...string) map[string]string {
+	o := v.GetObject(keys...)
+	if o == nil {
+		return nil
+	}
+	o.unescapeKeys()
+	m := make(map[string]string, len(o.kvs))
+	for _, kv := range o.kvs {
+		if kv.v.Type() != TypeString {
+			return nil
+		}
+		if _, ok := m[kv.k]; !ok {
+			m[strings.Clone(kv.k)] = strings.Clone(kv.v.s)
+		}
+	}
+	return m
+}
+
+// GetMap returns a map of object values by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned for non-existing keys path or for invalid value type.
+// The first value wins for duplicate keys.
+//
+// The returned map is valid until Parse is called on the Parser returned v.
+func (v *Value) GetMap(keys ...string) map[string]*Value {
+	o := v.GetObject(keys...)
+	if o == nil {
+		return nil
+	}
+	o.unescapeKeys()
+	m := make(map[string]*Value, len(o.kvs))
+	for _, kv := range o.kvs {
+		if _, ok := m[kv.k]; !ok {
+			m[kv.k] = kv.v
+		}
+	}
+	return m
+}
+
 // Object returns the underlying JSON object for the v.
 //
 // The returned object is valid until Parse is called on the Parser returned v.
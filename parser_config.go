@@ -0,0 +1,241 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParserConfig contains optional settings for Parser.
+//
+// The zero ParserConfig accepts everything the parser understands.
+type ParserConfig struct {
+	// RejectDuplicateKeys makes Parse* fail if an object contains
+	// duplicate keys.
+	//
+	// By default the first value is returned by Object.Get for duplicate keys,
+	// so copy-paste errors in config files may go unnoticed.
+	RejectDuplicateKeys bool
+
+	// MaxDepth is the maximum nesting depth for groups, arrays and lists.
+	//
+	// Only containers are counted, while the top-level config itself
+	// and scalar values aren't counted. For example, `a = 1;` has depth 0,
+	// `a = [1];` has depth 1 and `a = { b = [1]; };` has depth 2.
+	//
+	// The MaxDepth constant is used if MaxDepth is zero.
+	// MaxDepth cannot exceed the MaxDepth constant.
+	MaxDepth int
+
+	// MaxInputSize is the maximum size in bytes of the parsed input.
+	//
+	// There is no limit if MaxInputSize is zero.
+	MaxInputSize int
+
+	// MaxStringLen is the maximum length in bytes of strings and object keys
+	// before unescaping.
+	//
+	// There is no limit if MaxStringLen is zero.
+	MaxStringLen int
+
+	// MaxArrayElements is the maximum number of elements in a single array.
+	//
+	// There is no limit if MaxArrayElements is zero.
+	MaxArrayElements int
+
+	// UTF8Mode defines how invalid UTF-8 and invalid escape sequences
+	// in strings and object keys are handled.
+	UTF8Mode UTF8Mode
+
+	// NumberMode defines which numbers are accepted.
+	//
+	// Number tokens are stored without conversion by default.
+	NumberMode NumberMode
+
+	// TranscodeInput enables transcoding UTF-16 and UTF-32 input to UTF-8.
+	//
+	// The encoding is detected by BOM or by zero bytes at the start of input.
+	// Such input is rejected with a descriptive error by default.
+	// UTF-8 BOM is always stripped.
+	TranscodeInput bool
+
+	// RejectTrailingData makes Parse* fail if anything but whitespace
+	// and comments follows the document, e.g. data after unbalanced '}'.
+	//
+	// By default such data may be silently ignored or rejected depending
+	// on its contents. Use Parser.ParseOne for parsing multiple documents.
+	RejectTrailingData bool
+
+	// StrictRFC8259 makes Parser parse the input as RFC 8259 JSON text
+	// instead of libconfig.
+	//
+	// Everything the RFC rejects is rejected, including leading zeros
+	// and leading '+' in numbers, control chars and invalid UTF-8 in strings,
+	// invalid escape sequences, trailing commas, comments and trailing data
+	// after the top-level value. The top-level value may have any type.
+	//
+	// StrictRFC8259 isn't supported by LazyParser and ParallelParser.
+	StrictRFC8259 bool
+
+	// ExtendedJSON enables recognition of MongoDB Extended JSON constructs
+	// {"$date": ...}, {"$numberLong": "..."} and {"$numberInt": "..."}.
+	//
+	// Recognized constructs remain objects, so they are marshaled back
+	// unchanged, while Value.GetTime and Value.GetInt64 return their values.
+	ExtendedJSON bool
+
+	// TrackPositions makes Parse* record byte offsets, lines and columns
+	// of every parsed value. They are available via Value.Offset,
+	// Value.EndOffset and Value.LineColumn.
+	//
+	// Positions of values from @include files aren't tracked.
+	// TrackPositions isn't supported in StrictRFC8259 mode.
+	TrackPositions bool
+
+	// MaxIncludeDepth is the maximum nesting depth for @include directives.
+	//
+	// The default limit is 16 if MaxIncludeDepth is zero. Include cycles
+	// are always rejected.
+	MaxIncludeDepth int
+}
+
+func (cfg *ParserConfig) maxDepth() int {
+	if cfg == nil || cfg.MaxDepth <= 0 || cfg.MaxDepth > MaxDepth {
+		return MaxDepth
+	}
+	return cfg.MaxDepth
+}
+
+func (cfg *ParserConfig) checkInputSize(n int) error {
+	if cfg == nil || cfg.MaxInputSize <= 0 || n <= cfg.MaxInputSize {
+		return nil
+	}
+	return fmt.Errorf("too big input size: %d bytes; it exceeds MaxInputSize=%d", n, cfg.MaxInputSize)
+}
+
+func (cfg *ParserConfig) checkStringLen(s string) error {
+	if cfg == nil || cfg.MaxStringLen <= 0 || len(s) <= cfg.MaxStringLen {
+		return nil
+	}
+	return fmt.Errorf("too long string: %d bytes; it exceeds MaxStringLen=%d", len(s), cfg.MaxStringLen)
+}
+
+func (cfg *ParserConfig) checkArrayLen(n int) error {
+	if cfg == nil || cfg.MaxArrayElements <= 0 || n <= cfg.MaxArrayElements {
+		return nil
+	}
+	return fmt.Errorf("too many array elements; they exceed MaxArrayElements=%d", cfg.MaxArrayElements)
+}
+
+// maxDuplicateKeyScan is the number of keys in an object, which are
+// scanned linearly by checkDuplicateKey. A set of keys is used for bigger
+// objects in order to avoid quadratic complexity.
+const maxDuplicateKeyScan = 16
+
+// checkDuplicateKey returns an error if the last key in o duplicates
+// a previous key.
+//
+// Keys are compared after unescaping, so "a" and "\u0061" are duplicates.
+// keys must point to nil map before the first call for o; it is filled
+// with unescaped keys of o when o becomes big. s must point to the start
+// of the last key in the input.
+func (c *cache) checkDuplicateKey(o *Object, keys *map[string]struct{}, s string) error {
+	if c.cfg == nil || !c.cfg.RejectDuplicateKeys {
+		return nil
+	}
+	n := len(o.kvs) - 1
+	k := unescapeStringCopy(o.kvs[n].k)
+	if n <= maxDuplicateKeyScan {
+		for _, kv := range o.kvs[:n] {
+			if unescapeStringCopy(kv.k) == k {
+				return fmt.Errorf("duplicate key %q at %s", k, c.position(s))
+			}
+		}
+		return nil
+	}
+	m := *keys
+	if m == nil {
+		m = make(map[string]struct{}, 2*n)
+		for _, kv := range o.kvs[:n] {
+			m[unescapeStringCopy(kv.k)] = struct{}{}
+		}
+		*keys = m
+	}
+	if _, ok := m[k]; ok {
+		return fmt.Errorf("duplicate key %q at %s", k, c.position(s))
+	}
+	m[k] = struct{}{}
+	return nil
+}
+
+// unescapeStringCopy returns unescaped s.
+//
+// Unlike unescapeStringBestEffort, it doesn't modify the memory s points to,
+// so it may be used for parsed keys, which are unescaped later
+// by Object.unescapeKeys, and for strings outside Parser buffer.
+func unescapeStringCopy(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	b := []byte(s)
+	return unescapeStringBestEffort(b2s(b))
+}
+
+// position returns human-readable position of the tail s in the input.
+func (c *cache) position(s string) string {
+	if !c.inSource(s) {
+		// s doesn't belong to src, e.g. it is located in @include file.
+		if i := c.includeAt(len(s)); i >= 0 {
+			return fmt.Sprintf("%q in %s", startEndString(s), c.includeChain(i))
+		}
+		return fmt.Sprintf("%q", startEndString(s))
+	}
+	line, column := c.lineColumn(s)
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// lineColumn returns 1-based line and column for the tail s of c.src.
+func (c *cache) lineColumn(s string) (int, int) {
+	src := c.src
+	if !c.unwrapped && len(src) > 0 {
+		// Skip the '{' added by Parser.Parse.
+		src = src[1:]
+	}
+	offset := len(src) - len(s)
+	if offset < 0 {
+		offset = 0
+	}
+	prefix := src[:offset]
+	line := strings.Count(prefix, "\n") + 1
+	column := offset - strings.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
+// inSource returns true if the tail s belongs to c.src.
+func (c *cache) inSource(s string) bool {
+	return len(s) <= len(c.src) && c.src[len(c.src)-len(s):] == s
+}
@@ -0,0 +1,103 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParserRejectDuplicateKeys(t *testing.T) {
+	var p Parser
+	doc := "a = 1;\nb = { x = 1; y = 2; };\nc = { x = 1;\n  x = 2; };"
+	if _, err := p.Parse(doc); err != nil {
+		t.Fatalf("unexpected error in default mode: %s", err)
+	}
+
+	p.Config.RejectDuplicateKeys = true
+	_, err := p.Parse(doc)
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	errExpected := `duplicate key "x" at line 4, column 3`
+	if !strings.Contains(err.Error(), errExpected) {
+		t.Fatalf("unexpected error; got %q; want it to contain %q", err, errExpected)
+	}
+
+	f := func(s string, ok bool) {
+		t.Helper()
+		_, err := p.Parse(s)
+		if ok && err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		if !ok && err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f(`a = 1; b = 2;`, true)
+	f(`a = { a = 1; }; b = [ { a = 1; }, { a = 2; } ];`, true)
+	f(`a = 1; a = 2;`, false)
+	f(`a = [ { b = 1; b = 2; } ];`, false)
+
+	// Keys are compared after unescaping.
+	f(`"a" = 1; "\u0061" = 2;`, false)
+	f(`"a\tb" = 1; "a\u0009b" = 2;`, false)
+	f(`"\\u0061" = 1; "a" = 2;`, true)
+
+	// Big objects are checked via a set of keys.
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "k%d = %d; ", i, i)
+	}
+	big := sb.String()
+	f(big, true)
+	f(big+`k0 = 1;`, false)
+	f(big+`k99 = 1;`, false)
+	f(`"k5" = 1; `+big+`"k\u0035" = 2;`, false)
+	f(`"k\u0035" = 1; `+big+`"k5" = 2;`, false)
+	f(`a = {`+big+`}; b = {`+big+`};`, true)
+
+	// The checked keys must remain intact.
+	v, err := p.Parse(`"x\ny" = 1; "x\u0041" = 2;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt(`"x` + "\n" + `y"`); n != 1 {
+		t.Fatalf("unexpected value; got %d; want 1", n)
+	}
+	if n := v.GetInt(`"xA"`); n != 2 {
+		t.Fatalf("unexpected value; got %d; want 2", n)
+	}
+}
+
+func TestParserLimits(t *testing.T) {
+	f := func(cfg ParserConfig, s, errExpected string) {
+		t.Helper()
+		p := Parser{
+			Config: cfg,
+		}
+		_, err := p.Parse(s)
+		if errExpected == "" {
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %s", s, err)
+			}
+			return
+		}
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+		if !strings.Contains(err.Error(), errExpected) {
+			t.Fatalf("unexpected error for %q; got %q; want it to contain %q", s, err, errExpected)
+		}
+	}
+
+	doc := `a = { b = { c = [1, 2, 3]; }; }; s = "foobar";`
+	f(ParserConfig{}, doc, "")
+	f(ParserConfig{MaxDepth: 3, MaxInputSize: len(doc), MaxStringLen: 6, MaxArrayElements: 3}, doc, "")
+	f(ParserConfig{MaxDepth: 2}, doc, "too deep nesting of groups, arrays and lists; it exceeds MaxDepth=2")
+	f(ParserConfig{MaxDepth: 1}, `a = 1; b = "x"; c = [1, 2]; d = {};`, "")
+	f(ParserConfig{MaxDepth: 1}, `a = ([1]);`, "it exceeds MaxDepth=1")
+	f(ParserConfig{MaxInputSize: 10}, doc, "too big input size")
+	f(ParserConfig{MaxStringLen: 5}, doc, "too long string: 6 bytes; it exceeds MaxStringLen=5")
+	f(ParserConfig{MaxStringLen: 2}, `abc = 1;`, "too long string: 3 bytes")
+	f(ParserConfig{MaxArrayElements: 2}, doc, "too many array elements; they exceed MaxArrayElements=2")
+}
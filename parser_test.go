@@ -75,6 +75,7 @@ func TestUnescapeStringBestEffort(t *testing.T) {
 		testUnescapeStringBestEffort(t, `йцук\n\"\\Y`, "йцук\n\"\\Y")
 		testUnescapeStringBestEffort(t, `q\u1234we`, "q\u1234we")
 		testUnescapeStringBestEffort(t, `п\ud83e\udd2dи`, "п🤭и")
+		testUnescapeStringBestEffort(t, `\x41b\x7e`, "Ab~")
 	})
 
 	t.Run("error", func(t *testing.T) {
@@ -83,6 +84,8 @@ func TestUnescapeStringBestEffort(t *testing.T) {
 		testUnescapeStringBestEffort(t, `\"x\uyz\"`, `"x\uyz"`)
 		testUnescapeStringBestEffort(t, `\u12\"пролw`, `\u12"пролw`)
 		testUnescapeStringBestEffort(t, `п\ud83eи`, "п\\ud83eи")
+		testUnescapeStringBestEffort(t, `a\x4`, `a\x4`)
+		testUnescapeStringBestEffort(t, `\xzz`, `\xzz`)
 	})
 }
 
@@ -1272,3 +1275,137 @@ func testParseGetSerial(s string) error {
 	}
 	return nil
 }
+
+func TestValueGetStringSliceCopy(t *testing.T) {
+	var p Parser
+	v, err := p.Parse(`hosts = ["foo", "b\\ar"];`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ss := v.GetStringSlice("hosts")
+	if _, err := p.Parse(`hosts = ["xxxxxxxxxxxx"];`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ss) != 2 || ss[0] != "foo" || ss[1] != `b\ar` {
+		t.Fatalf("unexpected strings after parser re-use: %q", ss)
+	}
+	if empty := v.GetStringSlice("hosts", "0"); empty != nil {
+		t.Fatalf("unexpected non-nil strings: %q", empty)
+	}
+}
+
+func TestParseEmptyContainers(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %s; want %s", s, result, resultExpected)
+		}
+	}
+
+	// The ';' after empty containers belongs to the enclosing object,
+	// so empty containers may be followed by other items and settings.
+	f(``, `{}`)
+	f(`a = [];`, `{"a":[]}`)
+	f(`a = {};`, `{"a":{}}`)
+	f(`a = []; b = {}; c = 1;`, `{"a":[],"b":{},"c":1}`)
+	f(`a = [[], {}, ()];`, `{"a":[[],{},[]]}`)
+	f(`a = { b = {}; c = []; };`, `{"a":{"b":{},"c":[]}}`)
+
+	fErr := func(s string) {
+		t.Helper()
+		if _, err := Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	fErr(`a = [] b = 1;`)
+	fErr(`a = {} b = 1;`)
+}
+
+func TestParseHexEscapes(t *testing.T) {
+	// \x escapes produced by MarshalTo for invalid UTF-8 and control chars
+	// are decoded, so MarshalTo output may be parsed back.
+	var a Arena
+	for _, s := range []string{"x\xffy", "\x7f", "Ab~"} {
+		x := a.NewString(s)
+		v, err := Parse("a = " + x.String() + ";")
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		if result := string(v.GetStringBytes("a")); result != s {
+			t.Fatalf("unexpected string; got %q; want %q", result, s)
+		}
+	}
+	v := MustParse(`a = "\x41b\x7e\x4";`)
+	if result := string(v.GetStringBytes("a")); result != `Ab~\x4` {
+		t.Fatalf("unexpected string; got %q; want %q", result, `Ab~\x4`)
+	}
+}
+
+func TestValueGetMap(t *testing.T) {
+	v := MustParse(`servers = { a = { port = 1; }; b = { port = 2; }; }; list = [1];`)
+	m := v.GetMap("servers")
+	if len(m) != 2 || m["a"].GetInt("port") != 1 || m["b"].GetInt("port") != 2 {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	if m := v.GetMap("list"); m != nil {
+		t.Fatalf("unexpected non-nil map: %v", m)
+	}
+	if m := v.GetMap("missing"); m != nil {
+		t.Fatalf("unexpected non-nil map: %v", m)
+	}
+}
+
+func TestObjectRange(t *testing.T) {
+	o := MustParse(`a = 1; b = 2; c = 3;`).GetObject()
+
+	var keys []string
+	err := o.Range(func(key []byte, v *Value) (bool, error) {
+		keys = append(keys, string(key))
+		return string(key) == "b", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(keys, ",") != "a,b" {
+		t.Fatalf("unexpected keys visited: %q", keys)
+	}
+
+	errExpected := fmt.Errorf("foo")
+	n := 0
+	err = o.Range(func(key []byte, v *Value) (bool, error) {
+		n++
+		return false, errExpected
+	})
+	if err != errExpected {
+		t.Fatalf("unexpected error; got %v; want %v", err, errExpected)
+	}
+	if n != 1 {
+		t.Fatalf("unexpected number of calls; got %d; want 1", n)
+	}
+
+	var nilObj *Object
+	if err := nilObj.Range(func(key []byte, v *Value) (bool, error) { return false, errExpected }); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestObjectAt(t *testing.T) {
+	o := MustParse(`a = 1; b = "x";`).GetObject()
+	if o.Len() != 2 {
+		t.Fatalf("unexpected len; got %d; want 2", o.Len())
+	}
+	k, v := o.At(1)
+	if string(k) != "b" || string(v.GetStringBytes()) != "x" {
+		t.Fatalf("unexpected item #1: %q=%s", k, v)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expecting panic for out of range index")
+		}
+	}()
+	o.At(2)
+}
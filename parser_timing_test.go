@@ -36,6 +36,58 @@ func benchmarkParseRawString(b *testing.B, s string) {
 	})
 }
 
+func BenchmarkSkipWS(b *testing.B) {
+	for _, n := range []int{1, 4, 16, 64} {
+		s := strings.Repeat(" \t\n", n) + "x"
+		b.Run(fmt.Sprintf("ws_%d", 3*n), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(s)))
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if tail := skipWS(s); tail != "x" {
+						panic(fmt.Errorf("unexpected tail; got %q; want %q", tail, "x"))
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParseDocument(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("servers = (\n")
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `    {
+        server_name = "host-%d.example.com";
+        listen_port = %d;
+        connection_timeout = 1.5;
+        allowed_networks = ["10.0.0.0/8", "192.168.0.0/16"];
+        tls_enabled = true;
+    }`, i, 1000+i)
+	}
+	sb.WriteString("\n);\n")
+	s := sb.String()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(s)))
+	b.RunParallel(func(pb *testing.PB) {
+		p := benchPool.Get()
+		for pb.Next() {
+			v, err := p.Parse(s)
+			if err != nil {
+				panic(fmt.Errorf("unexpected error: %s", err))
+			}
+			if n := len(v.GetArray("servers")); n != 1000 {
+				panic(fmt.Errorf("unexpected number of servers; got %d; want 1000", n))
+			}
+		}
+		benchPool.Put(p)
+	})
+}
+
 func BenchmarkParseRawNumber(b *testing.B) {
 	for _, s := range []string{"1", "1234", "123456", "-1234", "1234567890.1234567", "-1.32434e+12"} {
 		b.Run(s, func(b *testing.B) {
@@ -0,0 +1,273 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a keys path identifying a value inside a Value tree.
+//
+// Array indexes are represented as decimal numbers in the path.
+//
+// Path allows performing path-based reads and writes with proper error
+// handling, while Value.Get returns nil without the reason.
+type Path []string
+
+// ParsePath parses dotted path s such as "server.listeners.0.port".
+//
+// Dots and backslashes inside keys must be escaped with backslash,
+// e.g. `hosts.example\.com.port`. An empty s corresponds to the root path.
+func ParsePath(s string) (Path, error) {
+	return ParsePathSep(s, '.')
+}
+
+// ParsePathSep parses path s with keys delimited by sep,
+// e.g. "server/listeners/0/port" for sep='/'.
+//
+// sep and backslash chars inside keys must be escaped with backslash.
+// An empty s corresponds to the root path.
+func ParsePathSep(s string, sep byte) (Path, error) {
+	if sep == '\\' {
+		return nil, fmt.Errorf("backslash cannot be used as path separator")
+	}
+	if s == "" {
+		return nil, nil
+	}
+	if strings.IndexByte(s, '\\') < 0 {
+		// Fast path - no escaped chars.
+		return Path(strings.Split(s, string(sep))), nil
+	}
+	var p Path
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '\\':
+			if i+1 >= len(s) || (s[i+1] != sep && s[i+1] != '\\') {
+				return nil, fmt.Errorf("invalid escape sequence at position %d in path %q; only `\\%c` and `\\\\` are supported", i, s, sep)
+			}
+			i++
+			b = append(b, s[i])
+		case sep:
+			p = append(p, string(b))
+			b = b[:0]
+		default:
+			b = append(b, ch)
+		}
+	}
+	return append(p, string(b)), nil
+}
+
+// MustParsePath parses dotted path s.
+//
+// The function panics if s cannot be parsed.
+func MustParsePath(s string) Path {
+	p, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// ParsePointer parses JSON Pointer s according to RFC 6901, e.g. "/server/listeners/0/port".
+//
+// An empty s corresponds to the root path.
+func ParsePointer(s string) (Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer %q must start with '/'", s)
+	}
+	p := Path(strings.Split(s[1:], "/"))
+	for i, key := range p {
+		if strings.IndexByte(key, '~') < 0 {
+			continue
+		}
+		for j := 0; j < len(key); j++ {
+			if key[j] == '~' && (j+1 >= len(key) || key[j+1] != '0' && key[j+1] != '1') {
+				return nil, fmt.Errorf("invalid escape sequence in JSON Pointer %q; only ~0 and ~1 are supported", s)
+			}
+		}
+		key = strings.ReplaceAll(key, "~1", "/")
+		p[i] = strings.ReplaceAll(key, "~0", "~")
+	}
+	return p, nil
+}
+
+// Pointer returns JSON Pointer representation of p, which may be parsed
+// with ParsePointer.
+func (p Path) Pointer() string {
+	var b []byte
+	for _, key := range p {
+		b = append(b, '/')
+		b = append(b, escapePointerToken(key)...)
+	}
+	return string(b)
+}
+
+// String returns dotted representation of p, which may be parsed
+// with ParsePath.
+func (p Path) String() string {
+	var b []byte
+	for i, key := range p {
+		if i > 0 {
+			b = append(b, '.')
+		}
+		for j := 0; j < len(key); j++ {
+			if key[j] == '.' || key[j] == '\\' {
+				b = append(b, '\\')
+			}
+			b = append(b, key[j])
+		}
+	}
+	return string(b)
+}
+
+// GetByPath returns value by the given dotted path such as "server.hosts.0.name".
+//
+// Dots inside keys must be escaped with backslash. See ParsePath for details.
+//
+// nil is returned for non-existing or invalid path.
+func (v *Value) GetByPath(path string) *Value {
+	return v.GetByPathSep(path, '.')
+}
+
+// GetByPathSep returns value by the given path with keys delimited by sep.
+//
+// See ParsePathSep for details.
+//
+// nil is returned for non-existing or invalid path.
+func (v *Value) GetByPathSep(path string, sep byte) *Value {
+	p, err := ParsePathSep(path, sep)
+	if err != nil {
+		return nil
+	}
+	return v.Get(p...)
+}
+
+// LookupIn returns the value at p inside v.
+//
+// An error is returned if the value is missing. The error contains the
+// longest existing prefix of p and the type of the value at it.
+//
+// The returned value is valid until Parse is called on the Parser returned v.
+func (p Path) LookupIn(v *Value) (*Value, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot lookup %q in nil value", p)
+	}
+	for i, key := range p {
+		child, err := lookupKey(v, key, p[:i])
+		if err != nil {
+			return nil, err
+		}
+		v = child
+	}
+	return v, nil
+}
+
+// lookupKey returns the value for the given key in v located at prefix.
+func lookupKey(v *Value, key string, prefix Path) (*Value, error) {
+	switch v.Type() {
+	case TypeObject:
+		child := v.o.Get(key)
+		if child == nil {
+			return nil, fmt.Errorf("missing key %q at %q", key, prefix)
+		}
+		return child, nil
+	case TypeArray:
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid array index %q at %q", key, prefix)
+		}
+		if n >= len(v.a) {
+			return nil, fmt.Errorf("array index %d at %q is out of range; array length is %d", n, prefix, len(v.a))
+		}
+		return v.a[n], nil
+	default:
+		return nil, fmt.Errorf("cannot lookup key %q in %s at %q", key, v.Type(), prefix)
+	}
+}
+
+// SetIn sets newVal at p inside v.
+//
+// Missing intermediate objects are created via a. Array items may be
+// replaced or appended by setting the index equal to the array length.
+// The root value cannot be replaced, so p must be non-empty.
+//
+// newVal must be unchanged during v lifetime.
+func (p Path) SetIn(a *Arena, v *Value, newVal *Value) error {
+	if len(p) == 0 {
+		return fmt.Errorf("cannot set value at the root path")
+	}
+	parent, err := p[:len(p)-1].getOrCreate(a, v)
+	if err != nil {
+		return err
+	}
+	key := p[len(p)-1]
+	switch parent.Type() {
+	case TypeObject:
+		parent.Set(key, newVal)
+	case TypeArray:
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid array index %q at %q", key, p[:len(p)-1])
+		}
+		if n > len(parent.a) {
+			return fmt.Errorf("array index %d at %q is out of range; array length is %d", n, p[:len(p)-1], len(parent.a))
+		}
+		parent.SetArrayItem(n, newVal)
+	default:
+		return fmt.Errorf("cannot set key %q in %s at %q", key, parent.Type(), p[:len(p)-1])
+	}
+	return nil
+}
+
+// getOrCreate returns the value at p inside v, creating missing objects via a.
+func (p Path) getOrCreate(a *Arena, v *Value) (*Value, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot set %q in nil value", p)
+	}
+	for i, key := range p {
+		if v.Type() == TypeObject {
+			child := v.o.Get(key)
+			if child == nil {
+				child = a.NewObject()
+				v.o.Set(key, child)
+			}
+			v = child
+			continue
+		}
+		child, err := lookupKey(v, key, p[:i])
+		if err != nil {
+			return nil, err
+		}
+		v = child
+	}
+	return v, nil
+}
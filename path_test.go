@@ -0,0 +1,188 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	f := func(s string, keysExpected []string) {
+		t.Helper()
+		p, err := ParsePath(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if len(p) != len(keysExpected) {
+			t.Fatalf("unexpected number of keys for %q; got %d; want %d", s, len(p), len(keysExpected))
+		}
+		for i := range p {
+			if p[i] != keysExpected[i] {
+				t.Fatalf("unexpected key #%d for %q; got %q; want %q", i, s, p[i], keysExpected[i])
+			}
+		}
+		if result := p.String(); result != s {
+			t.Fatalf("unexpected String() result; got %q; want %q", result, s)
+		}
+	}
+
+	f(``, nil)
+	f(`foo`, []string{"foo"})
+	f(`foo.0.bar`, []string{"foo", "0", "bar"})
+	f(`hosts.example\.com.port`, []string{"hosts", "example.com", "port"})
+	f(`a\\.b`, []string{`a\`, "b"})
+	f(`a..b`, []string{"a", "", "b"})
+
+	for _, s := range []string{`a\`, `a\b`} {
+		if _, err := ParsePath(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+}
+
+func TestParsePathSep(t *testing.T) {
+	f := func(s string, sep byte, keysExpected []string) {
+		t.Helper()
+		p, err := ParsePathSep(s, sep)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if len(p) != len(keysExpected) {
+			t.Fatalf("unexpected number of keys for %q; got %d; want %d", s, len(p), len(keysExpected))
+		}
+		for i := range p {
+			if p[i] != keysExpected[i] {
+				t.Fatalf("unexpected key #%d for %q; got %q; want %q", i, s, p[i], keysExpected[i])
+			}
+		}
+	}
+
+	f(`a/b.c/0`, '/', []string{"a", "b.c", "0"})
+	f(`a\/b\\c`, '/', []string{"a/b\\c"})
+	f(`a:b`, ':', []string{"a", "b"})
+
+	for _, s := range []string{`a\`, `a\.b`} {
+		if _, err := ParsePathSep(s, '/'); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+	if _, err := ParsePathSep("a", '\\'); err == nil {
+		t.Fatalf("expecting non-nil error for backslash separator")
+	}
+}
+
+func TestValueGetByPath(t *testing.T) {
+	v := MustParse(`server = {hosts = ({name = "foo";}, {name = "bar";});}; "a.b" = 1;`)
+	if s := v.GetByPath("server.hosts.1.name").String(); s != `"bar"` {
+		t.Fatalf("unexpected value; got %s; want %s", s, `"bar"`)
+	}
+	if s := v.GetByPathSep("server/hosts/0/name", '/').String(); s != `"foo"` {
+		t.Fatalf("unexpected value; got %s; want %s", s, `"foo"`)
+	}
+	if x := v.GetByPath("server.missing"); x != nil {
+		t.Fatalf("expecting nil value for missing path; got %s", x)
+	}
+	if x := v.GetByPath(`server\x`); x != nil {
+		t.Fatalf("expecting nil value for invalid path; got %s", x)
+	}
+
+	x := GetByPath([]byte(`a = {b = [1, 2];};`), "a.b.1")
+	if x == nil || x.String() != "2" {
+		t.Fatalf("unexpected value from handy GetByPath; got %v; want 2", x)
+	}
+	if x := GetByPath([]byte(`invalid`), "a"); x != nil {
+		t.Fatalf("expecting nil value for invalid data; got %s", x)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	f := func(s string, keysExpected []string) {
+		t.Helper()
+		p, err := ParsePointer(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if len(p) != len(keysExpected) {
+			t.Fatalf("unexpected number of keys for %q; got %d; want %d", s, len(p), len(keysExpected))
+		}
+		for i := range p {
+			if p[i] != keysExpected[i] {
+				t.Fatalf("unexpected key #%d for %q; got %q; want %q", i, s, p[i], keysExpected[i])
+			}
+		}
+		if result := p.Pointer(); result != s {
+			t.Fatalf("unexpected Pointer() result; got %q; want %q", result, s)
+		}
+	}
+
+	// Examples from RFC 6901.
+	f(``, nil)
+	f(`/foo`, []string{"foo"})
+	f(`/foo/0`, []string{"foo", "0"})
+	f(`/`, []string{""})
+	f(`/a~1b`, []string{"a/b"})
+	f(`/m~0n`, []string{"m~n"})
+	f(`/~01`, []string{"~1"})
+	f(`/a.b/c`, []string{"a.b", "c"})
+
+	for _, s := range []string{`foo`, `/a~`, `/a~2`} {
+		if _, err := ParsePointer(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+}
+
+func TestPathLookupIn(t *testing.T) {
+	v := MustParse(`server: {host: "localhost"; ports: [80, 443]}; name: "app"`)
+
+	f := func(path, resultExpected string) {
+		t.Helper()
+		x, err := MustParsePath(path).LookupIn(v)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", path, err)
+		}
+		if result := x.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %s; want %s", path, result, resultExpected)
+		}
+	}
+	f(`name`, `"app"`)
+	f(`server.host`, `"localhost"`)
+	f(`server.ports.1`, `443`)
+
+	fErr := func(path, errExpected string) {
+		t.Helper()
+		_, err := MustParsePath(path).LookupIn(v)
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", path)
+		}
+		if err.Error() != errExpected {
+			t.Fatalf("unexpected error for %q; got %q; want %q", path, err, errExpected)
+		}
+	}
+	fErr(`server.missing`, `missing key "missing" at "server"`)
+	fErr(`server.ports.2`, `array index 2 at "server.ports" is out of range; array length is 2`)
+	fErr(`server.ports.x`, `invalid array index "x" at "server.ports"`)
+	fErr(`name.foo`, `cannot lookup key "foo" in string at "name"`)
+}
+
+func TestPathSetIn(t *testing.T) {
+	var a Arena
+	v := MustParse(`server: {ports: [80]}; name: "app"`)
+
+	f := func(path, resultExpected string) {
+		t.Helper()
+		if err := MustParsePath(path).SetIn(&a, v, a.NewNumberInt(1)); err != nil {
+			t.Fatalf("unexpected error for %q: %s", path, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", path, result, resultExpected)
+		}
+	}
+	f(`server.ports.0`, `{"server":{"ports":[1]},"name":"app"}`)
+	f(`server.ports.1`, `{"server":{"ports":[1,1]},"name":"app"}`)
+	f(`db.conn.port`, `{"server":{"ports":[1,1]},"name":"app","db":{"conn":{"port":1}}}`)
+
+	for _, path := range []string{``, `server.ports.5`, `server.ports.x.y`, `name.foo`} {
+		if err := MustParsePath(path).SetIn(&a, v, valueNull); err == nil {
+			t.Fatalf("expecting non-nil error for %q", path)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// defaultPathCacheMaxEntries is the default PathCache.MaxEntries.
+const defaultPathCacheMaxEntries = 1024
+
+// PathCache caches paths compiled by ParsePath and ParsePointer.
+//
+// This is useful when a small set of dynamic path strings is resolved
+// at high rates. The cache is bounded, so it is safe to use with
+// paths obtained from untrusted sources.
+//
+// PathCache may be used from concurrent goroutines.
+// The zero PathCache is ready for use.
+type PathCache struct {
+	// MaxEntries is the maximum number of cached paths.
+	//
+	// Arbitrary entries are evicted from the full cache.
+	// 1024 is used by default.
+	MaxEntries int
+
+	mu sync.RWMutex
+	m  map[pathCacheKey]Path
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type pathCacheKey struct {
+	s       string
+	pointer bool
+}
+
+// PathCacheStats contains PathCache counters.
+type PathCacheStats struct {
+	// Hits is the number of lookups served from the cache.
+	Hits uint64
+
+	// Misses is the number of lookups, which required path compilation.
+	Misses uint64
+
+	// Evictions is the number of entries evicted from the full cache.
+	Evictions uint64
+
+	// Entries is the current number of cached paths.
+	Entries int
+}
+
+// ParsePath returns dotted path s compiled with ParsePath.
+//
+// The returned path is shared between callers, so it mustn't be modified.
+func (pc *PathCache) ParsePath(s string) (Path, error) {
+	return pc.get(s, false)
+}
+
+// ParsePointer returns JSON Pointer s compiled with ParsePointer.
+//
+// The returned path is shared between callers, so it mustn't be modified.
+func (pc *PathCache) ParsePointer(s string) (Path, error) {
+	return pc.get(s, true)
+}
+
+// Stats returns pc counters.
+func (pc *PathCache) Stats() PathCacheStats {
+	pc.mu.RLock()
+	n := len(pc.m)
+	pc.mu.RUnlock()
+	return PathCacheStats{
+		Hits:      pc.hits.Load(),
+		Misses:    pc.misses.Load(),
+		Evictions: pc.evictions.Load(),
+		Entries:   n,
+	}
+}
+
+// Reset removes all the entries from pc.
+//
+// Counters aren't reset.
+func (pc *PathCache) Reset() {
+	pc.mu.Lock()
+	pc.m = nil
+	pc.mu.Unlock()
+}
+
+func (pc *PathCache) get(s string, pointer bool) (Path, error) {
+	k := pathCacheKey{
+		s:       s,
+		pointer: pointer,
+	}
+	pc.mu.RLock()
+	p, ok := pc.m[k]
+	pc.mu.RUnlock()
+	if ok {
+		pc.hits.Add(1)
+		return p, nil
+	}
+
+	pc.misses.Add(1)
+	// Copy s, so the cached path doesn't reference the buffer s may point to.
+	s = string(append([]byte(nil), s...))
+	k.s = s
+	var err error
+	if pointer {
+		p, err = ParsePointer(s)
+	} else {
+		p, err = ParsePath(s)
+	}
+	if err != nil {
+		// Invalid paths aren't cached, so they cannot pollute the cache.
+		return nil, err
+	}
+
+	maxEntries := pc.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultPathCacheMaxEntries
+	}
+	pc.mu.Lock()
+	if pc.m == nil {
+		pc.m = make(map[pathCacheKey]Path)
+	}
+	for len(pc.m) >= maxEntries {
+		for key := range pc.m {
+			delete(pc.m, key)
+			pc.evictions.Add(1)
+			break
+		}
+	}
+	pc.m[k] = p
+	pc.mu.Unlock()
+	return p, nil
+}
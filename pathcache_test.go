@@ -0,0 +1,103 @@
+package libconfig
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPathCache(t *testing.T) {
+	var pc PathCache
+
+	f := func(s string, pointer bool, pathExpected string) {
+		t.Helper()
+		var p Path
+		var err error
+		if pointer {
+			p, err = pc.ParsePointer(s)
+		} else {
+			p, err = pc.ParsePath(s)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		if result := p.String(); result != pathExpected {
+			t.Fatalf("unexpected path for %q; got %q; want %q", s, result, pathExpected)
+		}
+	}
+	checkStats := func(hits, misses, evictions uint64, entries int) {
+		t.Helper()
+		stats := pc.Stats()
+		statsExpected := PathCacheStats{
+			Hits:      hits,
+			Misses:    misses,
+			Evictions: evictions,
+			Entries:   entries,
+		}
+		if stats != statsExpected {
+			t.Fatalf("unexpected stats; got %+v; want %+v", stats, statsExpected)
+		}
+	}
+
+	f("a.b", false, "a.b")
+	f("a.b", false, "a.b")
+	checkStats(1, 1, 0, 1)
+
+	// Dotted paths and pointers must be cached separately.
+	f("/a.b", false, "/a.b")
+	f("/a.b", true, `a\.b`)
+	f("/a.b", true, `a\.b`)
+	checkStats(2, 3, 0, 3)
+
+	// Invalid paths mustn't be cached.
+	for i := 0; i < 2; i++ {
+		if _, err := pc.ParsePointer("a"); err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	}
+	checkStats(2, 5, 0, 3)
+
+	pc.Reset()
+	checkStats(2, 5, 0, 0)
+}
+
+func TestPathCacheEviction(t *testing.T) {
+	pc := &PathCache{
+		MaxEntries: 10,
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := pc.ParsePath(fmt.Sprintf("a.%d", i)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	stats := pc.Stats()
+	if stats.Entries != 10 || stats.Evictions != 90 || stats.Misses != 100 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestPathCacheConcurrent(t *testing.T) {
+	var pc PathCache
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				s := fmt.Sprintf("a.b.%d", j%10)
+				p, err := pc.ParsePath(s)
+				if err != nil {
+					panic(fmt.Errorf("unexpected error: %s", err))
+				}
+				if p.String() != s {
+					panic(fmt.Errorf("unexpected path; got %q; want %q", p, s))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	stats := pc.Stats()
+	if stats.Hits+stats.Misses != 5000 || stats.Entries != 10 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gitteamer/libconfig/fastfloat"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// b2sPortable converts b to string without unsafe tricks.
+func b2sPortable(b []byte) string {
+	return string(b)
+}
+
+// s2bPortable converts s to byte slice without unsafe tricks.
+func s2bPortable(s string) []byte {
+	return []byte(s)
+}
+
+// VerifyPlatform verifies the package works correctly on the current platform.
+//
+// It exercises the unsafe fast paths and the number parsing routines against
+// their portable counterparts from the standard library and parses a sample
+// config. It is intended to be called at startup or in CI on exotic platforms
+// such as s390x or riscv64. If it fails, build with purego build tag
+// in order to disable unsafe fast paths and report the error.
+func VerifyPlatform() error {
+	if err := verifyConversions(); err != nil {
+		return fmt.Errorf("unsafe conversions are broken: %s", err)
+	}
+	if err := verifyNumbers(); err != nil {
+		return fmt.Errorf("number parsing is broken: %s", err)
+	}
+	if err := verifyStructural(); err != nil {
+		return fmt.Errorf("structural scanning is broken: %s", err)
+	}
+	if err := verifyParse(); err != nil {
+		return fmt.Errorf("parsing is broken: %s", err)
+	}
+	return nil
+}
+
+func verifyConversions() error {
+	for _, n := range []int{0, 1, 7, 8, 15, 16, 255, 4096} {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i*31 + 7)
+		}
+		s := b2s(b)
+		if s != b2sPortable(b) {
+			return fmt.Errorf("b2s(%d bytes) returned unexpected string", n)
+		}
+		bb := s2b(s)
+		if !bytes.Equal(bb, s2bPortable(s)) || cap(bb) < len(s) {
+			return fmt.Errorf("s2b(%d bytes) returned unexpected bytes", n)
+		}
+		if unsafeFastPaths && n > 0 && &bb[0] != &b[0] {
+			return fmt.Errorf("b2s and s2b copy memory for %d bytes", n)
+		}
+	}
+	return nil
+}
+
+func verifyNumbers() error {
+	ints := []int64{0, 1, -1, 42, -42, 1 << 31, -1 << 31, 1<<53 + 1, math.MaxInt64, math.MinInt64}
+	for _, n := range ints {
+		s := strconv.FormatInt(n, 10)
+		x, err := fastfloat.ParseInt64(s)
+		if err != nil || x != n {
+			return fmt.Errorf("ParseInt64(%q) = %d, %v; want %d", s, x, err, n)
+		}
+		f := fastfloat.ParseBestEffort(s)
+		if f != float64(n) {
+			return fmt.Errorf("ParseBestEffort(%q) = %v; want %v", s, f, float64(n))
+		}
+	}
+	uints := []uint64{0, 1, 1 << 32, 1<<63 + 5, math.MaxUint64}
+	for _, n := range uints {
+		s := strconv.FormatUint(n, 10)
+		x, err := fastfloat.ParseUint64(s)
+		if err != nil || x != n {
+			return fmt.Errorf("ParseUint64(%q) = %d, %v; want %d", s, x, err, n)
+		}
+	}
+	floats := []string{"0", "-0.5", "3.141592654", "1e10", "1.5E-7", "123456789.123456789", "2.2250738585072014e-308", "1.7976931348623157e308"}
+	for _, s := range floats {
+		want, _ := strconv.ParseFloat(s, 64)
+		f, err := fastfloat.Parse(s)
+		if err != nil || f != want {
+			return fmt.Errorf("Parse(%q) = %v, %v; want %v", s, f, err, want)
+		}
+	}
+	if n, err := parseIntToken("0x1FC3"); err != nil || n != 0x1FC3 {
+		return fmt.Errorf("cannot parse hex number 0x1FC3: %d, %v", n, err)
+	}
+	return nil
+}
+
+func verifyStructural() error {
+	chars := " \t\r\n:=x\x00\x80\xba\xbd\xff"
+	var b []byte
+	for n := 0; n < 64; n++ {
+		for i := 0; i < len(chars); i++ {
+			// Put every char at every position after whitespace or key chars.
+			b = append(b[:0], strings.Repeat(" \t", n/2)...)
+			b = append(b, strings.Repeat("k", n%2)...)
+			b = append(b, chars[i])
+			b = append(b, "  = x"...)
+			s := string(b)
+			if n, nExpected := indexNonWS(s), indexNonWSGeneric(s); n != nExpected {
+				return fmt.Errorf("indexNonWS(%q) = %d; want %d", s, n, nExpected)
+			}
+			if n, nExpected := indexKeySep(s), indexKeySepGeneric(s); n != nExpected {
+				return fmt.Errorf("indexKeySep(%q) = %d; want %d", s, n, nExpected)
+			}
+		}
+	}
+	return nil
+}
+
+func verifyParse() error {
+	var p Parser
+	v, err := p.Parse(`s = "föo\tbar"; n = -1234; f = 1.5; b = true; big = 9223372036854775807L;
+		a = [1, 2, 3]; o = { k = "v"; };`)
+	if err != nil {
+		return err
+	}
+	if s := string(v.GetStringBytes("s")); s != "föo\tbar" {
+		return fmt.Errorf("unexpected string %q", s)
+	}
+	if n := v.GetInt("n"); n != -1234 {
+		return fmt.Errorf("unexpected int %d", n)
+	}
+	if f := v.GetFloat64("f"); f != 1.5 {
+		return fmt.Errorf("unexpected float %v", f)
+	}
+	if !v.GetBool("b") {
+		return fmt.Errorf("unexpected bool")
+	}
+	if n := v.GetBigint("big"); n.Int64() != math.MaxInt64 {
+		return fmt.Errorf("unexpected big int %s", n)
+	}
+	if s := v.String(); s != `{"s":"föo\tbar","n":-1234,"f":1.5,"b":true,"big":9223372036854775807L,"a":[1,2,3],"o":{"k":"v"}}` {
+		return fmt.Errorf("unexpected marshaled value %s", s)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestVerifyPlatform(t *testing.T) {
+	if err := VerifyPlatform(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
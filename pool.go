@@ -27,32 +27,123 @@ package libconfig
 
 import (
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 // ParserPool may be used for pooling Parsers for similarly typed JSONs.
+//
+// The zero ParserPool retains an unbounded number of idle Parsers
+// of any size. Set MaxParsers and MaxBufferSize before the first use
+// in order to bound memory usage.
 type ParserPool struct {
+	// MaxParsers is the maximum number of idle Parsers retained by the pool.
+	//
+	// Parsers Put into the full pool are discarded.
+	// There is no limit if MaxParsers is zero.
+	MaxParsers int
+
+	// MaxBufferSize is the maximum size in bytes of the buffers retained
+	// by an idle Parser.
+	//
+	// Parsers with bigger buffers are discarded on Put, so a single huge
+	// document doesn't pin its memory in the pool forever.
+	// There is no limit if MaxBufferSize is zero.
+	MaxBufferSize int
+
 	pool sync.Pool
+
+	idleOnce sync.Once
+	idle     chan *Parser
+
+	gets               atomic.Uint64
+	puts               atomic.Uint64
+	news               atomic.Uint64
+	discardedOversized atomic.Uint64
+	discardedFull      atomic.Uint64
+}
+
+// ParserPoolStats contains ParserPool counters.
+type ParserPoolStats struct {
+	// Gets is the number of Get calls.
+	Gets uint64
+
+	// Puts is the number of Put calls.
+	Puts uint64
+
+	// News is the number of Parsers created by Get calls.
+	News uint64
+
+	// DiscardedOversized is the number of Parsers discarded by Put calls
+	// because of exceeded MaxBufferSize.
+	DiscardedOversized uint64
+
+	// DiscardedFull is the number of Parsers discarded by Put calls
+	// because of exceeded MaxParsers.
+	DiscardedFull uint64
 }
 
 // Get returns a Parser from pp.
 //
 // The Parser must be Put to pp after use.
 func (pp *ParserPool) Get() *Parser {
-	v := pp.pool.Get()
-	if v == nil {
-		return &Parser{}
+	pp.gets.Add(1)
+	if pp.MaxParsers > 0 {
+		select {
+		case p := <-pp.getIdle():
+			return p
+		default:
+		}
+	} else if v := pp.pool.Get(); v != nil {
+		return v.(*Parser)
 	}
-	return v.(*Parser)
+	pp.news.Add(1)
+	return &Parser{}
 }
 
 // Put returns p to pp.
 //
 // p and objects recursively returned from p cannot be used after p
-// is put into pp.
+// is put into pp. p.Config is reset, so settings for the current document
+// don't leak to the next user of p.
 func (pp *ParserPool) Put(p *Parser) {
+	p.mustNotBeScoped()
+	poisonBytes(p.b[:cap(p.b)])
+	p.Config = ParserConfig{}
+	pp.puts.Add(1)
+	if pp.MaxBufferSize > 0 && p.bufferSize() > pp.MaxBufferSize {
+		pp.discardedOversized.Add(1)
+		return
+	}
+	if pp.MaxParsers > 0 {
+		select {
+		case pp.getIdle() <- p:
+		default:
+			pp.discardedFull.Add(1)
+		}
+		return
+	}
 	pp.pool.Put(p)
 }
 
+// Stats returns pp counters.
+func (pp *ParserPool) Stats() ParserPoolStats {
+	return ParserPoolStats{
+		Gets:               pp.gets.Load(),
+		Puts:               pp.puts.Load(),
+		News:               pp.news.Load(),
+		DiscardedOversized: pp.discardedOversized.Load(),
+		DiscardedFull:      pp.discardedFull.Load(),
+	}
+}
+
+func (pp *ParserPool) getIdle() chan *Parser {
+	pp.idleOnce.Do(func() {
+		pp.idle = make(chan *Parser, pp.MaxParsers)
+	})
+	return pp.idle
+}
+
 // ArenaPool may be used for pooling Arenas for similarly typed JSONs.
 type ArenaPool struct {
 	pool sync.Pool
@@ -75,3 +166,8 @@ func (ap *ArenaPool) Get() *Arena {
 func (ap *ArenaPool) Put(a *Arena) {
 	ap.pool.Put(a)
 }
+
+// bufferSize returns the size in bytes of the buffers retained by p.
+func (p *Parser) bufferSize() int {
+	return cap(p.b) + cap(p.c.vs)*int(unsafe.Sizeof(Value{}))
+}
@@ -0,0 +1,56 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParserPoolBounded(t *testing.T) {
+	pp := &ParserPool{
+		MaxParsers:    2,
+		MaxBufferSize: 64 * 1024,
+	}
+
+	ps := []*Parser{pp.Get(), pp.Get(), pp.Get()}
+	for _, p := range ps {
+		if _, err := p.Parse(`foo = "bar";`); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		pp.Put(p)
+	}
+
+	// Idle parsers must be re-used.
+	p := pp.Get()
+	if p != ps[0] && p != ps[1] {
+		t.Fatalf("expecting re-used parser")
+	}
+
+	// Oversized parsers must be discarded.
+	big := `foo = "` + strings.Repeat("x", 100*1024) + `";`
+	if _, err := p.Parse(big); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pp.Put(p)
+
+	stats := pp.Stats()
+	statsExpected := ParserPoolStats{
+		Gets:               4,
+		Puts:               4,
+		News:               3,
+		DiscardedOversized: 1,
+		DiscardedFull:      1,
+	}
+	if stats != statsExpected {
+		t.Fatalf("unexpected stats\ngot\n%+v\nwant\n%+v", stats, statsExpected)
+	}
+}
+
+func TestParserPoolStats(t *testing.T) {
+	var pp ParserPool
+	p := pp.Get()
+	pp.Put(p)
+	stats := pp.Stats()
+	if stats.Gets != 1 || stats.Puts != 1 || stats.News != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
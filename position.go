@@ -0,0 +1,148 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"strings"
+)
+
+// position is the location of a value in the parsed input.
+type position struct {
+	// offset is the byte offset of the value start.
+	offset int
+
+	// end is the byte offset after the value end.
+	end int
+
+	// line and column are 1-based line and column for offset.
+	line   int
+	column int
+}
+
+// Offset returns the byte offset of v in the parsed input.
+//
+// -1 is returned if the position is unknown, e.g. if v wasn't obtained
+// from Parser with ParserConfig.TrackPositions enabled, if v was created
+// via Arena or Clone, or if v was read from @include file.
+func (v *Value) Offset() int {
+	if v == nil || v.pos == nil {
+		return -1
+	}
+	return v.pos.offset
+}
+
+// EndOffset returns the byte offset after the end of v in the parsed input.
+//
+// -1 is returned if the position is unknown. See Value.Offset for details.
+func (v *Value) EndOffset() int {
+	if v == nil || v.pos == nil {
+		return -1
+	}
+	return v.pos.end
+}
+
+// LineColumn returns 1-based line and column of v in the parsed input.
+//
+// Columns are counted in bytes. Zeros are returned if the position is unknown.
+// See Value.Offset for details.
+func (v *Value) LineColumn() (int, int) {
+	if v == nil || v.pos == nil {
+		return 0, 0
+	}
+	return v.pos.line, v.pos.column
+}
+
+// source returns the input for the current parse without the wrapper
+// added by Parser.Parse.
+func (c *cache) source() string {
+	if c.unwrapped || len(c.src) < len("{};") {
+		return c.src
+	}
+	return c.src[1 : len(c.src)-len("};")]
+}
+
+// offset returns the offset of the tail s of c.src in the parsed input.
+func (c *cache) offset(s string) int {
+	src := c.source()
+	n := len(c.src) - len(s)
+	if !c.unwrapped {
+		n--
+	}
+	if n < 0 {
+		return 0
+	}
+	if n > len(src) {
+		return len(src)
+	}
+	return n
+}
+
+// positionAt returns the position of the tail s of c.src.
+//
+// Lines are counted incrementally from the previously located position,
+// since values are usually located in the input order.
+func (c *cache) positionAt(s string) position {
+	n := c.offset(s)
+	if c.line.line == 0 || n < c.line.offset {
+		c.line = position{
+			line:   1,
+			column: 1,
+		}
+	}
+	prefix := c.source()[c.line.offset:n]
+	if i := strings.LastIndexByte(prefix, '\n'); i >= 0 {
+		c.line.line += strings.Count(prefix, "\n")
+		c.line.column = len(prefix) - i
+	} else {
+		c.line.column += len(prefix)
+	}
+	c.line.offset = n
+	return c.line
+}
+
+// setPosition sets the position for v starting at start and ending
+// at the tail of c.src.
+//
+// Shared values such as true, false and null are replaced with their copies.
+func (c *cache) setPosition(v *Value, start position, tail string) *Value {
+	if v == valueTrue || v == valueFalse || v == valueNull {
+		t := v.t
+		v = c.getValue()
+		*v = Value{
+			t: t,
+		}
+	}
+	start.end = c.offset(tail)
+	if cap(c.ps) > len(c.ps) {
+		c.ps = c.ps[:len(c.ps)+1]
+	} else {
+		c.ps = append(c.ps, position{})
+	}
+	pos := &c.ps[len(c.ps)-1]
+	*pos = start
+	v.pos = pos
+	return v
+}
@@ -0,0 +1,71 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValuePosition(t *testing.T) {
+	const doc = "a = 1;\nsrv = {\n  hosts = [\"x\", true];\n  port = 8080;\n};\n"
+	var p Parser
+	p.Config.TrackPositions = true
+	v, err := p.Parse(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f := func(raw string, line, column int, keys ...string) {
+		t.Helper()
+		x := v.Get(keys...)
+		if x == nil {
+			t.Fatalf("cannot find value at %q", keys)
+		}
+		start, end := x.Offset(), x.EndOffset()
+		if start < 0 || end > len(doc) || start > end {
+			t.Fatalf("unexpected offsets for %q: %d, %d", keys, start, end)
+		}
+		if s := doc[start:end]; s != raw {
+			t.Fatalf("unexpected raw value for %q; got %q; want %q", keys, s, raw)
+		}
+		lineGot, columnGot := x.LineColumn()
+		if lineGot != line || columnGot != column {
+			t.Fatalf("unexpected line:column for %q; got %d:%d; want %d:%d", keys, lineGot, columnGot, line, column)
+		}
+	}
+
+	f(doc, 1, 1)
+	f("1", 1, 5, "a")
+	f("{\n  hosts = [\"x\", true];\n  port = 8080;\n}", 2, 7, "srv")
+	f(`["x", true]`, 3, 11, "srv", "hosts")
+	f(`"x"`, 3, 12, "srv", "hosts", "0")
+	f("true", 3, 17, "srv", "hosts", "1")
+	f("8080", 4, 10, "srv", "port")
+
+	// Shared values must have distinct positions.
+	v, err = p.Parse("a = true;\nb = true;")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if line, _ := v.Get("b").LineColumn(); line != 2 {
+		t.Fatalf("unexpected line; got %d; want %d", line, 2)
+	}
+	if line, _ := v.Get("a").LineColumn(); line != 1 {
+		t.Fatalf("unexpected line; got %d; want %d", line, 1)
+	}
+
+	// Positions aren't tracked by default.
+	var p2 Parser
+	v, err = p2.Parse(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	x := v.Get("a")
+	if n := x.Offset(); n != -1 {
+		t.Fatalf("unexpected offset; got %d; want -1", n)
+	}
+	if line, column := x.LineColumn(); line != 0 || column != 0 {
+		t.Fatalf("unexpected line:column; got %d:%d; want 0:0", line, column)
+	}
+	if n := x.Clone().EndOffset(); n != -1 {
+		t.Fatalf("unexpected end offset for clone; got %d; want -1", n)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// TypeAt returns the type of the value at the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// false is returned for non-existing keys path.
+func (v *Value) TypeAt(keys ...string) (Type, bool) {
+	v = v.Get(keys...)
+	if v == nil {
+		return TypeNull, false
+	}
+	return v.Type(), true
+}
+
+// IsObject returns true if the value at the given keys path is an object.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsObject(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && t == TypeObject
+}
+
+// IsArray returns true if the value at the given keys path is an array.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsArray(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && t == TypeArray
+}
+
+// IsString returns true if the value at the given keys path is a string.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsString(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && t == TypeString
+}
+
+// IsNumber returns true if the value at the given keys path is a number.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsNumber(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && t == TypeNumber
+}
+
+// IsBool returns true if the value at the given keys path is true or false.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsBool(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && (t == TypeTrue || t == TypeFalse)
+}
+
+// IsNull returns true if the value at the given keys path is null.
+//
+// false is returned for non-existing keys path.
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) IsNull(keys ...string) bool {
+	t, ok := v.TypeAt(keys...)
+	return ok && t == TypeNull
+}
+
+// ExistsType checks the value at the given keys path against type t.
+//
+// exists is false for non-existing keys path, while ok is true only
+// if the value exists and has type t. This allows distinguishing missing
+// values from values of unexpected type with a single lookup.
+// TypeTrue and TypeFalse match any bool value.
+//
+// Array indexes may be represented as decimal numbers in keys.
+func (v *Value) ExistsType(t Type, keys ...string) (exists, ok bool) {
+	typ, exists := v.TypeAt(keys...)
+	if !exists {
+		return false, false
+	}
+	if t == TypeTrue || t == TypeFalse {
+		return true, typ == TypeTrue || typ == TypeFalse
+	}
+	return true, typ == t
+}
+
+// ExistsObject is the same as ExistsType for TypeObject.
+func (v *Value) ExistsObject(keys ...string) (exists, ok bool) {
+	return v.ExistsType(TypeObject, keys...)
+}
+
+// ExistsArray is the same as ExistsType for TypeArray.
+func (v *Value) ExistsArray(keys ...string) (exists, ok bool) {
+	return v.ExistsType(TypeArray, keys...)
+}
+
+// ExistsString is the same as ExistsType for TypeString.
+func (v *Value) ExistsString(keys ...string) (exists, ok bool) {
+	return v.ExistsType(TypeString, keys...)
+}
+
+// ExistsNumber is the same as ExistsType for TypeNumber.
+func (v *Value) ExistsNumber(keys ...string) (exists, ok bool) {
+	return v.ExistsType(TypeNumber, keys...)
+}
+
+// ExistsBool is the same as ExistsType for bool values.
+func (v *Value) ExistsBool(keys ...string) (exists, ok bool) {
+	return v.ExistsType(TypeTrue, keys...)
+}
+
+// ExistsInt checks whether the value at the given keys path exists
+// and contains an integer fitting int.
+//
+// See ExistsType for details.
+func (v *Value) ExistsInt(keys ...string) (exists, ok bool) {
+	v = v.Get(keys...)
+	if v == nil {
+		return false, false
+	}
+	if v.Type() != TypeNumber || !isIntToken(v.s) {
+		return true, false
+	}
+	n, err := parseIntToken(v.s)
+	return true, err == nil && int64(int(n)) == n
+}
@@ -0,0 +1,114 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueTypeAt(t *testing.T) {
+	v := MustParse(`o = {a = 1;}; a = [1, "x", null]; s = "foo"; n = 1.5; t = true; f = false;`)
+
+	f := func(path []string, typeExpected Type, okExpected bool) {
+		t.Helper()
+		typ, ok := v.TypeAt(path...)
+		if ok != okExpected {
+			t.Fatalf("unexpected ok for %q; got %v; want %v", path, ok, okExpected)
+		}
+		if ok && typ != typeExpected {
+			t.Fatalf("unexpected type for %q; got %s; want %s", path, typ, typeExpected)
+		}
+	}
+
+	f(nil, TypeObject, true)
+	f([]string{"o"}, TypeObject, true)
+	f([]string{"o", "a"}, TypeNumber, true)
+	f([]string{"a"}, TypeArray, true)
+	f([]string{"a", "1"}, TypeString, true)
+	f([]string{"a", "2"}, TypeNull, true)
+	f([]string{"s"}, TypeString, true)
+	f([]string{"t"}, TypeTrue, true)
+	f([]string{"f"}, TypeFalse, true)
+	f([]string{"missing"}, TypeNull, false)
+	f([]string{"a", "3"}, TypeNull, false)
+
+	var vNil *Value
+	if _, ok := vNil.TypeAt("a"); ok {
+		t.Fatalf("expecting false for nil value")
+	}
+}
+
+func TestValuePredicates(t *testing.T) {
+	v := MustParse(`o = {a = 1;}; a = [1, "x", null]; s = "foo"; n = 1.5; t = true; f = false;`)
+
+	f := func(name string, pred func(keys ...string) bool, keysExpected ...string) {
+		t.Helper()
+		for _, key := range []string{"o", "a", "s", "n", "t", "f", "missing"} {
+			resultExpected := false
+			for _, k := range keysExpected {
+				if k == key {
+					resultExpected = true
+				}
+			}
+			if result := pred(key); result != resultExpected {
+				t.Fatalf("unexpected %s result for %q; got %v; want %v", name, key, result, resultExpected)
+			}
+		}
+	}
+
+	f("IsObject", v.IsObject, "o")
+	f("IsArray", v.IsArray, "a")
+	f("IsString", v.IsString, "s")
+	f("IsNumber", v.IsNumber, "n")
+	f("IsBool", v.IsBool, "t", "f")
+	f("IsNull", v.IsNull)
+
+	if !v.IsNull("a", "2") {
+		t.Fatalf("expecting null at a.2")
+	}
+	if !v.IsNumber("o", "a") {
+		t.Fatalf("expecting number at o.a")
+	}
+}
+
+func TestValueExistsType(t *testing.T) {
+	v := MustParse(`o = {a = 1;}; a = [1, "x"]; s = "foo"; n = 1.5; i = 0x10; big = 100000000000000000000L; t = true; f = false; z = null;`)
+
+	f := func(name string, check func(keys ...string) (bool, bool), key string, existsExpected, okExpected bool) {
+		t.Helper()
+		exists, ok := check(key)
+		if exists != existsExpected || ok != okExpected {
+			t.Fatalf("unexpected %s result for %q; got (%v, %v); want (%v, %v)", name, key, exists, ok, existsExpected, okExpected)
+		}
+	}
+
+	f("ExistsObject", v.ExistsObject, "o", true, true)
+	f("ExistsObject", v.ExistsObject, "a", true, false)
+	f("ExistsObject", v.ExistsObject, "missing", false, false)
+	f("ExistsArray", v.ExistsArray, "a", true, true)
+	f("ExistsArray", v.ExistsArray, "s", true, false)
+	f("ExistsString", v.ExistsString, "s", true, true)
+	f("ExistsString", v.ExistsString, "n", true, false)
+	f("ExistsString", v.ExistsString, "missing", false, false)
+	f("ExistsNumber", v.ExistsNumber, "n", true, true)
+	f("ExistsNumber", v.ExistsNumber, "z", true, false)
+	f("ExistsBool", v.ExistsBool, "t", true, true)
+	f("ExistsBool", v.ExistsBool, "f", true, true)
+	f("ExistsBool", v.ExistsBool, "s", true, false)
+	f("ExistsInt", v.ExistsInt, "i", true, true)
+	f("ExistsInt", v.ExistsInt, "n", true, false)
+	f("ExistsInt", v.ExistsInt, "big", true, false)
+	f("ExistsInt", v.ExistsInt, "s", true, false)
+	f("ExistsInt", v.ExistsInt, "missing", false, false)
+
+	exists, ok := v.ExistsType(TypeNull, "z")
+	if !exists || !ok {
+		t.Fatalf("unexpected result for null; got (%v, %v); want (true, true)", exists, ok)
+	}
+	exists, ok = v.ExistsType(TypeFalse, "t")
+	if !exists || !ok {
+		t.Fatalf("TypeFalse must match true value; got (%v, %v)", exists, ok)
+	}
+	exists, ok = v.ExistsType(TypeNumber, "o", "a")
+	if !exists || !ok {
+		t.Fatalf("unexpected result for o.a; got (%v, %v); want (true, true)", exists, ok)
+	}
+}
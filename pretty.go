@@ -0,0 +1,232 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"io"
+	"strconv"
+)
+
+// Printer writes human-readable representation of values to W.
+//
+// The output is intended for terminals and debugging, so it isn't
+// valid JSON. Use Encoder for machine-readable output.
+//
+// Printer cannot be used from concurrent goroutines.
+type Printer struct {
+	// W is the destination for the printed values.
+	W io.Writer
+
+	// Color enables coloring values by type with ANSI escape sequences.
+	Color bool
+
+	// MaxDepth is the maximum depth for printed values.
+	//
+	// Deeper objects and arrays are collapsed into a summary
+	// such as {… 3 keys}. There is no limit if MaxDepth is zero.
+	MaxDepth int
+
+	// MaxArrayItems is the maximum number of printed items per array.
+	//
+	// The remaining items are collapsed into a summary such as
+	// … 10 more items. There is no limit if MaxArrayItems is zero.
+	MaxArrayItems int
+
+	// Gutter enables printing the path to the value at every line.
+	Gutter bool
+
+	b     []byte
+	lines []prettyLine
+}
+
+type prettyLine struct {
+	path  string
+	start int
+}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorKey    = "\x1b[34m"
+	colorString = "\x1b[32m"
+	colorNumber = "\x1b[36m"
+	colorBool   = "\x1b[33m"
+	colorMuted  = "\x1b[90m"
+)
+
+// Print writes v to pr.W.
+func (pr *Printer) Print(v *Value) error {
+	pr.b = pr.b[:0]
+	pr.lines = pr.lines[:0]
+	pr.newLine(nil, 0)
+	pr.appendValue(v, nil, 0)
+	pr.b = append(pr.b, '\n')
+	b := pr.b
+	if pr.Gutter {
+		b = pr.appendGutter(nil)
+	}
+	_, err := pr.W.Write(b)
+	return err
+}
+
+// appendGutter appends pr.b lines prefixed with paths to dst.
+func (pr *Printer) appendGutter(dst []byte) []byte {
+	width := 0
+	for _, l := range pr.lines {
+		if len(l.path) > width {
+			width = len(l.path)
+		}
+	}
+	for i, l := range pr.lines {
+		end := len(pr.b)
+		if i+1 < len(pr.lines) {
+			end = pr.lines[i+1].start
+		}
+		dst = pr.colored(dst, colorMuted, func(dst []byte) []byte {
+			dst = append(dst, l.path...)
+			for j := len(l.path); j < width; j++ {
+				dst = append(dst, ' ')
+			}
+			return append(dst, " | "...)
+		})
+		dst = append(dst, pr.b[l.start:end]...)
+	}
+	return dst
+}
+
+// newLine starts a new line for the value at path with the given indent.
+func (pr *Printer) newLine(path Path, indent int) {
+	if len(pr.b) > 0 {
+		pr.b = append(pr.b, '\n')
+	}
+	if pr.Gutter {
+		pr.lines = append(pr.lines, prettyLine{
+			path:  path.String(),
+			start: len(pr.b),
+		})
+	}
+	for i := 0; i < indent; i++ {
+		pr.b = append(pr.b, "  "...)
+	}
+}
+
+func (pr *Printer) colored(dst []byte, color string, f func(dst []byte) []byte) []byte {
+	if !pr.Color {
+		return f(dst)
+	}
+	dst = append(dst, color...)
+	dst = f(dst)
+	return append(dst, colorReset...)
+}
+
+func (pr *Printer) appendSummary(s string) {
+	pr.b = pr.colored(pr.b, colorMuted, func(dst []byte) []byte {
+		return append(dst, s...)
+	})
+}
+
+func (pr *Printer) appendValue(v *Value, path Path, depth int) {
+	if v == nil {
+		v = valueNull
+	}
+	switch v.Type() {
+	case TypeObject:
+		n := v.o.Len()
+		if n == 0 {
+			pr.b = append(pr.b, "{}"...)
+			return
+		}
+		if pr.MaxDepth > 0 && depth >= pr.MaxDepth {
+			pr.appendSummary("{… " + pluralize(n, "key") + "}")
+			return
+		}
+		pr.b = append(pr.b, '{')
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			childPath := append(path[:len(path):len(path)], kv.k)
+			pr.newLine(childPath, depth+1)
+			pr.b = pr.colored(pr.b, colorKey, func(dst []byte) []byte {
+				if isClassicName(kv.k) {
+					return append(dst, kv.k...)
+				}
+				return strconv.AppendQuote(dst, kv.k)
+			})
+			pr.b = append(pr.b, ": "...)
+			pr.appendValue(kv.v, childPath, depth+1)
+		}
+		pr.newLine(path, depth)
+		pr.b = append(pr.b, '}')
+	case TypeArray:
+		n := len(v.a)
+		if n == 0 {
+			pr.b = append(pr.b, "[]"...)
+			return
+		}
+		if pr.MaxDepth > 0 && depth >= pr.MaxDepth {
+			pr.appendSummary("[… " + pluralize(n, "item") + "]")
+			return
+		}
+		pr.b = append(pr.b, '[')
+		for i, item := range v.a {
+			childPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if pr.MaxArrayItems > 0 && i >= pr.MaxArrayItems {
+				pr.newLine(childPath, depth+1)
+				pr.appendSummary("… " + strconv.Itoa(n-i) + " more " + pluralizeWord(n-i, "item"))
+				break
+			}
+			pr.newLine(childPath, depth+1)
+			pr.appendValue(item, childPath, depth+1)
+		}
+		pr.newLine(path, depth)
+		pr.b = append(pr.b, ']')
+	case TypeString:
+		pr.b = pr.colored(pr.b, colorString, func(dst []byte) []byte {
+			return strconv.AppendQuote(dst, v.s)
+		})
+	case TypeNumber, TypeRawJSON:
+		pr.b = pr.colored(pr.b, colorNumber, func(dst []byte) []byte {
+			return append(dst, v.s...)
+		})
+	case TypeTrue, TypeFalse:
+		pr.b = pr.colored(pr.b, colorBool, func(dst []byte) []byte {
+			return append(dst, v.Type().String()...)
+		})
+	default:
+		pr.b = pr.colored(pr.b, colorMuted, func(dst []byte) []byte {
+			return append(dst, "null"...)
+		})
+	}
+}
+
+func pluralize(n int, word string) string {
+	return strconv.Itoa(n) + " " + pluralizeWord(n, word)
+}
+
+func pluralizeWord(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,99 @@
+package libconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrinter(t *testing.T) {
+	f := func(pr *Printer, s, resultExpected string) {
+		t.Helper()
+		var bb bytes.Buffer
+		pr.W = &bb
+		if err := pr.Print(MustParse(s)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := bb.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %s\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(&Printer{}, ``, "{}\n")
+	f(&Printer{}, `a = 1; b = "x\ny"; d = [true, null, {}]; e = [];`, `{
+  a: 1
+  b: "x\ny"
+  d: [
+    true
+    null
+    {}
+  ]
+  e: []
+}
+`)
+
+	// MaxDepth and MaxArrayItems
+	f(&Printer{MaxDepth: 1}, `a = {b = 1; c = 2;}; d = [1]; e = 1;`, `{
+  a: {… 2 keys}
+  d: [… 1 item]
+  e: 1
+}
+`)
+	f(&Printer{MaxArrayItems: 2}, `a = [1, 2, 3, 4]; b = [1, 2, 3]; c = [1, 2];`, `{
+  a: [
+    1
+    2
+    … 2 more items
+  ]
+  b: [
+    1
+    2
+    … 1 more item
+  ]
+  c: [
+    1
+    2
+  ]
+}
+`)
+
+	// Gutter
+	f(&Printer{Gutter: true}, `a = {b = [1, "x"];}; d = 2;`, strings.Join([]string{
+		"      | {",
+		"a     |   a: {",
+		"a.b   |     b: [",
+		"a.b.0 |       1",
+		"a.b.1 |       \"x\"",
+		"a.b   |     ]",
+		"a     |   }",
+		"d     |   d: 2",
+		"      | }",
+		"",
+	}, "\n"))
+
+	// Keys requiring quotes
+	var a Arena
+	o := a.NewObject()
+	o.Set("b.c d", a.NewNumberInt(1))
+	var bb bytes.Buffer
+	pr := &Printer{W: &bb, Gutter: true}
+	if err := pr.Print(o); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resultExpected := "       | {\n" +
+		"b\\.c d |   \"b.c d\": 1\n" +
+		"       | }\n"
+	if result := bb.String(); result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Color
+	f(&Printer{Color: true}, `a = ["x", 1, false, null];`, "{\n"+
+		"  \x1b[34ma\x1b[0m: [\n"+
+		"    \x1b[32m\"x\"\x1b[0m\n"+
+		"    \x1b[36m1\x1b[0m\n"+
+		"    \x1b[33mfalse\x1b[0m\n"+
+		"    \x1b[90mnull\x1b[0m\n"+
+		"  ]\n"+
+		"}\n")
+}
@@ -0,0 +1,258 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned by Parser.Parse for syntax errors.
+type SyntaxError struct {
+	// Msg describes the error.
+	Msg string
+
+	// Line and Column are 1-based position of the error in the parsed input.
+	//
+	// They are zero if the error is located in @include file.
+	Line   int
+	Column int
+
+	// Tail is the beginning and the end of the unparsed tail.
+	Tail string
+}
+
+// Error implements error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("cannot parse libconfig: %s; unparsed tail: %q", e.Msg, e.Tail)
+}
+
+// syntaxError returns SyntaxError for the err occurred at the tail s.
+func (c *cache) syntaxError(err error, s string) *SyntaxError {
+	e := &SyntaxError{
+		Msg:  c.includeError(err, s).Error(),
+		Tail: startEndString(s),
+	}
+	if c.inSource(s) {
+		e.Line, e.Column = c.lineColumn(s)
+	}
+	return e
+}
+
+// Problem is a machine-readable description of a config problem.
+//
+// Problems may be returned to clients as JSON via MarshalTo.
+// Use ProblemsFromError for converting errors returned by this package
+// into problems.
+type Problem struct {
+	// Code identifies the problem kind. It is one of "syntax", "schema",
+	// "spec", "lint" or "error".
+	Code string
+
+	// Path is the path to the problematic value if known.
+	//
+	// Its format depends on Code: JSON Pointer for "schema" problems
+	// and dotted path for "spec" and "lint" problems.
+	Path string
+
+	// Line and Column are 1-based position of the problem if known.
+	Line   int
+	Column int
+
+	// Message describes the problem.
+	Message string
+
+	// Hint is an optional suggestion for fixing the problem.
+	Hint string
+}
+
+// Error implements error interface.
+func (p *Problem) Error() string {
+	switch {
+	case p.Line > 0:
+		return fmt.Sprintf("line %d, column %d: %s", p.Line, p.Column, p.Message)
+	case p.Path != "":
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	default:
+		return p.Message
+	}
+}
+
+// MarshalTo appends JSON representation of p to dst and returns the result.
+//
+// The result is an object with code, path, line, column, message and hint
+// fields. Empty fields are omitted.
+func (p *Problem) MarshalTo(dst []byte) []byte {
+	var a Arena
+	return p.value(&a).MarshalTo(dst)
+}
+
+func (p *Problem) value(a *Arena) *Value {
+	v := a.NewObject()
+	v.Set("code", a.NewString(p.Code))
+	if p.Path != "" {
+		v.Set("path", a.NewString(p.Path))
+	}
+	if p.Line > 0 {
+		v.Set("line", a.NewNumberInt(p.Line))
+		v.Set("column", a.NewNumberInt(p.Column))
+	}
+	v.Set("message", a.NewString(p.Message))
+	if p.Hint != "" {
+		v.Set("hint", a.NewString(p.Hint))
+	}
+	return v
+}
+
+// Problems is a list of problems.
+type Problems []*Problem
+
+// Error implements error interface.
+func (ps Problems) Error() string {
+	a := make([]string, len(ps))
+	for i, p := range ps {
+		a[i] = p.Error()
+	}
+	return strings.Join(a, "; ")
+}
+
+// MarshalTo appends JSON array with ps to dst and returns the result.
+func (ps Problems) MarshalTo(dst []byte) []byte {
+	var a Arena
+	v := a.NewArray()
+	for i, p := range ps {
+		v.SetArrayItem(i, p.value(&a))
+	}
+	return v.MarshalTo(dst)
+}
+
+// ProblemsFromError converts err into problems.
+//
+// SyntaxError, SchemaError, SchemaErrors, SpecError and SpecErrors are
+// converted into problems with the corresponding codes, while other errors
+// are converted into a single problem with "error" code.
+//
+// nil is returned for nil err.
+func ProblemsFromError(err error) Problems {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case Problems:
+		return e
+	case *Problem:
+		return Problems{e}
+	case *SyntaxError:
+		return Problems{{
+			Code:    "syntax",
+			Line:    e.Line,
+			Column:  e.Column,
+			Message: e.Msg,
+			Hint:    syntaxHint(e.Msg),
+		}}
+	case *SchemaError:
+		return Problems{schemaProblem(e)}
+	case SchemaErrors:
+		ps := make(Problems, len(e))
+		for i, se := range e {
+			ps[i] = schemaProblem(se)
+		}
+		return ps
+	case *SpecError:
+		return Problems{specProblem(e)}
+	case SpecErrors:
+		ps := make(Problems, len(e))
+		for i, se := range e {
+			ps[i] = specProblem(se)
+		}
+		return ps
+	default:
+		return Problems{{
+			Code:    "error",
+			Message: err.Error(),
+		}}
+	}
+}
+
+// ProblemsFromLint converts lint findings into problems with "lint" code.
+func ProblemsFromLint(findings []LintFinding) Problems {
+	ps := make(Problems, len(findings))
+	for i := range findings {
+		f := &findings[i]
+		ps[i] = &Problem{
+			Code:    "lint",
+			Path:    f.Path,
+			Message: fmt.Sprintf("%s (%s)", f.Message, f.Rule),
+		}
+	}
+	return ps
+}
+
+func schemaProblem(e *SchemaError) *Problem {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return &Problem{
+		Code:    "schema",
+		Path:    path,
+		Message: e.Message,
+	}
+}
+
+func specProblem(e *SpecError) *Problem {
+	return &Problem{
+		Code:    "spec",
+		Path:    e.Path,
+		Message: e.Message,
+	}
+}
+
+// syntaxHints maps substrings of syntax error messages to hints.
+//
+// The first matching entry wins, so more specific entries go first.
+var syntaxHints = []struct {
+	substr string
+	hint   string
+}{
+	{"duplicate key", "remove or rename one of the duplicate settings"},
+	{"invalid setting name", "setting names must start with a letter or '*' and contain only letters, digits, '-', '_' and '*'"},
+	{"missing ';'", "terminate every setting with ';'"},
+	{"missing ':' or '='", "separate setting names from values with '=' or ':'"},
+	{`missing closing '"'`, `close the string with '"'`},
+	{"missing ']'", "close the array with ']'"},
+	{"missing ',' after array value", "separate array items with ','"},
+	{"unexpected end of object", "close the group with '};'"},
+	{"@include", "check the path of the included file"},
+}
+
+func syntaxHint(msg string) string {
+	for _, h := range syntaxHints {
+		if strings.Contains(msg, h.substr) {
+			return h.hint
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package libconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	f := func(s string, lineExpected, columnExpected int) {
+		t.Helper()
+		var p Parser
+		_, err := p.Parse(s)
+		e, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("expecting *SyntaxError for %q; got %T: %v", s, err, err)
+		}
+		if e.Line != lineExpected || e.Column != columnExpected {
+			t.Fatalf("unexpected position for %q; got line %d, column %d; want line %d, column %d",
+				s, e.Line, e.Column, lineExpected, columnExpected)
+		}
+	}
+
+	f("a = ", 1, 5)
+	f("a = 1;\nb = [1, 2;", 2, 10)
+	f("a = 1;\n\n  b = {c = 1 d = 2;};", 3, 14)
+}
+
+func TestProblemsFromError(t *testing.T) {
+	f := func(err error, resultExpected string) {
+		t.Helper()
+		result := string(ProblemsFromError(err).MarshalTo(nil))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %v; got %s; want %s", err, result, resultExpected)
+		}
+	}
+
+	f(nil, "[]")
+	f(fmt.Errorf("foo \"bar\""), `[{"code":"error","message":"foo \"bar\""}]`)
+
+	var p Parser
+	_, err := p.Parse("a = 1;\nb = 2 c = 3;")
+	f(err, `[{"code":"syntax","line":2,"column":7,"message":"cannot parse object: missing ';' after object value, or missing '};' for close object","hint":"terminate every setting with ';'"}]`)
+
+	schema := MustCompileSchema(MustParse(`
+		type = "object";
+		required = ["port"];
+		properties = { name = { type = "string"; }; };
+	`))
+	err = schema.Validate(MustParse(`name = 1;`))
+	f(err, `[{"code":"schema","path":"/","message":"missing required property \"port\""},{"code":"schema","path":"/name","message":"unexpected type integer; want string"}]`)
+
+	f(SpecErrors{{Path: "db.port", Message: "missing"}}, `[{"code":"spec","path":"db.port","message":"missing"}]`)
+
+	ps := Problems{{Code: "error", Message: "foo"}}
+	if result := ProblemsFromError(ps); len(result) != 1 || result[0] != ps[0] {
+		t.Fatalf("problems must be returned as is")
+	}
+}
+
+func TestProblemsFromLint(t *testing.T) {
+	l := &Linter{
+		Rules: []LintRule{
+			&UnknownKeysRule{
+				Known: []string{"port"},
+			},
+		},
+	}
+	ps := ProblemsFromLint(l.Lint(MustParse(`port = 1; prot = 2;`)))
+	result := string(ps.MarshalTo(nil))
+	resultExpected := `[{"code":"lint","path":"prot","message":"unknown key \"prot\" (unknown-keys)"}]`
+	if result != resultExpected {
+		t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+	}
+	if s := ps.Error(); s != `prot: unknown key "prot" (unknown-keys)` {
+		t.Fatalf("unexpected error string: %s", s)
+	}
+}
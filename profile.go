@@ -0,0 +1,135 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ProfilesKey is the object key for profile-scoped overrides.
+//
+// See ApplyProfile for details.
+const ProfilesKey = "$profiles"
+
+// ApplyProfile returns v with overrides for the given profile applied.
+//
+// Overrides are stored in `$profiles` objects, which may be located
+// at any level of v:
+//
+//	server = {
+//	  host = "localhost";
+//	  port = 8080;
+//	};
+//	$profiles = {
+//	  prod = { server = { host = "example.com"; }; };
+//	  staging = { server = { port = 9090; }; };
+//	};
+//
+// The override for the profile is deep-merged into the object containing
+// the `$profiles` object after the nested objects are processed.
+// Objects are merged recursively, while other values including arrays
+// replace the original values. `$profiles` objects are removed
+// from the result. Objects without override for the profile remain
+// unchanged, so a missing profile isn't an error.
+//
+// v isn't modified. Objects and arrays in the result are allocated in a,
+// while scalar values are shared with v.
+func ApplyProfile(v *Value, a *Arena, profile string) (*Value, error) {
+	return applyProfile(a, v, profile, nil)
+}
+
+func applyProfile(a *Arena, v *Value, profile string, path Path) (*Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch v.t {
+	case TypeObject:
+		o := a.NewObject()
+		var override *Value
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			if kv.k == ProfilesKey {
+				x, err := profileOverride(kv.v, profile, path)
+				if err != nil {
+					return nil, err
+				}
+				if x != nil {
+					override = x
+				}
+				continue
+			}
+			x, err := applyProfile(a, kv.v, profile, append(path[:len(path):len(path)], kv.k))
+			if err != nil {
+				return nil, err
+			}
+			okv := o.o.getKV()
+			okv.k = kv.k
+			okv.v = x
+		}
+		o.o.keysUnescaped = true
+		if override == nil {
+			return o, nil
+		}
+		x, err := applyProfile(a, override, profile, append(path[:len(path):len(path)], ProfilesKey, profile))
+		if err != nil {
+			return nil, err
+		}
+		var m Merger
+		return m.mergeValue(a, o, x), nil
+	case TypeArray:
+		arr := a.NewArray()
+		for i, item := range v.a {
+			x, err := applyProfile(a, item, profile, append(path[:len(path):len(path)], strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			arr.SetArrayItem(i, x)
+		}
+		return arr, nil
+	default:
+		return v, nil
+	}
+}
+
+// profileOverride returns the override for the profile from profiles
+// located at path.
+//
+// nil is returned if profiles doesn't contain the profile.
+func profileOverride(profiles *Value, profile string, path Path) (*Value, error) {
+	p := append(path[:len(path):len(path)], ProfilesKey)
+	if profiles.Type() != TypeObject {
+		return nil, fmt.Errorf("unexpected type at %q; got %s; want %s", p, profiles.Type(), TypeObject)
+	}
+	x := profiles.o.Get(profile)
+	if x == nil {
+		return nil, nil
+	}
+	if x.Type() != TypeObject {
+		return nil, fmt.Errorf("unexpected type for profile %q at %q; got %s; want %s", profile, p, x.Type(), TypeObject)
+	}
+	return x, nil
+}
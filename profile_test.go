@@ -0,0 +1,49 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	f := func(s, profile, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		vStr := v.String()
+		var a Arena
+		result, err := ApplyProfile(v, &a, profile)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if s := result.String(); s != resultExpected {
+			t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", s, resultExpected)
+		}
+		if v.String() != vStr {
+			t.Fatalf("v must be unchanged; got\n%s\nwant\n%s", v.String(), vStr)
+		}
+	}
+
+	doc := `server = { host = "localhost"; port = 8080; tags = ["a"]; };
+		$profiles = {
+			prod = { server = { host = "example.com"; tags = ["b", "c"]; }; debug = false; };
+			dev = { debug = true; };
+		};`
+	f(doc, "prod", `{"server":{"host":"example.com","port":8080,"tags":["b","c"]},"debug":false}`)
+	f(doc, "dev", `{"server":{"host":"localhost","port":8080,"tags":["a"]},"debug":true}`)
+	f(doc, "missing", `{"server":{"host":"localhost","port":8080,"tags":["a"]}}`)
+
+	// Nested profiles are applied before the outer ones.
+	f(`db = { pool = 1; $profiles = { prod = { pool = 10; }; }; };
+		$profiles = { prod = { db = { timeout = 5; }; }; };`,
+		"prod", `{"db":{"pool":10,"timeout":5}}`)
+	f(`items = ({ x = 1; $profiles = { prod = { x = 2; }; }; }, 3);`, "prod", `{"items":[{"x":2},3]}`)
+
+	fErr := func(s, profile string) {
+		t.Helper()
+		var a Arena
+		if _, err := ApplyProfile(MustParse(s), &a, profile); err == nil {
+			t.Fatalf("expecting non-nil error for %s", s)
+		}
+	}
+	fErr(`$profiles = 1;`, "prod")
+	fErr(`x = { $profiles = { prod = [1]; }; };`, "prod")
+}
@@ -0,0 +1,212 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrQuotaExceeded is returned by TenantManager when a parse request
+// doesn't fit into the tenant quota.
+var ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+// TenantQuota contains limits applied to a single tenant by TenantManager.
+//
+// Zero values mean no limit.
+type TenantQuota struct {
+	// MaxBytes is the maximum number of bytes the tenant may parse in total.
+	MaxBytes int64
+
+	// MaxConcurrent is the maximum number of parses the tenant may run
+	// at the same time.
+	MaxConcurrent int
+
+	// Queue makes parse requests exceeding MaxConcurrent wait for a free slot
+	// instead of being rejected with ErrQuotaExceeded.
+	Queue bool
+}
+
+// TenantUsage contains usage counters for a single tenant.
+type TenantUsage struct {
+	// BytesParsed is the number of bytes parsed by the tenant so far.
+	BytesParsed int64
+
+	// Active is the number of currently running parses.
+	Active int
+
+	// Rejected is the number of parse requests rejected due to quota.
+	Rejected int64
+}
+
+// TenantManager parses data on behalf of multiple tenants while enforcing
+// per-tenant quotas on parsed bytes and concurrent parses.
+//
+// TenantManager may be used from concurrent goroutines.
+type TenantManager struct {
+	// Default is the quota applied to tenants without explicit quota.
+	Default TenantQuota
+
+	mu      sync.Mutex
+	tenants map[string]*tenantState
+
+	pp ParserPool
+}
+
+type tenantState struct {
+	quota    TenantQuota
+	hasQuota bool
+	usage    TenantUsage
+
+	// wakeCh is closed and replaced each time a parse slot is released.
+	wakeCh chan struct{}
+}
+
+// SetQuota sets quota q for the given tenant.
+func (tm *TenantManager) SetQuota(tenant string, q TenantQuota) {
+	tm.mu.Lock()
+	ts := tm.getTenantLocked(tenant)
+	ts.quota = q
+	ts.hasQuota = true
+	tm.wakeLocked(ts)
+	tm.mu.Unlock()
+}
+
+// Usage returns usage counters for the given tenant.
+func (tm *TenantManager) Usage(tenant string) TenantUsage {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	ts := tm.tenants[tenant]
+	if ts == nil {
+		return TenantUsage{}
+	}
+	return ts.usage
+}
+
+// ResetUsage resets the parsed bytes and rejection counters for the given tenant.
+//
+// This is usually called periodically in order to implement per-period quotas.
+func (tm *TenantManager) ResetUsage(tenant string) {
+	tm.mu.Lock()
+	if ts := tm.tenants[tenant]; ts != nil {
+		ts.usage.BytesParsed = 0
+		ts.usage.Rejected = 0
+	}
+	tm.mu.Unlock()
+}
+
+// Parse parses data on behalf of tenant and calls f with the parsed value.
+//
+// v is valid only during f call, since the underlying Parser is re-used
+// after f returns.
+//
+// ErrQuotaExceeded is returned if data doesn't fit the tenant quota.
+// If the tenant quota has Queue set, Parse waits for a free parse slot
+// until ctx is done.
+func (tm *TenantManager) Parse(ctx context.Context, tenant string, data []byte, f func(v *Value) error) error {
+	if err := tm.acquire(ctx, tenant, int64(len(data))); err != nil {
+		return err
+	}
+	defer tm.release(tenant)
+
+	p := tm.pp.Get()
+	defer tm.pp.Put(p)
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return f(v)
+}
+
+func (tm *TenantManager) acquire(ctx context.Context, tenant string, n int64) error {
+	tm.mu.Lock()
+	ts := tm.getTenantLocked(tenant)
+	for {
+		q := tm.quotaLocked(ts)
+		if q.MaxBytes > 0 && ts.usage.BytesParsed+n > q.MaxBytes {
+			ts.usage.Rejected++
+			tm.mu.Unlock()
+			return fmt.Errorf("%w: tenant %q cannot parse %d bytes; %d of %d bytes already parsed",
+				ErrQuotaExceeded, tenant, n, ts.usage.BytesParsed, q.MaxBytes)
+		}
+		if q.MaxConcurrent <= 0 || ts.usage.Active < q.MaxConcurrent {
+			ts.usage.BytesParsed += n
+			ts.usage.Active++
+			tm.mu.Unlock()
+			return nil
+		}
+		if !q.Queue {
+			ts.usage.Rejected++
+			tm.mu.Unlock()
+			return fmt.Errorf("%w: tenant %q already runs %d concurrent parses", ErrQuotaExceeded, tenant, ts.usage.Active)
+		}
+
+		wakeCh := ts.wakeCh
+		tm.mu.Unlock()
+		select {
+		case <-wakeCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		tm.mu.Lock()
+	}
+}
+
+func (tm *TenantManager) release(tenant string) {
+	tm.mu.Lock()
+	ts := tm.tenants[tenant]
+	ts.usage.Active--
+	tm.wakeLocked(ts)
+	tm.mu.Unlock()
+}
+
+func (tm *TenantManager) quotaLocked(ts *tenantState) TenantQuota {
+	if ts.hasQuota {
+		return ts.quota
+	}
+	return tm.Default
+}
+
+func (tm *TenantManager) wakeLocked(ts *tenantState) {
+	close(ts.wakeCh)
+	ts.wakeCh = make(chan struct{})
+}
+
+func (tm *TenantManager) getTenantLocked(tenant string) *tenantState {
+	if tm.tenants == nil {
+		tm.tenants = make(map[string]*tenantState)
+	}
+	ts := tm.tenants[tenant]
+	if ts == nil {
+		ts = &tenantState{
+			wakeCh: make(chan struct{}),
+		}
+		tm.tenants[tenant] = ts
+	}
+	return ts
+}
@@ -0,0 +1,89 @@
+package libconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTenantManagerBytesQuota(t *testing.T) {
+	var tm TenantManager
+	tm.SetQuota("foo", TenantQuota{MaxBytes: 30})
+
+	data := []byte(`foo="bar"; baz=1234;`)
+	var s string
+	err := tm.Parse(context.Background(), "foo", data, func(v *Value) error {
+		s = string(v.GetStringBytes("foo"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s != "bar" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "bar")
+	}
+
+	err = tm.Parse(context.Background(), "foo", data, func(v *Value) error { return nil })
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expecting ErrQuotaExceeded; got %v", err)
+	}
+	u := tm.Usage("foo")
+	if u.BytesParsed != int64(len(data)) || u.Rejected != 1 || u.Active != 0 {
+		t.Fatalf("unexpected usage: %+v", u)
+	}
+
+	// Other tenants use the default quota.
+	if err := tm.Parse(context.Background(), "bar", data, func(v *Value) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tm.ResetUsage("foo")
+	if err := tm.Parse(context.Background(), "foo", data, func(v *Value) error { return nil }); err != nil {
+		t.Fatalf("unexpected error after usage reset: %s", err)
+	}
+}
+
+func TestTenantManagerConcurrency(t *testing.T) {
+	var tm TenantManager
+	tm.Default = TenantQuota{MaxConcurrent: 1}
+	data := []byte(`foo=1;`)
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = tm.Parse(context.Background(), "foo", data, func(v *Value) error {
+			close(started)
+			<-done
+			return nil
+		})
+	}()
+	<-started
+
+	err := tm.Parse(context.Background(), "foo", data, func(v *Value) error { return nil })
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expecting ErrQuotaExceeded; got %v", err)
+	}
+
+	tm.SetQuota("foo", TenantQuota{MaxConcurrent: 1, Queue: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	err = tm.Parse(ctx, "foo", data, func(v *Value) error { return nil })
+	cancel()
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expecting context.DeadlineExceeded; got %v", err)
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		ch <- tm.Parse(context.Background(), "foo", data, func(v *Value) error { return nil })
+	}()
+	close(done)
+	select {
+	case err := <-ch:
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout")
+	}
+}
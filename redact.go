@@ -0,0 +1,284 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RedactSection is the name of the config section containing
+// the redaction policy. See RedactPolicyFromConfig.
+const RedactSection = "_redact"
+
+// defaultRedactMask is the default replacement for redacted values.
+const defaultRedactMask = "***"
+
+// RedactPolicy masks sensitive values when exporting configs,
+// e.g. for debug output and logs.
+//
+// Policies may be declared in the config itself via RedactSection,
+// so masking rules are kept together with the values they protect.
+type RedactPolicy struct {
+	// Patterns contains dotted path patterns for the redacted values,
+	// e.g. "db.password".
+	//
+	// Every pattern segment may contain '*' wildcards matching any chars
+	// inside a key, e.g. "*secret*" or "servers.*.token". The "**" segment
+	// matches any number of keys, e.g. "**.password" matches password
+	// at any depth. Dots inside keys must be escaped as in ParsePath.
+	// Redacted objects and arrays are masked as a whole.
+	Patterns []string
+
+	// Mask is the replacement for redacted values.
+	//
+	// "***" is used by default.
+	Mask string
+}
+
+// RedactPolicyFromConfig returns the redaction policy declared
+// in RedactSection of v.
+//
+// The section may contain an array of patterns:
+//
+//	_redact = ["db.password", "**.*token*"];
+//
+// or an object with patterns and mask:
+//
+//	_redact = { patterns = ["db.password"]; mask = "<hidden>"; };
+//
+// nil policy is returned if v doesn't contain the section.
+func RedactPolicyFromConfig(v *Value) (*RedactPolicy, error) {
+	section := v.Get(RedactSection)
+	if section == nil {
+		return nil, nil
+	}
+	return NewRedactPolicy(section)
+}
+
+// NewRedactPolicy returns the redaction policy from the given policy value.
+//
+// See RedactPolicyFromConfig for the supported formats.
+func NewRedactPolicy(policy *Value) (*RedactPolicy, error) {
+	var rp RedactPolicy
+	patterns := policy
+	if policy.Type() == TypeObject {
+		patterns = policy.Get("patterns")
+		if mask := policy.Get("mask"); mask != nil {
+			b, err := mask.StringBytes()
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s.mask: %s", RedactSection, err)
+			}
+			rp.Mask = string(b)
+		}
+	}
+	if patterns == nil {
+		return nil, fmt.Errorf("missing %s.patterns", RedactSection)
+	}
+	items, err := patterns.Array()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s patterns: %s", RedactSection, err)
+	}
+	for _, item := range items {
+		b, err := item.StringBytes()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern: %s", RedactSection, err)
+		}
+		if _, err := ParsePath(string(b)); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %s", RedactSection, b, err)
+		}
+		rp.Patterns = append(rp.Patterns, string(b))
+	}
+	return &rp, nil
+}
+
+func (rp *RedactPolicy) mask() string {
+	if rp.Mask == "" {
+		return defaultRedactMask
+	}
+	return rp.Mask
+}
+
+// MarshalTo appends marshaled v to dst with the values matching
+// rp patterns replaced by mask and returns the result.
+//
+// Invalid patterns are ignored.
+func (rp *RedactPolicy) MarshalTo(dst []byte, v *Value) []byte {
+	r := rp.redactor()
+	return r.marshalTo(dst, v, nil)
+}
+
+// Redact returns a copy of v with the values matching rp patterns
+// replaced by mask strings.
+//
+// The returned value may reference non-redacted values from v.
+// New values are allocated in a. Invalid patterns are ignored.
+func (rp *RedactPolicy) Redact(a *Arena, v *Value) *Value {
+	r := rp.redactor()
+	return r.redact(a, v, nil)
+}
+
+// Redact returns a copy of v with the values for keys matching keyPatterns
+// replaced by "***" at any depth, e.g. for logging.
+//
+// Key patterns may contain '*' wildcards matching any chars inside a key,
+// e.g. "password", "token" or "*_secret". Redacted objects and arrays are
+// masked as a whole. Use RedactPolicy for path-based patterns.
+//
+// v isn't modified. Objects and arrays in the returned value are allocated
+// in a, while scalar values may be shared with v.
+func Redact(v *Value, a *Arena, keyPatterns []string) *Value {
+	r := &redactor{
+		mask: defaultRedactMask,
+	}
+	for _, key := range keyPatterns {
+		r.patterns = append(r.patterns, Path{"**", key})
+	}
+	return r.redact(a, v, nil)
+}
+
+// MarshalRedactedTo appends marshaled v to dst with values redacted according
+// to the policy declared in RedactSection of v, and returns the result.
+//
+// The whole v is masked if the policy is invalid, so sensitive values
+// don't leak because of typos in the policy.
+func (v *Value) MarshalRedactedTo(dst []byte) []byte {
+	rp, err := RedactPolicyFromConfig(v)
+	if err != nil {
+		return escapeString(dst, defaultRedactMask)
+	}
+	if rp == nil {
+		return v.MarshalTo(dst)
+	}
+	return rp.MarshalTo(dst, v)
+}
+
+type redactor struct {
+	patterns []Path
+	mask     string
+}
+
+func (rp *RedactPolicy) redactor() *redactor {
+	r := &redactor{
+		mask: defaultRedactMask,
+	}
+	if rp == nil {
+		return r
+	}
+	r.mask = rp.mask()
+	for _, s := range rp.Patterns {
+		if p, err := ParsePath(s); err == nil {
+			r.patterns = append(r.patterns, p)
+		}
+	}
+	return r
+}
+
+func (r *redactor) isRedacted(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for _, p := range r.patterns {
+		if matchRedactPattern(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRedactPattern(path []string, pattern Path) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			for i := 0; i <= len(path); i++ {
+				if matchRedactPattern(path[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 || !matchFile(path[0], pattern[0]) {
+			return false
+		}
+		path = path[1:]
+		pattern = pattern[1:]
+	}
+	return len(path) == 0
+}
+
+func (r *redactor) marshalTo(dst []byte, v *Value, path []string) []byte {
+	if r.isRedacted(path) {
+		return escapeString(dst, r.mask)
+	}
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		dst = append(dst, '{')
+		for i, kv := range v.o.kvs {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = escapeString(dst, kv.k)
+			dst = append(dst, ':')
+			dst = r.marshalTo(dst, kv.v, append(path, kv.k))
+		}
+		return append(dst, '}')
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, item := range v.a {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = r.marshalTo(dst, item, append(path, strconv.Itoa(i)))
+		}
+		return append(dst, ']')
+	default:
+		return v.MarshalTo(dst)
+	}
+}
+
+func (r *redactor) redact(a *Arena, v *Value, path []string) *Value {
+	if r.isRedacted(path) {
+		return a.NewString(r.mask)
+	}
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		o := a.NewObject()
+		for _, kv := range v.o.kvs {
+			o.o.Set(kv.k, r.redact(a, kv.v, append(path, kv.k)))
+		}
+		return o
+	case TypeArray:
+		arr := a.NewArray()
+		for i, item := range v.a {
+			arr.a = append(arr.a, r.redact(a, item, append(path, strconv.Itoa(i))))
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,94 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestRedactPolicy(t *testing.T) {
+	s := `db = {host = "localhost"; password = "secret"; replicas = ({host = "r1"; api_token = "t1";});};
+auth = {keys = ["k1", "k2"];};
+"a.b" = 1;`
+	f := func(patterns []string, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		rp := &RedactPolicy{
+			Patterns: patterns,
+		}
+		result := string(rp.MarshalTo(nil, v))
+		if result != resultExpected {
+			t.Fatalf("unexpected MarshalTo result for %q\ngot\n%s\nwant\n%s", patterns, result, resultExpected)
+		}
+		var a Arena
+		if result := rp.Redact(&a, v).String(); result != resultExpected {
+			t.Fatalf("unexpected Redact result for %q\ngot\n%s\nwant\n%s", patterns, result, resultExpected)
+		}
+	}
+
+	f(nil, `{"db":{"host":"localhost","password":"secret","replicas":[{"host":"r1","api_token":"t1"}]},"auth":{"keys":["k1","k2"]},"\"a.b\"":1}`)
+	f([]string{"db.password"}, `{"db":{"host":"localhost","password":"***","replicas":[{"host":"r1","api_token":"t1"}]},"auth":{"keys":["k1","k2"]},"\"a.b\"":1}`)
+	f([]string{"**.*token*", "auth"}, `{"db":{"host":"localhost","password":"secret","replicas":[{"host":"r1","api_token":"***"}]},"auth":"***","\"a.b\"":1}`)
+	f([]string{"db.replicas.*.host", "auth.keys.1"}, `{"db":{"host":"localhost","password":"secret","replicas":[{"host":"***","api_token":"t1"}]},"auth":{"keys":["k1","***"]},"\"a.b\"":1}`)
+	f([]string{"**.host"}, `{"db":{"host":"***","password":"secret","replicas":[{"host":"***","api_token":"t1"}]},"auth":{"keys":["k1","k2"]},"\"a.b\"":1}`)
+	f([]string{"**"}, `{"db":"***","auth":"***","\"a.b\"":"***"}`)
+	f([]string{`"a\.b"`}, `{"db":{"host":"localhost","password":"secret","replicas":[{"host":"r1","api_token":"t1"}]},"auth":{"keys":["k1","k2"]},"\"a.b\"":"***"}`)
+
+	// Custom mask
+	rp := &RedactPolicy{
+		Patterns: []string{"x"},
+		Mask:     "<hidden>",
+	}
+	if result := string(rp.MarshalTo(nil, MustParse(`x = 1; y = 2;`))); result != `{"x":"<hidden>","y":2}` {
+		t.Fatalf("unexpected result with custom mask: %s", result)
+	}
+}
+
+func TestMarshalRedactedTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		if result := string(v.MarshalRedactedTo(nil)); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(`password = "x";`, `{"password":"x"}`)
+	f(`_redact = ["password"]; password = "x";`, `{"_redact":["password"],"password":"***"}`)
+	f(`_redact = {patterns = ["**.password"]; mask = "-";}; db = {password = "x";};`, `{"_redact":{"patterns":["**.password"],"mask":"-"},"db":{"password":"-"}}`)
+
+	// Invalid policies mask the whole value.
+	f(`_redact = "password"; password = "x";`, `"***"`)
+	f(`_redact = [1]; password = "x";`, `"***"`)
+	f(`_redact = ["a\\b"]; password = "x";`, `"***"`)
+	f(`_redact = {mask = 1; patterns = [];}; password = "x";`, `"***"`)
+
+	if _, err := RedactPolicyFromConfig(MustParse(`_redact = {};`)); err == nil {
+		t.Fatalf("expecting non-nil error for policy without patterns")
+	}
+	rp, err := RedactPolicyFromConfig(MustParse(`a = 1;`))
+	if err != nil || rp != nil {
+		t.Fatalf("unexpected result for config without policy: %v, %v", rp, err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	s := `password = "p"; db = {user = "u"; token = "t"; api_secret = "s"; nested = {password = ["a", "b"];};}; list = ({token = "x";}, 1);`
+	v := MustParse(s)
+	var a Arena
+	result := Redact(v, &a, []string{"password", "token", "*_secret"}).String()
+	resultExpected := `{"password":"***","db":{"user":"u","token":"***","api_secret":"***","nested":{"password":"***"}},"list":[{"token":"***"},1]}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Verify the original value isn't modified.
+	if result := v.String(); result != MustParse(s).String() {
+		t.Fatalf("the original value has been modified: %s", result)
+	}
+
+	// Verify the returned value may be modified without affecting the original value.
+	r := Redact(v, &a, nil)
+	r.Get("db").Set("user", a.NewString("x"))
+	if user := string(v.GetStringBytes("db", "user")); user != "u" {
+		t.Fatalf("unexpected user in the original value; got %q; want %q", user, "u")
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// maxRegexpCacheEntries is the maximum number of regexps cached
+// by Value.Regexp.
+const maxRegexpCacheEntries = 1024
+
+var regexpCache struct {
+	mu sync.RWMutex
+	m  map[string]*regexp.Regexp
+}
+
+// Regexp returns regexp compiled from the underlying pattern string for the v.
+//
+// Compiled regexps are cached by pattern, so the same patterns aren't
+// recompiled on config reloads. The cache is bounded, so it is safe to use
+// with patterns obtained from untrusted sources. The returned regexp
+// is shared between callers, so it may be used from concurrent goroutines.
+//
+// Use GetRegexp for obtaining regexp by keys path.
+func (v *Value) Regexp() (*regexp.Regexp, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain regexp; it contains %s", v.Type())
+	}
+
+	regexpCache.mu.RLock()
+	re, ok := regexpCache.m[v.s]
+	regexpCache.mu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	// Copy v.s, so the cache doesn't reference Parser buffers.
+	pattern := string(append([]byte(nil), v.s...))
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		// Invalid patterns aren't cached, so they cannot pollute the cache.
+		return nil, fmt.Errorf("cannot compile regexp %q: %s", pattern, err)
+	}
+
+	regexpCache.mu.Lock()
+	if regexpCache.m == nil {
+		regexpCache.m = make(map[string]*regexp.Regexp)
+	}
+	for len(regexpCache.m) >= maxRegexpCacheEntries {
+		for k := range regexpCache.m {
+			delete(regexpCache.m, k)
+			break
+		}
+	}
+	regexpCache.m[pattern] = re
+	regexpCache.mu.Unlock()
+	return re, nil
+}
+
+// GetRegexp returns regexp compiled from the pattern string
+// by the given keys path.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// See Value.Regexp for details.
+func (v *Value) GetRegexp(keys ...string) (*regexp.Regexp, error) {
+	x := v.Get(keys...)
+	if x == nil {
+		return nil, fmt.Errorf("missing value at %q", Path(keys))
+	}
+	return x.Regexp()
+}
@@ -0,0 +1,33 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestValueGetRegexp(t *testing.T) {
+	v := MustParse(`routes = ["^/api/v[0-9]+/", "^/static/"]; bad = "(["; num = 1;`)
+
+	re, err := v.GetRegexp("routes", "0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !re.MatchString("/api/v2/users") || re.MatchString("/static/x") {
+		t.Fatalf("unexpected regexp behavior for %q", re)
+	}
+
+	// The compiled regexp must be cached.
+	v2 := MustParse(`route = "^/api/v[0-9]+/";`)
+	re2, err := v2.GetRegexp("route")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if re2 != re {
+		t.Fatalf("expecting cached regexp")
+	}
+
+	for _, key := range []string{"bad", "num", "missing"} {
+		if _, err := v.GetRegexp(key); err == nil {
+			t.Fatalf("expecting non-nil error for %q", key)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RetryPolicy is time-boxed exponential backoff policy for fetching
+// remote configs.
+//
+// The zero value is usable and uses the defaults mentioned below.
+type RetryPolicy struct {
+	// InitialInterval is the delay before the first retry.
+	//
+	// 100ms is used by default.
+	InitialInterval time.Duration
+
+	// MaxInterval is the maximum delay between retries.
+	//
+	// 10s is used by default.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the delay is multiplied by after each retry.
+	//
+	// 2 is used by default.
+	Multiplier float64
+
+	// MaxElapsedTime limits the total time spent on attempts and delays.
+	//
+	// One minute is used by default.
+	MaxElapsedTime time.Duration
+}
+
+// Do calls f until it succeeds, ctx is canceled or rp.MaxElapsedTime passes.
+//
+// The context passed to f is canceled when rp.MaxElapsedTime passes.
+// The last error returned by f is returned on failure.
+func (rp *RetryPolicy) Do(ctx context.Context, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, durationOrDefault(rp.MaxElapsedTime, time.Minute))
+	defer cancel()
+
+	interval := durationOrDefault(rp.InitialInterval, 100*time.Millisecond)
+	maxInterval := durationOrDefault(rp.MaxInterval, 10*time.Second)
+	multiplier := rp.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+		t := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return fmt.Errorf("giving up after %d attempts: %s", attempt, err)
+		case <-t.C:
+		}
+		interval = time.Duration(float64(interval) * multiplier)
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// SourceHealth describes the state of a RemoteSource.
+type SourceHealth struct {
+	// LastSuccess is the time of the last successful load.
+	//
+	// It is zero if the source has never been loaded.
+	LastSuccess time.Time
+
+	// LastError is the error from the last failed load.
+	//
+	// It is nil if the last load succeeded.
+	LastError error
+
+	// LastErrorTime is the time of the last failed load.
+	LastErrorTime time.Time
+
+	// Staleness is the time since LastSuccess.
+	Staleness time.Duration
+
+	// Stale is set if the source has never been loaded or Staleness exceeds
+	// RemoteSource.MaxStaleness.
+	Stale bool
+}
+
+// RemoteSource loads configs from a remote store such as HTTP server,
+// etcd, Consul or S3 with retries and health reporting.
+//
+// The store is accessed via Fetch, so any client may be plugged in.
+// RemoteSource may be used from concurrent goroutines.
+type RemoteSource struct {
+	// Name is the source name used in errors and audit events, e.g. URL.
+	Name string
+
+	// Fetch must return the raw config data from the store.
+	//
+	// Data starting with '{' or '[' is parsed as RFC 8259 JSON,
+	// while other data is parsed as libconfig.
+	Fetch func(ctx context.Context) ([]byte, error)
+
+	// Retry is the policy for retrying failed fetches.
+	Retry RetryPolicy
+
+	// MaxStaleness is the maximum time since the last successful load
+	// before Health reports the source as stale.
+	//
+	// The source never becomes stale after the first successful load
+	// if MaxStaleness is zero.
+	MaxStaleness time.Duration
+
+	// Verifier is an optional verifier for the fetched data.
+	//
+	// Verification failures aren't retried.
+	Verifier Verifier
+
+	// AuditSink is an optional sink for AuditLoaded and AuditLoadFailed events.
+	AuditSink AuditSink
+
+	mu     sync.Mutex
+	health SourceHealth
+}
+
+// Load fetches, verifies and parses the config from rs.
+//
+// Failed fetches are retried according to rs.Retry. Compressed data
+// is transparently decompressed; see RegisterDecompressor.
+// The returned value doesn't reference any Parser, so it remains valid
+// for arbitrary long time.
+func (rs *RemoteSource) Load(ctx context.Context) (*Value, error) {
+	v, checksum, err := rs.load(ctx)
+	now := time.Now()
+	rs.mu.Lock()
+	if err != nil {
+		rs.health.LastError = err
+		rs.health.LastErrorTime = now
+	} else {
+		rs.health.LastSuccess = now
+		rs.health.LastError = nil
+	}
+	rs.mu.Unlock()
+
+	if err != nil {
+		emitAudit(rs.AuditSink, AuditLoadFailed, rs.Name, checksum, err)
+		return nil, err
+	}
+	emitAudit(rs.AuditSink, AuditLoaded, rs.Name, checksum, nil)
+	return v, nil
+}
+
+func (rs *RemoteSource) load(ctx context.Context) (*Value, string, error) {
+	var data []byte
+	err := rs.Retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = rs.Fetch(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot fetch config from %q: %s", rs.Name, err)
+	}
+	checksum := sha256Hex(data)
+	if rs.Verifier != nil {
+		if err := rs.Verifier.Verify(rs.Name, data); err != nil {
+			return nil, checksum, fmt.Errorf("cannot verify config from %q: %s", rs.Name, err)
+		}
+	}
+	v, err := parseRemoteConfig(rs.Name, data, nil)
+	if err != nil {
+		return nil, checksum, err
+	}
+	return v, checksum, nil
+}
+
+// parseRemoteConfig decompresses and parses data fetched from name
+// according to cfg. cfg may be nil.
+//
+// data is parsed as RFC 8259 JSON if it starts with '{' or '[',
+// since such data cannot be valid libconfig.
+func parseRemoteConfig(name string, data []byte, cfg *ParserConfig) (*Value, error) {
+	data, err := decompress(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load config from %q: %s", name, err)
+	}
+	var p Parser
+	if cfg != nil {
+		p.Config = *cfg
+	}
+	if s := skipJSONWS(b2s(data)); len(s) > 0 && (s[0] == '{' || s[0] == '[') {
+		p.Config.StrictRFC8259 = true
+	}
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse config from %q: %s", name, err)
+	}
+	return v.Clone(), nil
+}
+
+// Health returns the current health of rs.
+func (rs *RemoteSource) Health() SourceHealth {
+	rs.mu.Lock()
+	h := rs.health
+	rs.mu.Unlock()
+
+	if h.LastSuccess.IsZero() {
+		h.Stale = true
+		return h
+	}
+	h.Staleness = time.Since(h.LastSuccess)
+	h.Stale = rs.MaxStaleness > 0 && h.Staleness > rs.MaxStaleness
+	return h
+}
@@ -0,0 +1,116 @@
+package libconfig
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	rp := &RetryPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+	}
+
+	// Success after failures.
+	n := 0
+	err := rp.Do(context.Background(), func(ctx context.Context) error {
+		n++
+		if n < 3 {
+			return fmt.Errorf("failure %d", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n != 3 {
+		t.Fatalf("unexpected number of attempts; got %d; want 3", n)
+	}
+
+	// Time box.
+	rp.MaxElapsedTime = 20 * time.Millisecond
+	err = rp.Do(context.Background(), func(ctx context.Context) error {
+		return fmt.Errorf("permanent failure")
+	})
+	if err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// Canceled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rp.MaxElapsedTime = time.Hour
+	err = rp.Do(ctx, func(ctx context.Context) error {
+		return fmt.Errorf("failure")
+	})
+	if err == nil {
+		t.Fatalf("expecting non-nil error for canceled context")
+	}
+}
+
+func TestRemoteSource(t *testing.T) {
+	var data string
+	var fetchErr error
+	rs := &RemoteSource{
+		Name: "test",
+		Fetch: func(ctx context.Context) ([]byte, error) {
+			return []byte(data), fetchErr
+		},
+		Retry: RetryPolicy{
+			InitialInterval: time.Millisecond,
+			MaxElapsedTime:  10 * time.Millisecond,
+		},
+		MaxStaleness: time.Hour,
+	}
+
+	h := rs.Health()
+	if !h.Stale || !h.LastSuccess.IsZero() {
+		t.Fatalf("unexpected health before the first load: %+v", h)
+	}
+
+	data = `port = 8080;`
+	v, err := rs.Load(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want 8080", n)
+	}
+	h = rs.Health()
+	if h.Stale || h.LastSuccess.IsZero() || h.LastError != nil {
+		t.Fatalf("unexpected health after successful load: %+v", h)
+	}
+
+	// Failed fetch keeps LastSuccess.
+	fetchErr = fmt.Errorf("connection refused")
+	if _, err := rs.Load(context.Background()); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	h2 := rs.Health()
+	if h2.LastError == nil || h2.LastErrorTime.IsZero() || h2.LastSuccess != h.LastSuccess || h2.Stale {
+		t.Fatalf("unexpected health after failed load: %+v", h2)
+	}
+
+	// Staleness.
+	rs.MaxStaleness = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if h := rs.Health(); !h.Stale {
+		t.Fatalf("expecting stale source; got %+v", h)
+	}
+
+	// Invalid config.
+	fetchErr = nil
+	data = `port = ;`
+	if _, err := rs.Load(context.Background()); err == nil {
+		t.Fatalf("expecting non-nil error for invalid config")
+	}
+
+	// Verification failure.
+	data = `port = 1;`
+	rs.Verifier = SHA256Sum("00")
+	if _, err := rs.Load(context.Background()); err == nil {
+		t.Fatalf("expecting non-nil error for failed verification")
+	}
+}
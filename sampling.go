@@ -0,0 +1,100 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+// sampleBuckets is the number of buckets identities are hashed into.
+//
+// It allows percentages with two decimal digits such as 0.25.
+const sampleBuckets = 10000
+
+// GetSampledBool returns a stable pseudo-random decision for identity
+// according to the percentage at percentKey.
+//
+// seedKey and percentKey are dotted paths in the form accepted by ParsePath,
+// e.g. "rollout.canary.percent". identity is hashed together with the value
+// at seedKey, so the same identity always gets the same decision for
+// the same seed, while changing the seed reshuffles the decisions.
+// Increasing the percentage only adds identities to the sampled set.
+//
+// The value at percentKey must be a number in the range [0 .. 100].
+// Smaller numbers are treated as 0, while bigger numbers are treated as 100.
+// false is returned if the percentage is missing or isn't a number.
+// The seed may be a string or a number. An empty seed is used if it is missing.
+func (v *Value) GetSampledBool(seedKey, percentKey, identity string) bool {
+	pp, err := ParsePath(percentKey)
+	if err != nil {
+		return false
+	}
+	x := v.Get(pp...)
+	if x == nil || x.Type() != TypeNumber {
+		return false
+	}
+	percent, err := parseFloatToken(x.s)
+	if err != nil || math.IsNaN(percent) {
+		return false
+	}
+
+	var seed string
+	if sp, err := ParsePath(seedKey); err == nil {
+		if x := v.Get(sp...); x != nil {
+			switch x.Type() {
+			case TypeString, TypeNumber:
+				seed = x.s
+			}
+		}
+	}
+	return sampled(seed, identity, percent)
+}
+
+// sampled returns true if identity falls into the given percent of buckets
+// for the given seed.
+func sampled(seed, identity string, percent float64) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	return sampleBucket(seed, identity) < uint64(percent*sampleBuckets/100)
+}
+
+// sampleBucket returns the bucket in the range [0 .. sampleBuckets) for identity.
+func sampleBucket(seed, identity string) uint64 {
+	h := sha256.New()
+	h.Write([]byte(seed))
+	// The separator prevents collisions between ("ab", "c") and ("a", "bc").
+	h.Write([]byte{0})
+	h.Write([]byte(identity))
+	var buf [sha256.Size]byte
+	sum := h.Sum(buf[:0])
+	return binary.BigEndian.Uint64(sum) % sampleBuckets
+}
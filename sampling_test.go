@@ -0,0 +1,66 @@
+package libconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetSampledBool(t *testing.T) {
+	f := func(data, identity string, resultExpected bool) {
+		t.Helper()
+		result := GetSampledBool([]byte(data), "canary.seed", "canary.percent", identity)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q and identity %q; got %v; want %v", data, identity, result, resultExpected)
+		}
+	}
+
+	f(`canary = {seed = "x"; percent = 100;};`, "user1", true)
+	f(`canary = {seed = "x"; percent = 150;};`, "user1", true)
+	f(`canary = {percent = 100;};`, "user1", true)
+	f(`canary = {seed = "x"; percent = 0;};`, "user1", false)
+	f(`canary = {seed = "x"; percent = -5;};`, "user1", false)
+	f(`canary = {seed = "x"; percent = "50";};`, "user1", false)
+	f(`canary = {seed = "x";};`, "user1", false)
+	f(`canary = {seed = "x"; percent = nan;};`, "user1", false)
+	f(`canary = `, "user1", false)
+
+	// The decision must be stable.
+	data := []byte(`canary = {seed = "release-42"; percent = 30;};`)
+	v := MustParse(string(data))
+	n := 0
+	for i := 0; i < 10000; i++ {
+		identity := fmt.Sprintf("user%d", i)
+		result := v.GetSampledBool("canary.seed", "canary.percent", identity)
+		if result != GetSampledBool(data, "canary.seed", "canary.percent", identity) {
+			t.Fatalf("unstable decision for identity %q", identity)
+		}
+		if result {
+			n++
+		}
+	}
+	if n < 2700 || n > 3300 {
+		t.Fatalf("unexpected number of sampled identities; got %d; want about %d", n, 3000)
+	}
+
+	// Increasing the percentage must only add identities.
+	v2 := MustParse(`canary = {seed = "release-42"; percent = 60.5;};`)
+	for i := 0; i < 10000; i++ {
+		identity := fmt.Sprintf("user%d", i)
+		if v.GetSampledBool("canary.seed", "canary.percent", identity) && !v2.GetSampledBool("canary.seed", "canary.percent", identity) {
+			t.Fatalf("identity %q must remain sampled after increasing the percentage", identity)
+		}
+	}
+
+	// Changing the seed must reshuffle the decisions.
+	v3 := MustParse(`canary = {seed = 43; percent = 30;};`)
+	same := 0
+	for i := 0; i < 1000; i++ {
+		identity := fmt.Sprintf("user%d", i)
+		if v.GetSampledBool("canary.seed", "canary.percent", identity) == v3.GetSampledBool("canary.seed", "canary.percent", identity) {
+			same++
+		}
+	}
+	if same > 800 {
+		t.Fatalf("too many identical decisions after changing the seed: %d", same)
+	}
+}
@@ -27,6 +27,8 @@ package libconfig
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
 // Scanner scans a series of JSON values. Values may be delimited by whitespace.
@@ -39,6 +41,11 @@ import (
 //
 // Use Parser for parsing only a single JSON value.
 type Scanner struct {
+	// JSONSeq enables parsing JSON text sequences ( https://tools.ietf.org/html/rfc7464 ).
+	//
+	// Every value must be preceded by RS (0x1E) char in this mode.
+	JSONSeq bool
+
 	// b contains a working copy of json value passed to Init.
 	b []byte
 
@@ -89,6 +96,10 @@ func (sc *Scanner) Next() bool {
 		return false
 	}
 
+	if sc.JSONSeq {
+		return sc.nextSeq()
+	}
+
 	sc.c.reset()
 	v, tail, err := parseValue(sc.s, &sc.c, "", 0)
 	if err != nil {
@@ -101,6 +112,53 @@ func (sc *Scanner) Next() bool {
 	return true
 }
 
+// recordSeparator starts every JSON text in JSON text sequence.
+const recordSeparator = 0x1E
+
+func (sc *Scanner) nextSeq() bool {
+	for {
+		if len(sc.s) == 0 {
+			sc.err = errEOF
+			return false
+		}
+		if sc.s[0] != recordSeparator {
+			sc.err = fmt.Errorf("missing RS char at the start of JSON text: %q", startEndString(sc.s))
+			return false
+		}
+
+		// JSON text cannot contain unescaped RS chars, so the record ends
+		// at the next RS char.
+		record := sc.s[1:]
+		tail := ""
+		if n := strings.IndexByte(record, recordSeparator); n >= 0 {
+			record, tail = record[:n], record[n:]
+		}
+		record = skipWS(record)
+		sc.s = tail
+		if len(record) == 0 {
+			// Empty records are ignored according to RFC 7464.
+			continue
+		}
+		return sc.parseRecord(record)
+	}
+}
+
+func (sc *Scanner) parseRecord(record string) bool {
+	sc.c.reset()
+	v, recordTail, err := parseValue(record, &sc.c, "", 0)
+	if err != nil {
+		sc.err = err
+		return false
+	}
+	if recordTail = skipWS(recordTail); len(recordTail) > 0 {
+		sc.err = fmt.Errorf("unexpected tail in JSON text: %q", startEndString(recordTail))
+		return false
+	}
+
+	sc.v = v
+	return true
+}
+
 // Error returns the last error.
 func (sc *Scanner) Error() error {
 	if sc.err == errEOF {
@@ -3,6 +3,7 @@ package libconfig
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -37,3 +38,55 @@ func TestScanner(t *testing.T) {
 		}
 	})
 }
+
+func TestScannerJSONSeq(t *testing.T) {
+	sc := Scanner{
+		JSONSeq: true,
+	}
+
+	sc.Init("\x1e[1, 2]\n\x1e\n\x1e\"foo\"\n\x1e123\n")
+	var bb bytes.Buffer
+	for sc.Next() {
+		fmt.Fprintf(&bb, "%s;", sc.Value())
+	}
+	if err := sc.Error(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := bb.String(); s != `[1,2];"foo";123;` {
+		t.Fatalf("unexpected values; got %q; want %q", s, `[1,2];"foo";123;`)
+	}
+
+	// Many empty records mustn't exhaust the stack.
+	sc.Init(strings.Repeat("\x1e", 20<<20) + "\x1e1\n")
+	if !sc.Next() {
+		t.Fatalf("unexpected error: %s", sc.Error())
+	}
+	if s := sc.Value().String(); s != "1" {
+		t.Fatalf("unexpected value; got %q; want %q", s, "1")
+	}
+	if sc.Next() {
+		t.Fatalf("unexpected value after the last record: %s", sc.Value())
+	}
+
+	f := func(s string) {
+		t.Helper()
+		sc.Init(s)
+		for sc.Next() {
+		}
+		if err := sc.Error(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f("123\n")
+	f("\x1e123 456\n")
+	f("\x1e[1, \n\x1e2\n")
+
+	// Truncated records mustn't panic.
+	f("\x1e[1,\n")
+	f("\x1e[1 # comment\n")
+	f("\x1e{a = {}\n")
+	f("\x1e{a = 1;\n")
+	f("\x1e{a = {};\n")
+	f("\x1e[1,")
+	f("\x1e{a = {};")
+}
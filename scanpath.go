@@ -0,0 +1,183 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScanPath returns raw bytes of the value at the given keys path in data
+// without parsing data into Value tree.
+//
+// Only the parts of data preceding the value are scanned, so this is much
+// faster than Parse when a single field must be extracted from large data.
+// Array indexes may be represented as decimal numbers in keys.
+//
+// The returned bytes reference data. nil is returned for non-existing keys path.
+// The returned error only covers the scanned parts of data, so ScanPath
+// may succeed on invalid data. @include directives aren't supported.
+func ScanPath(data []byte, keys ...string) ([]byte, error) {
+	s := b2s(data)
+	tail, n, err := scanPath(s, keys)
+	if err != nil {
+		return nil, fmt.Errorf("cannot scan libconfig for %q: %s", Path(keys), err)
+	}
+	if tail == "" {
+		return nil, nil
+	}
+	start := len(s) - len(tail)
+	end := start + len(strings.TrimRight(tail[:n], " \t\r\n"))
+	return data[start:end:end], nil
+}
+
+// GetRaw returns raw bytes of the value at the given keys path in data
+// without parsing data into Value tree.
+//
+// Array indexes may be represented as decimal numbers in keys.
+//
+// nil is returned on error or for non-existing keys path. Use ScanPath
+// for proper error handling.
+func GetRaw(data []byte, keys ...string) []byte {
+	b, err := ScanPath(data, keys...)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// scanPath returns the tail of s starting from the value at the given keys path
+// and the value length.
+//
+// An empty tail is returned for non-existing keys path.
+func scanPath(s string, keys []string) (string, int, error) {
+	if len(keys) == 0 {
+		s = skipJunk(s)
+		return s, len(s), nil
+	}
+	s, err := scanObject(s, keys[0], true)
+	if err != nil || s == "" {
+		return "", 0, err
+	}
+	for _, key := range keys[1:] {
+		switch s[0] {
+		case '{':
+			s, err = scanObject(s[1:], key, false)
+		case '[', '(':
+			n, errAtoi := strconv.Atoi(key)
+			if errAtoi != nil || n < 0 {
+				return "", 0, nil
+			}
+			s, err = scanArray(s[1:], n)
+		default:
+			return "", 0, nil
+		}
+		if err != nil || s == "" {
+			return "", 0, err
+		}
+	}
+	end, err := skipLazyValue(s)
+	if err != nil {
+		return "", 0, err
+	}
+	return s, len(s) - len(end), nil
+}
+
+// scanObject returns the tail of s starting from the value for the given key.
+//
+// s must start after the opening '{' unless root is set.
+// An empty string is returned if the key is missing.
+func scanObject(s, key string, root bool) (string, error) {
+	for {
+		s = skipJunk(s)
+		if len(s) == 0 {
+			if root {
+				return "", nil
+			}
+			return "", fmt.Errorf("missing '}'")
+		}
+		if !root && s[0] == '}' {
+			return "", nil
+		}
+		if strings.HasPrefix(s, "@include") {
+			return "", fmt.Errorf("@include isn't supported")
+		}
+
+		k, tail, err := parseRawKey(s)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse object key: %s", err)
+		}
+		if strings.IndexByte(k, '\\') >= 0 {
+			k = unescapeStringBestEffort(k)
+		}
+		value := skipJunk(tail[1:])
+		if k == key {
+			if len(value) == 0 {
+				return "", fmt.Errorf("missing value for key %q", key)
+			}
+			return value, nil
+		}
+		end, err := skipLazyValue(value)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse value for key %q: %s", k, err)
+		}
+		s = skipJunk(end)
+		if len(s) > 0 && (s[0] == ';' || s[0] == ',') {
+			s = s[1:]
+		}
+	}
+}
+
+// scanArray returns the tail of s starting from the n-th array item.
+//
+// s must start after the opening '[' or '('.
+// An empty string is returned if the array has no n-th item.
+func scanArray(s string, n int) (string, error) {
+	for i := 0; ; i++ {
+		s = skipJunk(s)
+		if len(s) == 0 {
+			return "", fmt.Errorf("unexpected end of array")
+		}
+		if s[0] == ']' || s[0] == ')' {
+			return "", nil
+		}
+		if i == n {
+			return s, nil
+		}
+		end, err := skipLazyValue(s)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse array item #%d: %s", i, err)
+		}
+		if len(end) == len(s) {
+			return "", fmt.Errorf("unexpected char at array item #%d: %q", i, s[:1])
+		}
+		s = skipJunk(end)
+		if len(s) > 0 && s[0] == ',' {
+			s = s[1:]
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestScanPath(t *testing.T) {
+	f := func(data string, keys []string, resultExpected string) {
+		t.Helper()
+		b, err := ScanPath([]byte(data), keys...)
+		if err != nil {
+			t.Fatalf("unexpected error for %q at %q: %s", data, keys, err)
+		}
+		if result := string(b); result != resultExpected {
+			t.Fatalf("unexpected result for %q at %q; got %q; want %q", data, keys, result, resultExpected)
+		}
+		if result := string(GetRaw([]byte(data), keys...)); result != resultExpected {
+			t.Fatalf("unexpected GetRaw result for %q at %q; got %q; want %q", data, keys, result, resultExpected)
+		}
+	}
+
+	data := `
+		# comment
+		name = "foo;bar";
+		db = {
+			host = "localhost"; // comment
+			ports = [5432, 5433];
+			opts = ({a = 1;}, {b = "}";});
+		};
+		big = 12345678901L;
+	`
+	f(data, []string{"name"}, `"foo;bar"`)
+	f(data, []string{"db"}, `{
+			host = "localhost"; // comment
+			ports = [5432, 5433];
+			opts = ({a = 1;}, {b = "}";});
+		}`)
+	f(data, []string{"db", "host"}, `"localhost"`)
+	f(data, []string{"db", "ports"}, `[5432, 5433]`)
+	f(data, []string{"db", "ports", "1"}, `5433`)
+	f(data, []string{"db", "opts", "1"}, `{b = "}";}`)
+	f(data, []string{"db", "opts", "1", "b"}, `"}"`)
+	f(data, []string{"big"}, `12345678901L`)
+	f(`a = 1; b : 2`, []string{"b"}, `2`)
+
+	// Missing paths
+	f(data, []string{"missing"}, "")
+	f(data, []string{"db", "missing"}, "")
+	f(data, []string{"db", "ports", "2"}, "")
+	f(data, []string{"db", "ports", "x"}, "")
+	f(data, []string{"name", "x"}, "")
+
+	// The returned value must match the parsed one.
+	v := MustParse(data)
+	for _, keys := range [][]string{{"name"}, {"db"}, {"db", "opts"}, {"big"}} {
+		b := GetRaw([]byte(data), keys...)
+		x, err := Parse("x = " + string(b) + ";")
+		if err != nil {
+			t.Fatalf("cannot parse raw value %q: %s", b, err)
+		}
+		if !EqualExcept(x.Get("x"), v.Get(keys...)) {
+			t.Fatalf("unexpected value at %q; got %s; want %s", keys, x.Get("x"), v.Get(keys...))
+		}
+	}
+
+	// Errors
+	fErr := func(data string, keys ...string) {
+		t.Helper()
+		if _, err := ScanPath([]byte(data), keys...); err == nil {
+			t.Fatalf("expecting non-nil error for %q at %q", data, keys)
+		}
+		if b := GetRaw([]byte(data), keys...); b != nil {
+			t.Fatalf("expecting nil result for %q at %q; got %q", data, keys, b)
+		}
+	}
+	fErr(`a = {b = 1;`, "a", "c")
+	fErr(`a = [1, 2`, "a", "5")
+	fErr(`a = [1, }]`, "a", "5")
+	fErr(`a = "foo`, "a")
+	fErr(`a`, "a")
+	fErr(`@include "x.cfg"`, "a")
+}
@@ -0,0 +1,431 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"github.com/gitteamer/libconfig/fastfloat"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+//
+// The following subset of JSON Schema draft 2020-12 keywords is supported:
+// type, enum, const, required, properties, additionalProperties, items,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength,
+// pattern, minItems and maxItems. Other keywords are ignored.
+//
+// Schema may be used from concurrent goroutines.
+type Schema struct {
+	types []string
+
+	enum     []*Value
+	constVal *Value
+
+	required             []string
+	properties           map[string]*Schema
+	propertyOrder        []string
+	additionalProperties *Schema
+	noAdditional         bool
+
+	items *Schema
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+
+	minItems int
+	maxItems int
+
+	// alwaysFalse is set for `false` schema.
+	alwaysFalse bool
+}
+
+// SchemaError describes a single schema violation.
+type SchemaError struct {
+	// Path is JSON Pointer (RFC 6901) to the violating value.
+	Path string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements error interface.
+func (e *SchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// SchemaErrors contains all the violations found by Schema.Validate.
+type SchemaErrors []*SchemaError
+
+// Error implements error interface.
+func (es SchemaErrors) Error() string {
+	a := make([]string, len(es))
+	for i, e := range es {
+		a[i] = e.Error()
+	}
+	return strings.Join(a, "; ")
+}
+
+// CompileSchema compiles JSON Schema from v.
+//
+// The schema is usually obtained by parsing libconfig document, e.g.:
+//
+//	type = "object";
+//	required = ["port"];
+//	properties = { port = { type = "integer"; minimum = 1; maximum = 65535; }; };
+//
+// The returned schema doesn't reference v.
+func CompileSchema(v *Value) (*Schema, error) {
+	s, err := compileSchema(v.Clone(), "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile schema: %s", err)
+	}
+	return s, nil
+}
+
+// MustCompileSchema is like CompileSchema, but panics on error.
+func MustCompileSchema(v *Value) *Schema {
+	s, err := CompileSchema(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func compileSchema(v *Value, path string) (*Schema, error) {
+	switch v.Type() {
+	case TypeTrue:
+		return &Schema{}, nil
+	case TypeFalse:
+		return &Schema{alwaysFalse: true}, nil
+	case TypeObject:
+	default:
+		return nil, fmt.Errorf("%s: schema must be object or bool; got %s", schemaPath(path), v.Type())
+	}
+
+	s := &Schema{
+		minLength: -1,
+		maxLength: -1,
+		minItems:  -1,
+		maxItems:  -1,
+	}
+	var err error
+	if t := v.Get("type"); t != nil {
+		switch t.Type() {
+		case TypeString:
+			s.types = []string{string(t.s)}
+		case TypeArray:
+			for _, item := range t.a {
+				if item.Type() != TypeString {
+					return nil, fmt.Errorf("%s/type: items must be strings", schemaPath(path))
+				}
+				s.types = append(s.types, item.s)
+			}
+		default:
+			return nil, fmt.Errorf("%s/type: must be string or array", schemaPath(path))
+		}
+		for _, typ := range s.types {
+			switch typ {
+			case "object", "array", "string", "number", "integer", "boolean", "null":
+			default:
+				return nil, fmt.Errorf("%s/type: unsupported type %q", schemaPath(path), typ)
+			}
+		}
+	}
+	if e := v.Get("enum"); e != nil {
+		if e.Type() != TypeArray {
+			return nil, fmt.Errorf("%s/enum: must be array", schemaPath(path))
+		}
+		s.enum = e.a
+	}
+	s.constVal = v.Get("const")
+	if r := v.Get("required"); r != nil {
+		if r.Type() != TypeArray {
+			return nil, fmt.Errorf("%s/required: must be array", schemaPath(path))
+		}
+		for _, item := range r.a {
+			if item.Type() != TypeString {
+				return nil, fmt.Errorf("%s/required: items must be strings", schemaPath(path))
+			}
+			s.required = append(s.required, item.s)
+		}
+	}
+	if props := v.Get("properties"); props != nil {
+		if props.Type() != TypeObject {
+			return nil, fmt.Errorf("%s/properties: must be object", schemaPath(path))
+		}
+		s.properties = make(map[string]*Schema, props.o.Len())
+		props.o.unescapeKeys()
+		for _, kv := range props.o.kvs {
+			ps, err := compileSchema(kv.v, path+"/properties/"+escapePointerToken(kv.k))
+			if err != nil {
+				return nil, err
+			}
+			s.properties[kv.k] = ps
+			s.propertyOrder = append(s.propertyOrder, kv.k)
+		}
+	}
+	if ap := v.Get("additionalProperties"); ap != nil {
+		if ap.Type() == TypeFalse {
+			s.noAdditional = true
+		} else if s.additionalProperties, err = compileSchema(ap, path+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if items := v.Get("items"); items != nil {
+		if s.items, err = compileSchema(items, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	for _, kw := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"minimum", &s.minimum},
+		{"maximum", &s.maximum},
+		{"exclusiveMinimum", &s.exclusiveMinimum},
+		{"exclusiveMaximum", &s.exclusiveMaximum},
+	} {
+		if x := v.Get(kw.name); x != nil {
+			if x.Type() != TypeNumber {
+				return nil, fmt.Errorf("%s/%s: must be number", schemaPath(path), kw.name)
+			}
+			f := schemaNumber(x)
+			*kw.dst = &f
+		}
+	}
+	for _, kw := range []struct {
+		name string
+		dst  *int
+	}{
+		{"minLength", &s.minLength},
+		{"maxLength", &s.maxLength},
+		{"minItems", &s.minItems},
+		{"maxItems", &s.maxItems},
+	} {
+		if x := v.Get(kw.name); x != nil {
+			n, err := x.Int()
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s/%s: must be non-negative integer", schemaPath(path), kw.name)
+			}
+			*kw.dst = n
+		}
+	}
+	if p := v.Get("pattern"); p != nil {
+		if p.Type() != TypeString {
+			return nil, fmt.Errorf("%s/pattern: must be string", schemaPath(path))
+		}
+		if s.pattern, err = regexp.Compile(p.s); err != nil {
+			return nil, fmt.Errorf("%s/pattern: %s", schemaPath(path), err)
+		}
+	}
+	return s, nil
+}
+
+// Validate validates v against s.
+//
+// SchemaErrors with all the found violations is returned if v doesn't
+// match s.
+func (s *Schema) Validate(v *Value) error {
+	var es SchemaErrors
+	s.validate(v, "", &es)
+	if len(es) > 0 {
+		return es
+	}
+	return nil
+}
+
+func (s *Schema) validate(v *Value, path string, es *SchemaErrors) {
+	addError := func(format string, args ...interface{}) {
+		*es = append(*es, &SchemaError{
+			Path:    path,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if s.alwaysFalse {
+		addError("value isn't allowed")
+		return
+	}
+	if len(s.types) > 0 && !s.matchesType(v) {
+		addError("unexpected type %s; want %s", schemaTypeName(v), strings.Join(s.types, " or "))
+		return
+	}
+	if len(s.enum) > 0 {
+		found := false
+		for _, item := range s.enum {
+			if equalValue(v, item, nil, nil) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addError("value %s isn't in enum %s", v, schemaList(s.enum))
+		}
+	}
+	if s.constVal != nil && !equalValue(v, s.constVal, nil, nil) {
+		addError("value %s must be equal to %s", v, s.constVal)
+	}
+
+	switch v.Type() {
+	case TypeObject:
+		for _, key := range s.required {
+			if v.o.Get(key) == nil {
+				addError("missing required property %q", key)
+			}
+		}
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			itemPath := path + "/" + escapePointerToken(kv.k)
+			if ps := s.properties[kv.k]; ps != nil {
+				ps.validate(kv.v, itemPath, es)
+				continue
+			}
+			if s.noAdditional {
+				*es = append(*es, &SchemaError{
+					Path:    itemPath,
+					Message: "additional property isn't allowed",
+				})
+			} else if s.additionalProperties != nil {
+				s.additionalProperties.validate(kv.v, itemPath, es)
+			}
+		}
+	case TypeArray:
+		if s.minItems >= 0 && len(v.a) < s.minItems {
+			addError("array must contain at least %d items; got %d items", s.minItems, len(v.a))
+		}
+		if s.maxItems >= 0 && len(v.a) > s.maxItems {
+			addError("array must contain at most %d items; got %d items", s.maxItems, len(v.a))
+		}
+		if s.items != nil {
+			for i, item := range v.a {
+				s.items.validate(item, path+"/"+strconv.Itoa(i), es)
+			}
+		}
+	case TypeString:
+		n := utf8.RuneCountInString(v.s)
+		if s.minLength >= 0 && n < s.minLength {
+			addError("string must contain at least %d chars; got %d chars", s.minLength, n)
+		}
+		if s.maxLength >= 0 && n > s.maxLength {
+			addError("string must contain at most %d chars; got %d chars", s.maxLength, n)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v.s) {
+			addError("string %q doesn't match pattern %q", v.s, s.pattern)
+		}
+	case TypeNumber:
+		f := schemaNumber(v)
+		if s.minimum != nil && f < *s.minimum {
+			addError("number %s must be greater than or equal to %v", v.s, *s.minimum)
+		}
+		if s.maximum != nil && f > *s.maximum {
+			addError("number %s must be less than or equal to %v", v.s, *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+			addError("number %s must be greater than %v", v.s, *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+			addError("number %s must be less than %v", v.s, *s.exclusiveMaximum)
+		}
+	}
+}
+
+func (s *Schema) matchesType(v *Value) bool {
+	vt := schemaTypeName(v)
+	for _, typ := range s.types {
+		if typ == vt || typ == "number" && vt == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaTypeName(v *Value) string {
+	switch v.Type() {
+	case TypeObject:
+		return "object"
+	case TypeArray:
+		return "array"
+	case TypeString:
+		return "string"
+	case TypeNumber:
+		f := schemaNumber(v)
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case TypeTrue, TypeFalse:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func schemaNumber(v *Value) float64 {
+	if isHexToken(v.s) {
+		n, _ := parseUintToken(v.s)
+		return float64(n)
+	}
+	return fastfloat.ParseBestEffort(strings.TrimSuffix(v.s, "L"))
+}
+
+func schemaList(a []*Value) string {
+	ss := make([]string, len(a))
+	for i, v := range a {
+		ss[i] = v.String()
+	}
+	return "[" + strings.Join(ss, ",") + "]"
+}
+
+func schemaPath(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return "schema" + path
+}
+
+// escapePointerToken escapes s for use in JSON Pointer according to RFC 6901.
+func escapePointerToken(s string) string {
+	if strings.IndexByte(s, '~') < 0 && strings.IndexByte(s, '/') < 0 {
+		return s
+	}
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
@@ -0,0 +1,92 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema := MustCompileSchema(MustParse(`
+		type = "object";
+		required = ["port", "mode"];
+		properties = {
+			port = { type = "integer"; minimum = 1; maximum = 65535; };
+			mode = { enum = ["dev", "prod"]; };
+			name = { type = "string"; minLength = 2; maxLength = 5; pattern = "^[a-z]+$"; };
+			ratio = { type = "number"; exclusiveMinimum = 0; exclusiveMaximum = 1; };
+			hosts = { type = "array"; minItems = 1; maxItems = 2; items = { type = "string"; }; };
+			limits = { type = "object"; additionalProperties = { type = "integer"; }; };
+			strict = { type = "object"; properties = { a = true; }; additionalProperties = false; };
+			version = { const = 2; };
+			opt = { type = ["string", "null"]; };
+		};
+	`))
+
+	f := func(doc string, errsExpected ...string) {
+		t.Helper()
+		err := schema.Validate(MustParse(doc))
+		if len(errsExpected) == 0 {
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %s", doc, err)
+			}
+			return
+		}
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", doc)
+		}
+		es := err.(SchemaErrors)
+		var errs []string
+		for _, e := range es {
+			errs = append(errs, e.Error())
+		}
+		if strings.Join(errs, "\n") != strings.Join(errsExpected, "\n") {
+			t.Fatalf("unexpected errors for %q\ngot\n%s\nwant\n%s", doc, strings.Join(errs, "\n"), strings.Join(errsExpected, "\n"))
+		}
+	}
+
+	f(`port = 8080; mode = "dev"; name = "abc"; ratio = 0.5; hosts = ["a"]; limits = { x = 1; }; strict = { a = 1; }; version = 2.0; opt = null;`)
+	f(`port = 0x50; mode = "prod"; opt = "x";`)
+	f(`mode = "test";`,
+		`/: missing required property "port"`,
+		`/mode: value "test" isn't in enum ["dev","prod"]`)
+	f(`port = 1.5; mode = "dev";`, `/port: unexpected type number; want integer`)
+	f(`port = 70000; mode = "dev";`, `/port: number 70000 must be less than or equal to 65535`)
+	f(`port = 1; mode = "dev"; name = "a"; ratio = 1;`,
+		`/name: string must contain at least 2 chars; got 1 chars`,
+		`/ratio: number 1 must be less than 1`)
+	f(`port = 1; mode = "dev"; name = "ABCDEF";`,
+		`/name: string must contain at most 5 chars; got 6 chars`,
+		`/name: string "ABCDEF" doesn't match pattern "^[a-z]+$"`)
+	f(`port = 1; mode = "dev"; hosts = ["a", 2, "c"];`,
+		`/hosts: array must contain at most 2 items; got 3 items`,
+		`/hosts/1: unexpected type integer; want string`)
+	f(`port = 1; mode = "dev"; limits = { x = "y"; }; strict = { a = 1; b = 2; };`,
+		`/limits/x: unexpected type string; want integer`,
+		`/strict/b: additional property isn't allowed`)
+	f(`port = 1; mode = "dev"; version = 3; opt = 1;`,
+		`/version: value 3 must be equal to 2`,
+		`/opt: unexpected type integer; want string or null`)
+}
+
+func TestCompileSchemaError(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := CompileSchema(MustParse(s)); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f(`type = "foo";`)
+	f(`type = 1;`)
+	f(`required = "x";`)
+	f(`properties = { a = 1; };`)
+	f(`minimum = "x";`)
+	f(`minLength = -1;`)
+	f(`pattern = "[";`)
+	f(`items = "x";`)
+}
+
+func TestEscapePointerToken(t *testing.T) {
+	if s := escapePointerToken("a/b~c"); s != "a~1b~0c" {
+		t.Fatalf("unexpected escaped token; got %q; want %q", s, "a~1b~0c")
+	}
+}
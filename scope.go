@@ -0,0 +1,79 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+)
+
+// StringBytesNoCopy returns the underlying string for v without copying it.
+//
+// The returned bytes reference the buffer of the Parser or Arena returned v,
+// so they are valid only until the Parser is re-used or the Arena is reset.
+// The returned bytes mustn't be modified. Use WithScope for limiting
+// the lifetime of the returned bytes and build with libconfig_poison tag
+// for catching their use after the Parser re-use.
+//
+// The bytes for frozen values remain valid for the lifetime of the value.
+func (v *Value) StringBytesNoCopy() ([]byte, error) {
+	if v.Type() != TypeString {
+		return nil, fmt.Errorf("value doesn't contain string; it contains %s", v.Type())
+	}
+	return s2b(v.s), nil
+}
+
+// WithScope parses s and calls f with the parsed value.
+//
+// The value and all the byte slices borrowed from it via StringBytesNoCopy,
+// GetStringBytes and similar calls are valid only inside f. Re-using p
+// inside f, either via Parse* calls or via ParserPool.Put, panics.
+// When built with libconfig_poison tag, the Parser buffer is overwritten
+// with garbage after f returns, so the borrowed byte slices used after
+// the scope end are easy to notice.
+//
+// The error returned by f is returned as is.
+//
+// WithScope is a Parser method rather than a Value method, since values
+// don't reference the Parser owning their buffer, so a Value method
+// couldn't detect the Parser re-use nor poison the buffer.
+func (p *Parser) WithScope(s string, f func(v *Value) error) error {
+	v, err := p.Parse(s)
+	if err != nil {
+		return err
+	}
+	p.scoped = true
+	defer func() {
+		p.scoped = false
+		poisonBytes(p.b[:cap(p.b)])
+	}()
+	return f(v)
+}
+
+func (p *Parser) mustNotBeScoped() {
+	if p.scoped {
+		panic(fmt.Errorf("BUG: Parser cannot be re-used inside WithScope callback"))
+	}
+}
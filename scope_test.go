@@ -0,0 +1,79 @@
+package libconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStringBytesNoCopy(t *testing.T) {
+	v := MustParse(`a = "foo\nbar"; b = 1;`)
+	b, err := v.Get("a").StringBytesNoCopy()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != "foo\nbar" {
+		t.Fatalf("unexpected result; got %q; want %q", b, "foo\nbar")
+	}
+	if _, err := v.Get("b").StringBytesNoCopy(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestParserWithScope(t *testing.T) {
+	var p Parser
+	var borrowed []byte
+	err := p.WithScope(`a = "foobar";`, func(v *Value) error {
+		borrowed = v.GetStringBytes("a")
+		if string(borrowed) != "foobar" {
+			return fmt.Errorf("unexpected value; got %q; want %q", borrowed, "foobar")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if poisonEnabled && string(borrowed) == "foobar" {
+		t.Fatalf("borrowed bytes must be poisoned after the scope end")
+	}
+
+	// Errors from f must be returned as is.
+	errExpected := fmt.Errorf("foo")
+	if err := p.WithScope(`a = 1;`, func(v *Value) error { return errExpected }); err != errExpected {
+		t.Fatalf("unexpected error; got %v; want %v", err, errExpected)
+	}
+
+	// Parse errors must be returned.
+	if err := p.WithScope(`a = `, func(v *Value) error { return nil }); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	// Parser re-use inside the scope must panic.
+	mustPanic := func(f func(p *Parser)) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expecting panic")
+			}
+		}()
+		_ = p.WithScope(`a = 1;`, func(v *Value) error {
+			f(&p)
+			return nil
+		})
+	}
+	mustPanic(func(p *Parser) {
+		_, _ = p.Parse(`b = 2;`)
+	})
+	var pp ParserPool
+	mustPanic(func(p *Parser) {
+		pp.Put(p)
+	})
+
+	// The parser must be usable after the scope end.
+	v, err := p.Parse(`b = 2;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("b"); n != 2 {
+		t.Fatalf("unexpected value; got %d; want %d", n, 2)
+	}
+}
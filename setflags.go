@@ -0,0 +1,121 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetFlags collects `key.path=value` overrides from repeated command-line
+// flags such as `--set server.port=9090`.
+//
+// SetFlags implements flag.Value, so it may be registered via flag.Var:
+//
+//	var sets libconfig.SetFlags
+//	flag.Var(&sets, "set", "override config value; may be repeated")
+//
+// The collected overrides may be applied via ApplySetFlags.
+type SetFlags []string
+
+// String returns comma-separated overrides from sf.
+func (sf *SetFlags) String() string {
+	if sf == nil {
+		return ""
+	}
+	return strings.Join(*sf, ",")
+}
+
+// Set adds the override s to sf.
+func (sf *SetFlags) Set(s string) error {
+	if strings.IndexByte(s, '=') <= 0 {
+		return fmt.Errorf("missing '=' in %q; want key.path=value", s)
+	}
+	*sf = append(*sf, s)
+	return nil
+}
+
+// ApplySetFlags applies overrides in `key.path=value` form to v
+// in the given order.
+//
+// Paths are parsed with ParsePath, so array items are addressed by decimal
+// indexes, e.g. servers.0.port. The index equal to the array length appends
+// a new item. Missing objects are created.
+//
+// Values starting with '{', '[' or '(' are parsed as libconfig values,
+// while quoted values such as "123" are always strings. Quoted values
+// support libconfig escapes and mustn't contain anything after the closing
+// quote. "null" becomes
+// null unless it replaces a string. Other values are converted the same way
+// as EnvOverlay does: values replacing strings remain strings, values
+// replacing numbers and bools must be numbers and bools, and the rest
+// are converted to numbers and bools where possible.
+//
+// New values are allocated in a.
+func ApplySetFlags(v *Value, a *Arena, flags []string) error {
+	if v.Type() != TypeObject {
+		return fmt.Errorf("cannot apply overrides to %s; object is required", v.Type())
+	}
+	for _, flag := range flags {
+		if err := applySetFlag(a, v, flag); err != nil {
+			return fmt.Errorf("cannot apply override %q: %s", flag, err)
+		}
+	}
+	return nil
+}
+
+func applySetFlag(a *Arena, v *Value, flag string) error {
+	n := strings.IndexByte(flag, '=')
+	if n <= 0 {
+		return fmt.Errorf("missing '='; want key.path=value")
+	}
+	p, err := ParsePath(flag[:n])
+	if err != nil {
+		return err
+	}
+	s := flag[n+1:]
+	prev := v.Get(p...)
+	var x *Value
+	switch {
+	case len(s) > 0 && s[0] == '"':
+		qs, tail, err := parseRawString(s[1:])
+		if err != nil {
+			return fmt.Errorf("cannot parse quoted string %s: %s", s, err)
+		}
+		if tail != "" {
+			return fmt.Errorf("unexpected tail after quoted string %s: %q", s, tail)
+		}
+		x = a.NewString(unescapeStringCopy(qs))
+	case s == "null" && (prev == nil || prev.Type() != TypeString):
+		x = valueNull
+	default:
+		x, err = envValue(a, s, prev)
+		if err != nil {
+			return err
+		}
+	}
+	return p.SetIn(a, v, x)
+}
@@ -0,0 +1,72 @@
+package libconfig
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestApplySetFlags(t *testing.T) {
+	f := func(flags []string, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(`server = { host = "localhost"; port = 8080; debug = false; }; hosts = ["a"];`).Clone()
+		if err := ApplySetFlags(v, &a, flags); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+		}
+	}
+
+	f(nil, `{"server":{"host":"localhost","port":8080,"debug":false},"hosts":["a"]}`)
+	f([]string{"server.port=9090", "server.debug=true", "server.host=123"},
+		`{"server":{"host":"123","port":9090,"debug":true},"hosts":["a"]}`)
+	f([]string{"hosts.1=b", "hosts.0=c", "db.pool.size=10", "db.name=\"42\"", "db.opt=null", "db.x=y=z"},
+		`{"server":{"host":"localhost","port":8080,"debug":false},"hosts":["c","b"],"db":{"pool":{"size":10},"name":"42","opt":null,"x":"y=z"}}`)
+	f([]string{"hosts=[1, 2]", "server={ port = 1; }"}, `{"server":{"port":1},"hosts":[1,2]}`)
+	f([]string{`db.name="a\tb\u00e9\x41\"q\""`, `db.empty=""`, `db.semi="a; y = 1"`},
+		`{"server":{"host":"localhost","port":8080,"debug":false},"hosts":["a"],"db":{"name":"a\tbéA\"q\"","empty":"","semi":"a; y = 1"}}`)
+	f([]string{"server.port=1", "server.port=2"}, `{"server":{"host":"localhost","port":2,"debug":false},"hosts":["a"]}`)
+
+	fErr := func(flags ...string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(`server = { port = 8080; debug = false; }; hosts = ["a"];`).Clone()
+		if err := ApplySetFlags(v, &a, flags); err == nil {
+			t.Fatalf("expecting non-nil error for %q", flags)
+		}
+	}
+	fErr("server.port")
+	fErr("=1")
+	fErr("server.port=http")
+	fErr("server.debug=maybe")
+	fErr("hosts.5=x")
+	fErr("server.port.x=1")
+	fErr(`server.name="unclosed`)
+	fErr(`server.name="a"; y = 1`)
+	fErr(`server.name="a" "b"`)
+}
+
+func TestSetFlags(t *testing.T) {
+	var sets SetFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&sets, "set", "override config value")
+	if err := fs.Parse([]string{"--set", "a.b=1", "--set=c=x"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := sets.String(); s != "a.b=1,c=x" {
+		t.Fatalf("unexpected flags; got %q; want %q", s, "a.b=1,c=x")
+	}
+	if err := sets.Set("missing"); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+
+	var a Arena
+	v := MustParse(`a = { b = 0; };`).Clone()
+	if err := ApplySetFlags(v, &a, sets); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := v.String(); s != `{"a":{"b":1},"c":"x"}` {
+		t.Fatalf("unexpected result; got %s", s)
+	}
+}
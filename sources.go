@@ -0,0 +1,304 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// refPrefix is the prefix for cross-document references.
+const refPrefix = "ref://"
+
+// Sources is a set of named config documents, which may reference
+// each other.
+//
+// String values in the form "ref://<source>/<pointer>" are replaced
+// with the referenced values by ResolveRefs, e.g. "ref://secrets/db/password"
+// is replaced with the value at /db/password JSON Pointer inside
+// the "secrets" document. This allows keeping secrets and settings
+// in separate documents.
+//
+// Sources cannot be used from concurrent goroutines.
+type Sources struct {
+	names   []string
+	docs    map[string]*Value
+	records []RefRecord
+}
+
+// RefRecord describes a resolved reference.
+type RefRecord struct {
+	// Source is the name of the document containing the reference.
+	Source string
+
+	// Path is the path to the reference inside Source.
+	Path Path
+
+	// Ref is the reference, e.g. "ref://secrets/db/password".
+	Ref string
+
+	// Target is the name of the referenced document.
+	Target string
+
+	// TargetPath is the path to the referenced value inside Target.
+	TargetPath Path
+}
+
+// Add registers document v under the given name.
+//
+// The name must be non-empty and mustn't contain '/'. v mustn't be frozen,
+// since references are resolved in place. Use Clone for obtaining
+// a modifiable copy of a frozen value.
+func (ss *Sources) Add(name string, v *Value) error {
+	if name == "" || strings.IndexByte(name, '/') >= 0 {
+		return fmt.Errorf("invalid source name %q; it must be non-empty and mustn't contain '/'", name)
+	}
+	if _, ok := ss.docs[name]; ok {
+		return fmt.Errorf("duplicate source name %q", name)
+	}
+	if v == nil {
+		return fmt.Errorf("cannot add nil value as source %q", name)
+	}
+	if v.IsFrozen() {
+		return fmt.Errorf("cannot add frozen value as source %q; use Clone for obtaining a modifiable copy", name)
+	}
+	if ss.docs == nil {
+		ss.docs = make(map[string]*Value)
+	}
+	ss.names = append(ss.names, name)
+	ss.docs[name] = v
+	return nil
+}
+
+// LoadFile loads the config file at path via LoadFile and registers
+// it under the given name.
+//
+// opts may be nil.
+func (ss *Sources) LoadFile(name, path string, opts *LoadOptions) error {
+	v, err := LoadFile(path, opts)
+	if err != nil {
+		return err
+	}
+	return ss.Add(name, v)
+}
+
+// Get returns the document registered under the given name.
+//
+// nil is returned for unknown names.
+func (ss *Sources) Get(name string) *Value {
+	return ss.docs[name]
+}
+
+// Names returns the names of the registered documents in the order they were added.
+func (ss *Sources) Names() []string {
+	return append([]string(nil), ss.names...)
+}
+
+// ResolveRefs replaces references in all the registered documents
+// with the referenced values.
+//
+// Call it after all the documents are added. References may point
+// to other references, and may go through them. Reference cycles
+// and references to missing values are reported as errors.
+//
+// The referenced values are shared between documents rather than copied.
+func (ss *Sources) ResolveRefs() error {
+	ss.records = ss.records[:0]
+	r := refResolver{
+		ss:       ss,
+		resolved: make(map[string]*Value),
+		walked:   make(map[*Value]walkState),
+	}
+	for _, name := range ss.names {
+		if err := r.walk(name, nil, ss.docs[name]); err != nil {
+			return fmt.Errorf("cannot resolve references in source %q: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// Records returns the references resolved by the last ResolveRefs call.
+//
+// This allows tracking where every referenced value came from.
+func (ss *Sources) Records() []RefRecord {
+	return append([]RefRecord(nil), ss.records...)
+}
+
+type refResolver struct {
+	ss *Sources
+
+	// resolved contains the resolved values keyed by the reference
+	// without the prefix.
+	resolved map[string]*Value
+
+	// stack contains the references being resolved for detecting cycles.
+	stack []string
+
+	// walked contains walk states for containers.
+	walked map[*Value]walkState
+}
+
+type walkState int
+
+const (
+	walkNone walkState = iota
+
+	// walkInProgress is set for containers, which are being walked,
+	// i.e. for ancestors of the currently resolved reference.
+	walkInProgress
+
+	walkDone
+)
+
+// walk resolves references in v located at path inside the given source.
+func (r *refResolver) walk(source string, path Path, v *Value) error {
+	t := v.Type()
+	if t != TypeObject && t != TypeArray || r.walked[v] != walkNone {
+		return nil
+	}
+	r.walked[v] = walkInProgress
+	defer func() {
+		r.walked[v] = walkDone
+	}()
+	switch t {
+	case TypeObject:
+		v.o.unescapeKeys()
+		for i := range v.o.kvs {
+			kv := &v.o.kvs[i]
+			x, err := r.walkChild(source, path, kv.k, kv.v)
+			if err != nil {
+				return err
+			}
+			kv.v = x
+		}
+	case TypeArray:
+		for i, item := range v.a {
+			x, err := r.walkChild(source, path, strconv.Itoa(i), item)
+			if err != nil {
+				return err
+			}
+			v.a[i] = x
+		}
+	}
+	return nil
+}
+
+// walkChild resolves references in the child v located at path+key
+// and returns the resulting child.
+func (r *refResolver) walkChild(source string, path Path, key string, v *Value) (*Value, error) {
+	childPath := append(path[:len(path):len(path)], key)
+	ref, ok := refString(v)
+	if !ok {
+		return v, r.walk(source, childPath, v)
+	}
+	x, err := r.resolve(ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q at %q: %s", ref, childPath, err)
+	}
+	target, targetPath, _ := splitRef(ref)
+	r.ss.records = append(r.ss.records, RefRecord{
+		Source:     source,
+		Path:       childPath,
+		Ref:        ref,
+		Target:     target,
+		TargetPath: targetPath,
+	})
+	return x, nil
+}
+
+// resolve returns the fully resolved value for ref.
+func (r *refResolver) resolve(ref string) (*Value, error) {
+	key := ref[len(refPrefix):]
+	if v, ok := r.resolved[key]; ok {
+		return v, nil
+	}
+	for i, k := range r.stack {
+		if k == key {
+			chain := append(r.stack[i:len(r.stack):len(r.stack)], key)
+			return nil, fmt.Errorf("reference cycle detected: %s", refPrefix+strings.Join(chain, " -> "+refPrefix))
+		}
+	}
+	r.stack = append(r.stack, key)
+	defer func() {
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+
+	source, path, err := splitRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	v := r.ss.docs[source]
+	if v == nil {
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+	for i, k := range path {
+		// References may go through other references.
+		if x, ok := refString(v); ok {
+			if v, err = r.resolve(x); err != nil {
+				return nil, err
+			}
+		}
+		if v, err = lookupKey(v, k, path[:i]); err != nil {
+			return nil, err
+		}
+	}
+	if x, ok := refString(v); ok {
+		if v, err = r.resolve(x); err != nil {
+			return nil, err
+		}
+	}
+	if r.walked[v] == walkInProgress {
+		return nil, fmt.Errorf("reference cycle detected: %s%s contains the reference", refPrefix, key)
+	}
+	if err := r.walk(source, path, v); err != nil {
+		return nil, err
+	}
+	r.resolved[key] = v
+	return v, nil
+}
+
+// refString returns the reference contained in v if any.
+func refString(v *Value) (string, bool) {
+	if v == nil || v.Type() != TypeString || !strings.HasPrefix(v.s, refPrefix) {
+		return "", false
+	}
+	return v.s, true
+}
+
+// splitRef splits ref into source name and path.
+func splitRef(ref string) (string, Path, error) {
+	s := ref[len(refPrefix):]
+	n := strings.IndexByte(s, '/')
+	if n < 0 {
+		return s, nil, nil
+	}
+	path, err := ParsePointer(s[n:])
+	if err != nil {
+		return "", nil, err
+	}
+	return s[:n], path, nil
+}
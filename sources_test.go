@@ -0,0 +1,126 @@
+package libconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourcesResolveRefs(t *testing.T) {
+	var ss Sources
+	add := func(name, s string) {
+		t.Helper()
+		if err := ss.Add(name, MustParse(s).Clone()); err != nil {
+			t.Fatalf("cannot add source %q: %s", name, err)
+		}
+	}
+	add("settings", `
+		db = {
+			host = "localhost";
+			password = "ref://secrets/db/password";
+			tls = "ref://secrets/tls";
+		};
+		replicas = ["ref://secrets/db/password", "plain"];
+		alias = "ref://settings/db/password";
+	`)
+	add("secrets", `
+		db = {password = "s3cr3t";};
+		tls = {cert = "ref://certs/main"; key = "k";};
+	`)
+	add("certs", `main = "CERT";`)
+
+	if err := ss.ResolveRefs(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	result := ss.Get("settings").String()
+	resultExpected := `{"db":{"host":"localhost","password":"s3cr3t","tls":{"cert":"CERT","key":"k"}},"replicas":["s3cr3t","plain"],"alias":"s3cr3t"}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result; got %s; want %s", result, resultExpected)
+	}
+
+	records := ss.Records()
+	found := false
+	for _, rec := range records {
+		if rec.Source == "settings" && rec.Path.String() == "replicas.0" {
+			found = true
+			if rec.Target != "secrets" || rec.TargetPath.String() != "db.password" || rec.Ref != "ref://secrets/db/password" {
+				t.Fatalf("unexpected record: %+v", rec)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("missing record for replicas.0 in %+v", records)
+	}
+	if len(records) != 5 {
+		t.Fatalf("unexpected number of records; got %d; want %d: %+v", len(records), 5, records)
+	}
+}
+
+func TestSourcesResolveRefsErrors(t *testing.T) {
+	f := func(docs ...string) {
+		t.Helper()
+		var ss Sources
+		for i := 0; i < len(docs); i += 2 {
+			if err := ss.Add(docs[i], MustParse(docs[i+1]).Clone()); err != nil {
+				t.Fatalf("cannot add source %q: %s", docs[i], err)
+			}
+		}
+		if err := ss.ResolveRefs(); err == nil {
+			t.Fatalf("expecting non-nil error for %q", docs)
+		}
+	}
+
+	f("a", `x = "ref://b/y";`)
+	f("a", `x = "ref://a/y";`)
+	f("a", `x = "ref://a/x";`)
+	f("a", `x = "ref://a/y"; y = "ref://a/x";`)
+	f("a", `x = "ref://b/y";`, "b", `y = {z = "ref://a/x";};`)
+	f("a", `x = "ref://b";`, "b", `y = "ref://a";`)
+	f("a", `x = "ref://a";`)
+	f("a", `x = "ref://a/y~2";`)
+	f("a", `x = [1]; y = "ref://a/x/5";`)
+
+	var ss Sources
+	for _, name := range []string{"", "a/b"} {
+		if err := ss.Add(name, MustParse(`a = 1;`).Clone()); err == nil {
+			t.Fatalf("expecting non-nil error for source name %q", name)
+		}
+	}
+	if err := ss.Add("a", MustParse(`a = 1;`).Freeze()); err == nil {
+		t.Fatalf("expecting non-nil error for frozen value")
+	}
+	if err := ss.Add("a", MustParse(`a = 1;`).Clone()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ss.Add("a", MustParse(`a = 1;`).Clone()); err == nil {
+		t.Fatalf("expecting non-nil error for duplicate source")
+	}
+}
+
+func TestSourcesLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, s string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("cannot write file: %s", err)
+		}
+		return path
+	}
+	var ss Sources
+	if err := ss.LoadFile("settings", write("settings.cfg", `password = "ref://secrets/password";`), nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ss.LoadFile("secrets", write("secrets.cfg", `password = "foo";`), nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := ss.ResolveRefs(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(ss.Get("settings").GetStringBytes("password")); s != "foo" {
+		t.Fatalf("unexpected password; got %q; want %q", s, "foo")
+	}
+	if err := ss.LoadFile("missing", filepath.Join(dir, "missing.cfg"), nil); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Spec is a declarative config specification.
+//
+// Fields are registered via Int, Float64, String and Bool methods:
+//
+//	var spec libconfig.Spec
+//	spec.Int("server.port").Default(8080).Range(1, 65535)
+//	spec.String("mode").Enum("dev", "prod").Required()
+//
+// Then Spec.Apply validates the parsed config and fills in defaults.
+//
+// Spec may be applied from concurrent goroutines after all the fields
+// are registered.
+type Spec struct {
+	fields []*SpecField
+}
+
+// SpecField is a single field registered in Spec.
+//
+// SpecField methods return the field itself, so they may be chained.
+type SpecField struct {
+	path string
+	keys Path
+	kind Type
+	name string
+
+	def        interface{}
+	hasDefault bool
+	required   bool
+
+	min, max float64
+	hasRange bool
+
+	enum []string
+}
+
+// Int registers integer field at the given dotted path.
+//
+// String values containing integers are coerced to numbers.
+func (s *Spec) Int(path string) *SpecField {
+	return s.addField(path, TypeNumber, "int")
+}
+
+// Float64 registers float64 field at the given dotted path.
+//
+// String values containing numbers are coerced to numbers.
+func (s *Spec) Float64(path string) *SpecField {
+	return s.addField(path, TypeNumber, "float64")
+}
+
+// String registers string field at the given dotted path.
+func (s *Spec) String(path string) *SpecField {
+	return s.addField(path, TypeString, "string")
+}
+
+// Bool registers bool field at the given dotted path.
+//
+// "true" and "false" strings are coerced to bool values.
+func (s *Spec) Bool(path string) *SpecField {
+	return s.addField(path, TypeTrue, "bool")
+}
+
+func (s *Spec) addField(path string, kind Type, name string) *SpecField {
+	f := &SpecField{
+		path: path,
+		keys: Path(strings.Split(path, ".")),
+		kind: kind,
+		name: name,
+	}
+	s.fields = append(s.fields, f)
+	return f
+}
+
+// Default sets the default value for the field.
+//
+// The default is stored in the config by Spec.Apply if the field is missing.
+// x is converted to Value via Arena.Marshal.
+func (f *SpecField) Default(x interface{}) *SpecField {
+	f.def = x
+	f.hasDefault = true
+	return f
+}
+
+// Required marks the field as required.
+//
+// Required fields with defaults never fail.
+func (f *SpecField) Required() *SpecField {
+	f.required = true
+	return f
+}
+
+// Range limits numeric field values to [min ... max].
+func (f *SpecField) Range(min, max float64) *SpecField {
+	f.min = min
+	f.max = max
+	f.hasRange = true
+	return f
+}
+
+// Enum limits string field values to the given values.
+func (f *SpecField) Enum(values ...string) *SpecField {
+	f.enum = append(f.enum[:0], values...)
+	return f
+}
+
+// SpecError describes a single Spec violation.
+type SpecError struct {
+	// Path is the dotted path to the field.
+	Path string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements error interface.
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// SpecErrors contains all the violations found by Spec.Apply.
+type SpecErrors []*SpecError
+
+// Error implements error interface.
+func (es SpecErrors) Error() string {
+	a := make([]string, len(es))
+	for i, e := range es {
+		a[i] = e.Error()
+	}
+	return strings.Join(a, "; ")
+}
+
+// Apply validates v against s.
+//
+// Missing fields with defaults are added to v, while coercible values
+// are replaced with values of the expected type. New values are allocated
+// in a, so v must be used only while a is in use.
+//
+// SpecErrors with all the found violations is returned on failure.
+// v mustn't be frozen.
+func (s *Spec) Apply(a *Arena, v *Value) error {
+	var es SpecErrors
+	for _, f := range s.fields {
+		if err := f.apply(a, v); err != nil {
+			es = append(es, &SpecError{
+				Path:    f.path,
+				Message: err.Error(),
+			})
+		}
+	}
+	if len(es) > 0 {
+		return es
+	}
+	return nil
+}
+
+func (f *SpecField) apply(a *Arena, v *Value) error {
+	x := v.Get(f.keys...)
+	if x == nil {
+		if f.hasDefault {
+			dv, err := a.Marshal(f.def)
+			if err != nil {
+				return fmt.Errorf("cannot marshal default value: %s", err)
+			}
+			return f.keys.SetIn(a, v, dv)
+		}
+		if f.required {
+			return fmt.Errorf("missing required value")
+		}
+		return nil
+	}
+
+	y, err := f.coerce(a, x)
+	if err != nil {
+		return err
+	}
+	if y != x {
+		if err := f.keys.SetIn(a, v, y); err != nil {
+			return err
+		}
+	}
+
+	switch f.kind {
+	case TypeNumber:
+		if f.hasRange {
+			n, err := parseFloatToken(y.s)
+			if err != nil {
+				return fmt.Errorf("cannot parse value %s: %s", y, err)
+			}
+			if !(n >= f.min && n <= f.max) {
+				return fmt.Errorf("value %s is out of range [%v ... %v]", y, f.min, f.max)
+			}
+		}
+	case TypeString:
+		if len(f.enum) > 0 {
+			s := y.s
+			for _, e := range f.enum {
+				if s == e {
+					return nil
+				}
+			}
+			return fmt.Errorf("value %q must be one of %q", s, f.enum)
+		}
+	}
+	return nil
+}
+
+func (f *SpecField) coerce(a *Arena, x *Value) (*Value, error) {
+	t := x.Type()
+	switch f.kind {
+	case TypeNumber:
+		y := x
+		if t == TypeString {
+			if s := strings.TrimSpace(x.s); isNumberToken(s) {
+				y = a.NewNumberString(s)
+			}
+		}
+		if y.Type() != TypeNumber {
+			return nil, fmt.Errorf("value %s cannot be converted to %s", x, f.name)
+		}
+		if f.name == "int" {
+			if _, err := parseIntToken(y.s); err != nil {
+				return nil, fmt.Errorf("value %s cannot be converted to %s", x, f.name)
+			}
+			return y, nil
+		}
+		// NaN and Inf cannot be checked against Range and cannot be
+		// represented in JSON, so they are rejected.
+		n, err := parseFloatToken(y.s)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("value %s cannot be converted to %s", x, f.name)
+		}
+		return y, nil
+	case TypeTrue:
+		if t == TypeString {
+			switch x.s {
+			case "true":
+				return a.NewTrue(), nil
+			case "false":
+				return a.NewFalse(), nil
+			}
+		}
+		if t != TypeTrue && t != TypeFalse {
+			return nil, fmt.Errorf("value %s cannot be converted to %s", x, f.name)
+		}
+		return x, nil
+	default:
+		if t != TypeString {
+			return nil, fmt.Errorf("value %s cannot be converted to %s", x, f.name)
+		}
+		return x, nil
+	}
+}
@@ -0,0 +1,62 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestSpecApply(t *testing.T) {
+	var spec Spec
+	spec.Int("server.port").Default(8080).Range(1, 65535)
+	spec.String("server.host").Default("localhost")
+	spec.String("mode").Enum("dev", "prod").Required()
+	spec.Float64("ratio").Range(0, 1)
+	spec.Bool("debug").Default(false)
+	spec.Int("workers")
+
+	f := func(doc, resultExpected string) {
+		t.Helper()
+		var a Arena
+		v := MustParse(doc)
+		if err := spec.Apply(&a, v); err != nil {
+			t.Fatalf("unexpected error for %q: %s", doc, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", doc, result, resultExpected)
+		}
+	}
+	f(`mode = "dev";`, `{"mode":"dev","server":{"port":8080,"host":"localhost"},"debug":false}`)
+	f(`mode = "prod"; server = { port = "9090"; }; ratio = "0.5"; debug = "true"; workers = 4;`,
+		`{"mode":"prod","server":{"port":9090,"host":"localhost"},"ratio":0.5,"debug":true,"workers":4}`)
+	f(`mode = "dev"; server = { port = 0x1F90; }; workers = 100L;`,
+		`{"mode":"dev","server":{"port":0x1F90,"host":"localhost"},"workers":100L,"debug":false}`)
+	f(`mode = "dev"; server = { port = "0x1F90"; };`,
+		`{"mode":"dev","server":{"port":0x1F90,"host":"localhost"},"debug":false}`)
+}
+
+func TestSpecApplyError(t *testing.T) {
+	var spec Spec
+	spec.Int("server.port").Default(8080).Range(1, 65535)
+	spec.String("mode").Enum("dev", "prod").Required()
+	spec.Bool("debug")
+	spec.Int("workers")
+	spec.Float64("ratio").Range(0, 10)
+
+	f := func(doc, errExpected string) {
+		t.Helper()
+		var a Arena
+		err := spec.Apply(&a, MustParse(doc))
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", doc)
+		}
+		if err.Error() != errExpected {
+			t.Fatalf("unexpected error for %q\ngot\n%s\nwant\n%s", doc, err, errExpected)
+		}
+	}
+	f(`server = { port = 0; };`, `server.port: value 0 is out of range [1 ... 65535]; mode: missing required value`)
+	f(`server = { port = 0x10000; }; mode = "dev";`, `server.port: value 0x10000 is out of range [1 ... 65535]`)
+	f(`mode = "dev"; ratio = "NaN";`, `ratio: value "NaN" cannot be converted to float64`)
+	f(`mode = "dev"; ratio = nan;`, `ratio: value nan cannot be converted to float64`)
+	f(`mode = "dev"; ratio = "-inf";`, `ratio: value "-inf" cannot be converted to float64`)
+	f(`server = 1; mode = "test";`, `server.port: cannot set key "port" in number at "server"; mode: value "test" must be one of ["dev" "prod"]`)
+	f(`mode = "dev"; debug = "yes"; workers = 1.5;`, `debug: value "yes" cannot be converted to bool; workers: value 1.5 cannot be converted to int`)
+}
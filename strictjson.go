@@ -0,0 +1,306 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseStrictJSON parses s as RFC 8259 JSON text.
+//
+// s must be already decoded with decodeInput.
+func (p *Parser) parseStrictJSON(s string) (*Value, error) {
+	p.b = append(p.b[:0], s...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+	p.c.src = b2s(p.b)
+	p.c.unwrapped = true
+
+	s = skipJSONWS(b2s(p.b))
+	if len(s) == 0 {
+		return nil, p.c.syntaxError(fmt.Errorf("empty JSON text"), s)
+	}
+	v, tail, err := parseStrictJSONValue(s, &p.c, 0)
+	if err != nil {
+		return nil, p.c.syntaxError(err, tail)
+	}
+	tail = skipJSONWS(tail)
+	if len(tail) > 0 {
+		return nil, p.c.syntaxError(fmt.Errorf("unexpected data after JSON value"), tail)
+	}
+	return v, nil
+}
+
+// skipJSONWS skips whitespace chars allowed by RFC 8259.
+func skipJSONWS(s string) string {
+	for len(s) > 0 && (s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r') {
+		s = s[1:]
+	}
+	return s
+}
+
+func parseStrictJSONValue(s string, c *cache, depth int) (*Value, string, error) {
+	if len(s) == 0 {
+		return nil, s, fmt.Errorf("cannot parse empty string")
+	}
+	switch s[0] {
+	case '{', '[':
+		depth++
+		if maxDepth := c.cfg.maxDepth(); depth > maxDepth {
+			return nil, s, fmt.Errorf("too big depth for the nested JSON; it exceeds %d", maxDepth)
+		}
+		if s[0] == '{' {
+			v, tail, err := parseStrictJSONObject(s[1:], c, depth)
+			if err != nil {
+				return nil, tail, err
+			}
+			c.markExtendedJSON(v)
+			return v, tail, nil
+		}
+		return parseStrictJSONArray(s[1:], c, depth)
+	case '"':
+		raw, tail, err := parseStrictJSONString(s[1:])
+		if err != nil {
+			return nil, s, err
+		}
+		if err := c.cfg.checkStringLen(raw); err != nil {
+			return nil, s, err
+		}
+		v := c.getValue()
+		v.t = typeRawString
+		v.s = raw
+		return v, tail, nil
+	case 't':
+		if !strings.HasPrefix(s, "true") {
+			return nil, s, fmt.Errorf("unexpected value found: %q", startEndString(s))
+		}
+		return valueTrue, s[len("true"):], nil
+	case 'f':
+		if !strings.HasPrefix(s, "false") {
+			return nil, s, fmt.Errorf("unexpected value found: %q", startEndString(s))
+		}
+		return valueFalse, s[len("false"):], nil
+	case 'n':
+		if !strings.HasPrefix(s, "null") {
+			return nil, s, fmt.Errorf("unexpected value found: %q", startEndString(s))
+		}
+		return valueNull, s[len("null"):], nil
+	}
+
+	n := scanStrictJSONNumber(s)
+	if n == 0 {
+		return nil, s, fmt.Errorf("unexpected value found: %q", startEndString(s))
+	}
+	if err := c.cfg.checkNumber(s[:n]); err != nil {
+		return nil, s, err
+	}
+	v := c.getValue()
+	v.t = TypeNumber
+	v.s = s[:n]
+	return v, s[n:], nil
+}
+
+func parseStrictJSONObject(s string, c *cache, depth int) (*Value, string, error) {
+	o := c.getValue()
+	o.t = TypeObject
+	o.o.reset()
+	var keys map[string]struct{}
+
+	s = skipJSONWS(s)
+	if len(s) > 0 && s[0] == '}' {
+		return o, s[1:], nil
+	}
+	for {
+		if len(s) == 0 || s[0] != '"' {
+			return nil, s, fmt.Errorf(`cannot find opening '"' for object key`)
+		}
+		keyStart := s
+		key, tail, err := parseStrictJSONString(s[1:])
+		if err != nil {
+			return nil, s, fmt.Errorf("cannot parse object key: %s", err)
+		}
+		if err := c.cfg.checkStringLen(key); err != nil {
+			return nil, s, err
+		}
+		s = skipJSONWS(tail)
+		if len(s) == 0 || s[0] != ':' {
+			return nil, s, fmt.Errorf("missing ':' after object key")
+		}
+		s = skipJSONWS(s[1:])
+		v, tail, err := parseStrictJSONValue(s, c, depth)
+		if err != nil {
+			return nil, tail, fmt.Errorf("cannot parse object value: %s", err)
+		}
+		appendObjectKV(&o.o, key, v)
+		if err := c.checkDuplicateKey(&o.o, &keys, keyStart); err != nil {
+			return nil, keyStart, err
+		}
+		s = skipJSONWS(tail)
+		if len(s) == 0 {
+			return nil, s, fmt.Errorf("unexpected end of object")
+		}
+		switch s[0] {
+		case ',':
+			s = skipJSONWS(s[1:])
+		case '}':
+			return o, s[1:], nil
+		default:
+			return nil, s, fmt.Errorf("missing ',' after object value")
+		}
+	}
+}
+
+func parseStrictJSONArray(s string, c *cache, depth int) (*Value, string, error) {
+	a := c.getValue()
+	a.t = TypeArray
+	a.a = a.a[:0]
+
+	s = skipJSONWS(s)
+	if len(s) > 0 && s[0] == ']' {
+		return a, s[1:], nil
+	}
+	for {
+		v, tail, err := parseStrictJSONValue(s, c, depth)
+		if err != nil {
+			return nil, tail, fmt.Errorf("cannot parse array value: %s", err)
+		}
+		a.a = append(a.a, v)
+		if err := c.cfg.checkArrayLen(len(a.a)); err != nil {
+			return nil, s, err
+		}
+		s = skipJSONWS(tail)
+		if len(s) == 0 {
+			return nil, s, fmt.Errorf("unexpected end of array")
+		}
+		switch s[0] {
+		case ',':
+			s = skipJSONWS(s[1:])
+		case ']':
+			return a, s[1:], nil
+		default:
+			return nil, s, fmt.Errorf("missing ',' after array value")
+		}
+	}
+}
+
+// parseStrictJSONString returns the raw string starting after the opening '"'
+// and the tail after the closing '"'.
+//
+// Control chars, invalid escape sequences and invalid UTF-8 are rejected.
+func parseStrictJSONString(s string) (string, string, error) {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '"':
+			if !utf8.ValidString(s[:i]) {
+				return s, s, fmt.Errorf("string contains invalid UTF-8")
+			}
+			return s[:i], s[i+1:], nil
+		case ch < 0x20:
+			return s, s[i:], fmt.Errorf("unescaped control char 0x%02X in string", ch)
+		case ch == '\\':
+			i++
+			if i >= len(s) {
+				break
+			}
+			switch s[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(s) || !isHexDigits(s[i+1:i+5]) {
+					return s, s[i-1:], fmt.Errorf(`invalid \u escape sequence`)
+				}
+				i += 4
+			default:
+				return s, s[i-1:], fmt.Errorf("invalid escape sequence %q", s[i-1:i+1])
+			}
+		}
+	}
+	return s, "", fmt.Errorf(`missing closing '"'`)
+}
+
+func isHexDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !(ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// scanStrictJSONNumber returns the length of RFC 8259 number at the start of s.
+//
+// 0 is returned if s doesn't start with valid number.
+func scanStrictJSONNumber(s string) int {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		i = skipDigits(s, i)
+	default:
+		return 0
+	}
+	if i < len(s) && s[i] == '.' {
+		n := skipDigits(s, i+1)
+		if n == i+1 {
+			return 0
+		}
+		i = n
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		n := skipDigits(s, i)
+		if n == i {
+			return 0
+		}
+		i = n
+	}
+	if i < len(s) && (isDigit(s[i]) || s[i] == '.' || s[i] == 'e' || s[i] == 'E' || isLetter(s[i])) {
+		// Reject leading zeros such as 01 and garbage such as 1x.
+		return 0
+	}
+	return i
+}
+
+func skipDigits(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+}
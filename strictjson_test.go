@@ -0,0 +1,251 @@
+package libconfig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseStrictRFC8259(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		p := Parser{
+			Config: ParserConfig{
+				StrictRFC8259: true,
+			},
+		}
+		v, err := p.Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %s; want %s", s, result, resultExpected)
+		}
+	}
+
+	f(`{"a":1,"b":[true,false,null],"c":{}}`, `{"a":1,"b":[true,false,null],"c":{}}`)
+	f(" \t\r\n[1, -2.5e+3, 0, -0, 0.5E-1] \n", `[1,-2.5e+3,0,-0,0.5E-1]`)
+	f(`42`, `42`)
+
+	// Strings are unescaped.
+	p := Parser{
+		Config: ParserConfig{
+			StrictRFC8259: true,
+		},
+	}
+	v, err := p.Parse(`"\"\\\/\b\f\n\r\té\ud801\udc37\u00e9"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sb, err := v.StringBytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s, sExpected := string(sb), "\"\\/\b\f\n\r\té\U00010437é"; s != sExpected {
+		t.Fatalf("unexpected string; got %q; want %q", s, sExpected)
+	}
+
+	// Syntax errors contain positions.
+	_, err = p.Parse("{\n  \"a\": 01\n}")
+	se, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("expecting *SyntaxError; got %T: %v", err, err)
+	}
+	if se.Line != 2 || se.Column != 8 {
+		t.Fatalf("unexpected error position; got line %d, column %d; want line 2, column 8", se.Line, se.Column)
+	}
+}
+
+// TestParseStrictRFC8259Conformance contains cases from JSONTestSuite
+// (https://github.com/nst/JSONTestSuite). y_* cases must be accepted,
+// while n_* cases must be rejected.
+func TestParseStrictRFC8259Conformance(t *testing.T) {
+	accepted := map[string]string{
+		"y_array_arraysWithSpaces":              `[[]   ]`,
+		"y_array_empty":                         `[]`,
+		"y_array_empty-string":                  `[""]`,
+		"y_array_false":                         `[false]`,
+		"y_array_heterogeneous":                 `[null, 1, "1", {}]`,
+		"y_array_with_leading_space":            ` [1]`,
+		"y_number_0e+1":                         `[0e+1]`,
+		"y_number_0e1":                          `[0e1]`,
+		"y_number_after_space":                  `[ 4]`,
+		"y_number_double_close_to_zero":         `[-0.000000000000000000000000000000000000000000000000000000000000000000000000000001]`,
+		"y_number_int_with_exp":                 `[20e1]`,
+		"y_number_minus_zero":                   `[-0]`,
+		"y_number_negative_int":                 `[-123]`,
+		"y_number_real_capital_e_neg_exp":       `[1E-2]`,
+		"y_number_real_fraction_exponent":       `[123.456e78]`,
+		"y_object_basic":                        `{"asd":"sdf"}`,
+		"y_object_duplicated_key":               `{"a":"b","a":"c"}`,
+		"y_object_empty_key":                    `{"":0}`,
+		"y_object_escaped_null_in_key":          `{"foo\u0000bar": 42}`,
+		"y_string_accepted_surrogate_pair":      `["𐐷"]`,
+		"y_string_allowed_escapes":              `["\"\\\/\b\f\n\r\t"]`,
+		"y_string_unicode_2":                    `["⍂㈴⍂"]`,
+		"y_string_utf8":                         `["€𝄞"]`,
+		"y_string_with_del_character":           "[\"a\x7fa\"]",
+		"y_structure_lonely_false":              `false`,
+		"y_structure_lonely_string":             `"asd"`,
+		"y_structure_trailing_newline":          "[\"a\"]\n",
+		"y_structure_whitespace_array":          ` [] `,
+		"y_structure_true_in_array":             `[true]`,
+		"y_object_extreme_numbers":              `{ "min": -1.0e+28, "max": 1.0e+28 }`,
+		"y_string_nonCharacterInUTF-8_U+FFFF":   "[\"\xef\xbf\xbf\"]",
+		"y_structure_lonely_negative_real":      `-0.1`,
+		"y_array_with_several_null":             `[1,null,null,null,2]`,
+		"y_object_with_newlines":                "{\n\"a\": \"b\"\n}",
+		"y_string_in_array_with_leading_space":  `[ "asd"]`,
+		"y_structure_string_empty":              `""`,
+		"y_number_real_pos_exponent":            `[1e+2]`,
+		"y_string_escaped_control_character":    `["\u0012"]`,
+		"y_object_long_strings":                 `{"x":[{"id": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}], "id": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}`,
+		"y_structure_whitespace_array_newlines": "[\r\n]",
+	}
+	rejected := map[string]string{
+		"n_array_extra_comma":                       `["",]`,
+		"n_array_incomplete":                        `["x"`,
+		"n_array_just_minus":                        `[-]`,
+		"n_array_number_and_comma":                  `[1,]`,
+		"n_array_unclosed":                          `[""`,
+		"n_array_comma_after_close":                 `[""],`,
+		"n_array_double_comma":                      `[1,,2]`,
+		"n_incomplete_false":                        `[fals]`,
+		"n_number_+1":                               `[+1]`,
+		"n_number_-01":                              `[-01]`,
+		"n_number_.2e-3":                            `[.2e-3]`,
+		"n_number_0.e1":                             `[0.e1]`,
+		"n_number_2.e3":                             `[2.e3]`,
+		"n_number_Inf":                              `[Inf]`,
+		"n_number_NaN":                              `[NaN]`,
+		"n_number_hex_1_digit":                      `[0x1]`,
+		"n_number_neg_int_starting_with_zero":       `[-012]`,
+		"n_number_with_leading_zero":                `[012]`,
+		"n_number_real_without_fractional_part":     `[1.]`,
+		"n_number_minus_space_1":                    `[- 1]`,
+		"n_number_0_capital_E":                      `[0E]`,
+		"n_number_1eE2":                             `[1eE2]`,
+		"n_number_expression":                       `[1+2]`,
+		"n_object_single_quote":                     `{'a':0}`,
+		"n_object_trailing_comma":                   `{"id":0,}`,
+		"n_object_unquoted_key":                     `{a: "b"}`,
+		"n_object_missing_colon":                    `{"a" b}`,
+		"n_object_non_string_key":                   `{1:1}`,
+		"n_object_trailing_comment":                 `{"a":"b"}/**/`,
+		"n_object_missing_value":                    `{"a":`,
+		"n_object_several_trailing_commas":          `{"id":0,,,,,}`,
+		"n_string_escape_x":                         `["\x00"]`,
+		"n_string_escaped_ctrl_char_tab":            "[\"\\\t\"]",
+		"n_string_invalid_unicode_escape":           `["\uqqqq"]`,
+		"n_string_unescaped_newline":                "[\"new\nline\"]",
+		"n_string_unescaped_tab":                    "[\"\t\"]",
+		"n_string_unescaped_ctrl_char":              "[\"a\x00a\"]",
+		"n_string_single_quote":                     `['single quote']`,
+		"n_string_incomplete_escaped_character":     `["\u00A"]`,
+		"n_string_invalid_utf8_after_escape":        "[\"\\\xe5\"]",
+		"n_string_no_quotes_with_bad_escape":        `[\n]`,
+		"n_structure_array_with_extra_array_close":  `[1]]`,
+		"n_structure_double_array":                  `[][]`,
+		"n_structure_no_data":                       ``,
+		"n_single_space":                            ` `,
+		"n_structure_trailing_#":                    `{"a":"b"}#{}`,
+		"n_structure_whitespace_formfeed":           "[\f]",
+		"n_structure_object_with_trailing_garbage":  `{"a": true} "x"`,
+		"n_structure_unclosed_object":               `{"asd":"asd"`,
+		"n_structure_capitalized_True":              `[True]`,
+		"n_structure_UTF8_BOM_no_data":              "\xef\xbb\xbf",
+		"n_structure_lone-open-bracket":             `[`,
+		"n_structure_angle_bracket_null":            `[<null>]`,
+		"n_structure_close_unopened_array":          `1]`,
+		"n_structure_unicode-identifier":            `å`,
+		"n_structure_number_with_trailing_garbage":  `2@`,
+		"n_structure_object_unclosed_no_value":      `{"":`,
+		"n_structure_single_star":                   `*`,
+		"n_structure_whitespace_U+2060_word_joiner": "[⁠]",
+	}
+
+	p := Parser{
+		Config: ParserConfig{
+			StrictRFC8259: true,
+		},
+	}
+	for name, s := range accepted {
+		if _, err := p.Parse(s); err != nil {
+			t.Fatalf("unexpected error for %s (%q): %s", name, s, err)
+		}
+	}
+	for name, s := range rejected {
+		if _, err := p.Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error for %s (%q)", name, s)
+		}
+	}
+
+	// Strictness beyond JSONTestSuite n_* cases.
+	for _, s := range []string{
+		"[\"\xff\"]",
+		"{\"\xc3\":1}",
+	} {
+		if _, err := p.Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error for invalid UTF-8 in %q", s)
+		}
+	}
+}
+
+func TestParseStrictRFC8259Config(t *testing.T) {
+	p := Parser{
+		Config: ParserConfig{
+			StrictRFC8259:       true,
+			RejectDuplicateKeys: true,
+			MaxDepth:            2,
+			NumberMode:          NumberInt64,
+		},
+	}
+	for _, s := range []string{
+		`{"a":1,"a":2}`,
+		`{"a":1,"\u0061":2}`,
+		`{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8,"i":9,"j":10,"k":11,"l":12,"m":13,"n":14,"o":15,"p":16,"q":17,"r":18,"\u0061":19}`,
+		`[[[]]]`,
+		`[1.5]`,
+	} {
+		if _, err := p.Parse(s); err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	if _, err := p.Parse(`{"a":[1]}`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The default lenient mode accepts libconfig syntax.
+	var lp Parser
+	if _, err := lp.Parse(`/* comment */ a = +1; b = 012;`); err != nil {
+		t.Fatalf("unexpected error in the default mode: %s", err)
+	}
+
+	lazy := LazyParser{
+		Config: ParserConfig{
+			StrictRFC8259: true,
+		},
+	}
+	if err := lazy.Parse(`{}`); err == nil {
+		t.Fatalf("expecting non-nil error for LazyParser in StrictRFC8259 mode")
+	}
+}
+
+func FuzzParseStrictRFC8259(f *testing.F) {
+	for _, s := range []string{`{"a":[1,-2.5e3,"x\u00e9"]}`, `[]`, `"\ud801\udc37"`, `[01]`, `{"a":1,}`, "[\"\t\"]"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		p := Parser{
+			Config: ParserConfig{
+				StrictRFC8259: true,
+			},
+		}
+		if _, err := p.Parse(s); err != nil {
+			return
+		}
+		// Everything accepted in strict mode must be valid JSON.
+		if !json.Valid([]byte(s)) {
+			t.Fatalf("StrictRFC8259 accepted invalid JSON %q", s)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+// Whitespace and key separators ':' and '=' are located with indexNonWS
+// and indexKeySep. Build with libconfig_swar tag on amd64 or arm64 in order
+// to scan them 8 bytes at a time (see structural_swar.go). This speeds up
+// long whitespace runs (see BenchmarkSkipWS), but whole-document parsing
+// is dominated by other work, so its throughput stays roughly the same
+// (see BenchmarkParseDocument). Quotes and escapes are located with
+// strings.IndexByte, which is already vectorized by the Go runtime.
+//
+// The generic byte-by-byte versions below are used by default and for
+// verifying the SWAR versions in tests and in VerifyPlatform.
+
+// isWS returns true if c is whitespace recognized by the parser.
+func isWS(c byte) bool {
+	return c == 0x20 || c == 0x0A || c == 0x09 || c == 0x0D
+}
+
+// indexNonWSGeneric returns the index of the first non-whitespace char in s
+// or len(s) if s contains only whitespace.
+func indexNonWSGeneric(s string) int {
+	for i := 0; i < len(s); i++ {
+		if !isWS(s[i]) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// indexKeySepGeneric returns the index of the first ':' or '=' in s
+// or -1 if s contains none of them.
+func indexKeySepGeneric(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' || s[i] == '=' {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,95 @@
+//go:build (amd64 || arm64) && libconfig_swar && !purego
+
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// SWAR (SIMD within a register) versions of structural scanning functions.
+// They process 8 bytes at a time with plain 64-bit integer operations,
+// so they don't need per-platform assembly. Both amd64 and arm64
+// support fast unaligned little-endian loads, which are required here.
+//
+// These versions are opt-in via libconfig_swar build tag.
+
+const (
+	swarOnes = 0x0101010101010101
+	swarHigh = 0x8080808080808080
+	swarLow7 = 0x7f7f7f7f7f7f7f7f
+)
+
+// swarNonZero returns x with the high bit set in every non-zero byte
+// and all the other bits cleared.
+//
+// Unlike the usual haszero trick, the result is exact for every byte,
+// since carries cannot cross byte boundaries.
+func swarNonZero(x uint64) uint64 {
+	return ((x & swarLow7) + swarLow7 | x) & swarHigh
+}
+
+// swarEq returns x with the high bit set in every byte equal to c
+// and all the other bits cleared.
+func swarEq(x uint64, c byte) uint64 {
+	return swarNonZero(x^(swarOnes*uint64(c))) ^ swarHigh
+}
+
+// indexNonWS returns the index of the first non-whitespace char in s
+// or len(s) if s contains only whitespace.
+func indexNonWS(s string) int {
+	b := s2b(s)
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		x := binary.LittleEndian.Uint64(b[i:])
+		m := (swarEq(x, 0x20) | swarEq(x, 0x0A) | swarEq(x, 0x09) | swarEq(x, 0x0D)) ^ swarHigh
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+	}
+	return i + indexNonWSGeneric(s[i:])
+}
+
+// indexKeySep returns the index of the first ':' or '=' in s
+// or -1 if s contains none of them.
+func indexKeySep(s string) int {
+	b := s2b(s)
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		x := binary.LittleEndian.Uint64(b[i:])
+		m := swarEq(x, ':') | swarEq(x, '=')
+		if m != 0 {
+			return i + bits.TrailingZeros64(m)/8
+		}
+	}
+	n := indexKeySepGeneric(s[i:])
+	if n < 0 {
+		return -1
+	}
+	return i + n
+}
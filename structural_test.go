@@ -0,0 +1,69 @@
+package libconfig
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestIndexNonWS(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		n := indexNonWS(s)
+		nExpected := indexNonWSGeneric(s)
+		if n != nExpected {
+			t.Fatalf("unexpected index for %q; got %d; want %d", s, n, nExpected)
+		}
+	}
+
+	f("")
+	f(" ")
+	f("x")
+	f(" \t\r\n x")
+	f(strings.Repeat(" ", 7) + "x")
+	f(strings.Repeat(" ", 8) + "x")
+	f(strings.Repeat(" \n", 20))
+	f(strings.Repeat("\t", 17) + "\x00")
+	f(strings.Repeat(" ", 9) + "\x80")
+	f(strings.Repeat(" ", 9) + "\xa0")
+
+	r := rand.New(rand.NewSource(1))
+	chars := " \t\r\n\x00\x1f\x20\x21\x7f\x80\x8a\xa0\xffx"
+	for i := 0; i < 10000; i++ {
+		b := make([]byte, r.Intn(40))
+		for j := range b {
+			b[j] = chars[r.Intn(len(chars))]
+		}
+		f(string(b))
+	}
+}
+
+func TestIndexKeySep(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		n := indexKeySep(s)
+		nExpected := indexKeySepGeneric(s)
+		if n != nExpected {
+			t.Fatalf("unexpected index for %q; got %d; want %d", s, n, nExpected)
+		}
+	}
+
+	f("")
+	f("=")
+	f("key = 1")
+	f("key: 1")
+	f("long_key_name = 1")
+	f("long_key_name_without_separator")
+	f(strings.Repeat("a", 16) + ":")
+	f("\xba\xbd\x3a\x3d")
+
+	r := rand.New(rand.NewSource(1))
+	chars := "abc:=\x00\x3a\x3d\xba\xbd\xff"
+	for i := 0; i < 10000; i++ {
+		b := make([]byte, r.Intn(40))
+		for j := range b {
+			b[j] = chars[r.Intn(len(chars))]
+		}
+		f(string(b))
+	}
+}
@@ -0,0 +1,892 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseTOML parses s containing TOML ( https://toml.io/en/v1.0.0 ).
+//
+// The result is represented with the same Value tree as the result of Parse,
+// so the same Get* code may be used for TOML and libconfig documents.
+// Date and time values are returned as strings.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseTOML(s string) (*Value, error) {
+	s, err := decodeInput(s, p.Config.TranscodeInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse TOML: %s", err)
+	}
+	p.b = append(p.b[:0], s...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	tp := &tomlParser{
+		src: b2s(p.b),
+		s:   b2s(p.b),
+		c:   &p.c,
+	}
+	v, err := tp.parse()
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse TOML: %s", err)
+	}
+	return v, nil
+}
+
+// ParseTOMLBytes parses b containing TOML.
+//
+// See Parser.ParseTOML for details.
+func (p *Parser) ParseTOMLBytes(b []byte) (*Value, error) {
+	return p.ParseTOML(b2s(b))
+}
+
+// ParseTOML parses s containing TOML.
+//
+// The function is slower than the Parser.ParseTOML for re-used Parser.
+func ParseTOML(s string) (*Value, error) {
+	var p Parser
+	return p.ParseTOML(s)
+}
+
+// ParseTOMLBytes parses b containing TOML.
+//
+// The function is slower than the Parser.ParseTOMLBytes for re-used Parser.
+func ParseTOMLBytes(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseTOMLBytes(b)
+}
+
+type tomlParser struct {
+	// src is the whole input. It is used for error positions.
+	src string
+
+	// s is the unparsed tail of src.
+	s string
+
+	c *cache
+
+	// defined contains tables defined via [table] headers or inline tables.
+	defined map[*Value]bool
+
+	// arrayTables contains arrays defined via [[array]] headers.
+	arrayTables map[*Value]bool
+
+	// depth is the nesting depth of the currently parsed arrays and inline tables.
+	depth int
+}
+
+func (tp *tomlParser) errorf(format string, args ...interface{}) error {
+	prefix := tp.src[:len(tp.src)-len(tp.s)]
+	line := strings.Count(prefix, "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (tp *tomlParser) newObject() *Value {
+	v := tp.c.getValue()
+	v.t = TypeObject
+	v.o.reset()
+	v.o.keysUnescaped = true
+	return v
+}
+
+func (tp *tomlParser) parse() (*Value, error) {
+	tp.defined = make(map[*Value]bool)
+	tp.arrayTables = make(map[*Value]bool)
+	root := tp.newObject()
+	cur := root
+	for {
+		tp.skipBlank(true)
+		if len(tp.s) == 0 {
+			return root, nil
+		}
+		var err error
+		if tp.s[0] == '[' {
+			cur, err = tp.parseTableHeader(root)
+		} else {
+			err = tp.parseKeyValue(cur)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := tp.parseLineEnd(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipBlank skips whitespace and comments. Newlines are skipped if newlines is set.
+func (tp *tomlParser) skipBlank(newlines bool) {
+	s := tp.s
+	for len(s) > 0 {
+		switch s[0] {
+		case ' ', '\t':
+			s = s[1:]
+		case '\r', '\n':
+			if !newlines {
+				tp.s = s
+				return
+			}
+			s = s[1:]
+		case '#':
+			n := strings.IndexByte(s, '\n')
+			if n < 0 {
+				n = len(s)
+			}
+			s = s[n:]
+		default:
+			tp.s = s
+			return
+		}
+	}
+	tp.s = s
+}
+
+func (tp *tomlParser) parseLineEnd() error {
+	tp.skipBlank(false)
+	if len(tp.s) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(tp.s, "\n") || strings.HasPrefix(tp.s, "\r\n") {
+		return nil
+	}
+	return tp.errorf("unexpected data after value: %q", startEndString(tp.s))
+}
+
+func (tp *tomlParser) parseTableHeader(root *Value) (*Value, error) {
+	isArray := strings.HasPrefix(tp.s, "[[")
+	if isArray {
+		tp.s = tp.s[2:]
+	} else {
+		tp.s = tp.s[1:]
+	}
+	keys, err := tp.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	tp.skipBlank(false)
+	if !strings.HasPrefix(tp.s, closing) {
+		return nil, tp.errorf("missing %q after table name", closing)
+	}
+	tp.s = tp.s[len(closing):]
+
+	t, err := tp.walkTables(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	key := keys[len(keys)-1]
+	v := t.o.Get(key)
+	if isArray {
+		if v == nil {
+			v = tp.c.getValue()
+			v.t = TypeArray
+			v.a = v.a[:0]
+			tp.arrayTables[v] = true
+			appendObjectKV(&t.o, key, v)
+		} else if !tp.arrayTables[v] {
+			return nil, tp.errorf("cannot define array of tables %q, since the key is already defined", strings.Join(keys, "."))
+		}
+		item := tp.newObject()
+		tp.defined[item] = true
+		v.a = append(v.a, item)
+		return item, nil
+	}
+	if v == nil {
+		v = tp.newObject()
+		appendObjectKV(&t.o, key, v)
+	} else if v.t != TypeObject || tp.defined[v] {
+		return nil, tp.errorf("table %q is already defined", strings.Join(keys, "."))
+	}
+	tp.defined[v] = true
+	return v, nil
+}
+
+// walkTables returns the table identified by keys starting from t.
+//
+// Missing tables are created. The last item is used for arrays of tables.
+func (tp *tomlParser) walkTables(t *Value, keys []string) (*Value, error) {
+	for i, key := range keys {
+		v := t.o.Get(key)
+		if v == nil {
+			v = tp.newObject()
+			appendObjectKV(&t.o, key, v)
+		}
+		if tp.arrayTables[v] {
+			v = v.a[len(v.a)-1]
+		}
+		if v.t != TypeObject {
+			return nil, tp.errorf("key %q is already defined as %s", strings.Join(keys[:i+1], "."), v.t)
+		}
+		t = v
+	}
+	return t, nil
+}
+
+func (tp *tomlParser) parseKeyValue(t *Value) error {
+	keys, err := tp.parseKey()
+	if err != nil {
+		return err
+	}
+	tp.skipBlank(false)
+	if len(tp.s) == 0 || tp.s[0] != '=' {
+		return tp.errorf("missing '=' after key %q", strings.Join(keys, "."))
+	}
+	tp.s = tp.s[1:]
+	tp.skipBlank(false)
+	v, err := tp.parseValue()
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys[:len(keys)-1] {
+		child := t.o.Get(key)
+		if child == nil {
+			child = tp.newObject()
+			appendObjectKV(&t.o, key, child)
+		} else if child.t != TypeObject || tp.defined[child] {
+			return tp.errorf("cannot define key %q inside already defined %q", strings.Join(keys, "."), strings.Join(keys[:i+1], "."))
+		}
+		t = child
+	}
+	key := keys[len(keys)-1]
+	if t.o.Get(key) != nil {
+		return tp.errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	appendObjectKV(&t.o, key, v)
+	return nil
+}
+
+func (tp *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		tp.skipBlank(false)
+		if len(tp.s) == 0 {
+			return nil, tp.errorf("missing key")
+		}
+		var key string
+		var err error
+		switch tp.s[0] {
+		case '"':
+			tp.s = tp.s[1:]
+			key, err = tp.parseBasicString()
+		case '\'':
+			tp.s = tp.s[1:]
+			key, err = tp.parseLiteralString()
+		default:
+			n := 0
+			for n < len(tp.s) && isTOMLBareKeyChar(tp.s[n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, tp.errorf("unexpected char in key: %q", startEndString(tp.s))
+			}
+			key = tp.s[:n]
+			tp.s = tp.s[n:]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		tp.skipBlank(false)
+		if len(tp.s) == 0 || tp.s[0] != '.' {
+			return keys, nil
+		}
+		tp.s = tp.s[1:]
+	}
+}
+
+func isTOMLBareKeyChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-'
+}
+
+func (tp *tomlParser) parseValue() (*Value, error) {
+	if len(tp.s) == 0 {
+		return nil, tp.errorf("missing value")
+	}
+	switch tp.s[0] {
+	case '"', '\'':
+		s, err := tp.parseString()
+		if err != nil {
+			return nil, err
+		}
+		v := tp.c.getValue()
+		v.t = TypeString
+		v.s = s
+		return v, nil
+	case '[':
+		tp.s = tp.s[1:]
+		return tp.parseArray()
+	case '{':
+		tp.s = tp.s[1:]
+		return tp.parseInlineTable()
+	case 't':
+		if strings.HasPrefix(tp.s, "true") {
+			tp.s = tp.s[len("true"):]
+			return valueTrue, nil
+		}
+	case 'f':
+		if strings.HasPrefix(tp.s, "false") {
+			tp.s = tp.s[len("false"):]
+			return valueFalse, nil
+		}
+	}
+	return tp.parseNumberOrDatetime()
+}
+
+func (tp *tomlParser) parseString() (string, error) {
+	switch {
+	case strings.HasPrefix(tp.s, `"""`):
+		tp.s = tp.s[3:]
+		return tp.parseMultilineString(`"""`, true)
+	case strings.HasPrefix(tp.s, `'''`):
+		tp.s = tp.s[3:]
+		return tp.parseMultilineString(`'''`, false)
+	case tp.s[0] == '"':
+		tp.s = tp.s[1:]
+		return tp.parseBasicString()
+	default:
+		tp.s = tp.s[1:]
+		return tp.parseLiteralString()
+	}
+}
+
+func (tp *tomlParser) parseBasicString() (string, error) {
+	var b []byte
+	s := tp.s
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			tp.s = s[i+1:]
+			if b == nil {
+				return s[:i], nil
+			}
+			return string(b), nil
+		case '\n':
+			return "", tp.errorf("unexpected newline in string")
+		case '\\':
+			if b == nil {
+				b = append(b, s[:i]...)
+			}
+			var err error
+			b, i, err = tp.appendEscape(b, s, i)
+			if err != nil {
+				return "", err
+			}
+		default:
+			if b != nil {
+				b = append(b, s[i])
+			}
+		}
+	}
+	return "", tp.errorf(`missing closing '"'`)
+}
+
+func (tp *tomlParser) parseLiteralString() (string, error) {
+	n := strings.IndexByte(tp.s, '\'')
+	if n < 0 {
+		return "", tp.errorf(`missing closing "'"`)
+	}
+	s := tp.s[:n]
+	if strings.IndexByte(s, '\n') >= 0 {
+		return "", tp.errorf("unexpected newline in string")
+	}
+	tp.s = tp.s[n+1:]
+	return s, nil
+}
+
+func (tp *tomlParser) parseMultilineString(delim string, escapes bool) (string, error) {
+	s := tp.s
+	// A newline immediately following the opening delimiter is trimmed.
+	if strings.HasPrefix(s, "\r\n") {
+		s = s[2:]
+	} else if strings.HasPrefix(s, "\n") {
+		s = s[1:]
+	}
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], delim) {
+			// Up to two quotes are allowed right before the closing delimiter.
+			end := i
+			for end+3 < len(s) && end-i < 2 && s[end+3] == delim[0] {
+				end++
+			}
+			b = append(b, s[i:end]...)
+			tp.s = s[end+3:]
+			return string(b), nil
+		}
+		if !escapes || s[i] != '\\' {
+			b = append(b, s[i])
+			continue
+		}
+		// Line ending backslash trims all the whitespace up to the next
+		// non-whitespace char.
+		j := i + 1
+		for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+			j++
+		}
+		if j < len(s) && (s[j] == '\n' || s[j] == '\r') {
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\n' || s[j] == '\r') {
+				j++
+			}
+			i = j - 1
+			continue
+		}
+		var err error
+		b, i, err = tp.appendEscape(b, s, i)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", tp.errorf("missing closing %s", delim)
+}
+
+// appendEscape appends the escape sequence starting at s[i] to b.
+//
+// It returns the index of the last char of the escape sequence.
+func (tp *tomlParser) appendEscape(b []byte, s string, i int) ([]byte, int, error) {
+	if i+1 >= len(s) {
+		return b, i, tp.errorf("unfinished escape sequence")
+	}
+	ch := s[i+1]
+	switch ch {
+	case 'b':
+		b = append(b, '\b')
+	case 't':
+		b = append(b, '\t')
+	case 'n':
+		b = append(b, '\n')
+	case 'f':
+		b = append(b, '\f')
+	case 'r':
+		b = append(b, '\r')
+	case 'e':
+		b = append(b, 0x1B)
+	case '"':
+		b = append(b, '"')
+	case '\\':
+		b = append(b, '\\')
+	case 'u', 'U':
+		n := 4
+		if ch == 'U' {
+			n = 8
+		}
+		if i+2+n > len(s) {
+			return b, i, tp.errorf("too short unicode escape sequence")
+		}
+		x, err := strconv.ParseUint(s[i+2:i+2+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(x)) {
+			return b, i, tp.errorf("invalid unicode escape sequence %q", s[i:i+2+n])
+		}
+		b = utf8.AppendRune(b, rune(x))
+		return b, i + 1 + n, nil
+	default:
+		return b, i, tp.errorf("unknown escape sequence \\%c", ch)
+	}
+	return b, i + 1, nil
+}
+
+func (tp *tomlParser) parseArray() (*Value, error) {
+	tp.depth++
+	defer func() {
+		tp.depth--
+	}()
+	if maxDepth := tp.c.cfg.maxDepth(); tp.depth > maxDepth {
+		return nil, tp.errorf("too big depth for the nested TOML; it exceeds %d", maxDepth)
+	}
+	a := tp.c.getValue()
+	a.t = TypeArray
+	a.a = a.a[:0]
+	for {
+		tp.skipBlank(true)
+		if len(tp.s) == 0 {
+			return nil, tp.errorf("missing ']'")
+		}
+		if tp.s[0] == ']' {
+			tp.s = tp.s[1:]
+			return a, nil
+		}
+		v, err := tp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		a.a = append(a.a, v)
+		if err := tp.c.cfg.checkArrayLen(len(a.a)); err != nil {
+			return nil, tp.errorf("%s", err)
+		}
+		tp.skipBlank(true)
+		if len(tp.s) == 0 {
+			return nil, tp.errorf("missing ']'")
+		}
+		switch tp.s[0] {
+		case ',':
+			tp.s = tp.s[1:]
+		case ']':
+			tp.s = tp.s[1:]
+			return a, nil
+		default:
+			return nil, tp.errorf("missing ',' after array value")
+		}
+	}
+}
+
+func (tp *tomlParser) parseInlineTable() (*Value, error) {
+	tp.depth++
+	defer func() {
+		tp.depth--
+	}()
+	if maxDepth := tp.c.cfg.maxDepth(); tp.depth > maxDepth {
+		return nil, tp.errorf("too big depth for the nested TOML; it exceeds %d", maxDepth)
+	}
+	t := tp.newObject()
+	tp.skipBlank(false)
+	if strings.HasPrefix(tp.s, "}") {
+		tp.s = tp.s[1:]
+		tp.defined[t] = true
+		return t, nil
+	}
+	for {
+		if err := tp.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		tp.skipBlank(false)
+		if len(tp.s) == 0 {
+			return nil, tp.errorf("missing '}'")
+		}
+		switch tp.s[0] {
+		case ',':
+			tp.s = tp.s[1:]
+		case '}':
+			tp.s = tp.s[1:]
+			// Inline tables cannot be extended later.
+			tp.defined[t] = true
+			return t, nil
+		default:
+			return nil, tp.errorf("missing ',' after inline table value")
+		}
+	}
+}
+
+func (tp *tomlParser) parseNumberOrDatetime() (*Value, error) {
+	n := 0
+	for n < len(tp.s) && isTOMLTokenChar(tp.s[n]) {
+		n++
+	}
+	token := tp.s[:n]
+	// Date and time may be delimited by space.
+	if isTOMLDate(token) && n+3 < len(tp.s) && tp.s[n] == ' ' && isDigit(tp.s[n+1]) && isDigit(tp.s[n+2]) && tp.s[n+3] == ':' {
+		m := n + 1
+		for m < len(tp.s) && isTOMLTokenChar(tp.s[m]) {
+			m++
+		}
+		token = tp.s[:m]
+	}
+	if token == "" {
+		return nil, tp.errorf("unexpected value: %q", startEndString(tp.s))
+	}
+
+	v := tp.c.getValue()
+	if isTOMLDate(token) || isTOMLTime(token) {
+		v.t = TypeString
+		v.s = token
+		tp.s = tp.s[len(token):]
+		return v, nil
+	}
+	ns, err := tomlNumber(token)
+	if err != nil {
+		return nil, tp.errorf("cannot parse number %q: %s", token, err)
+	}
+	v.t = TypeNumber
+	v.s = ns
+	tp.s = tp.s[len(token):]
+	return v, nil
+}
+
+func isTOMLTokenChar(ch byte) bool {
+	return isTOMLBareKeyChar(ch) || ch == '.' || ch == ':' || ch == '+'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isTOMLDate(s string) bool {
+	return len(s) >= 10 && isDigit(s[0]) && isDigit(s[3]) && s[4] == '-' && s[7] == '-'
+}
+
+func isTOMLTime(s string) bool {
+	return len(s) >= 8 && isDigit(s[0]) && isDigit(s[1]) && s[2] == ':' && s[5] == ':'
+}
+
+// tomlNumber converts TOML number to the number token understood by Value.
+func tomlNumber(s string) (string, error) {
+	switch strings.TrimLeft(s, "+-") {
+	case "inf", "nan":
+		return strings.TrimPrefix(s, "+"), nil
+	}
+	if strings.Contains(s, "__") || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") {
+		return "", fmt.Errorf("invalid underscores")
+	}
+	s = strings.ReplaceAll(s, "_", "")
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x':
+			if _, err := strconv.ParseUint(s[2:], 16, 64); err != nil {
+				return "", err
+			}
+			return s, nil
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base > 0 {
+			n, err := strconv.ParseUint(s[2:], base, 64)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatUint(n, 10), nil
+		}
+	}
+	digits := strings.TrimLeft(s, "+-")
+	if len(digits) > 1 && digits[0] == '0' && isDigit(digits[1]) {
+		return "", fmt.Errorf("leading zeros aren't allowed")
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", fmt.Errorf("invalid number")
+	}
+	return strings.TrimPrefix(s, "+"), nil
+}
+
+// MarshalTOMLTo appends TOML representation of v to dst and returns the result.
+//
+// v must be an object. Nested objects are written as [table] sections,
+// while arrays of objects are written as [[array]] sections. Objects inside
+// other arrays are written as inline tables.
+//
+// An error is returned if v contains null values, since TOML doesn't
+// support them. dst is returned unchanged on error.
+func (v *Value) MarshalTOMLTo(dst []byte) ([]byte, error) {
+	if v.Type() != TypeObject {
+		return dst, fmt.Errorf("cannot marshal %s to TOML; only objects are supported at the top level", v.Type())
+	}
+	dstLen := len(dst)
+	dst, err := appendTOMLTable(dst, v, nil)
+	if err != nil {
+		return dst[:dstLen], err
+	}
+	return dst, nil
+}
+
+// isTOMLArrayOfTables returns true if v must be written as [[array]] sections.
+func isTOMLArrayOfTables(v *Value) bool {
+	if v.Type() != TypeArray || len(v.a) == 0 {
+		return false
+	}
+	for _, item := range v.a {
+		if item.Type() != TypeObject {
+			return false
+		}
+	}
+	return true
+}
+
+// appendTOMLTable appends contents of the table v located at path.
+func appendTOMLTable(dst []byte, v *Value, path []string) ([]byte, error) {
+	v.o.unescapeKeys()
+	var err error
+
+	// Key/value pairs must precede sub-tables.
+	for _, kv := range v.o.kvs {
+		if kv.v.Type() == TypeObject || isTOMLArrayOfTables(kv.v) {
+			continue
+		}
+		dst = appendTOMLKey(dst, kv.k)
+		dst = append(dst, " = "...)
+		if dst, err = appendTOMLValue(dst, kv.v, append(path, kv.k)); err != nil {
+			return dst, err
+		}
+		dst = append(dst, '\n')
+	}
+
+	for _, kv := range v.o.kvs {
+		childPath := append(path[:len(path):len(path)], kv.k)
+		switch {
+		case kv.v.Type() == TypeObject:
+			dst = appendTOMLHeader(dst, childPath, "[", "]")
+			if dst, err = appendTOMLTable(dst, kv.v, childPath); err != nil {
+				return dst, err
+			}
+		case isTOMLArrayOfTables(kv.v):
+			for _, item := range kv.v.a {
+				dst = appendTOMLHeader(dst, childPath, "[[", "]]")
+				if dst, err = appendTOMLTable(dst, item, childPath); err != nil {
+					return dst, err
+				}
+			}
+		}
+	}
+	return dst, nil
+}
+
+func appendTOMLHeader(dst []byte, path []string, open, close string) []byte {
+	if len(dst) > 0 {
+		dst = append(dst, '\n')
+	}
+	dst = append(dst, open...)
+	for i, key := range path {
+		if i > 0 {
+			dst = append(dst, '.')
+		}
+		dst = appendTOMLKey(dst, key)
+	}
+	dst = append(dst, close...)
+	return append(dst, '\n')
+}
+
+func appendTOMLKey(dst []byte, key string) []byte {
+	if key == "" {
+		return append(dst, `""`...)
+	}
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			return appendTOMLString(dst, key)
+		}
+	}
+	return append(dst, key...)
+}
+
+// appendTOMLValue appends v as TOML value located at path.
+func appendTOMLValue(dst []byte, v *Value, path []string) ([]byte, error) {
+	var err error
+	switch v.Type() {
+	case TypeObject:
+		dst = append(dst, '{')
+		for i, kv := range v.o.kvs {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, ' ')
+			dst = appendTOMLKey(dst, kv.k)
+			dst = append(dst, " = "...)
+			if dst, err = appendTOMLValue(dst, kv.v, append(path, kv.k)); err != nil {
+				return dst, err
+			}
+		}
+		if len(v.o.kvs) > 0 {
+			dst = append(dst, ' ')
+		}
+		return append(dst, '}'), nil
+	case TypeArray:
+		dst = append(dst, '[')
+		for i, item := range v.a {
+			if i > 0 {
+				dst = append(dst, ", "...)
+			}
+			if dst, err = appendTOMLValue(dst, item, append(path, strconv.Itoa(i))); err != nil {
+				return dst, err
+			}
+		}
+		return append(dst, ']'), nil
+	case TypeString:
+		return appendTOMLString(dst, v.s), nil
+	case TypeNumber:
+		ns, err := formatTOMLNumber(v.s)
+		if err != nil {
+			return dst, fmt.Errorf("cannot marshal %q to TOML: %s", strings.Join(path, "."), err)
+		}
+		return append(dst, ns...), nil
+	case TypeTrue:
+		return append(dst, "true"...), nil
+	case TypeFalse:
+		return append(dst, "false"...), nil
+	case TypeRawJSON:
+		return dst, fmt.Errorf("cannot marshal %q to TOML: raw JSON values aren't supported", strings.Join(path, "."))
+	default:
+		return dst, fmt.Errorf("cannot marshal %q to TOML: null values aren't supported", strings.Join(path, "."))
+	}
+}
+
+func appendTOMLString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			dst = append(dst, `\"`...)
+		case r == '\\':
+			dst = append(dst, `\\`...)
+		case r == '\n':
+			dst = append(dst, `\n`...)
+		case r == '\t':
+			dst = append(dst, `\t`...)
+		case r == '\r':
+			dst = append(dst, `\r`...)
+		case r < 0x20 || r == 0x7f:
+			dst = append(dst, fmt.Sprintf(`\u%04X`, r)...)
+		default:
+			dst = utf8.AppendRune(dst, r)
+		}
+	}
+	return append(dst, '"')
+}
+
+// formatTOMLNumber converts number token to TOML number.
+func formatTOMLNumber(s string) (string, error) {
+	s = trimBigintSuffix(s)
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		return "inf", nil
+	case "-inf":
+		return "-inf", nil
+	case "nan", "+nan", "-nan":
+		return "nan", nil
+	}
+	if hs := strings.TrimPrefix(s, "-"); len(hs) > 2 && (hs[:2] == "0x" || hs[:2] == "0X") {
+		if hs == s {
+			return "0x" + s[2:], nil
+		}
+		// TOML doesn't allow signed hex numbers.
+		n, err := strconv.ParseInt(s[:1]+hs[2:], 16, 64)
+		if err != nil {
+			return "", fmt.Errorf("cannot convert hex number %q", s)
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+	// Normalize numbers via YAML rules, since they are compatible with TOML
+	// after fixing leading zeros, .5 and 1. forms.
+	ns, ok := yamlNumber(s)
+	if !ok {
+		return "", fmt.Errorf("unsupported number %q", s)
+	}
+	return ns, nil
+}
@@ -0,0 +1,223 @@
+package libconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTOMLSuccess(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseTOML(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	f(``, `{}`)
+	f(`# comment only`, `{}`)
+	f(`a = 1`, `{"a":1}`)
+	f(`a = "x\ty\u00e9\"" # comment`, `{"a":"x\tyé\""}`)
+	f(`a = 'C:\path'`, `{"a":"C:\\path"}`)
+	f("a = \"\"\"\nline1\nline2\\\n    continued\"\"\"", `{"a":"line1\nline2continued"}`)
+	f("a = '''\nraw \\n'''", `{"a":"raw \\n"}`)
+	f(`a = true
+b = false`, `{"a":true,"b":false}`)
+	f(`a = 1_000
+b = -17
+c = 0xdead_beef
+d = 0o755
+e = 0b1101
+f = +3.14
+g = 6.02e23
+h = -inf
+i = nan`, `{"a":1000,"b":-17,"c":0xdeadbeef,"d":493,"e":13,"f":3.14,"g":6.02e23,"h":-inf,"i":nan}`)
+	f(`d1 = 1979-05-27T07:32:00Z
+d2 = 1979-05-27 07:32:00-07:00
+d3 = 1979-05-27
+t = 07:32:00`, `{"d1":"1979-05-27T07:32:00Z","d2":"1979-05-27 07:32:00-07:00","d3":"1979-05-27","t":"07:32:00"}`)
+	f(`a = [1, 2, [3, "x"], ]
+b = [
+  1, # one
+  2,
+]`, `{"a":[1,2,[3,"x"]],"b":[1,2]}`)
+	f(`point = { x = 1, y = 2, "quoted key" = { z = 3 } }`, `{"point":{"x":1,"y":2,"quoted key":{"z":3}}}`)
+	f(`a.b.c = 1
+a.b.d = 2
+"x.y" = 3`, `{"a":{"b":{"c":1,"d":2}},"x.y":3}`)
+	f(`title = "t"
+
+[server]
+host = "localhost"
+port = 8080
+
+[server.tls]
+enabled = true
+
+[database]
+ports = [8000, 8001]`, `{"title":"t","server":{"host":"localhost","port":8080,"tls":{"enabled":true}},"database":{"ports":[8000,8001]}}`)
+	f(`[[products]]
+name = "Hammer"
+
+[[products]]
+
+[[products]]
+name = "Nail"
+[products.size]
+len = 5`, `{"products":[{"name":"Hammer"},{},{"name":"Nail","size":{"len":5}}]}`)
+	f(`[x.y.z]
+a = 1
+[x]
+b = 2`, `{"x":{"y":{"z":{"a":1}},"b":2}}`)
+}
+
+func TestParseTOMLGet(t *testing.T) {
+	v, err := ParseTOMLBytes([]byte(`
+[server]
+port = 8080
+hosts = ["a", "b"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("server", "port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+	}
+	if s := string(v.GetStringBytes("server", "hosts", "1")); s != "b" {
+		t.Fatalf("unexpected host; got %q; want %q", s, "b")
+	}
+}
+
+func TestParseTOMLError(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := ParseTOML(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+
+	f(`a`)
+	f(`a =`)
+	f(`a = 1 b = 2`)
+	f(`a = "unclosed`)
+	f("a = \"new\nline\"")
+	f(`a = 'unclosed`)
+	f(`a = """unclosed`)
+	f(`a = "\q"`)
+	f(`a = "\u12"`)
+	f(`a = [1, 2`)
+	f(`a = [1 2]`)
+	f(`a = { b = 1`)
+	f(`a = 1
+a = 2`)
+	f(`[a]
+[a]`)
+	f(`a = 1
+[a]`)
+	f(`a = 1
+[[a]]`)
+	f(`a = { b = 1 }
+a.c = 2`)
+	f(`a = 012`)
+	f(`a = 1__0`)
+	f(`a = 0xzz`)
+	f(`a = abc`)
+	f(`[a`)
+
+	// Too deep nesting
+	if _, err := ParseTOML("a = " + strings.Repeat("[", MaxDepth) + strings.Repeat("]", MaxDepth)); err != nil {
+		t.Fatalf("unexpected error for nesting at MaxDepth: %s", err)
+	}
+	f("a = " + strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1))
+	f("a = " + strings.Repeat("{b = ", MaxDepth+1) + "1" + strings.Repeat("}", MaxDepth+1))
+	f("a = " + strings.Repeat("[", 1e6))
+}
+
+func TestMarshalTOMLTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		b, err := v.MarshalTOMLTo(nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		result := string(b)
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+
+		// Verify the result is read back to the same value.
+		v2, err := ParseTOML(result)
+		if err != nil {
+			t.Fatalf("cannot parse the marshaled TOML\n%s\nerror: %s", result, err)
+		}
+		if !EqualExcept(v, v2) {
+			t.Fatalf("unexpected value after round trip; got %s; want %s", v2, v)
+		}
+	}
+
+	f(``, "")
+	f(`name = "app \"q\"\n"; port = 8080; ratio = 0.5; debug = true; hex = 0x1F; big = 9223372036854775807L; list = [1, "a", {x = 1;}, []];`,
+		`name = "app \"q\"\n"
+port = 8080
+ratio = 0.5
+debug = true
+hex = 0x1F
+big = 9223372036854775807
+list = [1, "a", { x = 1 }, []]
+`)
+	f(`server = {host = "localhost"; tls = {enabled = false;}; port = 1;}; empty = {};`,
+		`[server]
+host = "localhost"
+port = 1
+
+[server.tls]
+enabled = false
+
+[empty]
+`)
+	f(`books = ({title = "a"; meta = {pages = 1;};}, {title = "b";});`,
+		`[[books]]
+title = "a"
+
+[books.meta]
+pages = 1
+
+[[books]]
+title = "b"
+`)
+
+	f(`a = 0X1fL; b = 0xFFL; c = 10L;`, "a = 0x1f\nb = 0xFF\nc = 10\n")
+
+	// Keys
+	var a Arena
+	v := a.NewObject()
+	v.Set("a b", a.NewNumberInt(1))
+	v.Set("", a.NewNumberInt(2))
+	v.Set("a-b_c", a.NewNumberInt(3))
+	b, err := v.MarshalTOMLTo(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resultExpected := "\"a b\" = 1\n\"\" = 2\na-b_c = 3\n"
+	if result := string(b); result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+
+	// Errors
+	for _, s := range []string{`a = null;`, `a = [1, null];`, `a = 1; b = {c = 2; d = null;};`} {
+		dst, err := MustParse(s).MarshalTOMLTo([]byte("prefix"))
+		if err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+		if string(dst) != "prefix" {
+			t.Fatalf("unexpected dst on error for %q; got %q; want %q", s, dst, "prefix")
+		}
+	}
+	if _, err := MustParse(`a = [1];`).Get("a").MarshalTOMLTo(nil); err == nil {
+		t.Fatalf("expecting non-nil error for array at the top level")
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// The helpers below perform the usual arithmetic on sizes and durations
+// obtained from config values. They never panic, overflow or return
+// values with surprising signs, so they may be used for deriving
+// settings without sprinkling guard checks around call sites.
+
+// SizePerWorker returns the share of total bytes per each of the given workers.
+//
+// The result is rounded down, but it is at least 1 for positive total,
+// so workers never get zero-sized buffers. 0 is returned for non-positive
+// total. Non-positive workers are treated as a single worker.
+func SizePerWorker(total int64, workers int) int64 {
+	if total <= 0 {
+		return 0
+	}
+	if workers <= 1 {
+		return total
+	}
+	n := total / int64(workers)
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// DurationPerWorker returns the share of total duration per each of the given workers.
+//
+// The result is rounded down, but it is at least 1ns for positive total.
+// 0 is returned for non-positive total. Non-positive workers are treated
+// as a single worker.
+func DurationPerWorker(total time.Duration, workers int) time.Duration {
+	return time.Duration(SizePerWorker(int64(total), workers))
+}
+
+// DurationJitter returns base randomly adjusted by up to pct percent
+// in both directions, e.g. DurationJitter(10*time.Second, 20) returns
+// a duration in the range [8s .. 12s].
+//
+// pct is clamped to [0 .. 100], so the result has the same sign as base.
+// The result saturates at the minimum and maximum durations.
+func DurationJitter(base time.Duration, pct float64) time.Duration {
+	if !(pct > 0) {
+		return base
+	}
+	if pct > 100 {
+		pct = 100
+	}
+	factor := 1 + pct/100*(2*rand.Float64()-1)
+	return ScaleDuration(base, factor)
+}
+
+// ScaleDuration returns d multiplied by factor.
+//
+// The result saturates at the minimum and maximum durations instead
+// of overflowing. 0 is returned for NaN factor.
+func ScaleDuration(d time.Duration, factor float64) time.Duration {
+	return time.Duration(scaleInt64(int64(d), factor))
+}
+
+// ScaleSize returns size multiplied by factor.
+//
+// The result saturates at math.MinInt64 and math.MaxInt64 instead
+// of overflowing. 0 is returned for NaN factor.
+func ScaleSize(size int64, factor float64) int64 {
+	return scaleInt64(size, factor)
+}
+
+func scaleInt64(n int64, factor float64) int64 {
+	if math.IsNaN(factor) {
+		return 0
+	}
+	f := math.Round(float64(n) * factor)
+	switch {
+	case math.IsNaN(f):
+		// 0 * Inf
+		return 0
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	default:
+		return int64(f)
+	}
+}
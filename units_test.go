@@ -0,0 +1,71 @@
+package libconfig
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSizePerWorker(t *testing.T) {
+	f := func(total int64, workers int, resultExpected int64) {
+		t.Helper()
+		if result := SizePerWorker(total, workers); result != resultExpected {
+			t.Fatalf("unexpected result for SizePerWorker(%d, %d); got %d; want %d", total, workers, result, resultExpected)
+		}
+	}
+
+	f(100, 4, 25)
+	f(100, 3, 33)
+	f(100, 1, 100)
+	f(100, 0, 100)
+	f(100, -5, 100)
+	f(3, 8, 1)
+	f(0, 8, 0)
+	f(-100, 4, 0)
+	f(math.MaxInt64, 2, math.MaxInt64/2)
+
+	if d := DurationPerWorker(time.Second, 4); d != 250*time.Millisecond {
+		t.Fatalf("unexpected DurationPerWorker result; got %s; want %s", d, 250*time.Millisecond)
+	}
+}
+
+func TestScaleDuration(t *testing.T) {
+	f := func(d time.Duration, factor float64, resultExpected time.Duration) {
+		t.Helper()
+		if result := ScaleDuration(d, factor); result != resultExpected {
+			t.Fatalf("unexpected result for ScaleDuration(%s, %v); got %s; want %s", d, factor, result, resultExpected)
+		}
+	}
+
+	f(time.Second, 1.5, 1500*time.Millisecond)
+	f(time.Second, 0, 0)
+	f(time.Second, -1, -time.Second)
+	f(time.Hour, 1e10, math.MaxInt64)
+	f(-time.Hour, 1e10, math.MinInt64)
+	f(time.Second, math.Inf(1), math.MaxInt64)
+	f(0, math.Inf(1), 0)
+	f(time.Second, math.NaN(), 0)
+
+	if n := ScaleSize(1<<20, 0.5); n != 1<<19 {
+		t.Fatalf("unexpected ScaleSize result; got %d; want %d", n, 1<<19)
+	}
+}
+
+func TestDurationJitter(t *testing.T) {
+	f := func(base time.Duration, pct float64, minExpected, maxExpected time.Duration) {
+		t.Helper()
+		for i := 0; i < 1000; i++ {
+			d := DurationJitter(base, pct)
+			if d < minExpected || d > maxExpected {
+				t.Fatalf("DurationJitter(%s, %v) result %s is out of range [%s .. %s]", base, pct, d, minExpected, maxExpected)
+			}
+		}
+	}
+
+	f(10*time.Second, 20, 8*time.Second, 12*time.Second)
+	f(10*time.Second, 0, 10*time.Second, 10*time.Second)
+	f(10*time.Second, -5, 10*time.Second, 10*time.Second)
+	f(10*time.Second, math.NaN(), 10*time.Second, 10*time.Second)
+	f(10*time.Second, 500, 0, 20*time.Second)
+	f(math.MaxInt64, 50, math.MaxInt64/2, math.MaxInt64)
+}
@@ -26,6 +26,7 @@
 package libconfig
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -35,6 +36,7 @@ func (o *Object) Del(key string) {
 	if o == nil {
 		return
 	}
+	o.mustNotBeFrozen()
 	if !o.keysUnescaped && strings.IndexByte(key, '\\') < 0 {
 		// Fast path - try searching for the key without object keys unescaping.
 		for i, kv := range o.kvs {
@@ -61,6 +63,7 @@ func (v *Value) Del(key string) {
 	if v == nil {
 		return
 	}
+	v.o.mustNotBeFrozen()
 	if v.t == TypeObject {
 		v.o.Del(key)
 		return
@@ -81,6 +84,7 @@ func (o *Object) Set(key string, value *Value) {
 	if o == nil {
 		return
 	}
+	o.mustNotBeFrozen()
 	if value == nil {
 		value = valueNull
 	}
@@ -95,6 +99,18 @@ func (o *Object) Set(key string, value *Value) {
 		}
 	}
 
+	if o.sorted {
+		// Insert new entry at its sorted position.
+		n := o.sortedIndex(key)
+		o.getKV()
+		copy(o.kvs[n+1:], o.kvs[n:len(o.kvs)-1])
+		o.kvs[n] = kv{
+			k: key,
+			v: value,
+		}
+		return
+	}
+
 	// Add new entry.
 	kv := o.getKV()
 	kv.k = key
@@ -108,6 +124,7 @@ func (v *Value) Set(key string, value *Value) {
 	if v == nil {
 		return
 	}
+	v.o.mustNotBeFrozen()
 	if v.t == TypeObject {
 		v.o.Set(key, value)
 		return
@@ -128,8 +145,43 @@ func (v *Value) SetArrayItem(idx int, value *Value) {
 	if v == nil || v.t != TypeArray {
 		return
 	}
+	v.o.mustNotBeFrozen()
 	for idx >= len(v.a) {
 		v.a = append(v.a, valueNull)
 	}
 	v.a[idx] = value
 }
+
+// ArrayAppend appends item to the array at the given keys path inside v.
+//
+// The array is created via a if the keys path is missing. Missing
+// intermediate objects are created too. The array at the root is appended
+// if keys are empty.
+//
+// item must be unchanged during v lifetime.
+func (v *Value) ArrayAppend(a *Arena, item *Value, keys ...string) error {
+	if item == nil {
+		item = valueNull
+	}
+	if len(keys) > 0 && v.Get(keys...) == nil {
+		arr := a.NewArray()
+		arr.a = append(arr.a, item)
+		return Path(keys).SetIn(a, v, arr)
+	}
+	arr := v.Get(keys...)
+	if arr == nil {
+		return fmt.Errorf("cannot append item to nil value")
+	}
+	if arr.t != TypeArray {
+		return fmt.Errorf("cannot append item to %s at %q", arr.Type(), Path(keys))
+	}
+	arr.o.mustNotBeFrozen()
+	arr.a = append(arr.a, item)
+	return nil
+}
+
+func (o *Object) mustNotBeFrozen() {
+	if o.flags&flagFrozen != 0 {
+		panic(fmt.Errorf("cannot modify frozen value; use Value.Clone for obtaining a modifiable copy"))
+	}
+}
@@ -101,3 +101,42 @@ func TestValueDelSet(t *testing.T) {
 	v.Set("x", MustParse(`[]`))
 	v.SetArrayItem(1, MustParse(`[]`))
 }
+
+func TestValueArrayAppend(t *testing.T) {
+	var a Arena
+	v := MustParse(`hosts = ["a"]; n = 1;`)
+
+	if err := v.ArrayAppend(&a, a.NewString("b"), "hosts"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := v.ArrayAppend(&a, a.NewNumberInt(1), "x", "y"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := v.ArrayAppend(&a, nil, "x", "y"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	str := v.String()
+	strExpected := `{"hosts":["a","b"],"n":1,"x":{"y":[1,null]}}`
+	if str != strExpected {
+		t.Fatalf("unexpected string representation; got %q; want %q", str, strExpected)
+	}
+
+	arr := a.NewArray()
+	if err := arr.ArrayAppend(&a, a.NewTrue()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if str := arr.String(); str != "[true]" {
+		t.Fatalf("unexpected string representation; got %q; want %q", str, "[true]")
+	}
+
+	if err := v.ArrayAppend(&a, a.NewTrue(), "n"); err == nil {
+		t.Fatalf("expecting non-nil error when appending to number")
+	}
+	if err := v.ArrayAppend(&a, a.NewTrue()); err == nil {
+		t.Fatalf("expecting non-nil error when appending to object")
+	}
+	v = nil
+	if err := v.ArrayAppend(&a, a.NewTrue()); err == nil {
+		t.Fatalf("expecting non-nil error when appending to nil value")
+	}
+}
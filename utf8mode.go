@@ -0,0 +1,185 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// UTF8Mode defines how Parser handles invalid UTF-8 and invalid escape
+// sequences in strings.
+type UTF8Mode int
+
+const (
+	// UTF8Default accepts strings as is. Invalid escape sequences are left
+	// unchanged during unescaping.
+	UTF8Default UTF8Mode = iota
+
+	// UTF8Strict rejects strings with invalid UTF-8 sequences, unknown
+	// escape sequences, malformed \u escapes and lone surrogates.
+	UTF8Strict
+
+	// UTF8Lenient replaces invalid UTF-8 sequences and lone surrogates
+	// in \u escapes with U+FFFD.
+	UTF8Lenient
+)
+
+// String returns string representation for m.
+func (m UTF8Mode) String() string {
+	switch m {
+	case UTF8Default:
+		return "default"
+	case UTF8Strict:
+		return "strict"
+	case UTF8Lenient:
+		return "lenient"
+	default:
+		return fmt.Sprintf("UTF8Mode(%d)", int(m))
+	}
+}
+
+// normalizeString checks raw string s according to cfg.UTF8Mode.
+//
+// s may be replaced with a sanitized copy in UTF8Lenient mode.
+func (cfg *ParserConfig) normalizeString(s string) (string, error) {
+	if cfg == nil {
+		return s, nil
+	}
+	switch cfg.UTF8Mode {
+	case UTF8Strict:
+		if !utf8.ValidString(s) {
+			return s, fmt.Errorf("invalid UTF-8 sequence in %q", startEndString(s))
+		}
+		if err := checkStringEscapes(s); err != nil {
+			return s, fmt.Errorf("%s in %q", err, startEndString(s))
+		}
+		return s, nil
+	case UTF8Lenient:
+		if !utf8.ValidString(s) {
+			s = strings.ToValidUTF8(s, "\uFFFD")
+		}
+		return replaceLoneSurrogates(s), nil
+	default:
+		return s, nil
+	}
+}
+
+func checkStringEscapes(s string) error {
+	for {
+		n := strings.IndexByte(s, '\\')
+		if n < 0 {
+			return nil
+		}
+		s = s[n+1:]
+		if len(s) == 0 {
+			return fmt.Errorf("unfinished escape sequence")
+		}
+		ch := s[0]
+		s = s[1:]
+		switch ch {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		case 'u':
+			r, ok := parseUnicodeEscape(s)
+			if !ok {
+				if len(s) > 4 {
+					s = s[:4]
+				}
+				return fmt.Errorf("invalid escape sequence \\u%s", s)
+			}
+			s = s[4:]
+			if !utf16.IsSurrogate(r) {
+				break
+			}
+			if r >= 0xDC00 {
+				return fmt.Errorf("lone surrogate \\u%04X", r)
+			}
+			if len(s) < 2 || s[0] != '\\' || s[1] != 'u' {
+				return fmt.Errorf("lone surrogate \\u%04X", r)
+			}
+			r1, ok := parseUnicodeEscape(s[2:])
+			if !ok || r1 < 0xDC00 || r1 > 0xDFFF {
+				return fmt.Errorf("lone surrogate \\u%04X", r)
+			}
+			s = s[6:]
+		default:
+			return fmt.Errorf("unknown escape sequence \\%c", ch)
+		}
+	}
+}
+
+// replaceLoneSurrogates replaces \u escapes for lone surrogates in s
+// with \uFFFD escapes.
+func replaceLoneSurrogates(s string) string {
+	if !strings.Contains(s, `\u`) {
+		return s
+	}
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			continue
+		}
+		if s[i+1] != 'u' {
+			// Skip the escaped char, so `\\u` isn't treated as \u escape.
+			i++
+			continue
+		}
+		r, ok := parseUnicodeEscape(s[i+2:])
+		if !ok || !utf16.IsSurrogate(r) {
+			continue
+		}
+		if r < 0xDC00 && i+12 <= len(s) && s[i+6] == '\\' && s[i+7] == 'u' {
+			if r1, ok := parseUnicodeEscape(s[i+8:]); ok && r1 >= 0xDC00 && r1 <= 0xDFFF {
+				// Valid surrogate pair.
+				i += 11
+				continue
+			}
+		}
+		if b == nil {
+			b = []byte(s)
+		}
+		copy(b[i+2:i+6], "FFFD")
+		i += 5
+	}
+	if b == nil {
+		return s
+	}
+	return string(b)
+}
+
+func parseUnicodeEscape(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	x, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(x), true
+}
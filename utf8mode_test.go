@@ -0,0 +1,68 @@
+package libconfig
+
+import (
+	"testing"
+)
+
+func TestParserUTF8Strict(t *testing.T) {
+	p := Parser{
+		Config: ParserConfig{
+			UTF8Mode: UTF8Strict,
+		},
+	}
+	f := func(s string, ok bool) {
+		t.Helper()
+		_, err := p.Parse(s)
+		if ok && err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		if !ok && err == nil {
+			t.Fatalf("expecting non-nil error for %q", s)
+		}
+	}
+	f(`a = "foo";`, true)
+	f(`a = "привет";`, true)
+	f(`a = "x\n\t\"\\\/A";`, true)
+	f(`a = "\ud83d\ude00";`, true)
+	f("a = \"\xff\";", false)
+	f("\xff = 1;", false)
+	f(`a = "\ud83d";`, false)
+	f(`a = "\ud83dx";`, false)
+	f(`a = "\ude00";`, false)
+	f(`a = "\ud83dA";`, false)
+	f(`a = "\x41";`, false)
+	f(`a = "\u12";`, false)
+	f(`a = "\uzzzz";`, false)
+}
+
+func TestParserUTF8Lenient(t *testing.T) {
+	p := Parser{
+		Config: ParserConfig{
+			UTF8Mode: UTF8Lenient,
+		},
+	}
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := p.Parse("a = \"" + s + "\";")
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", s, err)
+		}
+		result := string(v.GetStringBytes("a"))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q; got %q; want %q", s, result, resultExpected)
+		}
+	}
+	f(`foo`, "foo")
+	f("a\xffb", "a\uFFFDb")
+	f(`\ud83d\ude00`, "\U0001F600")
+	f(`x\ud83dy`, "x\uFFFDy")
+	f(`\ude00A`, "\uFFFDA")
+	f(`\ud83dA`, "\uFFFDA")
+	f(`\\ud83d`, `\ud83d`)
+}
+
+func TestUTF8ModeString(t *testing.T) {
+	if s := UTF8Lenient.String(); s != "lenient" {
+		t.Fatalf("unexpected string; got %q; want %q", s, "lenient")
+	}
+}
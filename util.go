@@ -29,25 +29,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"reflect"
 	"strconv"
 	"strings"
-	"unsafe"
 )
 
-func b2s(b []byte) string {
-	return *(*string)(unsafe.Pointer(&b))
-}
-
-func s2b(s string) (b []byte) {
-	strh := (*reflect.StringHeader)(unsafe.Pointer(&s))
-	sh := (*reflect.SliceHeader)(unsafe.Pointer(&b))
-	sh.Data = strh.Data
-	sh.Len = strh.Len
-	sh.Cap = strh.Len
-	return b
-}
-
 const maxStartEndStringLen = 80
 
 func startEndString(s string) string {
@@ -79,6 +64,10 @@ func scanMatch(path string) []string {
 }
 
 func matchFile(filename string, matching string) bool {
+	if strings.IndexByte(matching, '*') < 0 {
+		// Fast path - exact file name.
+		return filename == matching
+	}
 	matchs := strings.Split(matching, "*")
 
 	if matchs[0] != "" {
@@ -0,0 +1,460 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Viper is a compatibility layer emulating the core API of spf13/viper,
+// so code may migrate from viper incrementally.
+//
+// Values are resolved in the following order: values set via Set, env vars
+// bound via BindEnv, the config read via ReadInConfig or ReadConfig
+// and defaults set via SetDefault. Keys are dotted paths matched
+// case-insensitively, since all the keys are converted to lower case
+// in the same way as viper does.
+//
+// Typed getters convert values in the same way as viper does, e.g. GetInt
+// parses strings, while GetString formats numbers and bools.
+//
+// The zero value is usable. Viper cannot be used from concurrent goroutines.
+type Viper struct {
+	configFile string
+	envPrefix  string
+
+	// env maps lower-case keys to the bound env var names.
+	env map[string][]string
+
+	// a holds defaults and overrides, while ca holds config.
+	a  Arena
+	ca Arena
+
+	defaults  *Value
+	config    *Value
+	overrides *Value
+}
+
+// SetConfigFile sets the path to the config file for ReadInConfig.
+func (vp *Viper) SetConfigFile(path string) {
+	vp.configFile = path
+}
+
+// ReadInConfig reads the config file set via SetConfigFile.
+//
+// See LoadFile for details.
+func (vp *Viper) ReadInConfig() error {
+	if vp.configFile == "" {
+		return fmt.Errorf("config file isn't set; call SetConfigFile first")
+	}
+	v, err := LoadFile(vp.configFile, nil)
+	if err != nil {
+		return err
+	}
+	return vp.setConfig(v)
+}
+
+// ReadConfig reads the config from r.
+func (vp *Viper) ReadConfig(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("cannot read config: %s", err)
+	}
+	p := handyPool.Get()
+	defer handyPool.Put(p)
+	v, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return vp.setConfig(v.Clone())
+}
+
+func (vp *Viper) setConfig(v *Value) error {
+	if v.Type() != TypeObject {
+		return fmt.Errorf("config must be an object; got %s", v.Type())
+	}
+	vp.ca.Reset()
+	vp.config = lowerKeys(&vp.ca, v)
+	return nil
+}
+
+// SetDefault sets the default value for key.
+//
+// value is converted via Arena.Marshal. Values, which cannot be converted,
+// are ignored in the same way as viper ignores invalid values.
+func (vp *Viper) SetDefault(key string, value interface{}) {
+	if vp.defaults == nil {
+		vp.defaults = vp.a.NewObject()
+	}
+	vp.set(vp.defaults, key, value)
+}
+
+// Set overrides the value for key.
+//
+// value is converted via Arena.Marshal. Values, which cannot be converted,
+// are ignored in the same way as viper ignores invalid values.
+func (vp *Viper) Set(key string, value interface{}) {
+	if vp.overrides == nil {
+		vp.overrides = vp.a.NewObject()
+	}
+	vp.set(vp.overrides, key, value)
+}
+
+func (vp *Viper) set(layer *Value, key string, value interface{}) {
+	x, err := vp.a.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = viperPath(key).SetIn(&vp.a, layer, lowerKeys(&vp.a, x))
+}
+
+// SetEnvPrefix sets the prefix for env var names bound via BindEnv.
+//
+// The prefix is converted to upper case.
+func (vp *Viper) SetEnvPrefix(prefix string) {
+	vp.envPrefix = strings.ToUpper(prefix)
+}
+
+// BindEnv binds the key from input[0] to env vars from input[1:].
+//
+// If only the key is passed, then it is bound to the env var with the name
+// consisting of the prefix set via SetEnvPrefix and the upper-case key
+// with dots replaced by underscores, e.g. APP_DB_PORT for db.port.
+func (vp *Viper) BindEnv(input ...string) error {
+	if len(input) == 0 {
+		return fmt.Errorf("missing key to bind to")
+	}
+	key := strings.ToLower(input[0])
+	names := input[1:]
+	if len(names) == 0 {
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if vp.envPrefix != "" {
+			name = vp.envPrefix + "_" + name
+		}
+		names = []string{name}
+	}
+	if vp.env == nil {
+		vp.env = make(map[string][]string)
+	}
+	vp.env[key] = append([]string(nil), names...)
+	return nil
+}
+
+// find returns the value for key from the layer with the highest priority.
+func (vp *Viper) find(key string) *Value {
+	keys := viperPath(key)
+	if x := vp.overrides.Get(keys...); x != nil {
+		return x
+	}
+	if x := vp.envValue(strings.ToLower(key), vp.config.Get(keys...)); x != nil {
+		return x
+	}
+	if x := vp.config.Get(keys...); x != nil {
+		return x
+	}
+	return vp.defaults.Get(keys...)
+}
+
+// value returns the value for key or null if key is missing.
+func (vp *Viper) value(key string) *Value {
+	if x := vp.find(key); x != nil {
+		return x
+	}
+	return valueNull
+}
+
+// envValue returns the value of the env var bound to key.
+//
+// prev is the value overridden by the env var. It may be nil.
+func (vp *Viper) envValue(key string, prev *Value) *Value {
+	for _, name := range vp.env[key] {
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		var a Arena
+		x, err := envValue(&a, s, prev)
+		if err != nil {
+			x = a.NewString(s)
+		}
+		return x
+	}
+	return nil
+}
+
+// merged returns all the layers merged into a single value allocated in a.
+func (vp *Viper) merged(a *Arena) *Value {
+	env := a.NewObject()
+	for key := range vp.env {
+		if x := vp.envValue(key, vp.config.Get(viperPath(key)...)); x != nil {
+			_ = viperPath(key).SetIn(a, env, x)
+		}
+	}
+	v := MergeValues(a, vp.defaults, vp.config, env, vp.overrides)
+	if v == nil {
+		v = a.NewObject()
+	}
+	return v
+}
+
+// get returns the value for key.
+//
+// Objects are merged from all the layers.
+func (vp *Viper) get(key string) *Value {
+	x := vp.find(key)
+	if x == nil || x.Type() != TypeObject {
+		return x
+	}
+	var a Arena
+	return vp.merged(&a).Get(viperPath(key)...)
+}
+
+// IsSet returns true if key has a value in any layer.
+func (vp *Viper) IsSet(key string) bool {
+	return vp.find(key) != nil
+}
+
+// Get returns the value for key.
+//
+// Objects are returned as map[string]interface{}, arrays are returned
+// as []interface{}, while numbers are returned as float64.
+// nil is returned for missing keys.
+func (vp *Viper) Get(key string) interface{} {
+	x := vp.get(key)
+	if x == nil {
+		return nil
+	}
+	v, err := interfaceValue(x)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// GetString returns the string value for key.
+//
+// Numbers and bools are formatted as strings.
+func (vp *Viper) GetString(key string) string {
+	return viperString(vp.value(key))
+}
+
+// GetBool returns the bool value for key.
+//
+// Strings are parsed with strconv.ParseBool, while non-zero numbers are true.
+func (vp *Viper) GetBool(key string) bool {
+	x := vp.value(key)
+	switch x.Type() {
+	case TypeTrue:
+		return true
+	case TypeString:
+		b, _ := strconv.ParseBool(strings.TrimSpace(x.s))
+		return b
+	case TypeNumber:
+		f, _ := x.Float64()
+		return f != 0
+	default:
+		return false
+	}
+}
+
+// GetInt returns the int value for key.
+//
+// See GetInt64 for details.
+func (vp *Viper) GetInt(key string) int {
+	return int(vp.GetInt64(key))
+}
+
+// GetInt64 returns the int64 value for key.
+//
+// Strings are parsed as integers, while floats are truncated.
+// 0 is returned for missing keys and values, which cannot be converted.
+func (vp *Viper) GetInt64(key string) int64 {
+	x := vp.value(key)
+	switch x.Type() {
+	case TypeNumber:
+		if n, err := x.Int64(); err == nil {
+			return n
+		}
+		f, _ := x.Float64()
+		return int64(f)
+	case TypeString:
+		n, _ := strconv.ParseInt(strings.TrimSpace(x.s), 0, 64)
+		return n
+	case TypeTrue:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GetFloat64 returns the float64 value for key.
+//
+// Strings are parsed as floats.
+// 0 is returned for missing keys and values, which cannot be converted.
+func (vp *Viper) GetFloat64(key string) float64 {
+	x := vp.value(key)
+	switch x.Type() {
+	case TypeNumber:
+		f, _ := x.Float64()
+		return f
+	case TypeString:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(x.s), 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// GetDuration returns the time.Duration value for key.
+//
+// Strings are parsed with time.ParseDuration, while numbers
+// are treated as nanoseconds.
+func (vp *Viper) GetDuration(key string) time.Duration {
+	x := vp.value(key)
+	switch x.Type() {
+	case TypeNumber:
+		return time.Duration(vp.GetInt64(key))
+	case TypeString:
+		s := strings.TrimSpace(x.s)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Duration(n)
+		}
+		d, _ := time.ParseDuration(s)
+		return d
+	default:
+		return 0
+	}
+}
+
+// GetStringSlice returns the []string value for key.
+//
+// Array items are converted to strings, while strings are split
+// by whitespace.
+func (vp *Viper) GetStringSlice(key string) []string {
+	x := vp.value(key)
+	switch x.Type() {
+	case TypeArray:
+		a := make([]string, len(x.a))
+		for i, item := range x.a {
+			a[i] = viperString(item)
+		}
+		return a
+	case TypeString:
+		return strings.Fields(x.s)
+	default:
+		return nil
+	}
+}
+
+// AllSettings returns all the settings merged from all the layers.
+func (vp *Viper) AllSettings() map[string]interface{} {
+	var a Arena
+	x, err := interfaceValue(vp.merged(&a))
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return x.(map[string]interface{})
+}
+
+// Sub returns Viper containing the settings for key.
+//
+// nil is returned if key doesn't contain an object.
+// The returned Viper doesn't reference vp.
+func (vp *Viper) Sub(key string) *Viper {
+	x := vp.get(key)
+	if x == nil || x.Type() != TypeObject {
+		return nil
+	}
+	sub := &Viper{
+		envPrefix: vp.envPrefix,
+	}
+	sub.config = lowerKeys(&sub.ca, x.Clone())
+	return sub
+}
+
+// Unmarshal stores all the settings in the value pointed to by rawVal.
+//
+// See Value.Unmarshal for details.
+func (vp *Viper) Unmarshal(rawVal interface{}) error {
+	var a Arena
+	return vp.merged(&a).Unmarshal(rawVal)
+}
+
+// UnmarshalKey stores the settings for key in the value pointed to by rawVal.
+//
+// See Value.Unmarshal for details.
+func (vp *Viper) UnmarshalKey(key string, rawVal interface{}) error {
+	x := vp.get(key)
+	if x == nil {
+		return fmt.Errorf("missing key %q", key)
+	}
+	return x.Unmarshal(rawVal)
+}
+
+func viperPath(key string) Path {
+	return Path(strings.Split(strings.ToLower(key), "."))
+}
+
+func viperString(x *Value) string {
+	switch x.Type() {
+	case TypeString:
+		return strings.Clone(x.s)
+	case TypeNumber:
+		return jsonNumber(x.s)
+	case TypeTrue, TypeFalse:
+		return x.Type().String()
+	default:
+		return ""
+	}
+}
+
+// lowerKeys returns a copy of v with lower-case object keys.
+//
+// Objects and arrays are allocated in a, while scalar values are shared with v.
+func lowerKeys(a *Arena, v *Value) *Value {
+	switch v.Type() {
+	case TypeObject:
+		o := a.NewObject()
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			o.o.Set(strings.ToLower(kv.k), lowerKeys(a, kv.v))
+		}
+		return o
+	case TypeArray:
+		arr := a.NewArray()
+		for _, item := range v.a {
+			arr.a = append(arr.a, lowerKeys(a, item))
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,150 @@
+package libconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViper(t *testing.T) {
+	var vp Viper
+	vp.SetDefault("server.port", 80)
+	vp.SetDefault("server.host", "localhost")
+	vp.SetDefault("timeout", "5s")
+	vp.SetDefault("Tags", []string{"a"})
+
+	config := `server = {Port = 8080; tls = true;}; name = "app"; ratio = "0.5"; tags = ["x", 1];`
+	if err := vp.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Setenv("APP_SERVER_HOST", "example.com")
+	t.Setenv("APP_NAME", "123")
+	vp.SetEnvPrefix("app")
+	if err := vp.BindEnv("server.host"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := vp.BindEnv("name"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := vp.BindEnv("missing", "APP_MISSING_1", "APP_MISSING_2"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	vp.Set("server.tls", false)
+
+	if n := vp.GetInt("server.port"); n != 8080 {
+		t.Fatalf("unexpected server.port; got %d; want 8080", n)
+	}
+	if n := vp.GetInt("SERVER.PORT"); n != 8080 {
+		t.Fatalf("unexpected SERVER.PORT; got %d; want 8080", n)
+	}
+	if s := vp.GetString("server.port"); s != "8080" {
+		t.Fatalf("unexpected server.port string; got %q; want %q", s, "8080")
+	}
+	if s := vp.GetString("server.host"); s != "example.com" {
+		t.Fatalf("unexpected server.host; got %q; want %q", s, "example.com")
+	}
+	if s := vp.GetString("name"); s != "123" {
+		t.Fatalf("unexpected name; got %q; want %q", s, "123")
+	}
+	if vp.GetBool("server.tls") {
+		t.Fatalf("unexpected server.tls; got true; want false")
+	}
+	if f := vp.GetFloat64("ratio"); f != 0.5 {
+		t.Fatalf("unexpected ratio; got %v; want 0.5", f)
+	}
+	if d := vp.GetDuration("timeout"); d != 5*time.Second {
+		t.Fatalf("unexpected timeout; got %s; want 5s", d)
+	}
+	if a := vp.GetStringSlice("tags"); !reflect.DeepEqual(a, []string{"x", "1"}) {
+		t.Fatalf("unexpected tags; got %q; want %q", a, []string{"x", "1"})
+	}
+	if !vp.IsSet("server.tls") || vp.IsSet("missing") || vp.IsSet("server.missing") {
+		t.Fatalf("unexpected IsSet results")
+	}
+	if x := vp.Get("missing"); x != nil {
+		t.Fatalf("unexpected value for missing key: %v", x)
+	}
+	if n := vp.GetInt("missing"); n != 0 {
+		t.Fatalf("unexpected value for missing key: %d", n)
+	}
+
+	// Objects are merged from all the layers.
+	serverExpected := map[string]interface{}{
+		"port": float64(8080),
+		"host": "example.com",
+		"tls":  false,
+	}
+	if x := vp.Get("server"); !reflect.DeepEqual(x, serverExpected) {
+		t.Fatalf("unexpected server; got %v; want %v", x, serverExpected)
+	}
+
+	var server struct {
+		Host string
+		Port int
+		TLS  bool
+	}
+	if err := vp.UnmarshalKey("server", &server); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if server.Host != "example.com" || server.Port != 8080 || server.TLS {
+		t.Fatalf("unexpected server: %+v", server)
+	}
+	if err := vp.UnmarshalKey("missing", &server); err == nil {
+		t.Fatalf("expecting non-nil error for missing key")
+	}
+
+	var all struct {
+		Name   string
+		Server struct {
+			Host string
+		}
+	}
+	if err := vp.Unmarshal(&all); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if all.Name != "123" || all.Server.Host != "example.com" {
+		t.Fatalf("unexpected settings: %+v", all)
+	}
+
+	if n := len(vp.AllSettings()); n != 5 {
+		t.Fatalf("unexpected number of settings; got %d; want 5: %v", n, vp.AllSettings())
+	}
+
+	sub := vp.Sub("server")
+	if sub == nil {
+		t.Fatalf("unexpected nil Sub")
+	}
+	if s := sub.GetString("host"); s != "example.com" {
+		t.Fatalf("unexpected host in Sub; got %q; want %q", s, "example.com")
+	}
+	if sub := vp.Sub("name"); sub != nil {
+		t.Fatalf("expecting nil Sub for non-object")
+	}
+}
+
+func TestViperReadInConfig(t *testing.T) {
+	var vp Viper
+	if err := vp.ReadInConfig(); err == nil {
+		t.Fatalf("expecting non-nil error without config file")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.cfg")
+	if err := os.WriteFile(path, []byte(`a = {B = 1;};`), 0644); err != nil {
+		t.Fatalf("cannot write %q: %s", path, err)
+	}
+	vp.SetConfigFile(path)
+	if err := vp.ReadInConfig(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := vp.GetInt("a.b"); n != 1 {
+		t.Fatalf("unexpected a.b; got %d; want 1", n)
+	}
+	if err := vp.BindEnv(); err == nil {
+		t.Fatalf("expecting non-nil error for BindEnv without key")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"errors"
+	"strconv"
+)
+
+// SkipChildren may be returned by the callback passed to Walk in order
+// to skip the children of the current value.
+var SkipChildren = errors.New("skip children")
+
+// Walk calls fn for v and all its descendants in depth-first order.
+//
+// path contains keys path from v to the visited value. Array indexes are
+// represented as decimal numbers in path. The root v is visited with
+// an empty path. path is valid only until fn returns, so it must be copied
+// if it is retained.
+//
+// Walking stops and the error is returned if fn returns non-nil error,
+// except of SkipChildren, which skips the children of the visited value.
+func Walk(v *Value, fn func(path []string, v *Value) error) error {
+	if v == nil {
+		return nil
+	}
+	var path []string
+	return walkValue(&path, v, fn)
+}
+
+func walkValue(path *[]string, v *Value, fn func(path []string, v *Value) error) error {
+	if err := fn(*path, v); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	n := len(*path)
+	switch v.Type() {
+	case TypeObject:
+		v.o.unescapeKeys()
+		for _, kv := range v.o.kvs {
+			*path = append((*path)[:n], kv.k)
+			if err := walkValue(path, kv.v, fn); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		for i, x := range v.a {
+			*path = append((*path)[:n], strconv.Itoa(i))
+			if err := walkValue(path, x, fn); err != nil {
+				return err
+			}
+		}
+	}
+	*path = (*path)[:n]
+	return nil
+}
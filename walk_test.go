@@ -0,0 +1,54 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	v := MustParse(`a = {b = 1; c = [true, "x"];}; d = {e = 2;}; f = null;`)
+
+	f := func(skipKey, resultExpected string) {
+		t.Helper()
+		var a []string
+		err := Walk(v, func(path []string, x *Value) error {
+			a = append(a, fmt.Sprintf("%s=%s", Path(path), x.Type()))
+			if len(path) > 0 && path[len(path)-1] == skipKey {
+				return SkipChildren
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result := strings.Join(a, " "); result != resultExpected {
+			t.Fatalf("unexpected result; got %q; want %q", result, resultExpected)
+		}
+	}
+
+	f("", "=object a=object a.b=number a.c=array a.c.0=true a.c.1=string d=object d.e=number f=null")
+	f("a", "=object a=object d=object d.e=number f=null")
+	f("c", "=object a=object a.b=number a.c=array d=object d.e=number f=null")
+
+	// Verify the error is propagated and stops walking.
+	n := 0
+	errExpected := fmt.Errorf("stop")
+	err := Walk(v, func(path []string, x *Value) error {
+		n++
+		if x.Type() == TypeArray {
+			return errExpected
+		}
+		return nil
+	})
+	if err != errExpected {
+		t.Fatalf("unexpected error; got %v; want %v", err, errExpected)
+	}
+	if n != 4 {
+		t.Fatalf("unexpected number of visited values; got %d; want %d", n, 4)
+	}
+
+	if err := Walk(nil, nil); err != nil {
+		t.Fatalf("unexpected error for nil value: %s", err)
+	}
+}
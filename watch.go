@@ -0,0 +1,437 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchOptions contains options for Watch.
+type WatchOptions struct {
+	// Interval is the interval for checking the watched file for modifications.
+	//
+	// One second is used by default.
+	Interval time.Duration
+
+	// Validate is an optional function for validating newly parsed values.
+	//
+	// Values failing validation aren't activated.
+	Validate func(v *Value) error
+
+	// Verifier is an optional verifier for the watched file contents.
+	//
+	// Modified files failing verification aren't activated.
+	Verifier Verifier
+
+	// AuditSink is an optional sink for config lifecycle events.
+	AuditSink AuditSink
+
+	// MaxInputSize is the maximum size in bytes of the watched file.
+	//
+	// See LoadOptions.MaxInputSize for details.
+	MaxInputSize int
+
+	// OnChange is an optional function called with each newly activated snapshot.
+	OnChange func(v *Value)
+
+	// OnError is an optional function called on failed reloads.
+	OnError func(err error)
+
+	// ReloadBudget is an optional time budget for a single reload.
+	//
+	// Reloads exceeding the budget are reported via OnSlowReload.
+	ReloadBudget time.Duration
+
+	// OnSlowReload is an optional function called for reloads exceeding ReloadBudget.
+	OnSlowReload func(stats ReloadStats)
+
+	// OnReload is an optional function called with timings of every reload.
+	//
+	// It may be used for benchmarking reloads of production-sized configs.
+	OnReload func(stats ReloadStats)
+
+	// LazyFallback switches the watcher to differential reloads after
+	// a reload exceeds ReloadBudget.
+	//
+	// Differential reloads index top-level keys only and re-use top-level
+	// subtrees from the current snapshot if their source text didn't change,
+	// so only the modified subtrees are parsed and frozen. Files with
+	// @include directives are always reloaded in full.
+	LazyFallback bool
+}
+
+// ReloadStats contains timings for a single reload.
+type ReloadStats struct {
+	// Parse is the time spent on reading and parsing the file.
+	//
+	// Only top-level keys are indexed during differential reloads.
+	Parse time.Duration
+
+	// Diff is the time spent on comparing top-level entries with the current
+	// snapshot and parsing the modified entries during differential reloads.
+	Diff time.Duration
+
+	// Validate is the time spent in WatchOptions.Validate.
+	Validate time.Duration
+
+	// Total is the total reload duration.
+	Total time.Duration
+
+	// Lazy is set for differential reloads.
+	Lazy bool
+
+	// Reused is the number of top-level subtrees re-used from the current snapshot
+	// during differential reloads.
+	Reused int
+
+	// Err is the reload error if any.
+	Err error
+}
+
+// ConfigWatcher watches a config file or URL and reloads it on modification.
+//
+// ConfigWatcher may be used from concurrent goroutines.
+type ConfigWatcher struct {
+	path string
+	opts WatchOptions
+
+	// remote, ctx and cancel are set for configs watched via WatchHTTP.
+	remote *HTTPLoader
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	cfg Config
+	ch  chan *Value
+
+	// prev and prevChecksum hold the previously active snapshot for Rollback.
+	prev         *Value
+	prevChecksum string
+	checksum     string
+
+	// mu serializes reloads.
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+
+	// lazy is set after switching to differential reloads.
+	lazy bool
+
+	// entries holds source texts for top-level entries of the current
+	// snapshot obtained via differential reload.
+	entries map[string]watchEntry
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type watchEntry struct {
+	raw string
+	v   *Value
+}
+
+// Watch loads the config file at path and starts watching it for modifications.
+//
+// An error is returned if the initial load fails. Subsequent failed reloads
+// keep the previous snapshot active and are reported via opts.OnError.
+//
+// opts may be nil. Call Stop when the returned watcher is no longer needed.
+func Watch(path string, opts *WatchOptions) (*ConfigWatcher, error) {
+	w := &ConfigWatcher{
+		path:   path,
+		ch:     make(chan *Value, 1),
+		stopCh: make(chan struct{}),
+	}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Interval <= 0 {
+		w.opts.Interval = time.Second
+	}
+	if _, err := w.reload(true); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.watch()
+	return w, nil
+}
+
+// Load returns the current config snapshot.
+//
+// The returned value is frozen, so it may be read from concurrent goroutines
+// and it remains valid after subsequent reloads. It must not be modified.
+func (w *ConfigWatcher) Load() *Value {
+	return w.cfg.Load()
+}
+
+// Changes returns a channel receiving newly activated snapshots.
+//
+// Only the latest snapshot is kept in the channel if the receiver
+// falls behind.
+func (w *ConfigWatcher) Changes() <-chan *Value {
+	return w.ch
+}
+
+// Reload forcibly re-reads the watched file.
+//
+// The previous snapshot remains active if the file cannot be loaded.
+// The config watched via WatchHTTP is activated only if it has been changed.
+func (w *ConfigWatcher) Reload() error {
+	_, err := w.reload(true)
+	return err
+}
+
+// Stop stops watching the file.
+func (w *ConfigWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		if w.cancel != nil {
+			w.cancel()
+		}
+	})
+	w.wg.Wait()
+}
+
+func (w *ConfigWatcher) watch() {
+	defer w.wg.Done()
+
+	t := time.NewTicker(w.opts.Interval)
+	defer t.Stop()
+	var done <-chan struct{}
+	if w.ctx != nil {
+		done = w.ctx.Done()
+	}
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-done:
+			return
+		case <-t.C:
+		}
+		if _, err := w.reload(false); err != nil && w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+	}
+}
+
+// reload loads the watched file if it has been modified since the last load
+// or if force is set.
+//
+// true is returned if a new snapshot has been activated.
+func (w *ConfigWatcher) reload(force bool) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.remote != nil {
+		return w.reloadRemote()
+	}
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return false, fmt.Errorf("cannot stat config file %q: %s", w.path, err)
+	}
+	if !force && fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return false, nil
+	}
+	// Remember the file state even if the load fails, so broken files
+	// aren't re-parsed on every check.
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+
+	stats := ReloadStats{
+		Lazy: w.lazy,
+	}
+	startTime := time.Now()
+	err = w.load(&stats)
+	stats.Total = time.Since(startTime)
+	stats.Err = err
+	w.reportReload(&stats)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *ConfigWatcher) load(stats *ReloadStats) error {
+	opts := &LoadOptions{
+		Verifier:     w.opts.Verifier,
+		AuditSink:    w.opts.AuditSink,
+		MaxInputSize: w.opts.MaxInputSize,
+	}
+	var v *Value
+	var entries map[string]watchEntry
+	var checksum string
+	var err error
+	if w.lazy {
+		v, entries, checksum, err = w.loadLazy(opts, stats)
+	} else {
+		startTime := time.Now()
+		var p Parser
+		v, checksum, err = loadFile(&p, w.path, opts)
+		stats.Parse = time.Since(startTime)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot load config file %q: %s", w.path, err)
+	}
+	if w.opts.Validate != nil {
+		startTime := time.Now()
+		err := w.opts.Validate(v)
+		stats.Validate = time.Since(startTime)
+		if err != nil {
+			err = fmt.Errorf("invalid config file %q: %s", w.path, err)
+			emitAudit(w.opts.AuditSink, AuditValidationFailed, w.path, checksum, err)
+			return err
+		}
+	}
+	w.activate(v.Freeze(), checksum, AuditActivated)
+	w.entries = entries
+	return nil
+}
+
+// loadLazy loads the watched file, re-using top-level subtrees from
+// the current snapshot, which have the same source text.
+//
+// The returned value is frozen.
+func (w *ConfigWatcher) loadLazy(opts *LoadOptions, stats *ReloadStats) (*Value, map[string]watchEntry, string, error) {
+	startTime := time.Now()
+	data, checksum, err := readConfigFile(w.path, opts)
+	if err != nil {
+		emitAudit(opts.AuditSink, AuditLoadFailed, w.path, checksum, err)
+		return nil, nil, checksum, err
+	}
+	var lp LazyParser
+	if err := lp.ParseBytes(data); err != nil {
+		// Fall back to full parsing, which supports @include directives
+		// and reports syntax errors in the usual way.
+		stats.Lazy = false
+		var p Parser
+		v, err := parseConfigFile(&p, w.path, data)
+		stats.Parse = time.Since(startTime)
+		if err != nil {
+			emitAudit(opts.AuditSink, AuditLoadFailed, w.path, checksum, err)
+			return nil, nil, checksum, err
+		}
+		emitAudit(opts.AuditSink, AuditLoaded, w.path, checksum, nil)
+		return v, nil, checksum, nil
+	}
+	stats.Parse = time.Since(startTime)
+
+	startTime = time.Now()
+	v := &Value{
+		t: TypeObject,
+	}
+	entries := make(map[string]watchEntry, len(lp.root.entries))
+	for i := range lp.root.entries {
+		e := &lp.root.entries[i]
+		raw := e.raw[:len(e.raw)-len(e.end)]
+		we, ok := w.entries[e.key]
+		if ok && we.raw == raw {
+			stats.Reused++
+		} else {
+			x, err := lp.root.parseEntry(&lp.c, e)
+			if err != nil {
+				err = fmt.Errorf("cannot parse libconfig: %s", err)
+				emitAudit(opts.AuditSink, AuditLoadFailed, w.path, checksum, err)
+				return nil, nil, checksum, err
+			}
+			we = watchEntry{
+				raw: raw,
+				v:   x.Freeze(),
+			}
+		}
+		entries[e.key] = we
+		appendObjectKV(&v.o, e.key, we.v)
+	}
+	v.o.unescapeKeys()
+	v.o.flags |= flagFrozen
+	stats.Diff = time.Since(startTime)
+
+	emitAudit(opts.AuditSink, AuditLoaded, w.path, checksum, nil)
+	return v, entries, checksum, nil
+}
+
+func (w *ConfigWatcher) reportReload(stats *ReloadStats) {
+	if w.opts.OnReload != nil {
+		w.opts.OnReload(*stats)
+	}
+	if w.opts.ReloadBudget <= 0 || stats.Total <= w.opts.ReloadBudget {
+		return
+	}
+	if w.opts.OnSlowReload != nil {
+		w.opts.OnSlowReload(*stats)
+	}
+	if w.opts.LazyFallback {
+		w.lazy = true
+	}
+}
+
+// Rollback re-activates the snapshot, which was active before the current one.
+//
+// false is returned if there is no previous snapshot.
+func (w *ConfigWatcher) Rollback() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.prev == nil {
+		return false
+	}
+	w.activate(w.prev, w.prevChecksum, AuditRolledBack)
+	// Source texts for the current entries don't match the rolled back snapshot.
+	w.entries = nil
+	return true
+}
+
+func (w *ConfigWatcher) activate(v *Value, checksum string, kind AuditEventKind) {
+	w.prev = w.cfg.Load()
+	w.prevChecksum = w.checksum
+	w.checksum = checksum
+	w.cfg.Store(v)
+	emitAudit(w.opts.AuditSink, kind, w.path, checksum, nil)
+	w.notify(v)
+}
+
+func (w *ConfigWatcher) notify(v *Value) {
+	if w.opts.OnChange != nil {
+		w.opts.OnChange(v)
+	}
+	for {
+		select {
+		case w.ch <- v:
+			return
+		default:
+		}
+		// Drop the stale snapshot, so the receiver gets the latest one.
+		select {
+		case <-w.ch:
+		default:
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package libconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cfg")
+	writeFile := func(s string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("cannot write file: %s", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("cannot change file times: %s", err)
+		}
+	}
+	now := time.Now()
+	writeFile(`port = 8080;`, now)
+
+	errCh := make(chan error, 10)
+	w, err := Watch(path, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		Validate: func(v *Value) error {
+			if v.GetInt("port") <= 0 {
+				return fmt.Errorf("port must be positive")
+			}
+			return nil
+		},
+		OnError: func(err error) {
+			errCh <- err
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer w.Stop()
+
+	if n := w.Load().GetInt("port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+	}
+	<-w.Changes()
+
+	writeFile(`port = 9090;`, now.Add(time.Second))
+	select {
+	case v := <-w.Changes():
+		if n := v.GetInt("port"); n != 9090 {
+			t.Fatalf("unexpected port; got %d; want %d", n, 9090)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout")
+	}
+
+	// Invalid files must keep the old snapshot.
+	writeFile(`port = 0;`, now.Add(2*time.Second))
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout")
+	}
+	writeFile(`port = `, now.Add(3*time.Second))
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expecting non-nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout")
+	}
+	if n := w.Load().GetInt("port"); n != 9090 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 9090)
+	}
+	if err := w.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestWatchMissingFile(t *testing.T) {
+	if _, err := Watch(filepath.Join(t.TempDir(), "missing.cfg"), nil); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+}
+
+func TestWatchReloadBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.cfg")
+	writeFile := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("cannot write file: %s", err)
+		}
+	}
+	writeFile(`db = {host = "localhost"; port = 5432;}; port = 8080;`)
+
+	var reloads, slowReloads []ReloadStats
+	w, err := Watch(path, &WatchOptions{
+		Interval:     time.Hour,
+		ReloadBudget: time.Nanosecond,
+		LazyFallback: true,
+		OnReload: func(stats ReloadStats) {
+			reloads = append(reloads, stats)
+		},
+		OnSlowReload: func(stats ReloadStats) {
+			slowReloads = append(slowReloads, stats)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer w.Stop()
+
+	if len(reloads) != 1 || len(slowReloads) != 1 {
+		t.Fatalf("unexpected number of reloads; got %d, %d; want 1, 1", len(reloads), len(slowReloads))
+	}
+	if stats := reloads[0]; stats.Lazy || stats.Total <= 0 || stats.Err != nil {
+		t.Fatalf("unexpected stats for the initial load: %+v", stats)
+	}
+
+	// The first differential reload parses all the entries.
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stats := reloads[1]; !stats.Lazy || stats.Reused != 0 {
+		t.Fatalf("unexpected stats for the first differential reload: %+v", stats)
+	}
+	db := w.Load().Get("db")
+
+	// Unchanged subtrees must be re-used.
+	writeFile(`db = {host = "localhost"; port = 5432;}; port = 9090;`)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stats := reloads[2]; !stats.Lazy || stats.Reused != 1 {
+		t.Fatalf("unexpected stats for the second differential reload: %+v", stats)
+	}
+	v := w.Load()
+	if !v.IsFrozen() {
+		t.Fatalf("the snapshot must be frozen")
+	}
+	if v.Get("db") != db {
+		t.Fatalf("unchanged subtree must be re-used")
+	}
+	if s := v.String(); s != `{"db":{"host":"localhost","port":5432},"port":9090}` {
+		t.Fatalf("unexpected snapshot; got %s", s)
+	}
+
+	// Syntax errors must keep the old snapshot.
+	writeFile(`db = {host = ; port = 5432;}; port = 9090;`)
+	if err := w.Reload(); err == nil {
+		t.Fatalf("expecting non-nil error")
+	}
+	if stats := reloads[3]; stats.Err == nil {
+		t.Fatalf("expecting non-nil error in stats")
+	}
+	if w.Load() != v {
+		t.Fatalf("the previous snapshot must remain active")
+	}
+
+	// Rollback must reset the re-used entries.
+	writeFile(`db = {host = "localhost"; port = 5433;}; port = 9090;`)
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !w.Rollback() {
+		t.Fatalf("expecting successful rollback")
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if stats := reloads[len(reloads)-1]; stats.Reused != 0 {
+		t.Fatalf("unexpected stats after rollback: %+v", stats)
+	}
+	if n := w.Load().GetInt("db", "port"); n != 5433 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 5433)
+	}
+}
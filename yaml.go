@@ -0,0 +1,1179 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Aliaksandr Valialkin
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ * Author: Aliaksandr Valialkin <valyala@gmail.com>
+ */
+package libconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseYAML parses s containing YAML document.
+//
+// The following safe subset of YAML 1.2 is supported: block and flow
+// mappings and sequences, plain, quoted and block scalars, anchors, aliases
+// and << merge keys. Scalar types are inferred according to the YAML 1.2
+// core schema. Only !!str, !!int, !!float, !!bool, !!null, !!map and !!seq
+// tags are accepted, and only !!str affects the result by disabling type
+// inference. Multiple documents in a single stream aren't supported.
+//
+// Aliases are expanded into copies of the anchored nodes, so modifying
+// the alias doesn't affect the anchored node. The total number of values
+// created by alias expansion is limited to 1M in order to prevent
+// billion laughs attacks.
+//
+// The returned value is valid until the next call to Parse*.
+func (p *Parser) ParseYAML(s string) (*Value, error) {
+	s, err := decodeInput(s, p.Config.TranscodeInput)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse YAML: %s", err)
+	}
+	p.b = append(p.b[:0], s...)
+	p.c.reset()
+	p.c.cfg = &p.Config
+
+	yp := &yamlParser{
+		c:       &p.c,
+		anchors: make(map[string]*Value),
+	}
+	v, err := yp.parse(b2s(p.b))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse YAML: %s", err)
+	}
+	return v, nil
+}
+
+// ParseYAMLBytes parses b containing YAML document.
+//
+// See Parser.ParseYAML for details.
+func (p *Parser) ParseYAMLBytes(b []byte) (*Value, error) {
+	return p.ParseYAML(b2s(b))
+}
+
+// ParseYAML parses s containing YAML document.
+//
+// The function is slower than the Parser.ParseYAML for re-used Parser.
+func ParseYAML(s string) (*Value, error) {
+	var p Parser
+	return p.ParseYAML(s)
+}
+
+// ParseYAMLBytes parses b containing YAML document.
+//
+// The function is slower than the Parser.ParseYAMLBytes for re-used Parser.
+func ParseYAMLBytes(b []byte) (*Value, error) {
+	var p Parser
+	return p.ParseYAMLBytes(b)
+}
+
+type yamlLine struct {
+	// num is 1-based line number.
+	num int
+
+	// indent is the number of leading spaces.
+	indent int
+
+	// text is the line contents without indentation and comments.
+	// It is empty for blank and comment-only lines.
+	text string
+
+	// raw is the original line contents.
+	raw string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+
+	// i is the index of the current line.
+	i int
+
+	c *cache
+
+	anchors map[string]*Value
+
+	// aliasValues is the number of values created by alias expansion.
+	aliasValues int
+
+	// depth is the nesting depth of the currently parsed flow collections.
+	depth int
+}
+
+// maxYAMLAliasValues is the maximum number of values, which may be created
+// by alias expansion in a single document.
+const maxYAMLAliasValues = 1 << 20
+
+func (yp *yamlParser) parse(s string) (*Value, error) {
+	if err := yp.splitLines(s); err != nil {
+		return nil, err
+	}
+	l := yp.peek()
+	if l == nil {
+		return valueNull, nil
+	}
+	v, err := yp.parseNode(l.indent)
+	if err != nil {
+		return nil, err
+	}
+	if l := yp.peek(); l != nil {
+		return nil, yp.errorf(l, "unexpected data: %q", startEndString(l.text))
+	}
+	return v, nil
+}
+
+func (yp *yamlParser) splitLines(s string) error {
+	started := false
+	for n, raw := range strings.Split(s, "\n") {
+		raw = strings.TrimSuffix(raw, "\r")
+		l := yamlLine{
+			num: n + 1,
+			raw: raw,
+		}
+		for l.indent < len(raw) && raw[l.indent] == ' ' {
+			l.indent++
+		}
+		text := strings.TrimRight(stripYAMLComment(raw[l.indent:]), " \t")
+		if strings.HasPrefix(text, "\t") {
+			return yp.errorf(&l, "tabs cannot be used for indentation")
+		}
+
+		// Handle directives and document markers.
+		if l.indent == 0 {
+			if !started && strings.HasPrefix(text, "%") {
+				text = ""
+			}
+			if text == "---" || strings.HasPrefix(text, "--- ") {
+				if started {
+					return yp.errorf(&l, "multiple documents aren't supported")
+				}
+				text = strings.TrimSpace(text[len("---"):])
+			}
+			if text == "..." {
+				break
+			}
+		}
+		if text != "" {
+			started = true
+		}
+		l.text = text
+		yp.lines = append(yp.lines, l)
+	}
+	return nil
+}
+
+// stripYAMLComment removes comment from s.
+//
+// Comments start with '#' preceded by whitespace outside quoted scalars.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote == '"':
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				quote = 0
+			}
+		case quote == '\'':
+			if ch == '\'' {
+				quote = 0
+			}
+		case ch == '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return s[:i]
+			}
+		case ch == '"' || ch == '\'':
+			// Quotes start quoted scalars only at token boundaries.
+			if i == 0 || strings.IndexByte(" \t:-,[{", s[i-1]) >= 0 {
+				quote = ch
+			}
+		}
+	}
+	return s
+}
+
+func (yp *yamlParser) errorf(l *yamlLine, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", l.num, fmt.Sprintf(format, args...))
+}
+
+// peek returns the current non-blank line. nil is returned at the end.
+func (yp *yamlParser) peek() *yamlLine {
+	for yp.i < len(yp.lines) {
+		l := &yp.lines[yp.i]
+		if l.text != "" {
+			return l
+		}
+		yp.i++
+	}
+	return nil
+}
+
+func (yp *yamlParser) newObject() *Value {
+	v := yp.c.getValue()
+	v.t = TypeObject
+	v.o.reset()
+	v.o.keysUnescaped = true
+	return v
+}
+
+func (yp *yamlParser) newArray() *Value {
+	v := yp.c.getValue()
+	v.t = TypeArray
+	v.a = v.a[:0]
+	return v
+}
+
+func (yp *yamlParser) newString(s string) *Value {
+	v := yp.c.getValue()
+	v.t = TypeString
+	v.s = s
+	return v
+}
+
+// copyAlias returns a deep copy of the anchored node v.
+func (yp *yamlParser) copyAlias(v *Value) (*Value, error) {
+	yp.aliasValues++
+	if yp.aliasValues > maxYAMLAliasValues {
+		return nil, fmt.Errorf("too many values created by alias expansion; they exceed %d", maxYAMLAliasValues)
+	}
+	switch v.t {
+	case TypeObject:
+		o := yp.newObject()
+		for _, kv := range v.o.kvs {
+			x, err := yp.copyAlias(kv.v)
+			if err != nil {
+				return nil, err
+			}
+			appendObjectKV(&o.o, kv.k, x)
+		}
+		return o, nil
+	case TypeArray:
+		a := yp.newArray()
+		for _, item := range v.a {
+			x, err := yp.copyAlias(item)
+			if err != nil {
+				return nil, err
+			}
+			a.a = append(a.a, x)
+		}
+		return a, nil
+	case TypeTrue, TypeFalse, TypeNull:
+		return v, nil
+	default:
+		x := yp.c.getValue()
+		x.t = v.t
+		x.s = v.s
+		return x, nil
+	}
+}
+
+// parseNode parses block node starting at the current line with the given indent.
+func (yp *yamlParser) parseNode(indent int) (*Value, error) {
+	l := yp.peek()
+	if l == nil || l.indent < indent {
+		return valueNull, nil
+	}
+	if isYAMLSeqItem(l.text) {
+		return yp.parseSequence(l.indent)
+	}
+	if _, _, ok, err := yp.splitMapEntry(l); err != nil {
+		return nil, err
+	} else if ok {
+		return yp.parseMapping(l.indent)
+	}
+	rest, anchor, tag, err := yp.parseProperties(l, l.text)
+	if err != nil {
+		return nil, err
+	}
+	if rest == "" {
+		yp.i++
+		v, err := yp.parseNode(indent + 1)
+		if err != nil {
+			return nil, err
+		}
+		return yp.finishNode(l, v, anchor, tag)
+	}
+	v, err := yp.parseInline(l, rest, tag)
+	if err != nil {
+		return nil, err
+	}
+	return yp.finishNode(l, v, anchor, tag)
+}
+
+func isYAMLSeqItem(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+func (yp *yamlParser) parseSequence(indent int) (*Value, error) {
+	a := yp.newArray()
+	for {
+		l := yp.peek()
+		if l == nil || l.indent < indent {
+			return a, nil
+		}
+		if l.indent > indent {
+			return nil, yp.errorf(l, "unexpected indentation")
+		}
+		if !isYAMLSeqItem(l.text) {
+			if _, _, ok, _ := yp.splitMapEntry(l); ok {
+				// The sequence is the value of mapping entry at the same indent.
+				return a, nil
+			}
+			return nil, yp.errorf(l, "expecting sequence item; got %q", startEndString(l.text))
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		contentIndent := l.indent + len(l.text) - len(rest)
+		v, err := yp.parseEntryValue(l, rest, indent, contentIndent, true)
+		if err != nil {
+			return nil, err
+		}
+		a.a = append(a.a, v)
+		if err := yp.c.cfg.checkArrayLen(len(a.a)); err != nil {
+			return nil, yp.errorf(l, "%s", err)
+		}
+	}
+}
+
+func (yp *yamlParser) parseMapping(indent int) (*Value, error) {
+	o := yp.newObject()
+	var merges []*Value
+	for {
+		l := yp.peek()
+		if l == nil || l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, yp.errorf(l, "unexpected indentation")
+		}
+		key, rest, ok, err := yp.splitMapEntry(l)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, yp.errorf(l, "expecting mapping key; got %q", startEndString(l.text))
+		}
+		v, err := yp.parseEntryValue(l, rest, indent, indent+1, false)
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			merges = append(merges, v)
+			continue
+		}
+		if o.o.Get(key) != nil {
+			return nil, yp.errorf(l, "duplicate key %q", key)
+		}
+		appendObjectKV(&o.o, key, v)
+	}
+
+	// Explicit keys take precedence over merged keys.
+	for _, m := range merges {
+		sources := []*Value{m}
+		if m.t == TypeArray {
+			sources = m.a
+		}
+		for _, src := range sources {
+			if src.t != TypeObject {
+				return nil, fmt.Errorf("merge key << must refer to mapping or sequence of mappings; got %s", src.t)
+			}
+			for _, kv := range src.o.kvs {
+				if o.o.Get(kv.k) == nil {
+					appendObjectKV(&o.o, kv.k, kv.v)
+				}
+			}
+		}
+	}
+	return o, nil
+}
+
+// parseEntryValue parses the value for sequence item or mapping entry
+// located at the line l.
+//
+// rest is the text following "- " or "key:". indent is the indent
+// of the sequence or mapping, while contentIndent is the indent of rest.
+func (yp *yamlParser) parseEntryValue(l *yamlLine, rest string, indent, contentIndent int, inSequence bool) (*Value, error) {
+	rest, anchor, tag, err := yp.parseProperties(l, rest)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *Value
+	switch {
+	case rest == "":
+		yp.i++
+		next := yp.peek()
+		switch {
+		case next == nil:
+			v = valueNull
+		case next.indent > indent:
+			v, err = yp.parseNode(next.indent)
+		case !inSequence && next.indent == indent && isYAMLSeqItem(next.text):
+			// Sequences may be indented at the same level as the parent mapping key.
+			v, err = yp.parseSequence(indent)
+		default:
+			v = valueNull
+		}
+	case rest[0] == '|' || rest[0] == '>':
+		yp.i++
+		var s string
+		s, err = yp.parseBlockScalar(l, rest, indent)
+		v = yp.newString(s)
+	case inSequence && anchor == "" && tag == "" && (isYAMLSeqItem(rest) || yp.isMapEntry(l, rest)):
+		// Compact nested collection, e.g. "- key: value" or "- - item".
+		// Replace the current line with its contents and parse it as a block node.
+		yp.lines[yp.i] = yamlLine{
+			num:    l.num,
+			indent: contentIndent,
+			text:   rest,
+			raw:    l.raw,
+		}
+		v, err = yp.parseNode(contentIndent)
+	default:
+		v, err = yp.parseInline(l, rest, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return yp.finishNode(l, v, anchor, tag)
+}
+
+func (yp *yamlParser) isMapEntry(l *yamlLine, s string) bool {
+	tmp := *l
+	tmp.text = s
+	_, _, ok, _ := yp.splitMapEntry(&tmp)
+	return ok
+}
+
+// finishNode registers anchor for v and verifies tag.
+func (yp *yamlParser) finishNode(l *yamlLine, v *Value, anchor, tag string) (*Value, error) {
+	switch tag {
+	case "", "!!str":
+	case "!!int", "!!float":
+		if v.t != TypeNumber {
+			return nil, yp.errorf(l, "%s value must be number; got %s", tag, v.t)
+		}
+	case "!!bool":
+		if v.t != TypeTrue && v.t != TypeFalse {
+			return nil, yp.errorf(l, "!!bool value must be bool; got %s", v.t)
+		}
+	case "!!null":
+		if v.t != TypeNull {
+			return nil, yp.errorf(l, "!!null value must be null; got %s", v.t)
+		}
+	case "!!map":
+		if v.t != TypeObject {
+			return nil, yp.errorf(l, "!!map value must be mapping; got %s", v.t)
+		}
+	case "!!seq":
+		if v.t != TypeArray {
+			return nil, yp.errorf(l, "!!seq value must be sequence; got %s", v.t)
+		}
+	}
+	if anchor != "" {
+		yp.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// parseProperties parses optional anchor and tag at the start of s.
+func (yp *yamlParser) parseProperties(l *yamlLine, s string) (string, string, string, error) {
+	var anchor, tag string
+	for len(s) > 0 && (s[0] == '&' || s[0] == '!') {
+		n := strings.IndexAny(s, " \t")
+		if n < 0 {
+			n = len(s)
+		}
+		prop := s[:n]
+		s = strings.TrimLeft(s[n:], " \t")
+		if prop[0] == '&' {
+			if len(prop) == 1 {
+				return "", "", "", yp.errorf(l, "missing anchor name")
+			}
+			anchor = prop[1:]
+			continue
+		}
+		switch prop {
+		case "!!str", "!!int", "!!float", "!!bool", "!!null", "!!map", "!!seq":
+			tag = prop
+		default:
+			return "", "", "", yp.errorf(l, "unsupported tag %q", prop)
+		}
+	}
+	return s, anchor, tag, nil
+}
+
+// splitMapEntry splits l.text into mapping key and value.
+//
+// ok is false if l.text isn't mapping entry.
+func (yp *yamlParser) splitMapEntry(l *yamlLine) (key, rest string, ok bool, err error) {
+	s := l.text
+	if s == "" || strings.IndexByte("[{&!*|>", s[0]) >= 0 {
+		return "", "", false, nil
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		key, tail, err := parseYAMLQuoted(s)
+		if err != nil {
+			// Not a mapping entry; the error is reported when parsing the scalar.
+			return "", "", false, nil
+		}
+		tail = strings.TrimLeft(tail, " ")
+		if tail == ":" || strings.HasPrefix(tail, ": ") {
+			return key, strings.TrimLeft(tail[1:], " "), true, nil
+		}
+		return "", "", false, nil
+	}
+	n := strings.Index(s, ": ")
+	if n < 0 {
+		if !strings.HasSuffix(s, ":") {
+			return "", "", false, nil
+		}
+		n = len(s) - 1
+	}
+	key = strings.TrimRight(s[:n], " ")
+	if key == "?" || strings.HasPrefix(key, "? ") {
+		return "", "", false, yp.errorf(l, "complex mapping keys aren't supported")
+	}
+	return key, strings.TrimLeft(s[n+1:], " "), true, nil
+}
+
+// parseInline parses scalar, alias or flow collection in s located at the line l.
+//
+// Flow collections may span multiple lines.
+func (yp *yamlParser) parseInline(l *yamlLine, s, tag string) (*Value, error) {
+	if s[0] == '[' || s[0] == '{' {
+		// Join lines until the flow collection is closed.
+		for !isYAMLFlowClosed(s) {
+			yp.i++
+			if yp.i >= len(yp.lines) {
+				return nil, yp.errorf(l, "unclosed flow collection")
+			}
+			s += " " + yp.lines[yp.i].text
+		}
+	}
+	yp.i++
+
+	v, tail, err := yp.parseFlowNode(l, s, false, tag)
+	if err != nil {
+		return nil, err
+	}
+	if tail = strings.TrimLeft(tail, " \t"); tail != "" {
+		return nil, yp.errorf(l, "unexpected data after value: %q", startEndString(tail))
+	}
+	return v, nil
+}
+
+func isYAMLFlowClosed(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote == '"':
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				quote = 0
+			}
+		case quote == '\'':
+			if ch == '\'' {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[' || ch == '{':
+			depth++
+		case ch == ']' || ch == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// parseFlowNode parses a node at the start of s.
+//
+// Plain scalars end at flow indicators if inFlow is set. Otherwise they end
+// at the end of s.
+func (yp *yamlParser) parseFlowNode(l *yamlLine, s string, inFlow bool, tag string) (*Value, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	if len(s) == 0 {
+		return valueNull, s, nil
+	}
+	if inFlow {
+		var anchor string
+		var err error
+		if s, anchor, tag, err = yp.parseProperties(l, s); err != nil {
+			return nil, s, err
+		}
+		if anchor != "" || tag != "" {
+			v, tail, err := yp.parseFlowNode(l, s, inFlow, tag)
+			if err != nil {
+				return nil, tail, err
+			}
+			v, err = yp.finishNode(l, v, anchor, tag)
+			return v, tail, err
+		}
+	}
+	switch s[0] {
+	case '[':
+		return yp.parseFlowSequence(l, s[1:])
+	case '{':
+		return yp.parseFlowMapping(l, s[1:])
+	case '"', '\'':
+		str, tail, err := parseYAMLQuoted(s)
+		if err != nil {
+			return nil, tail, yp.errorf(l, "%s", err)
+		}
+		return yp.newString(str), tail, nil
+	case '*':
+		n := 1
+		for n < len(s) && strings.IndexByte(" \t,[]{}", s[n]) < 0 {
+			n++
+		}
+		name := s[1:n]
+		v := yp.anchors[name]
+		if v == nil {
+			return nil, s, yp.errorf(l, "unknown alias %q", name)
+		}
+		v, err := yp.copyAlias(v)
+		if err != nil {
+			return nil, s, yp.errorf(l, "cannot expand alias %q: %s", name, err)
+		}
+		return v, s[n:], nil
+	}
+	n := len(s)
+	if inFlow {
+		n = strings.IndexAny(s, ",]}")
+		if n < 0 {
+			n = len(s)
+		}
+	}
+	plain := strings.TrimRight(s[:n], " \t")
+	if tag == "!!str" {
+		return yp.newString(plain), s[n:], nil
+	}
+	return yp.inferScalar(plain), s[n:], nil
+}
+
+func (yp *yamlParser) parseFlowSequence(l *yamlLine, s string) (*Value, string, error) {
+	yp.depth++
+	defer func() {
+		yp.depth--
+	}()
+	if maxDepth := yp.c.cfg.maxDepth(); yp.depth > maxDepth {
+		return nil, s, yp.errorf(l, "too big depth for the nested YAML; it exceeds %d", maxDepth)
+	}
+	a := yp.newArray()
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 {
+			return nil, s, yp.errorf(l, "missing ']'")
+		}
+		if s[0] == ']' {
+			return a, s[1:], nil
+		}
+		v, tail, err := yp.parseFlowNode(l, s, true, "")
+		if err != nil {
+			return nil, tail, err
+		}
+		a.a = append(a.a, v)
+		s = strings.TrimLeft(tail, " \t")
+		if len(s) == 0 {
+			return nil, s, yp.errorf(l, "missing ']'")
+		}
+		switch s[0] {
+		case ',':
+			s = s[1:]
+		case ']':
+			return a, s[1:], nil
+		default:
+			return nil, s, yp.errorf(l, "missing ',' after sequence item")
+		}
+	}
+}
+
+func (yp *yamlParser) parseFlowMapping(l *yamlLine, s string) (*Value, string, error) {
+	yp.depth++
+	defer func() {
+		yp.depth--
+	}()
+	if maxDepth := yp.c.cfg.maxDepth(); yp.depth > maxDepth {
+		return nil, s, yp.errorf(l, "too big depth for the nested YAML; it exceeds %d", maxDepth)
+	}
+	o := yp.newObject()
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 {
+			return nil, s, yp.errorf(l, "missing '}'")
+		}
+		if s[0] == '}' {
+			return o, s[1:], nil
+		}
+
+		var key string
+		if s[0] == '"' || s[0] == '\'' {
+			var err error
+			key, s, err = parseYAMLQuoted(s)
+			if err != nil {
+				return nil, s, yp.errorf(l, "%s", err)
+			}
+		} else {
+			n := strings.IndexAny(s, ":,}")
+			if n < 0 {
+				return nil, s, yp.errorf(l, "missing ':' after mapping key")
+			}
+			key = strings.TrimRight(s[:n], " \t")
+			s = s[n:]
+		}
+		s = strings.TrimLeft(s, " \t")
+		var v *Value
+		if len(s) > 0 && s[0] == ':' {
+			var err error
+			v, s, err = yp.parseFlowNode(l, s[1:], true, "")
+			if err != nil {
+				return nil, s, err
+			}
+		} else {
+			// Keys without values have null values.
+			v = valueNull
+		}
+		if o.o.Get(key) != nil {
+			return nil, s, yp.errorf(l, "duplicate key %q", key)
+		}
+		appendObjectKV(&o.o, key, v)
+
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 {
+			return nil, s, yp.errorf(l, "missing '}'")
+		}
+		switch s[0] {
+		case ',':
+			s = s[1:]
+		case '}':
+			return o, s[1:], nil
+		default:
+			return nil, s, yp.errorf(l, "missing ',' after mapping value")
+		}
+	}
+}
+
+// parseYAMLQuoted parses single- or double-quoted scalar at the start of s.
+func parseYAMLQuoted(s string) (string, string, error) {
+	if s[0] == '\'' {
+		var b []byte
+		s = s[1:]
+		for {
+			n := strings.IndexByte(s, '\'')
+			if n < 0 {
+				return "", s, fmt.Errorf("missing closing \"'\"")
+			}
+			b = append(b, s[:n]...)
+			s = s[n+1:]
+			if !strings.HasPrefix(s, "'") {
+				return string(b), s, nil
+			}
+			// '' is escaped single quote.
+			b = append(b, '\'')
+			s = s[1:]
+		}
+	}
+
+	var b []byte
+	s = s[1:]
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch == '"' {
+			return string(b), s[i+1:], nil
+		}
+		if ch != '\\' {
+			b = append(b, ch)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
+		switch s[i] {
+		case '0':
+			b = append(b, 0)
+		case 'a':
+			b = append(b, '\a')
+		case 'b':
+			b = append(b, '\b')
+		case 't', '\t':
+			b = append(b, '\t')
+		case 'n':
+			b = append(b, '\n')
+		case 'v':
+			b = append(b, '\v')
+		case 'f':
+			b = append(b, '\f')
+		case 'r':
+			b = append(b, '\r')
+		case 'e':
+			b = append(b, 0x1B)
+		case ' ', '"', '/', '\\':
+			b = append(b, s[i])
+		case 'N':
+			b = append(b, "\u0085"...)
+		case '_':
+			b = append(b, "\u00a0"...)
+		case 'L':
+			b = append(b, "\u2028"...)
+		case 'P':
+			b = append(b, "\u2029"...)
+		case 'x', 'u', 'U':
+			n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+			if i+n >= len(s) {
+				return "", s, fmt.Errorf("too short escape sequence")
+			}
+			x, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(x)) {
+				return "", s, fmt.Errorf("invalid escape sequence %q", s[i-1:i+1+n])
+			}
+			b = utf8.AppendRune(b, rune(x))
+			i += n
+		default:
+			return "", s, fmt.Errorf("unknown escape sequence \\%c", s[i])
+		}
+	}
+	return "", s, fmt.Errorf(`missing closing '"'`)
+}
+
+// parseBlockScalar parses literal (|) or folded (>) block scalar
+// starting at the current line.
+func (yp *yamlParser) parseBlockScalar(l *yamlLine, header string, indent int) (string, error) {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	contentIndent := 0
+	for _, ch := range []byte(header[1:]) {
+		switch {
+		case ch == '-' || ch == '+':
+			chomp = ch
+		case ch >= '1' && ch <= '9':
+			contentIndent = indent + int(ch-'0')
+		default:
+			return "", yp.errorf(l, "invalid block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	for yp.i < len(yp.lines) {
+		bl := &yp.lines[yp.i]
+		if strings.TrimSpace(bl.raw) == "" {
+			lines = append(lines, "")
+			yp.i++
+			continue
+		}
+		if contentIndent == 0 {
+			contentIndent = bl.indent
+		}
+		if bl.indent < contentIndent || bl.indent <= indent {
+			break
+		}
+		lines = append(lines, bl.raw[contentIndent:])
+		yp.i++
+	}
+
+	// Separate trailing empty lines, since they are subject to chomping.
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	body, trailing := lines[:n], len(lines)-n
+
+	var sb strings.Builder
+	for i, line := range body {
+		if i > 0 {
+			sb.WriteString(yamlLineBreak(body[i-1], line, folded))
+		}
+		sb.WriteString(line)
+	}
+	s := sb.String()
+	if len(body) == 0 {
+		if chomp == '+' {
+			return strings.Repeat("\n", trailing), nil
+		}
+		return "", nil
+	}
+	switch chomp {
+	case '-':
+		return s, nil
+	case '+':
+		return s + strings.Repeat("\n", trailing+1), nil
+	default:
+		return s + "\n", nil
+	}
+}
+
+// yamlLineBreak returns the replacement for the line break between prev
+// and line in block scalar.
+func yamlLineBreak(prev, line string, folded bool) string {
+	if !folded {
+		return "\n"
+	}
+	switch {
+	case prev == "":
+		return "\n"
+	case line == "":
+		// The line break before empty lines is dropped in folded scalars,
+		// while every empty line results in a line break.
+		return ""
+	case prev[0] == ' ' || line[0] == ' ':
+		// More indented lines aren't folded.
+		return "\n"
+	default:
+		return " "
+	}
+}
+
+// inferScalar returns Value for plain scalar s according to YAML 1.2 core schema.
+func (yp *yamlParser) inferScalar(s string) *Value {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return valueNull
+	case "true", "True", "TRUE":
+		return valueTrue
+	case "false", "False", "FALSE":
+		return valueFalse
+	}
+	if ns, ok := yamlNumber(s); ok {
+		v := yp.c.getValue()
+		v.t = TypeNumber
+		v.s = ns
+		return v
+	}
+	return yp.newString(s)
+}
+
+// yamlNumber converts YAML number to the number token understood by Value.
+func yamlNumber(s string) (string, bool) {
+	switch s {
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return "inf", true
+	case "-.inf", "-.Inf", "-.INF":
+		return "-inf", true
+	case ".nan", ".NaN", ".NAN":
+		return "nan", true
+	}
+	if strings.HasPrefix(s, "0x") {
+		if _, err := strconv.ParseUint(s[2:], 16, 64); err != nil {
+			return "", false
+		}
+		return s, true
+	}
+	if strings.HasPrefix(s, "0o") {
+		n, err := strconv.ParseUint(s[2:], 8, 64)
+		if err != nil {
+			return "", false
+		}
+		return strconv.FormatUint(n, 10), true
+	}
+	digits := strings.TrimLeft(s, "+-")
+	if digits == "" || strings.IndexByte("0123456789.", digits[0]) < 0 || strings.ContainsAny(digits, "_xob") {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", false
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		// Normalize ints with leading zeros and plus sign.
+		return strconv.FormatInt(n, 10), true
+	}
+	// Normalize floats such as .5 or 1., which aren't valid JSON numbers.
+	sign := s[:len(s)-len(digits)]
+	if sign == "+" {
+		sign = ""
+	}
+	if digits[0] == '.' {
+		digits = "0" + digits
+	}
+	digits = strings.Replace(digits, ".e", ".0e", 1)
+	digits = strings.Replace(digits, ".E", ".0E", 1)
+	if strings.HasSuffix(digits, ".") {
+		digits += "0"
+	}
+	return sign + digits, true
+}
+
+// MarshalYAMLTo appends YAML representation of v to dst and returns the result.
+//
+// Objects and arrays are written in block style, while empty objects
+// and arrays are written as {} and []. Strings are quoted only if they
+// would be read back as other types or contain special chars.
+func (v *Value) MarshalYAMLTo(dst []byte) []byte {
+	if isYAMLBlock(v) {
+		return appendYAMLBlock(dst, v, 0, false)
+	}
+	dst = appendYAMLScalar(dst, v)
+	return append(dst, '\n')
+}
+
+// isYAMLBlock returns true if v must be written in block style.
+func isYAMLBlock(v *Value) bool {
+	switch v.Type() {
+	case TypeObject:
+		return v.o.Len() > 0
+	case TypeArray:
+		return len(v.a) > 0
+	default:
+		return false
+	}
+}
+
+// appendYAMLBlock appends non-empty object or array v at the given indent.
+//
+// The indent for the first line must be already written if inline is set.
+func appendYAMLBlock(dst []byte, v *Value, indent int, inline bool) []byte {
+	if v.t == TypeArray {
+		for i, item := range v.a {
+			if i > 0 || !inline {
+				dst = appendIndent(dst, indent)
+			}
+			dst = append(dst, '-')
+			if isYAMLBlock(item) {
+				dst = append(dst, ' ')
+				dst = appendYAMLBlock(dst, item, indent+2, true)
+				continue
+			}
+			dst = append(dst, ' ')
+			dst = appendYAMLScalar(dst, item)
+			dst = append(dst, '\n')
+		}
+		return dst
+	}
+
+	v.o.unescapeKeys()
+	for i, kv := range v.o.kvs {
+		if i > 0 || !inline {
+			dst = appendIndent(dst, indent)
+		}
+		dst = appendYAMLString(dst, kv.k)
+		dst = append(dst, ':')
+		if isYAMLBlock(kv.v) {
+			dst = append(dst, '\n')
+			dst = appendYAMLBlock(dst, kv.v, indent+2, false)
+			continue
+		}
+		dst = append(dst, ' ')
+		dst = appendYAMLScalar(dst, kv.v)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
+func appendIndent(dst []byte, indent int) []byte {
+	for i := 0; i < indent; i++ {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
+
+// appendYAMLScalar appends scalar, empty object or empty array v.
+func appendYAMLScalar(dst []byte, v *Value) []byte {
+	switch v.Type() {
+	case TypeObject:
+		return append(dst, "{}"...)
+	case TypeArray:
+		return append(dst, "[]"...)
+	case TypeString:
+		return appendYAMLString(dst, v.s)
+	case TypeNumber:
+		return append(dst, formatYAMLNumber(v.s)...)
+	case TypeRawJSON:
+		// JSON is valid YAML flow node.
+		return append(dst, v.s...)
+	default:
+		return v.MarshalTo(dst)
+	}
+}
+
+func appendYAMLString(dst []byte, s string) []byte {
+	if !needsYAMLQuotes(s) {
+		return append(dst, s...)
+	}
+	// Go-quoted strings use a subset of YAML double-quoted escapes.
+	return strconv.AppendQuote(dst, s)
+}
+
+func needsYAMLQuotes(s string) bool {
+	if s == "" || s[0] == ' ' || s[len(s)-1] == ' ' || s[len(s)-1] == ':' {
+		return true
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`.~=<", s[0]) >= 0 {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		// yes, no, on, off, y and n are bools in YAML 1.1.
+		return true
+	}
+	if _, ok := yamlNumber(s); ok {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return true
+		}
+	}
+	return !utf8.ValidString(s)
+}
+
+// formatYAMLNumber converts number token to YAML number.
+func formatYAMLNumber(s string) string {
+	s = trimBigintSuffix(s)
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "+nan", "-nan":
+		return ".nan"
+	}
+	if strings.HasPrefix(s, "0X") {
+		return "0x" + s[2:]
+	}
+	return s
+}
+
+// trimBigintSuffix removes L suffix from big int number token.
+//
+// The suffix may follow hex numbers such as 0x1fL too.
+func trimBigintSuffix(s string) string {
+	if len(s) < 2 || (s[len(s)-1] != 'L' && s[len(s)-1] != 'l') {
+		return s
+	}
+	if isDigit(s[len(s)-2]) {
+		return s[:len(s)-1]
+	}
+	if hs := strings.TrimLeft(s, "+-"); len(hs) > 3 && isHexToken(hs) && isHexDigits(hs[2:len(hs)-1]) {
+		return s[:len(s)-1]
+	}
+	return s
+}
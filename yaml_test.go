@@ -0,0 +1,258 @@
+package libconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLSuccess(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v, err := ParseYAML(s)
+		if err != nil {
+			t.Fatalf("unexpected error when parsing %q: %s", s, err)
+		}
+		if result := v.String(); result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+	}
+
+	// Scalars
+	f(``, `null`)
+	f(`# comment`, `null`)
+	f(`foo`, `"foo"`)
+	f(`---
+123
+...
+ignored: data`, `123`)
+	f(`a: 1
+b: -2.5
+c: true
+d: False
+e: ~
+f:
+g: null
+h: 0x1F
+i: 0o17
+j: .inf
+k: -.Inf
+l: .nan
+m: +12
+n: 007
+o: .5
+p: 1.
+q: 1_000
+r: 12:30
+s: http://example.com:8080/path # comment
+t: "quoted # not comment"
+u: 'it''s'
+v: "esc\t\"\u00e9\x41"
+w: !!str 123
+x: 1e3`, `{"a":1,"b":-2.5,"c":true,"d":false,"e":null,"f":null,"g":null,"h":0x1F,"i":15,"j":inf,"k":-inf,"l":nan,"m":12,"n":7,"o":0.5,"p":1.0,"q":"1_000","r":"12:30","s":"http://example.com:8080/path","t":"quoted # not comment","u":"it's","v":"esc\t\"éA","w":"123","x":1e3}`)
+
+	// Nested block collections
+	f(`server:
+  host: localhost
+  ports:
+    - 80
+    - 443
+  tls:
+    enabled: yes
+list:
+- a
+- b
+`, `{"server":{"host":"localhost","ports":[80,443],"tls":{"enabled":"yes"}},"list":["a","b"]}`)
+
+	// Compact nested collections
+	f(`- name: a
+  value: 1
+- name: b
+- - x
+  - y
+-
+  nested: true
+- 
+`, `[{"name":"a","value":1},{"name":"b"},["x","y"],{"nested":true},null]`)
+
+	// Flow collections
+	f(`a: [1, "two", [3], {x: 1, "y": [z]}]
+b: {k: v, empty: , n}
+c: [
+  1,
+  2,
+]
+d: []
+e: {}`, `{"a":[1,"two",[3],{"x":1,"y":["z"]}],"b":{"k":"v","empty":null,"n":null},"c":[1,2],"d":[],"e":{}}`)
+
+	// Block scalars
+	f(`literal: |
+  line1
+   indented
+
+  line3
+folded: >
+  a
+  b
+
+  c
+strip: |-
+  x
+
+keep: |+
+  y
+
+last: end`, `{"literal":"line1\n indented\n\nline3\n","folded":"a b\nc\n","strip":"x","keep":"y\n\n","last":"end"}`)
+
+	// Anchors, aliases and merge keys
+	f(`base: &base
+  host: localhost
+  port: 80
+tags: &tags [a, b]
+dev:
+  <<: *base
+  port: 8080
+  tags: *tags
+multi:
+  <<: [*base, {extra: 1}]
+scalar: &s value
+copy: *s`, `{"base":{"host":"localhost","port":80},"tags":["a","b"],"dev":{"port":8080,"tags":["a","b"],"host":"localhost"},"multi":{"host":"localhost","port":80,"extra":1},"scalar":"value","copy":"value"}`)
+
+	// Quoted keys and tags
+	f(`"a b": 1
+'c:d': 2
+e: !!map
+  f: !!int 3`, `{"a b":1,"c:d":2,"e":{"f":3}}`)
+}
+
+func TestParseYAMLGet(t *testing.T) {
+	v, err := ParseYAMLBytes([]byte(`
+server:
+  port: 8080
+  hosts: [a, b]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n := v.GetInt("server", "port"); n != 8080 {
+		t.Fatalf("unexpected port; got %d; want %d", n, 8080)
+	}
+	if s := string(v.GetStringBytes("server", "hosts", "1")); s != "b" {
+		t.Fatalf("unexpected host; got %q; want %q", s, "b")
+	}
+}
+
+func TestParseYAMLError(t *testing.T) {
+	f := func(s string) {
+		t.Helper()
+		if _, err := ParseYAML(s); err == nil {
+			t.Fatalf("expecting non-nil error when parsing %q", s)
+		}
+	}
+
+	f("a: 1\n\tb: 2")
+	f("a: 1\nb: 2\n---\nc: 3")
+	f("a: 1\na: 2")
+	f("a:\n  b: 1\n   c: 2")
+	f("a: 1\n  b: 2")
+	f("a: *unknown")
+	f("a: !custom x")
+	f("a: !!int x")
+	f("a: [1, 2")
+	f("a: {b: 1")
+	f("a: [1, 2] x")
+	f(`a: "unclosed`)
+	f(`a: 'unclosed`)
+	f(`a: "\q"`)
+	f("- a\nb: 1")
+	f("? complex\n: value")
+	f("a: 1\n- b")
+	f("a: |x\n  foo")
+	f("<<: 1")
+
+	// Too deep flow collections
+	f("a: " + strings.Repeat("[", MaxDepth+1) + strings.Repeat("]", MaxDepth+1))
+	f("a: " + strings.Repeat("{b: ", MaxDepth+1) + "1" + strings.Repeat("}", MaxDepth+1))
+	f("a: " + strings.Repeat("[", 1e6))
+
+	// Billion laughs
+	laughs := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for i := 'b'; i <= 'i'; i++ {
+		laughs += fmt.Sprintf("%c: &%c [*%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c]\n", i, i, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1)
+	}
+	f(laughs)
+}
+
+func TestParseYAMLAliasCopy(t *testing.T) {
+	v, err := ParseYAML("a: &a\n  x: 1\nb: *a\nc: [*a]")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var a Arena
+	v.Get("b").Set("x", a.NewNumberInt(2))
+	v.Get("c", "0").Set("y", a.NewNumberInt(3))
+	result := v.String()
+	resultExpected := `{"a":{"x":1},"b":{"x":2},"c":[{"x":1,"y":3}]}`
+	if result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+}
+
+func TestMarshalYAMLTo(t *testing.T) {
+	f := func(s, resultExpected string) {
+		t.Helper()
+		v := MustParse(s)
+		result := string(v.MarshalYAMLTo(nil))
+		if result != resultExpected {
+			t.Fatalf("unexpected result for %q\ngot\n%s\nwant\n%s", s, result, resultExpected)
+		}
+
+		// Verify the result is read back to the same value.
+		v2, err := ParseYAML(result)
+		if err != nil {
+			t.Fatalf("cannot parse the marshaled YAML\n%s\nerror: %s", result, err)
+		}
+		if !EqualExcept(v, v2) {
+			t.Fatalf("unexpected value after round trip; got %s; want %s", v2, v)
+		}
+	}
+
+	f(``, "{}\n")
+	f(`name = "app"; port = 8080; ratio = 0.5; debug = true; hex = 0x1F; big = 9223372036854775807L;`,
+		"name: app\nport: 8080\nratio: 0.5\ndebug: true\nhex: 0x1F\nbig: 9223372036854775807\n")
+	f(`a = 0X1fL; b = 0xFFL;`, "a: 0x1f\nb: 0xFF\n")
+	f(`server = {host = "localhost"; tls = {enabled = false;};}; empty = {}; list = [];`,
+		"server:\n  host: localhost\n  tls:\n    enabled: false\nempty: {}\nlist: []\n")
+	f(`items = ( {name = "a"; tags = ["x", "y"];}, [1, 2], "s" );`,
+		"items:\n  - name: a\n    tags:\n      - x\n      - \"y\"\n  - - 1\n    - 2\n  - s\n")
+	f(`s = ["", "true", "yes", "123", "1.5", "null", "- item", "a: b", "a #b", "#c", " x", "line\nbreak", "tab\t", "é", "*ref", "x:"];`,
+		`s:
+  - ""
+  - "true"
+  - "yes"
+  - "123"
+  - "1.5"
+  - "null"
+  - "- item"
+  - "a: b"
+  - "a #b"
+  - "#c"
+  - " x"
+  - "line\nbreak"
+  - "tab\t"
+  - é
+  - "*ref"
+  - "x:"
+`)
+
+	// Keys
+	var a Arena
+	v := a.NewObject()
+	v.Set("key with: colon", a.NewNumberInt(1))
+	v.Set("true", a.NewNumberInt(2))
+	v.Set("plain key", a.NewNumberInt(3))
+	resultExpected := "\"key with: colon\": 1\n\"true\": 2\nplain key: 3\n"
+	if result := string(v.MarshalYAMLTo(nil)); result != resultExpected {
+		t.Fatalf("unexpected result\ngot\n%s\nwant\n%s", result, resultExpected)
+	}
+}